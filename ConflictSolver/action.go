@@ -5,10 +5,8 @@ import (
 	"strings"
 
 	gr "github.com/PlayerR9/LyneParser/Grammar"
-	ud "github.com/PlayerR9/MyGoLib/Units/Debugging"
 	uc "github.com/PlayerR9/MyGoLib/Units/common"
 	uterr "github.com/PlayerR9/MyGoLib/Utility/errors"
-	lls "github.com/PlayerR9/stack/stack"
 )
 
 // Actioner represents an action that the parser will take.
@@ -310,21 +308,27 @@ func NewActAccept[T gr.TokenTyper](rule *gr.Production[T]) *ActAccept[T] {
 	return ar
 }
 
-// Match matches the action with the top of the stack.
+// MatchAction matches the action with the stack, starting from its top.
 //
 // Parameters:
 //   - a: The action to match.
-//   - top: The top of the stack.
-//   - stack: The stack.
+//   - stack: The stack. It is never modified.
 //
 // Returns:
-//   - error: An error if the action does not match the top of the stack.
-func MatchAction[T gr.TokenTyper](a Actioner[T], top *gr.Token[T], stack *ud.History[lls.Stacker[*gr.Token[T]]]) error {
+//   - error: An error if the action does not match the stack.
+//
+// Behaviors:
+//   - The right-hand side of the action starts with the symbol at the
+//     position of its item (see minimum_unique). Thus, its first symbol is
+//     matched against the top of the stack itself and the next ones against
+//     the tokens below it.
+func MatchAction[T gr.TokenTyper](a Actioner[T], stack *gr.TokenStack[T]) error {
 	if a == nil {
 		return uc.NewErrNilParameter("a")
-	} else if top == nil {
-		return uc.NewErrNilParameter("top")
-	} else if stack == nil {
+	}
+
+	top, ok := stack.Peek()
+	if !ok {
 		return uc.NewErrNilParameter("stack")
 	}
 
@@ -339,6 +343,19 @@ func MatchAction[T gr.TokenTyper](a Actioner[T], top *gr.Token[T], stack *ud.His
 		}
 	}
 
+	return match_rhs(a, stack)
+}
+
+// match_rhs is a helper function that matches the right-hand side of the
+// action with the stack, starting from its top. The lookahead is ignored.
+//
+// Parameters:
+//   - a: The action to match.
+//   - stack: The stack. It is never modified.
+//
+// Returns:
+//   - error: An error if the right-hand side does not match the stack.
+func match_rhs[T gr.TokenTyper](a Actioner[T], stack *gr.TokenStack[T]) error {
 	iter := a.Iterator()
 
 	for {
@@ -347,17 +364,17 @@ func MatchAction[T gr.TokenTyper](a Actioner[T], top *gr.Token[T], stack *ud.His
 			break
 		}
 
-		cmd := lls.NewPop[*gr.Token[T]]()
-		err = stack.ExecuteCommand(cmd)
-		if err != nil {
+		elem, rest, ok := stack.Pop()
+		if !ok {
 			return uterr.NewErrUnexpected("", rhs.String())
 		}
-		top := cmd.Value()
 
-		id := top.GetID()
+		id := elem.GetID()
 		if id != rhs {
-			return uterr.NewErrUnexpected(top.GoString(), rhs.String())
+			return uterr.NewErrUnexpected(elem.GoString(), rhs.String())
 		}
+
+		stack = rest
 	}
 
 	return nil
@@ -0,0 +1,102 @@
+package ConflictSolver
+
+import (
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+type TestTokenType int
+
+const (
+	TtEof TestTokenType = iota
+	TtA
+	TtB
+	TtS
+)
+
+func (t TestTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"a",
+		"b",
+		"s",
+	}[t]
+}
+
+func (t TestTokenType) IsTerminal() bool {
+	return t != TtS
+}
+
+// new_test_stack pushes the tokens in order, so the last one is on top.
+func new_test_stack(ids ...TestTokenType) *gr.TokenStack[TestTokenType] {
+	var stack *gr.TokenStack[TestTokenType]
+
+	for i, id := range ids {
+		stack = stack.Push(gr.NewToken(id, id.String(), i, nil))
+	}
+
+	return stack
+}
+
+func TestMatchAction(t *testing.T) {
+	// The stack is "a b", with b on top.
+	stack := new_test_stack(TtA, TtB)
+
+	tests := []struct {
+		rhs  []TestTokenType
+		want bool
+	}{
+		{[]TestTokenType{TtB}, true},
+		{[]TestTokenType{TtB, TtA}, true},
+		{[]TestTokenType{TtA}, false},
+		{[]TestTokenType{TtB, TtB}, false},
+		{[]TestTokenType{TtB, TtA, TtA}, false},
+	}
+
+	for _, test := range tests {
+		act := NewActShift[TestTokenType]()
+
+		for _, symbol := range test.rhs {
+			act.AppendRhs(symbol)
+		}
+
+		err := MatchAction[TestTokenType](act, stack)
+		if (err == nil) != test.want {
+			t.Errorf("%v: expected a match to be %t, got %v", test.rhs, test.want, err)
+		}
+	}
+
+	if stack.Size() != 2 {
+		t.Errorf("expected the stack to be unchanged, got %d tokens", stack.Size())
+	}
+
+	err := MatchAction[TestTokenType](NewActShift[TestTokenType](), nil)
+	if err == nil {
+		t.Errorf("expected an error on an empty stack")
+	}
+}
+
+func TestMatchActionLookahead(t *testing.T) {
+	eof := gr.NewToken(TtEof, "", 1, nil)
+
+	stack := new_test_stack().Push(gr.NewToken(TtA, "a", 0, eof))
+
+	la := TtEof
+
+	act := NewActReduce(gr.NewProduction(TtS, []TestTokenType{TtA}))
+	act.AppendRhs(TtA)
+	act.SetLookahead(&la)
+
+	err := MatchAction[TestTokenType](act, stack)
+	if err != nil {
+		t.Errorf("expected a match, got %s", err.Error())
+	}
+
+	la = TtB
+
+	err = MatchAction[TestTokenType](act, stack)
+	if err == nil {
+		t.Errorf("expected the lookahead not to match")
+	}
+}
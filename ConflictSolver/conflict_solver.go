@@ -3,25 +3,16 @@ package ConflictSolver
 import (
 	"errors"
 	"fmt"
-	"log"
-	"os"
 	"slices"
 	"strconv"
 
 	gr "github.com/PlayerR9/LyneParser/Grammar"
+	tc "github.com/PlayerR9/LyneParser/Tracer"
 	ffs "github.com/PlayerR9/MyGoLib/Formatting/FString"
-	ud "github.com/PlayerR9/MyGoLib/Units/Debugging"
 	uc "github.com/PlayerR9/MyGoLib/Units/common"
 	us "github.com/PlayerR9/MyGoLib/Units/slice"
-	lls "github.com/PlayerR9/stack/stack"
 )
 
-var (
-	debugger *log.Logger = log.New(os.Stdout, "DEBUG: ", log.Lshortfile)
-)
-
-var GlobalDebugMode bool = true
-
 // ConflictSolver solves conflicts in a decision table.
 type ConflictSolver[T gr.TokenTyper] struct {
 	// table is a map of elements in the decision table.
@@ -29,6 +20,12 @@ type ConflictSolver[T gr.TokenTyper] struct {
 
 	// rt is the rule table.
 	rt *RuleTable[T]
+
+	// hook is the hook that receives the events of the solver. May be nil.
+	hook tc.Hooker
+
+	// resolved are the events of the conflicts that were resolved, in order.
+	resolved []*tc.ConflictResolvedEvent
 }
 
 // FString returns a formatted string representation of the decision table
@@ -102,6 +99,48 @@ func NewConflictSolver[T gr.TokenTyper](symbols []T, rules []*gr.Production[T])
 	return cs
 }
 
+// SetHook sets the hook that receives the events of the solver.
+//
+// Parameters:
+//   - hook: The hook to use. Nil disables the events.
+func (cs *ConflictSolver[T]) SetHook(hook tc.Hooker) {
+	cs.hook = hook
+}
+
+// fire_resolved is a helper method that fires a conflict resolved event.
+//
+// Parameters:
+//   - conflicts: The helpers that were in conflict.
+//   - resolution: How the conflict was resolved.
+//
+// Behaviors:
+//   - The event is recorded even if no hook is set.
+func (cs *ConflictSolver[T]) fire_resolved(conflicts []*HelperNode[T], resolution string) {
+	rules := make([]string, 0, len(conflicts))
+
+	for _, c := range conflicts {
+		rules = append(rules, c.String())
+	}
+
+	event := &tc.ConflictResolvedEvent{
+		Rules:      rules,
+		Resolution: resolution,
+	}
+
+	cs.resolved = append(cs.resolved, event)
+
+	tc.Fire(cs.hook, event)
+}
+
+// GetResolved returns the events of the conflicts that were resolved so far,
+// whether a hook was set or not.
+//
+// Returns:
+//   - []*tc.ConflictResolvedEvent: The events, in the order of the resolutions.
+func (cs *ConflictSolver[T]) GetResolved() []*tc.ConflictResolvedEvent {
+	return cs.resolved
+}
+
 // getHelpers is a helper function that returns all helpers in the decision table.
 //
 // Returns:
@@ -116,6 +155,36 @@ func (cs *ConflictSolver[T]) getHelpers() []*HelperNode[T] {
 	return result
 }
 
+// GetTopSymbols returns the symbols that have elements in the decision
+// table; that is, the symbols that can be on top of the stack.
+//
+// Returns:
+//   - []T: The symbols, sorted.
+func (cs *ConflictSolver[T]) GetTopSymbols() []T {
+	symbols := make([]T, 0, len(cs.table))
+
+	for symbol := range cs.table {
+		symbols = append(symbols, symbol)
+	}
+
+	slices.Sort(symbols)
+
+	return symbols
+}
+
+// GetHelpersOf returns the elements of the decision table for a symbol on
+// top of the stack, in the order Match tries them.
+//
+// Parameters:
+//   - symbol: The symbol.
+//
+// Returns:
+//   - []*HelperNode: The elements. Nil if there are none. The slice is a
+//     copy but the elements are not, so they must not be modified.
+func (cs *ConflictSolver[T]) GetHelpersOf(symbol T) []*HelperNode[T] {
+	return slices.Clone(cs.table[symbol])
+}
+
 // GetElemsWithLhs is a method that returns all elements with a specific LHS.
 //
 // Parameters:
@@ -143,7 +212,7 @@ func (cs *ConflictSolver[T]) solveSetLookaheadOnShifts() {
 
 	for _, h := range helpers {
 		err := h.EvaluateLookahead()
-		uc.AssertF(err == nil, "failed to evaluate lookahead: %s", err.Error())
+		uc.AssertF(err == nil, "failed to evaluate lookahead: %s", err)
 	}
 }
 
@@ -207,6 +276,8 @@ func (cs *ConflictSolver[T]) SolveAmbiguousShifts() error {
 		if err != nil {
 			return err
 		}
+
+		cs.fire_resolved(bucket, "minimum unique prefix")
 	}
 
 	return nil
@@ -333,8 +404,10 @@ func (cs *ConflictSolver[T]) SolveAmbiguous(index int, conflicts []*HelperNode[T
 	// then we have to pick one of them.
 	// As of now, we will pick the first one.
 	for c, forest := range possible_lookaheads {
+		resolution := fmt.Sprintf("lookahead %s", forest[0])
+
 		if len(forest) > 1 {
-			debugger.Println("Found more than one possible lookaheads. Picking the first one.")
+			resolution += fmt.Sprintf(" (first of %d possible lookaheads)", len(forest))
 		}
 
 		new_rule := c.ReplaceRhsAt(index+1, forest[0])
@@ -353,6 +426,8 @@ func (cs *ConflictSolver[T]) SolveAmbiguous(index int, conflicts []*HelperNode[T
 
 			cs.table[key] = slice
 		}
+
+		cs.fire_resolved([]*HelperNode[T]{c}, resolution)
 	}
 
 	return true, nil
@@ -397,6 +472,8 @@ func (cs *ConflictSolver[T]) Solve() error {
 			if err != nil {
 				return err
 			}
+
+			cs.fire_resolved(conflicts, "minimum unique prefix")
 		}
 	}
 
@@ -406,75 +483,122 @@ func (cs *ConflictSolver[T]) Solve() error {
 // Match is a method that matches the top of the stack with the elements in the decision table.
 //
 // Parameters:
-//   - stack: The stack to match the elements with.
+//   - stack: The stack to match the elements with. It is never modified, so
+//     Match can be called concurrently on the same decision table.
 //
 // Returns:
 //   - []HelperElem: The elements that match the top of the stack.
 //   - error: An error if the operation failed.
-func (cs *ConflictSolver[T]) Match(stack *ud.History[lls.Stacker[*gr.Token[T]]]) ([]HelperElem[T], error) {
-	var top *gr.Token[T]
-	var ok bool
+func (cs *ConflictSolver[T]) Match(stack *gr.TokenStack[T]) ([]HelperElem[T], error) {
+	firsts, _, err := cs.MatchWithRejections(stack)
+	return firsts, err
+}
 
-	stack.ReadData(func(data lls.Stacker[*gr.Token[T]]) {
-		top, ok = data.Peek()
-	})
+// MatchWithRejections is like Match but it also returns the elements of the
+// decision table that were considered and did not match, together with the
+// reason they did not.
+//
+// Parameters:
+//   - stack: The stack to match the elements with. It is never modified.
+//
+// Returns:
+//   - []HelperElem: The elements that match the top of the stack.
+//   - []*Rejection: The elements that did not match, in table order.
+//   - error: An error if no element matched.
+//
+// Behaviors:
+//   - If no element matches, the error is the reason of the first rejection.
+func (cs *ConflictSolver[T]) MatchWithRejections(stack *gr.TokenStack[T]) ([]HelperElem[T], []*Rejection[T], error) {
+	top, ok := stack.Peek()
 	if !ok {
-		return nil, errors.New("no top token found")
+		return nil, nil, errors.New("no top token found")
 	}
 
 	id := top.GetID()
 
 	elems, ok := cs.table[id]
 	if !ok {
-		return nil, fmt.Errorf("no elems found for symbol %s", id)
+		return nil, nil, fmt.Errorf("no elems found for symbol %s", id)
 	}
 
-	f := func(h *HelperNode[T]) (*HelperNode[T], error) {
-		cmd := lls.NewPop[*gr.Token[T]]()
-		err := stack.ExecuteCommand(cmd)
-		if err != nil {
-			return nil, errors.New("no top token found")
-		}
-		top := cmd.Value()
+	// Get the longest match
+	// TO DO: Implement a better way to get the longest match.
+	// As of now, every match is considered the longest match.
+	var firsts []HelperElem[T]
+	var rejections []*Rejection[T]
 
-		err = h.Match(top, stack)
+	for _, h := range elems {
+		err := h.Match(stack)
 		if err != nil {
-			return nil, err
+			rejections = append(rejections, NewRejection(h.GetAction(), err))
+		} else {
+			firsts = append(firsts, h.GetAction())
 		}
-
-		return h, nil
 	}
 
-	slice := make([]*HelperNode[T], len(elems))
-	copy(slice, elems)
-
-	success_or_fail, ok := us.EvaluateSimpleHelpers(slice, f)
-	if !ok {
+	if len(firsts) == 0 && len(rejections) > 0 {
 		// Return the most likely error
 		// As of now, we will return the first error
-		data := success_or_fail[0].GetData()
-		err := data.Second
-
-		return nil, err
+		return nil, rejections, rejections[0].Reason
 	}
 
-	success := us.ExtractResults(success_or_fail)
+	return firsts, rejections, nil
+}
 
-	// Get the longest match
-	// TO DO: Implement a better way to get the longest match.
-	// As of now, every match is considered the longest match.
-	firsts := make([]HelperElem[T], 0, len(success))
+// MatchIgnoringLookahead is like Match but the lookahead of the top of the
+// stack is not checked. Thus, it returns every action that is possible in
+// the current state, whatever the next token is.
+//
+// Parameters:
+//   - stack: The stack. It is never modified.
+//
+// Returns:
+//   - []HelperElem: The elements whose right-hand side matches the stack.
+//     Nil if there are none.
+func (cs *ConflictSolver[T]) MatchIgnoringLookahead(stack *gr.TokenStack[T]) []HelperElem[T] {
+	top, ok := stack.Peek()
+	if !ok {
+		return nil
+	}
 
-	for _, final := range success {
-		act := final.GetAction()
-		firsts = append(firsts, act)
+	var elems []HelperElem[T]
+
+	for _, h := range cs.table[top.GetID()] {
+		act := h.GetAction()
+		if act != nil && match_rhs[T](act, stack) == nil {
+			elems = append(elems, act)
+		}
 	}
 
-	if len(success) == 1 {
-		return firsts, nil
+	return elems
+}
+
+// ExpectedLookaheads returns the lookaheads that the decision table would
+// have accepted for the stack. This is what a parser error reports as the
+// expected terminals.
+//
+// Parameters:
+//   - stack: The stack. It is never modified.
+//
+// Returns:
+//   - []T: The lookaheads of the elements whose right-hand side matches the
+//     stack, sorted and without duplicates. Nil if there are none.
+func (cs *ConflictSolver[T]) ExpectedLookaheads(stack *gr.TokenStack[T]) []T {
+	var expected []T
+
+	for _, act := range cs.MatchIgnoringLookahead(stack) {
+		la, ok := act.GetLookahead()
+		if !ok {
+			continue
+		}
+
+		pos, found := slices.BinarySearch(expected, la)
+		if !found {
+			expected = slices.Insert(expected, pos, la)
+		}
 	}
 
-	return firsts, nil
+	return expected
 }
 
 /*
@@ -25,3 +25,39 @@ type HelperElem[T gr.TokenTyper] interface {
 	fmt.Stringer
 	uc.Copier
 }
+
+// Rejection is an element of the decision table that was considered while
+// matching a stack and did not match.
+type Rejection[T gr.TokenTyper] struct {
+	// Action is the action of the element.
+	Action HelperElem[T]
+
+	// Reason is the reason the element did not match.
+	Reason error
+}
+
+// String implements the fmt.Stringer interface.
+//
+// Format:
+//
+//	<action>: <reason>
+func (r *Rejection[T]) String() string {
+	return fmt.Sprintf("%s: %s", r.Action.String(), r.Reason.Error())
+}
+
+// NewRejection creates a new rejection.
+//
+// Parameters:
+//   - action: The action of the element.
+//   - reason: The reason the element did not match.
+//
+// Returns:
+//   - *Rejection: A pointer to the new rejection.
+func NewRejection[T gr.TokenTyper](action HelperElem[T], reason error) *Rejection[T] {
+	r := &Rejection[T]{
+		Action: action,
+		Reason: reason,
+	}
+
+	return r
+}
@@ -165,7 +165,7 @@ func (et *ExpansionTree[T]) PruneNonTerminalLeaves() {
 
 	for _, leaf := range todo {
 		err := et.tree.DeleteBranchContaining(leaf)
-		uc.AssertF(err == nil, "unexpected error: %s", err.Error())
+		uc.AssertF(err == nil, "unexpected error: %s", err)
 	}
 }
 
@@ -193,7 +193,7 @@ func (et *ExpansionTree[T]) Collapse() []T {
 		uc.Assert(ok, "Must be a *HelperNode[T]")
 
 		rhs, err := tr.GetRhsAt(0)
-		uc.AssertF(err == nil, "unexpected error: %s", err.Error())
+		uc.AssertF(err == nil, "unexpected error: %s", err)
 
 		pos, ok := slices.BinarySearch(result, rhs)
 		if !ok {
@@ -4,9 +4,7 @@ import (
 	"fmt"
 
 	gr "github.com/PlayerR9/LyneParser/Grammar"
-	ud "github.com/PlayerR9/MyGoLib/Units/Debugging"
 	uc "github.com/PlayerR9/MyGoLib/Units/common"
-	lls "github.com/PlayerR9/stack/stack"
 )
 
 // SetAction sets the action of the helper.
@@ -28,9 +26,17 @@ func (h *HelperNode[T]) SetAction(action HelperElem[T]) {
 //
 // Returns:
 //   - error: An error if the evaluation failed.
+//
+// Behaviors:
+//   - The lookahead is the symbol after the position of the item, if it is
+//     a terminal. Thus, an item at the end of its rule has no lookahead.
 func (h *HelperNode[T]) EvaluateLookahead() error {
 	pos := h.Item.GetPos()
 
+	if pos+1 >= h.Item.Rule.Size() {
+		return nil
+	}
+
 	lookahead, err := h.Item.GetRhsAt(pos + 1)
 	if err != nil {
 		return fmt.Errorf("failed to evaluate lookahead: %w", err)
@@ -124,26 +130,18 @@ func (h *HelperNode[T]) SubstituteRhsAt(index int, other_helper *HelperNode[T])
 	return h_copy
 }
 
-// Match matches the top of the stack with the helper.
+// Match matches the stack with the helper.
 //
 // Parameters:
-//   - top: The top of the stack.
-//   - stack: The stack.
+//   - stack: The stack. It is never modified.
 //
 // Returns:
 //   - error: An error if the match failed.
-//
-// Behaviors:
-//   - The stack is refused.
-func (h *HelperNode[T]) Match(top *gr.Token[T], stack *ud.History[lls.Stacker[*gr.Token[T]]]) error {
+func (h *HelperNode[T]) Match(stack *gr.TokenStack[T]) error {
 	act, ok := h.Action.(Actioner[T])
 	uc.Assert(ok, "In Helper.Match: h.Action is not an Actioner")
 
-	err := MatchAction(act, top, stack)
-
-	// Refuse the stack
-	stack.Reject()
-
+	err := MatchAction(act, stack)
 	if err != nil {
 		return err
 	}
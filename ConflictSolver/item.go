@@ -135,7 +135,7 @@ func (item *Item[T]) GetRhsAt(index int) (T, error) {
 //   - T: The right-hand side of the production rule.
 func (item *Item[T]) GetRhs() T {
 	rhs, err := item.Rule.GetRhsAt(item.Pos)
-	uc.AssertF(err == nil, "GetRhs: %s", err.Error())
+	uc.AssertF(err == nil, "GetRhs: %s", err)
 
 	return rhs
 }
@@ -0,0 +1,30 @@
+package Generator
+
+// ErrUnknownRule is an error that is returned when the decision table
+// reduces by a rule that is not in the grammar.
+type ErrUnknownRule struct {
+	// Rule is the rule.
+	Rule string
+}
+
+// Error implements the error interface.
+//
+// Message: "rule <rule> of the decision table is not in the grammar"
+func (e *ErrUnknownRule) Error() string {
+	return "rule " + e.Rule + " of the decision table is not in the grammar"
+}
+
+// NewErrUnknownRule creates a new error of type *ErrUnknownRule.
+//
+// Parameters:
+//   - rule: The rule.
+//
+// Returns:
+//   - *ErrUnknownRule: The new error. Never nil.
+func NewErrUnknownRule(rule string) *ErrUnknownRule {
+	e := &ErrUnknownRule{
+		Rule: rule,
+	}
+
+	return e
+}
@@ -0,0 +1,643 @@
+package Generator
+
+import (
+	"go/format"
+	"slices"
+	"strconv"
+	"strings"
+
+	cs "github.com/PlayerR9/LyneParser/ConflictSolver"
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	ps "github.com/PlayerR9/LyneParser/Parser"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// Config is the configuration of a generated parser.
+type Config[T gr.TokenTyper] struct {
+	// Package is the name of the package of the generated file.
+	Package string
+
+	// TypeName is the name of the type of the parser. "Parser" if empty.
+	// The unexported helpers of the file are prefixed with its lower-case
+	// form, so that several parsers can live in the same package.
+	TypeName string
+
+	// TokenType is the Go type of the symbols, such as "tk.TokenType".
+	TokenType string
+
+	// Imports are the imports that the symbols need, as written in an
+	// import declaration, such as `tk "example.com/lang/tokens"`.
+	Imports []string
+
+	// SymbolName returns the Go expression of a symbol, such as
+	// "tk.TkNumber". Nil writes the symbols as conversions of their values,
+	// such as "tk.TokenType(3)".
+	SymbolName func(symbol T) string
+}
+
+// decision is an element of the decision table, as written in the
+// generated parser.
+type decision[T gr.TokenTyper] struct {
+	// item is the description of the element.
+	item string
+
+	// lookahead is the type of the lookahead. Nil if there is none.
+	lookahead *T
+
+	// rhs are the symbols of the stack, from its top down.
+	rhs []T
+
+	// kind is the name of the kind of the action: "shift", "reduce" or
+	// "accept".
+	kind string
+
+	// rule is the index of the rule to reduce by. Only valid for reductions
+	// and acceptances.
+	rule int
+}
+
+// generator is the state of the generation of a parser.
+type generator[T gr.TokenTyper] struct {
+	// cfg is the configuration.
+	cfg Config[T]
+
+	// rules are the rules of the grammar.
+	rules []*gr.Production[T]
+
+	// builder is the builder of the file.
+	builder strings.Builder
+}
+
+// Generate writes the source of a direct-coded parser of a grammar: one
+// switch on the symbol on top of the stack whose cases encode the
+// decisions of the table, and one switch on the rules that encodes the
+// reductions. Nothing is interpreted at runtime.
+//
+// Parameters:
+//   - grammar: The grammar.
+//   - table: The solved decision table of the grammar. Nil solves it like
+//     ps.NewParser does.
+//   - cfg: The configuration.
+//
+// Returns:
+//   - []byte: The formatted source of the file.
+//   - error: An error if the parser could not be generated.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If the grammar is nil or the configuration
+//     has no package or token type.
+//   - *gr.ErrNoProductionRulesFound: If the grammar has no rules.
+//   - *ErrUnknownRule: If the table reduces by a rule that is not in the
+//     grammar.
+//   - *ps.ErrUnknownAction: If the table has an action that is neither a
+//     shift, a reduction nor an acceptance.
+//   - any error of the conflict solver.
+//
+// Behaviors:
+//   - The generated parser makes the same decisions as ps.Parser with the
+//     same table. When several decisions match, they are tried in table
+//     order with backtracking, and the first accepted parse is returned
+//     instead of all of them. The same action is only tried once, even if
+//     several decisions lead to it.
+//   - Unlike ps.Parser, an acceptance that leaves other tokens on the stack
+//     is a failure of the branch, since the result is a single tree.
+func Generate[T gr.TokenTyper](grammar *ps.Grammar[T], table *cs.ConflictSolver[T], cfg Config[T]) ([]byte, error) {
+	if grammar == nil {
+		return nil, uc.NewErrNilParameter("grammar")
+	}
+
+	if cfg.Package == "" {
+		return nil, uc.NewErrInvalidParameter("cfg.Package", uc.NewErrEmpty(cfg.Package))
+	}
+
+	if cfg.TokenType == "" {
+		return nil, uc.NewErrInvalidParameter("cfg.TokenType", uc.NewErrEmpty(cfg.TokenType))
+	}
+
+	if cfg.TypeName == "" {
+		cfg.TypeName = "Parser"
+	}
+
+	rules := grammar.GetProductions()
+	if len(rules) == 0 {
+		return nil, gr.NewErrNoProductionRulesFound()
+	}
+
+	if table == nil {
+		var err error
+
+		table, err = cs.SolveConflicts(grammar.GetSymbols(), rules)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	g := &generator[T]{
+		cfg:   cfg,
+		rules: rules,
+	}
+
+	g.write_header()
+
+	err := g.write_decide(table)
+	if err != nil {
+		return nil, err
+	}
+
+	g.write_reduce()
+
+	g.builder.WriteString(g.expand(driver_template))
+
+	return format.Source([]byte(g.builder.String()))
+}
+
+// symbol is a helper method that returns the Go expression of a symbol.
+//
+// Parameters:
+//   - symbol: The symbol.
+//
+// Returns:
+//   - string: The expression.
+func (g *generator[T]) symbol(symbol T) string {
+	if g.cfg.SymbolName != nil {
+		return g.cfg.SymbolName(symbol)
+	}
+
+	return g.cfg.TokenType + "(" + strconv.Itoa(int(symbol)) + ")"
+}
+
+// expand is a helper method that fills the placeholders of a template:
+// {{Name}} is the type of the parser, {{p}} the prefix of its helpers and
+// {{T}} the type of the symbols.
+//
+// Parameters:
+//   - template: The template.
+//
+// Returns:
+//   - string: The filled template.
+func (g *generator[T]) expand(template string) string {
+	r := strings.NewReplacer(
+		"{{Name}}", g.cfg.TypeName,
+		"{{p}}", strings.ToLower(g.cfg.TypeName),
+		"{{T}}", g.cfg.TokenType,
+	)
+
+	return r.Replace(template)
+}
+
+// write_header is a helper method that writes the package clause, the
+// imports and the type of the parser.
+func (g *generator[T]) write_header() {
+	g.builder.WriteString("// Code generated by LyneParser/Generator. DO NOT EDIT.\n\n")
+	g.builder.WriteString("package " + g.cfg.Package + "\n\n")
+
+	g.builder.WriteString("import (\n\t\"errors\"\n\t\"fmt\"\n\t\"slices\"\n\n")
+	g.builder.WriteString("\tgr \"github.com/PlayerR9/LyneParser/Grammar\"\n")
+
+	for _, imp := range g.cfg.Imports {
+		g.builder.WriteString("\t" + imp + "\n")
+	}
+
+	g.builder.WriteString(")\n\n")
+
+	g.builder.WriteString(g.expand("// {{Name}} is a direct-coded parser of the grammar:\n//\n"))
+
+	for _, rule := range g.rules {
+		g.builder.WriteString("//\t" + rule.String() + "\n")
+	}
+
+	g.builder.WriteString(g.expand(header_template))
+}
+
+// decision_of is a helper method that converts an element of the decision
+// table.
+//
+// Parameters:
+//   - h: The element.
+//
+// Returns:
+//   - *decision: The decision.
+//   - error: An error if the action of the element cannot be generated.
+func (g *generator[T]) decision_of(h *cs.HelperNode[T]) (*decision[T], error) {
+	act := h.GetAction()
+
+	d := &decision[T]{
+		item: h.String(),
+	}
+
+	if la, ok := act.GetLookahead(); ok {
+		d.lookahead = &la
+	}
+
+	iter := act.Iterator()
+
+	for {
+		symbol, err := iter.Consume()
+		if err != nil {
+			break
+		}
+
+		d.rhs = append(d.rhs, symbol)
+	}
+
+	var rule *gr.Production[T]
+
+	switch act := act.(type) {
+	case *cs.ActShift[T]:
+		d.kind = "shift"
+
+		return d, nil
+	case *cs.ActReduce[T]:
+		d.kind = "reduce"
+		rule = act.Original
+	case *cs.ActAccept[T]:
+		d.kind = "accept"
+		rule = act.Original
+	default:
+		return nil, ps.NewErrUnknownAction(act)
+	}
+
+	d.rule = slices.IndexFunc(g.rules, func(r *gr.Production[T]) bool {
+		return r == rule || r.Equals(rule)
+	})
+	if d.rule == -1 {
+		return nil, NewErrUnknownRule(rule.String())
+	}
+
+	return d, nil
+}
+
+// write_decide is a helper method that writes the decide method: one case
+// per symbol on top of the stack, with one condition per element of the
+// table.
+//
+// Parameters:
+//   - table: The decision table.
+//
+// Returns:
+//   - error: An error if an element cannot be generated.
+func (g *generator[T]) write_decide(table *cs.ConflictSolver[T]) error {
+	g.builder.WriteString(g.expand(decide_template))
+
+	for _, top := range table.GetTopSymbols() {
+		g.builder.WriteString("\tcase " + g.symbol(top) + ":\n")
+
+		for _, h := range table.GetHelpersOf(top) {
+			d, err := g.decision_of(h)
+			if err != nil {
+				return err
+			}
+
+			g.write_decision(top, d)
+		}
+	}
+
+	g.builder.WriteString("\t}\n\n\treturn actions\n}\n\n")
+
+	return nil
+}
+
+// write_decision is a helper method that writes the condition and the
+// action of an element of the table.
+//
+// Parameters:
+//   - top: The symbol on top of the stack.
+//   - d: The element.
+func (g *generator[T]) write_decision(top T, d *decision[T]) {
+	var conds []string
+
+	if d.lookahead != nil {
+		conds = append(conds, "top.Lookahead != nil && top.Lookahead.ID == "+g.symbol(*d.lookahead))
+	}
+
+	if len(d.rhs) > 1 {
+		conds = append(conds, "len(stack) >= "+strconv.Itoa(len(d.rhs)))
+	}
+
+	for i, symbol := range d.rhs {
+		if i == 0 && symbol == top {
+			continue
+		}
+
+		conds = append(conds, "stack[len(stack)-"+strconv.Itoa(i+1)+"].ID == "+g.symbol(symbol))
+	}
+
+	action := g.expand("{{p}}_action{kind: {{p}}_" + d.kind)
+	if d.kind != "shift" {
+		action += ", rule: " + strconv.Itoa(d.rule)
+	}
+
+	action += "}"
+
+	g.builder.WriteString("\t\t// " + d.item + "\n")
+
+	if len(conds) == 0 {
+		g.builder.WriteString("\t\tactions = append(actions, " + action + ")\n")
+		return
+	}
+
+	g.builder.WriteString("\t\tif " + strings.Join(conds, " && ") + " {\n")
+	g.builder.WriteString("\t\t\tactions = append(actions, " + action + ")\n")
+	g.builder.WriteString("\t\t}\n")
+}
+
+// write_reduce is a helper method that writes the reduce method: one case
+// per rule of the grammar.
+func (g *generator[T]) write_reduce() {
+	g.builder.WriteString(g.expand(reduce_template))
+
+	for i, rule := range g.rules {
+		rhs := rule_symbols(rule)
+
+		g.builder.WriteString("\tcase " + strconv.Itoa(i) + ":\n")
+		g.builder.WriteString("\t\t// " + rule.String() + "\n")
+
+		if len(rhs) > 0 {
+			conds := []string{"len(stack) < " + strconv.Itoa(len(rhs))}
+
+			for j, symbol := range rhs {
+				conds = append(conds, "stack[len(stack)-"+strconv.Itoa(len(rhs)-j)+"].ID != "+g.symbol(symbol))
+			}
+
+			g.builder.WriteString("\t\tif " + strings.Join(conds, " || ") + " {\n")
+			g.builder.WriteString("\t\t\treturn nil, errors.New(" + strconv.Quote("cannot reduce by "+rule.String()) + ")\n")
+			g.builder.WriteString("\t\t}\n\n")
+		}
+
+		g.builder.WriteString("\t\tlhs, n = " + g.symbol(rule.GetLhs()) + ", " + strconv.Itoa(len(rhs)) + "\n")
+	}
+
+	g.builder.WriteString(g.expand(reduce_end_template))
+}
+
+// rule_symbols is a helper function that returns the right-hand side of a
+// rule.
+//
+// Parameters:
+//   - rule: The rule.
+//
+// Returns:
+//   - []T: The symbols, from left to right.
+func rule_symbols[T gr.TokenTyper](rule *gr.Production[T]) []T {
+	var symbols []T
+
+	iter := rule.Iterator()
+
+	for {
+		symbol, err := iter.Consume()
+		if err != nil {
+			break
+		}
+
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols
+}
+
+// header_template is the end of the documentation of the parser, its type
+// and the types of its actions.
+const header_template string = `type {{Name}} struct{}
+
+// New{{Name}} creates a new parser.
+//
+// Returns:
+//   - *{{Name}}: The new parser. Never nil.
+func New{{Name}}() *{{Name}} {
+	p := &{{Name}}{}
+	return p
+}
+
+// {{p}}_kind is the kind of an action of {{Name}}.
+type {{p}}_kind int
+
+const (
+	// {{p}}_shift pushes the next token onto the stack.
+	{{p}}_shift {{p}}_kind = iota
+
+	// {{p}}_reduce replaces the top of the stack by the left-hand side of a
+	// rule.
+	{{p}}_reduce
+
+	// {{p}}_accept reduces by a rule and ends the parse.
+	{{p}}_accept
+)
+
+// {{p}}_action is an action of {{Name}}.
+type {{p}}_action struct {
+	// kind is the kind of the action.
+	kind {{p}}_kind
+
+	// rule is the index of the rule to reduce by. Only valid for the
+	// reductions and the acceptances.
+	rule int
+}
+
+`
+
+// decide_template is the beginning of the decide method.
+const decide_template string = `// decide returns the actions that match the stack, in the order of the
+// decision table.
+//
+// Parameters:
+//   - stack: The stack. Never empty.
+//
+// Returns:
+//   - []{{p}}_action: The actions. Nil if there are none.
+func (p *{{Name}}) decide(stack []*gr.Token[{{T}}]) []{{p}}_action {
+	top := stack[len(stack)-1]
+
+	var actions []{{p}}_action
+
+	switch top.ID {
+`
+
+// reduce_template is the beginning of the reduce method.
+const reduce_template string = `// reduce replaces the top of the stack by the left-hand side of a rule.
+//
+// Parameters:
+//   - stack: The stack. It may be modified.
+//   - rule: The index of the rule.
+//
+// Returns:
+//   - []*gr.Token: The new stack.
+//   - error: An error if the top of the stack is not the right-hand side of
+//     the rule.
+func (p *{{Name}}) reduce(stack []*gr.Token[{{T}}], rule int) ([]*gr.Token[{{T}}], error) {
+	var lhs {{T}}
+	var n int
+
+	switch rule {
+`
+
+// reduce_end_template is the end of the reduce method.
+const reduce_end_template string = `	default:
+		return nil, fmt.Errorf("unknown rule %d", rule)
+	}
+
+	children := slices.Clone(stack[len(stack)-n:])
+
+	var lookahead *gr.Token[{{T}}]
+
+	for i := len(children) - 1; i >= 0 && lookahead == nil; i-- {
+		lookahead = children[i].Lookahead
+	}
+
+	var at int
+
+	if n > 0 {
+		at = children[0].At
+	}
+
+	stack = append(stack[:len(stack)-n], gr.NewToken(lhs, children, at, lookahead))
+
+	return stack, nil
+}
+
+`
+
+// driver_template are the methods that run the parser.
+const driver_template string = `// apply applies an action.
+//
+// Parameters:
+//   - stack: The stack. It may be modified.
+//   - input: The tokens that are not shifted yet.
+//   - action: The action.
+//
+// Returns:
+//   - []*gr.Token: The new stack.
+//   - []*gr.Token: The new input.
+//   - bool: True if the parse is accepted.
+//   - error: An error if the action cannot be applied.
+func (p *{{Name}}) apply(stack, input []*gr.Token[{{T}}], action {{p}}_action) ([]*gr.Token[{{T}}], []*gr.Token[{{T}}], bool, error) {
+	switch action.kind {
+	case {{p}}_shift:
+		if len(input) == 0 {
+			return nil, nil, false, errors.New("reached end of input stream without accepting")
+		}
+
+		return append(stack, input[0]), input[1:], false, nil
+	case {{p}}_reduce:
+		stack, err := p.reduce(stack, action.rule)
+
+		return stack, input, false, err
+	default:
+		stack, err := p.reduce(stack, action.rule)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		if len(stack) != 1 {
+			return nil, nil, false, fmt.Errorf("accepted with %d tokens left on the stack", len(stack)-1)
+		}
+
+		return stack, input, true, nil
+	}
+}
+
+// run runs the parser until it accepts or fails.
+//
+// Parameters:
+//   - stack: The stack. It may be modified.
+//   - input: The tokens that are not shifted yet.
+//
+// Returns:
+//   - *gr.Token: The root of the parse tree.
+//   - error: An error if the parse fails.
+//
+// Behaviors:
+//   - When several decisions match, each distinct action is tried once, in
+//     table order. Several items of the table may lead to the same action;
+//     their branches would be the same, and trying each of them would
+//     multiply the work at every such decision.
+func (p *{{Name}}) run(stack, input []*gr.Token[{{T}}]) (*gr.Token[{{T}}], error) {
+	for {
+		actions := p.decide(stack)
+
+		if len(actions) == 0 {
+			top := stack[len(stack)-1]
+
+			return nil, fmt.Errorf("unexpected %s at %d", top.ID, top.At)
+		}
+
+		if len(actions) > 1 {
+			var first error
+
+			for i, action := range actions {
+				// Several items may lead to the same action, whose branch
+				// was already tried.
+				if slices.Contains(actions[:i], action) {
+					continue
+				}
+
+				root, err := p.branch(slices.Clone(stack), input, action)
+				if err == nil {
+					return root, nil
+				}
+
+				if first == nil {
+					first = err
+				}
+			}
+
+			return nil, first
+		}
+
+		var done bool
+		var err error
+
+		stack, input, done, err = p.apply(stack, input, actions[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if done {
+			return stack[len(stack)-1], nil
+		}
+	}
+}
+
+// branch applies an action and runs the parser from there.
+//
+// Parameters:
+//   - stack: The stack. It may be modified.
+//   - input: The tokens that are not shifted yet.
+//   - action: The action.
+//
+// Returns:
+//   - *gr.Token: The root of the parse tree.
+//   - error: An error if the parse fails.
+func (p *{{Name}}) branch(stack, input []*gr.Token[{{T}}], action {{p}}_action) (*gr.Token[{{T}}], error) {
+	stack, input, done, err := p.apply(stack, input, action)
+	if err != nil {
+		return nil, err
+	}
+
+	if done {
+		return stack[len(stack)-1], nil
+	}
+
+	return p.run(stack, input)
+}
+
+// Parse parses a stream of tokens.
+//
+// Parameters:
+//   - tokens: The tokens, each one with the next one as its lookahead.
+//
+// Returns:
+//   - *gr.Token: The root of the parse tree.
+//   - error: An error if the tokens could not be parsed.
+//
+// Behaviors:
+//   - When several decisions match, they are tried in the order of the
+//     decision table and the first accepted parse is returned.
+//   - A parse is only accepted once the whole stack is reduced to its root.
+func (p *{{Name}}) Parse(tokens []*gr.Token[{{T}}]) (*gr.Token[{{T}}], error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("no tokens to parse")
+	}
+
+	return p.run(tokens[:1:1], tokens[1:])
+}
+`
@@ -0,0 +1,309 @@
+package Generator
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	ps "github.com/PlayerR9/LyneParser/Parser"
+	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// AsmType are the symbols of the assembly grammar of SimpleLexer/Parser.
+type AsmType int
+
+const (
+	AsmEOF AsmType = iota
+	AsmBinOp
+	AsmUnaryOp
+	AsmOpParen
+	AsmClParen
+	AsmNewline
+	AsmImmediate
+	AsmRightArrow
+	AsmRegister
+	AsmSource
+	AsmSource1
+	AsmStatement
+	AsmOperand
+	AsmUnaryInstruction
+	AsmBinInstruction
+	AsmLoadImmediate
+)
+
+var asm_idents = [...]string{
+	"AsmEOF", "AsmBinOp", "AsmUnaryOp", "AsmOpParen", "AsmClParen",
+	"AsmNewline", "AsmImmediate", "AsmRightArrow", "AsmRegister",
+	"AsmSource", "AsmSource1", "AsmStatement", "AsmOperand",
+	"AsmUnaryInstruction", "AsmBinInstruction", "AsmLoadImmediate",
+}
+
+var asm_names = [...]string{
+	"EOF", "binary_operator", "unary_operator", "op_paren", "cl_paren",
+	"newline", "immediate", "right_arrow", "register",
+	"Source", "Source1", "Statement", "Operand",
+	"UnaryInstruction", "BinaryInstruction", "LoadImmediate",
+}
+
+func (t AsmType) IsTerminal() bool {
+	return t <= AsmRegister
+}
+
+func (t AsmType) String() string {
+	return asm_names[t]
+}
+
+func new_asm_grammar(t *testing.T) *ps.Grammar[AsmType] {
+	grammar, err := ps.NewGrammar[AsmType]()
+	if err != nil {
+		t.Fatalf("NewGrammar failed: %s", err.Error())
+	}
+
+	rules := []struct {
+		lhs AsmType
+		rhs []AsmType
+	}{
+		{AsmSource, []AsmType{AsmSource1, AsmEOF}},
+		{AsmSource1, []AsmType{AsmStatement}},
+		{AsmSource1, []AsmType{AsmStatement, AsmNewline, AsmSource1}},
+		{AsmStatement, []AsmType{AsmUnaryInstruction, AsmRightArrow, AsmRegister}},
+		{AsmStatement, []AsmType{AsmBinInstruction, AsmRightArrow, AsmRegister}},
+		{AsmStatement, []AsmType{AsmLoadImmediate, AsmRightArrow, AsmRegister}},
+		{AsmOperand, []AsmType{AsmRegister}},
+		{AsmOperand, []AsmType{AsmBinInstruction}},
+		{AsmUnaryInstruction, []AsmType{AsmOperand, AsmUnaryOp}},
+		{AsmBinInstruction, []AsmType{AsmOperand, AsmOperand, AsmBinOp}},
+		{AsmLoadImmediate, []AsmType{AsmOpParen, AsmImmediate, AsmClParen}},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.rhs)
+		if err != nil {
+			t.Fatalf("AddRule(%s) failed: %s", rule.lhs, err.Error())
+		}
+	}
+
+	return grammar
+}
+
+var asm_inputs = [][]AsmType{
+	{AsmOpParen, AsmImmediate, AsmClParen, AsmRightArrow, AsmRegister, AsmEOF},
+	{AsmRegister, AsmUnaryOp, AsmRightArrow, AsmRegister, AsmEOF},
+	{AsmRegister, AsmRegister, AsmBinOp, AsmRightArrow, AsmRegister, AsmNewline, AsmOpParen, AsmImmediate, AsmClParen, AsmRightArrow, AsmRegister, AsmEOF},
+	{AsmRegister, AsmRegister, AsmBinOp, AsmRegister, AsmBinOp, AsmUnaryOp, AsmRightArrow, AsmRegister, AsmEOF},
+	{AsmRegister, AsmRightArrow, AsmRegister, AsmEOF},
+	{AsmOpParen, AsmImmediate, AsmRightArrow, AsmRegister, AsmEOF},
+	{AsmEOF},
+}
+
+func asm_tokens(input []AsmType) []*gr.Token[AsmType] {
+	tokens := make([]*gr.Token[AsmType], 0, len(input))
+
+	for i, id := range input {
+		tokens = append(tokens, gr.NewToken(id, id.String(), i, nil))
+	}
+
+	for i := 0; i < len(tokens)-1; i++ {
+		tokens[i].SetLookahead(tokens[i+1])
+	}
+
+	return tokens
+}
+
+// dump writes a tree as "<id>(<child> <child>)"; dump_src is the same
+// function in the source of the driver.
+func dump(tok *gr.Token[AsmType]) string {
+	children, ok := tok.Data.([]*gr.Token[AsmType])
+	if !ok {
+		return strconv.Itoa(int(tok.ID))
+	}
+
+	values := make([]string, 0, len(children))
+
+	for _, child := range children {
+		values = append(values, dump(child))
+	}
+
+	return strconv.Itoa(int(tok.ID)) + "(" + strings.Join(values, " ") + ")"
+}
+
+const dump_src string = `
+func dump(tok *gr.Token[AsmType]) string {
+	children, ok := tok.Data.([]*gr.Token[AsmType])
+	if !ok {
+		return strconv.Itoa(int(tok.ID))
+	}
+
+	values := make([]string, 0, len(children))
+
+	for _, child := range children {
+		values = append(values, dump(child))
+	}
+
+	return strconv.Itoa(int(tok.ID)) + "(" + strings.Join(values, " ") + ")"
+}
+`
+
+// driver_src returns the source of a program that parses the inputs read
+// from its standard input, one per line as symbol values, with the
+// generated parser and writes the dump of each tree, or "error".
+func driver_src() string {
+	var builder strings.Builder
+
+	builder.WriteString("package main\n\nimport (\n\t\"bufio\"\n\t\"fmt\"\n\t\"os\"\n\t\"strconv\"\n\t\"strings\"\n\n")
+	builder.WriteString("\tgr \"github.com/PlayerR9/LyneParser/Grammar\"\n)\n\ntype AsmType int\n\nconst (\n")
+
+	for i, ident := range asm_idents {
+		builder.WriteString("\t" + ident + " AsmType = " + strconv.Itoa(i) + "\n")
+	}
+
+	builder.WriteString(")\n\nfunc (t AsmType) IsTerminal() bool { return t <= AsmRegister }\n\n")
+	builder.WriteString("func (t AsmType) String() string { return strconv.Itoa(int(t)) }\n")
+	builder.WriteString(dump_src)
+	builder.WriteString(`
+func main() {
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for scanner.Scan() {
+		var tokens []*gr.Token[AsmType]
+
+		for i, field := range strings.Fields(scanner.Text()) {
+			id, _ := strconv.Atoi(field)
+			tokens = append(tokens, gr.NewToken(AsmType(id), field, i, nil))
+		}
+
+		for i := 0; i < len(tokens)-1; i++ {
+			tokens[i].SetLookahead(tokens[i+1])
+		}
+
+		root, err := NewParser().Parse(tokens)
+		if err != nil {
+			fmt.Println("error")
+		} else {
+			fmt.Println(dump(root))
+		}
+	}
+}
+`)
+
+	return builder.String()
+}
+
+func TestGenerateDifferential(t *testing.T) {
+	goexe, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go command is not available")
+	}
+
+	grammar := new_asm_grammar(t)
+
+	src, err := Generate(grammar, nil, Config[AsmType]{
+		Package:   "main",
+		TokenType: "AsmType",
+		SymbolName: func(symbol AsmType) string {
+			return asm_idents[symbol]
+		},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %s", err.Error())
+	}
+
+	// The program must be in the module to import the Grammar package.
+	dir, err := os.MkdirTemp(".", "generated_")
+	if err != nil {
+		t.Fatalf("MkdirTemp failed: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.WriteFile(filepath.Join(dir, "parser.go"), src, 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile failed: %s", err.Error())
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "main.go"), []byte(driver_src()), 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile failed: %s", err.Error())
+	}
+
+	var stdin strings.Builder
+
+	for _, input := range asm_inputs {
+		values := make([]string, 0, len(input))
+
+		for _, id := range input {
+			values = append(values, strconv.Itoa(int(id)))
+		}
+
+		stdin.WriteString(strings.Join(values, " ") + "\n")
+	}
+
+	cmd := exec.Command(goexe, "run", "./"+filepath.Base(dir))
+	cmd.Stdin = strings.NewReader(stdin.String())
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exit_err *exec.ExitError
+		if errors.As(err, &exit_err) {
+			t.Logf("%s", exit_err.Stderr)
+		}
+
+		t.Fatalf("go run failed: %s", err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != len(asm_inputs) {
+		t.Fatalf("expected %d results, got %d", len(asm_inputs), len(lines))
+	}
+
+	parser, err := ps.NewParser(grammar)
+	if err != nil {
+		t.Fatalf("NewParser failed: %s", err.Error())
+	}
+
+	for i, input := range asm_inputs {
+		var trees []string
+
+		err := ps.Parse(parser, cds.NewStream(asm_tokens(input)))
+		if err == nil {
+			evals, _ := parser.GetEvals()
+
+			for _, eval := range evals {
+				if eval.GetStack().Size() != 1 {
+					continue
+				}
+
+				root, _ := eval.GetStack().Peek()
+				trees = append(trees, dump(root))
+			}
+		}
+
+		switch {
+		case len(trees) == 0 && lines[i] != "error":
+			t.Errorf("input %d: the table-driven parser failed (%v), the generated one gave %s", i, err, lines[i])
+		case len(trees) > 0 && !slices.Contains(trees, lines[i]):
+			t.Errorf("input %d: expected one of %v, got %s", i, trees, lines[i])
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	_, err := Generate[AsmType](nil, nil, Config[AsmType]{Package: "p", TokenType: "AsmType"})
+
+	var target *uc.ErrInvalidParameter
+
+	if !errors.As(err, &target) {
+		t.Errorf("nil grammar: expected *uc.ErrInvalidParameter, got %v", err)
+	}
+
+	_, err = Generate(new_asm_grammar(t), nil, Config[AsmType]{TokenType: "AsmType"})
+	if !errors.As(err, &target) {
+		t.Errorf("no package: expected *uc.ErrInvalidParameter, got %v", err)
+	}
+}
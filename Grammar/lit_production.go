@@ -0,0 +1,137 @@
+package Grammar
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LitProduction represents a production in a grammar that matches a fixed
+// string, such as a keyword or an operator.
+type LitProduction[T TokenTyper] struct {
+	// lhs is the left-hand side of the production.
+	lhs T
+
+	// literal is the string to match.
+	literal string
+
+	// fold is true if the match is case-insensitive.
+	fold bool
+}
+
+// GoString implements the fmt.GoStringer interface.
+func (p *LitProduction[T]) GoString() string {
+	return fmt.Sprintf("LitProduction[lhs=%s, literal=%q, fold=%t]", p.lhs.String(), p.literal, p.fold)
+}
+
+// NewLitProduction creates a new production that matches the given string.
+//
+// Parameters:
+//   - lhs: The left-hand side of the production.
+//   - literal: The string to match.
+//   - fold: True if the match is case-insensitive, under Unicode simple
+//     case folding.
+//
+// Returns:
+//   - *LitProduction: The new production. Nil if the literal is empty.
+func NewLitProduction[T TokenTyper](lhs T, literal string, fold bool) *LitProduction[T] {
+	if literal == "" {
+		return nil
+	}
+
+	p := &LitProduction[T]{
+		lhs:     lhs,
+		literal: literal,
+		fold:    fold,
+	}
+
+	return p
+}
+
+// GetLhs implements the TokenMatcher interface.
+func (p *LitProduction[T]) GetLhs() T {
+	return p.lhs
+}
+
+// GetSymbols implements the TokenMatcher interface.
+//
+// The slice only contains the left-hand side of the production.
+func (p *LitProduction[T]) GetSymbols() []T {
+	return []T{p.lhs}
+}
+
+// GetLiteral returns the string that the production matches.
+//
+// Returns:
+//   - string: The literal.
+func (p *LitProduction[T]) GetLiteral() string {
+	return p.literal
+}
+
+// IsCaseInsensitive checks whether the production ignores the case.
+//
+// Returns:
+//   - bool: True if the match is case-insensitive, false otherwise.
+func (p *LitProduction[T]) IsCaseInsensitive() bool {
+	return p.fold
+}
+
+// MatchLengths implements the TokenMatcher interface.
+//
+// There is at most one match. When the match is case-insensitive, its length
+// is the one of the matched text, which may differ from the literal's.
+func (p *LitProduction[T]) MatchLengths(input []byte, at int) []int {
+	if at < 0 || at >= len(input) {
+		return nil
+	}
+
+	rest := input[at:]
+
+	if !p.fold {
+		if len(rest) < len(p.literal) || string(rest[:len(p.literal)]) != p.literal {
+			return nil
+		}
+
+		return []int{len(p.literal)}
+	}
+
+	var size int
+
+	for _, want := range p.literal {
+		if size >= len(rest) {
+			return nil
+		}
+
+		got, s := utf8.DecodeRune(rest[size:])
+		if !equal_fold(want, got) {
+			return nil
+		}
+
+		size += s
+	}
+
+	return []int{size}
+}
+
+// equal_fold is a helper function that checks whether two runes are equal
+// under Unicode simple case folding.
+//
+// Parameters:
+//   - a: The first rune.
+//   - b: The second rune.
+//
+// Returns:
+//   - bool: True if the runes are equal, false otherwise.
+func equal_fold(a, b rune) bool {
+	if a == b {
+		return true
+	}
+
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,56 @@
+package Grammar
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLitProductionMatchLengths(t *testing.T) {
+	tests := []struct {
+		literal  string
+		fold     bool
+		input    string
+		at       int
+		expected []int
+	}{
+		{"if", false, "if x", 0, []int{2}},
+		{"if", false, "iffy", 0, []int{2}},
+		{"if", false, "x if", 2, []int{2}},
+		{"if", false, "IF", 0, nil},
+		{"if", false, "i", 0, nil},
+		{"if", true, "IF", 0, []int{2}},
+		{"if", true, "iF", 0, []int{2}},
+		{"if", true, "I", 0, nil},
+		// The length is the one of the matched text: "ſ" (2 bytes) folds to
+		// "s" (1 byte).
+		{"as", true, "aſ", 0, []int{3}},
+		{"é", true, "É", 0, []int{2}},
+		{"if", false, "if", 2, nil},
+		{"if", false, "if", -1, nil},
+	}
+
+	for _, test := range tests {
+		p := NewLitProduction(TtA, test.literal, test.fold)
+
+		lengths := p.MatchLengths([]byte(test.input), test.at)
+		if !slices.Equal(lengths, test.expected) {
+			t.Errorf("%q (fold: %t) on %q at %d: expected %v, got %v", test.literal, test.fold, test.input, test.at, test.expected, lengths)
+		}
+	}
+}
+
+func TestNewLitProduction(t *testing.T) {
+	if NewLitProduction(TtA, "", false) != nil {
+		t.Errorf("expected nil for an empty literal")
+	}
+
+	p := NewLitProduction(TtB, "while", true)
+
+	if p.GetLhs() != TtB || !slices.Equal(p.GetSymbols(), []TestTokenType{TtB}) {
+		t.Errorf("unexpected left-hand side: %s", p.GoString())
+	}
+
+	if p.GetLiteral() != "while" || !p.IsCaseInsensitive() {
+		t.Errorf("unexpected literal: %s", p.GoString())
+	}
+}
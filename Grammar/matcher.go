@@ -0,0 +1,148 @@
+package Grammar
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Matcher is the interface of the hand-written matchers that can be used
+// next to regular expressions to recognize tokens that are not regular;
+// such as nested comments or raw strings whose delimiters must match.
+type Matcher interface {
+	// Match returns the lengths of every match of the matcher that starts at
+	// the given offset of the input.
+	//
+	// Parameters:
+	//   - input: The whole input.
+	//   - offset: The offset where the match must start.
+	//
+	// Returns:
+	//   - []int: The lengths, in bytes, of the matches. Empty if there is no
+	//     match. Lengths that are not positive or that go past the end of the
+	//     input are ignored.
+	Match(input []byte, offset int) []int
+}
+
+// MatcherFunc is a function that implements the Matcher interface.
+type MatcherFunc func(input []byte, offset int) []int
+
+// Match implements the Matcher interface.
+func (f MatcherFunc) Match(input []byte, offset int) []int {
+	return f(input, offset)
+}
+
+// TokenMatcher is the interface of the productions that the lexer uses to
+// recognize tokens. It is implemented by *RegProduction, *FuncProduction
+// and *LitProduction.
+type TokenMatcher[T TokenTyper] interface {
+	// GetLhs returns the type of the tokens that the production recognizes.
+	//
+	// Returns:
+	//   - T: The left-hand side of the production.
+	GetLhs() T
+
+	// GetSymbols returns the symbols of the production.
+	//
+	// Returns:
+	//   - []T: The symbols of the production.
+	GetSymbols() []T
+
+	// MatchLengths returns the lengths of every match of the production that
+	// starts at the given offset of the input.
+	//
+	// Parameters:
+	//   - input: The whole input.
+	//   - at: The offset where the match must start.
+	//
+	// Returns:
+	//   - []int: The lengths of the matches, sorted in increasing order and
+	//     without duplicates. Only positive lengths that fit in the input are
+	//     returned.
+	MatchLengths(input []byte, at int) []int
+
+	fmt.GoStringer
+}
+
+// FuncProduction represents a production in a grammar that matches with a
+// Matcher.
+type FuncProduction[T TokenTyper] struct {
+	// lhs is the left-hand side of the production.
+	lhs T
+
+	// matcher is the matcher of the production.
+	matcher Matcher
+}
+
+// GoString implements the fmt.GoStringer interface.
+func (p *FuncProduction[T]) GoString() string {
+	return fmt.Sprintf("FuncProduction[lhs=%s]", p.lhs.String())
+}
+
+// NewFuncProduction creates a new production that matches with the given
+// matcher.
+//
+// Parameters:
+//   - lhs: The left-hand side of the production.
+//   - matcher: The matcher of the production.
+//
+// Returns:
+//   - *FuncProduction: The new production. Nil if the matcher is nil.
+func NewFuncProduction[T TokenTyper](lhs T, matcher Matcher) *FuncProduction[T] {
+	if matcher == nil {
+		return nil
+	}
+
+	p := &FuncProduction[T]{
+		lhs:     lhs,
+		matcher: matcher,
+	}
+
+	return p
+}
+
+// GetLhs implements the TokenMatcher interface.
+func (p *FuncProduction[T]) GetLhs() T {
+	return p.lhs
+}
+
+// GetSymbols implements the TokenMatcher interface.
+//
+// The slice only contains the left-hand side of the production.
+func (p *FuncProduction[T]) GetSymbols() []T {
+	return []T{p.lhs}
+}
+
+// MatchLengths implements the TokenMatcher interface.
+func (p *FuncProduction[T]) MatchLengths(input []byte, at int) []int {
+	if at < 0 || at >= len(input) {
+		return nil
+	}
+
+	return clean_lengths(p.matcher.Match(input, at), len(input)-at)
+}
+
+// clean_lengths is a helper function that sorts the lengths, removes the
+// duplicates and drops those that are not in (0, max].
+//
+// Parameters:
+//   - lengths: The lengths to clean.
+//   - max: The maximum length.
+//
+// Returns:
+//   - []int: The cleaned lengths.
+func clean_lengths(lengths []int, max int) []int {
+	var cleaned []int
+
+	for _, l := range lengths {
+		if l <= 0 || l > max {
+			continue
+		}
+
+		pos, ok := slices.BinarySearch(cleaned, l)
+		if !ok {
+			cleaned = slices.Insert(cleaned, pos, l)
+		}
+	}
+
+	return cleaned
+}
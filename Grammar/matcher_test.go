@@ -0,0 +1,80 @@
+package Grammar
+
+import (
+	"slices"
+	"testing"
+)
+
+// nested_comment matches "/* ... */" comments that can be nested.
+func nested_comment(input []byte, offset int) []int {
+	var depth int
+
+	for i := offset; i+1 < len(input); i++ {
+		switch string(input[i : i+2]) {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			if depth == 0 {
+				return nil
+			}
+
+			depth--
+			i++
+
+			if depth == 0 {
+				return []int{i + 1 - offset}
+			}
+		default:
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestFuncProduction(t *testing.T) {
+	if NewFuncProduction[TestTokenType](TtA, nil) != nil {
+		t.Errorf("expected a nil production for a nil matcher")
+	}
+
+	p := NewFuncProduction(TtA, MatcherFunc(nested_comment))
+
+	if p.GetLhs() != TtA || !slices.Equal(p.GetSymbols(), []TestTokenType{TtA}) {
+		t.Errorf("expected the symbols to be [a], got %v", p.GetSymbols())
+	}
+
+	tests := []struct {
+		input    string
+		at       int
+		expected []int
+	}{
+		{"/* a /* b */ c */ d", 0, []int{17}},
+		{"x /* a */", 2, []int{7}},
+		{"/* a /* b */", 0, nil},
+		{"a", 0, nil},
+		{"/**/", 4, nil},
+	}
+
+	for _, test := range tests {
+		lengths := p.MatchLengths([]byte(test.input), test.at)
+		if !slices.Equal(lengths, test.expected) {
+			t.Errorf("%q at %d: expected %v, got %v", test.input, test.at, test.expected, lengths)
+		}
+	}
+}
+
+func TestFuncProductionCleansLengths(t *testing.T) {
+	p := NewFuncProduction(TtA, MatcherFunc(func(input []byte, offset int) []int {
+		return []int{3, 0, 1, 3, -2, 10, 2}
+	}))
+
+	// Lengths are sorted and deduplicated; those that are not positive or
+	// that go past the end of the input are dropped.
+	lengths := p.MatchLengths([]byte("abcd"), 1)
+	if !slices.Equal(lengths, []int{1, 2, 3}) {
+		t.Errorf("expected [1 2 3], got %v", lengths)
+	}
+}
@@ -4,10 +4,8 @@ import (
 	"slices"
 	"strings"
 
-	ud "github.com/PlayerR9/MyGoLib/Units/Debugging"
 	uc "github.com/PlayerR9/MyGoLib/Units/common"
 	us "github.com/PlayerR9/MyGoLib/Units/slice"
-	lls "github.com/PlayerR9/stack/stack"
 )
 
 // Production represents a production in a grammar.
@@ -159,7 +157,7 @@ func (p *Production[T]) GetSymbols() []T {
 //
 // Parameters:
 //   - at: The current index in the input stack.
-//   - stack: The stack to match the production against.
+//   - stack: The stack to match the production against. It is never modified.
 //
 // Returns:
 //   - Token: A token that matches the production in the stack.
@@ -170,39 +168,25 @@ func (p *Production[T]) GetSymbols() []T {
 //     string. In parsers, however, it is not really used (at = 0). Despite
 //     that, it can be used to provide additional information to the parser
 //     for error reporting or debugging.
-func (p *Production[T]) Match(at int, stack *ud.History[lls.Stacker[*Token[T]]]) (*Token[T], error) {
-	if stack == nil {
-		return nil, uc.NewErrNilParameter("stack")
-	}
-
+func (p *Production[T]) Match(at int, stack *TokenStack[T]) (*Token[T], error) {
 	var solutions []*Token[T]
-	var reason error
 
 	for i := len(p.rhs) - 1; i >= 0; i-- {
 		rhs := p.rhs[i]
 
-		cmd := lls.NewPop[*Token[T]]()
-		err := stack.ExecuteCommand(cmd)
-		if err != nil {
-			reason = uc.NewErrUnexpected("", rhs.String())
-			break
+		top, rest, ok := stack.Pop()
+		if !ok {
+			return nil, uc.NewErrUnexpected("", rhs.String())
 		}
-		top := cmd.Value()
 
 		id := top.GetID()
 		if id != rhs {
 			str := top.GoString()
-			reason = uc.NewErrUnexpected(str, rhs.String())
-			break
+			return nil, uc.NewErrUnexpected(str, rhs.String())
 		}
 
 		solutions = append(solutions, top)
-	}
-
-	stack.Reject()
-
-	if reason != nil {
-		return nil, reason
+		stack = rest
 	}
 
 	slices.Reverse(solutions)
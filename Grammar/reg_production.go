@@ -56,8 +56,9 @@ func (p *RegProduction[T]) Copy() uc.Copier {
 // NewRegProduction is a function that returns a new RegProduction with the
 // given left-hand side and regular expression.
 //
-// It adds the '^' character to the beginning of the regular expression to
-// match the beginning of the input string.
+// The regular expression is wrapped in a non-capturing group anchored with
+// '^', so that it only matches at the beginning of the input string; even if
+// it is an alternation such as "a|b".
 //
 // Parameters:
 //   - lhs: The left-hand side of the production.
@@ -74,7 +75,7 @@ func (p *RegProduction[T]) Copy() uc.Copier {
 func NewRegProduction[T TokenTyper](lhs T, regex string) *RegProduction[T] {
 	p := &RegProduction[T]{
 		lhs: lhs,
-		rhs: "^" + regex,
+		rhs: "^(?:" + regex + ")",
 	}
 	return p
 }
@@ -127,6 +128,23 @@ func (p *RegProduction[T]) MatchRegProd(at int, b []byte) (*Token[T], bool) {
 	return lt, true
 }
 
+// MatchLengths implements the TokenMatcher interface.
+//
+// Since the regular expression is anchored at the start of the input, there
+// is at most one match: the leftmost-first one.
+func (p *RegProduction[T]) MatchLengths(input []byte, at int) []int {
+	if at < 0 || at >= len(input) {
+		return nil
+	}
+
+	loc := p.rxp.FindIndex(input[at:])
+	if loc == nil || loc[0] != 0 || loc[1] == 0 {
+		return nil
+	}
+
+	return []int{loc[1]}
+}
+
 // Compile is a method of RegProduction that compiles the regular
 // expression of the production.
 //
@@ -0,0 +1,52 @@
+package Grammar
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRegProductionMatchLengths(t *testing.T) {
+	tests := []struct {
+		regex    string
+		input    string
+		at       int
+		expected []int
+	}{
+		{`[a-z]+`, "abc1", 0, []int{3}},
+		{`[a-z]+`, "1abc", 1, []int{3}},
+		{`[a-z]+`, "1abc", 0, nil},
+		// The whole alternation is anchored, not only its first branch.
+		{`a|b`, "xb", 0, nil},
+		{`a|b`, "ba", 0, []int{1}},
+		{`a|ab`, "ab", 0, []int{1}},
+		{`ab|a`, "ab", 0, []int{2}},
+		// Empty matches are not tokens.
+		{`a*`, "b", 0, nil},
+		{`a`, "a", 1, nil},
+		{`a`, "a", -1, nil},
+	}
+
+	for _, test := range tests {
+		p := NewRegProduction(TtA, test.regex)
+
+		err := p.Compile()
+		if err != nil {
+			t.Fatalf("%q: expected no error, got %s", test.regex, err.Error())
+		}
+
+		lengths := p.MatchLengths([]byte(test.input), test.at)
+		if !slices.Equal(lengths, test.expected) {
+			t.Errorf("%q on %q at %d: expected %v, got %v", test.regex, test.input, test.at, test.expected, lengths)
+		}
+	}
+}
+
+func TestRegProductionCompile(t *testing.T) {
+	p := NewRegProduction(TtA, `(`)
+
+	err := p.Compile()
+	if err == nil {
+		t.Errorf("expected an error, got nil")
+	}
+
+}
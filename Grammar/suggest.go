@@ -0,0 +1,78 @@
+package Grammar
+
+// EditDistance computes the optimal string alignment distance between two
+// strings; that is, the number of insertions, deletions, substitutions and
+// transpositions of adjacent runes needed to turn one into the other.
+//
+// Parameters:
+//   - a: The first string.
+//   - b: The second string.
+//
+// Returns:
+//   - int: The distance. 0 if the strings are equal.
+func EditDistance(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	// Only three rows are needed: the current one and the two before it.
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(rb)]
+}
+
+// Closest finds the candidate that is the closest to a misspelled word.
+//
+// Parameters:
+//   - word: The misspelled word.
+//   - candidates: The words that could have been meant.
+//
+// Returns:
+//   - string: The closest candidate. Empty if none is close enough.
+//   - bool: True if a candidate was found, false otherwise.
+//
+// Behaviors:
+//   - A candidate is close enough if its distance to the word is not 0 and
+//     at most a third of the length of the word, with a minimum of 1.
+//   - On a tie, the first candidate wins.
+func Closest(word string, candidates []string) (string, bool) {
+	limit := max(len([]rune(word))/3, 1)
+
+	var closest string
+	best := limit + 1
+
+	for _, candidate := range candidates {
+		dist := EditDistance(word, candidate)
+
+		if dist > 0 && dist < best {
+			best = dist
+			closest = candidate
+		}
+	}
+
+	return closest, best <= limit
+}
@@ -0,0 +1,42 @@
+package Grammar
+
+import "testing"
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"while", "while", 0},
+		{"whlie", "while", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+		{"ca", "abc", 3},
+	}
+
+	for _, test := range tests {
+		got := EditDistance(test.a, test.b)
+		if got != test.want {
+			t.Errorf("EditDistance(%q, %q): expected %d, got %d", test.a, test.b, test.want, got)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"if", "while", "return"}
+
+	closest, ok := Closest("whlie", candidates)
+	if !ok || closest != "while" {
+		t.Errorf("expected %q, got %q", "while", closest)
+	}
+
+	_, ok = Closest("x", candidates)
+	if ok {
+		t.Errorf("expected no suggestion for %q", "x")
+	}
+
+	_, ok = Closest("while", candidates)
+	if ok {
+		t.Errorf("expected no suggestion for an exact match")
+	}
+}
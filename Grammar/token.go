@@ -41,13 +41,19 @@ type Token[T TokenTyper] struct {
 	//
 	// Only EofToken and RootToken have nil data.
 	Data any
+
+	// Mode is the lexer mode the token was matched in. Tokens of different
+	// modes may share the same ID; this tells them apart. Empty for the
+	// tokens that were not created by a lexer.
+	Mode string
 }
 
 // Copy implements common.Copier interface.
 func (tok *Token[T]) Copy() uc.Copier {
 	lt := &Token[T]{
-		ID: tok.ID,
-		At: tok.At,
+		ID:   tok.ID,
+		At:   tok.At,
+		Mode: tok.Mode,
 	}
 
 	switch data := tok.Data.(type) {
@@ -0,0 +1,145 @@
+package Grammar
+
+import (
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// TokenStack is an immutable stack of tokens. Pushing and popping never
+// modify the receiver; they return a new stack that shares its tail with the
+// original one. Because of this, copying a stack is O(1) and any number of
+// stacks can share the same tokens.
+//
+// A nil *TokenStack is a valid empty stack.
+type TokenStack[T TokenTyper] struct {
+	// top is the token at the top of the stack.
+	top *Token[T]
+
+	// below is the rest of the stack.
+	below *TokenStack[T]
+
+	// size is the number of tokens in the stack.
+	size int
+}
+
+// Iterator implements the common.Iterable interface.
+//
+// It scans the stack from the top to the bottom.
+func (ts *TokenStack[T]) Iterator() uc.Iterater[*Token[T]] {
+	iter := &TokenStackIterator[T]{
+		stack:   ts,
+		current: ts,
+	}
+
+	return iter
+}
+
+// Push returns a new stack with the token on top of the receiver.
+//
+// Parameters:
+//   - tok: The token to push.
+//
+// Returns:
+//   - *TokenStack: The new stack. Never nil.
+func (ts *TokenStack[T]) Push(tok *Token[T]) *TokenStack[T] {
+	new_stack := &TokenStack[T]{
+		top:   tok,
+		below: ts,
+		size:  ts.Size() + 1,
+	}
+
+	return new_stack
+}
+
+// Pop returns the token at the top of the stack and the rest of the stack.
+//
+// Returns:
+//   - *Token: The token at the top of the stack. Nil if the stack is empty.
+//   - *TokenStack: The rest of the stack.
+//   - bool: True if the stack was not empty, false otherwise.
+func (ts *TokenStack[T]) Pop() (*Token[T], *TokenStack[T], bool) {
+	if ts == nil {
+		return nil, nil, false
+	}
+
+	return ts.top, ts.below, true
+}
+
+// Peek returns the token at the top of the stack.
+//
+// Returns:
+//   - *Token: The token at the top of the stack. Nil if the stack is empty.
+//   - bool: True if the stack was not empty, false otherwise.
+func (ts *TokenStack[T]) Peek() (*Token[T], bool) {
+	if ts == nil {
+		return nil, false
+	}
+
+	return ts.top, true
+}
+
+// Size returns the number of tokens in the stack.
+//
+// Returns:
+//   - int: The number of tokens in the stack.
+func (ts *TokenStack[T]) Size() int {
+	if ts == nil {
+		return 0
+	}
+
+	return ts.size
+}
+
+// IsEmpty checks whether the stack is empty.
+//
+// Returns:
+//   - bool: True if the stack is empty, false otherwise.
+func (ts *TokenStack[T]) IsEmpty() bool {
+	return ts == nil
+}
+
+// Slice returns the tokens of the stack, from the top to the bottom.
+//
+// Returns:
+//   - []*Token: The tokens of the stack. Nil if the stack is empty.
+func (ts *TokenStack[T]) Slice() []*Token[T] {
+	if ts == nil {
+		return nil
+	}
+
+	slice := make([]*Token[T], 0, ts.size)
+
+	for current := ts; current != nil; current = current.below {
+		slice = append(slice, current.top)
+	}
+
+	return slice
+}
+
+// TokenStackIterator is an iterator over a TokenStack that goes from
+// the top to the bottom of the stack.
+type TokenStackIterator[T TokenTyper] struct {
+	// stack is the stack to iterate over.
+	stack *TokenStack[T]
+
+	// current is the part of the stack that is yet to be consumed.
+	current *TokenStack[T]
+}
+
+// Consume implements the common.Iterater interface.
+//
+// The only error returned is *common.ErrExhaustedIter.
+func (iter *TokenStackIterator[T]) Consume() (*Token[T], error) {
+	if iter.current == nil {
+		return nil, uc.NewErrExhaustedIter()
+	}
+
+	tok := iter.current.top
+	iter.current = iter.current.below
+
+	return tok, nil
+}
+
+// Restart implements the common.Iterater interface.
+func (iter *TokenStackIterator[T]) Restart() {
+	iter.current = iter.stack
+}
@@ -0,0 +1,111 @@
+package Grammar
+
+import (
+	"slices"
+	"testing"
+)
+
+type TestTokenType int
+
+const (
+	TtEof TestTokenType = iota
+	TtA
+	TtB
+	TtC
+)
+
+func (t TestTokenType) String() string {
+	return [...]string{
+		EOFTokenID,
+		"a",
+		"b",
+		"c",
+	}[t]
+}
+
+func (t TestTokenType) IsTerminal() bool {
+	return true
+}
+
+// ids returns the types of the tokens of a stack, from its top down.
+func ids(stack *TokenStack[TestTokenType]) []TestTokenType {
+	var result []TestTokenType
+
+	for _, tok := range stack.Slice() {
+		result = append(result, tok.ID)
+	}
+
+	return result
+}
+
+func TestTokenStackForks(t *testing.T) {
+	var empty *TokenStack[TestTokenType]
+
+	base := empty.Push(NewToken(TtA, "a", 0, nil))
+
+	// Both forks share base and never see each other's tokens.
+	left := base.Push(NewToken(TtB, "b", 1, nil))
+	right := base.Push(NewToken(TtC, "c", 1, nil))
+
+	if !slices.Equal(ids(left), []TestTokenType{TtB, TtA}) {
+		t.Errorf("left: expected [b a], got %v", ids(left))
+	}
+
+	if !slices.Equal(ids(right), []TestTokenType{TtC, TtA}) {
+		t.Errorf("right: expected [c a], got %v", ids(right))
+	}
+
+	if base.Size() != 1 || left.Size() != 2 || right.Size() != 2 {
+		t.Errorf("expected sizes 1, 2 and 2, got %d, %d and %d", base.Size(), left.Size(), right.Size())
+	}
+
+	top, rest, ok := left.Pop()
+	if !ok || top.ID != TtB {
+		t.Fatalf("expected to pop b, got %v", top)
+	}
+
+	if rest != base {
+		t.Errorf("expected the rest of the left fork to be the shared stack")
+	}
+
+	// Popping does not modify the stack.
+	if left.Size() != 2 {
+		t.Errorf("expected left to still have 2 tokens, got %d", left.Size())
+	}
+
+	a, _ := base.Peek()
+	la, _ := rest.Peek()
+
+	if a != la {
+		t.Errorf("expected both forks to share the token at the bottom")
+	}
+}
+
+func TestTokenStackEmpty(t *testing.T) {
+	var empty *TokenStack[TestTokenType]
+
+	top, ok := empty.Peek()
+	if ok || top != nil {
+		t.Errorf("Peek: expected nothing, got %v", top)
+	}
+
+	top, rest, ok := empty.Pop()
+	if ok || top != nil || rest != nil {
+		t.Errorf("Pop: expected nothing, got %v", top)
+	}
+
+	if !empty.IsEmpty() || empty.Size() != 0 || empty.Slice() != nil {
+		t.Errorf("expected an empty stack")
+	}
+
+	_, err := empty.Iterator().Consume()
+	if err == nil {
+		t.Errorf("expected the iterator of an empty stack to be exhausted")
+	}
+
+	// Popping the last token gives back an empty stack.
+	_, rest, _ = empty.Push(NewToken(TtA, "a", 0, nil)).Pop()
+	if !rest.IsEmpty() {
+		t.Errorf("expected an empty stack after popping the last token")
+	}
+}
@@ -0,0 +1,161 @@
+package Highlighter
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ColorMode is the set of colors a terminal supports.
+type ColorMode int
+
+const (
+	// TrueColorMode writes 24-bit colors as they are.
+	TrueColorMode ColorMode = iota
+
+	// Color256Mode writes every color as the closest one of the 256-color
+	// palette.
+	Color256Mode
+)
+
+// String implements the fmt.Stringer interface.
+func (m ColorMode) String() string {
+	return [...]string{
+		"true color",
+		"256 colors",
+	}[m]
+}
+
+const (
+	// ansi_reset is the escape sequence that resets the style.
+	ansi_reset string = "\x1b[0m"
+)
+
+// ANSIRenderer is a renderer that writes the segments with the ANSI escape
+// sequences of terminals.
+type ANSIRenderer struct {
+	// mode is the set of colors of the terminal.
+	mode ColorMode
+}
+
+// NewANSIRenderer creates a new ANSIRenderer.
+//
+// Parameters:
+//   - mode: The set of colors of the terminal.
+//
+// Returns:
+//   - *ANSIRenderer: The new renderer. Never nil.
+func NewANSIRenderer(mode ColorMode) *ANSIRenderer {
+	r := &ANSIRenderer{
+		mode: mode,
+	}
+
+	return r
+}
+
+// color_params is a helper method that writes the parameters of a color.
+//
+// Parameters:
+//   - params: The parameters written so far.
+//   - base: 38 for the foreground, 48 for the background.
+//   - c: The color. Must not be the default one.
+//
+// Returns:
+//   - []string: The parameters with the ones of the color.
+func (r *ANSIRenderer) color_params(params []string, base int, c Color) []string {
+	params = append(params, strconv.Itoa(base))
+
+	if r.mode == TrueColorMode && c.kind == rgb_color {
+		return append(params, "2", strconv.Itoa(int(c.r)), strconv.Itoa(int(c.g)), strconv.Itoa(int(c.b)))
+	}
+
+	return append(params, "5", strconv.Itoa(int(c.to_256())))
+}
+
+// Sequence returns the escape sequence that sets a style.
+//
+// Parameters:
+//   - style: The style.
+//
+// Returns:
+//   - string: The escape sequence. Empty if the style is plain.
+func (r *ANSIRenderer) Sequence(style Style) string {
+	var params []string
+
+	if style.Bold {
+		params = append(params, "1")
+	}
+
+	if style.Italic {
+		params = append(params, "3")
+	}
+
+	if style.Underline {
+		params = append(params, "4")
+	}
+
+	if !style.Fg.IsDefault() {
+		params = r.color_params(params, 38, style.Fg)
+	}
+
+	if !style.Bg.IsDefault() {
+		params = r.color_params(params, 48, style.Bg)
+	}
+
+	if len(params) == 0 {
+		return ""
+	}
+
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+// Render implements the Renderer interface.
+//
+// Behaviors:
+//   - Adjacent segments with the same style share one escape sequence.
+//   - The style is reset before every line break and set again after it, so
+//     that the background does not run to the edge of the terminal.
+func (r *ANSIRenderer) Render(w io.Writer, segments []*Segment) error {
+	var builder strings.Builder
+
+	var current string
+
+	for _, seg := range segments {
+		seq := r.Sequence(seg.Style)
+
+		lines := strings.Split(seg.Text, "\n")
+
+		for i, line := range lines {
+			if i > 0 {
+				if current != "" {
+					builder.WriteString(ansi_reset)
+					current = ""
+				}
+
+				builder.WriteByte('\n')
+			}
+
+			if line == "" {
+				continue
+			}
+
+			if seq != current {
+				if current != "" {
+					builder.WriteString(ansi_reset)
+				}
+
+				builder.WriteString(seq)
+				current = seq
+			}
+
+			builder.WriteString(line)
+		}
+	}
+
+	if current != "" {
+		builder.WriteString(ansi_reset)
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
@@ -0,0 +1,68 @@
+package Highlighter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestANSIRenderer(t *testing.T) {
+	keyword := Style{
+		Fg:   ColorRGB(255, 0, 0),
+		Bold: true,
+	}
+
+	segments := []*Segment{
+		{Text: "if", Type: "KW_IF", Style: keyword},
+		{Text: " ", Type: "WS"},
+		{Text: "x\ny", Type: "WORD", Style: Style{Bg: ColorIndex(4)}},
+		{Text: "else", Type: "KW_ELSE", Style: keyword},
+		{Text: "$", Style: Style{Underline: true}, Error: true},
+	}
+
+	tests := []struct {
+		mode ColorMode
+		want string
+	}{
+		{
+			mode: TrueColorMode,
+			want: "\x1b[1;38;2;255;0;0mif\x1b[0m \x1b[48;5;4mx\x1b[0m\n\x1b[48;5;4my\x1b[0m\x1b[1;38;2;255;0;0melse\x1b[0m\x1b[4m$\x1b[0m",
+		},
+		{
+			mode: Color256Mode,
+			want: "\x1b[1;38;5;196mif\x1b[0m \x1b[48;5;4mx\x1b[0m\n\x1b[48;5;4my\x1b[0m\x1b[1;38;5;196melse\x1b[0m\x1b[4m$\x1b[0m",
+		},
+	}
+
+	for _, test := range tests {
+		var builder strings.Builder
+
+		err := NewANSIRenderer(test.mode).Render(&builder, segments)
+		if err != nil {
+			t.Fatalf("Render failed: %s", err.Error())
+		}
+
+		if builder.String() != test.want {
+			t.Errorf("%s: expected %q, got %q", test.mode, test.want, builder.String())
+		}
+	}
+}
+
+func TestColorTo256(t *testing.T) {
+	tests := []struct {
+		color Color
+		want  uint8
+	}{
+		{ColorRGB(0, 0, 0), 16},
+		{ColorRGB(255, 255, 255), 231},
+		{ColorRGB(128, 128, 128), 244},
+		{ColorRGB(0, 95, 215), 26},
+		{ColorIndex(42), 42},
+	}
+
+	for _, test := range tests {
+		got := test.color.to_256()
+		if got != test.want {
+			t.Errorf("%s: expected %d, got %d", test.color, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package Highlighter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidColor is an error that is returned when a string is not a
+// color.
+type ErrInvalidColor struct {
+	// Value is the string that is not a color.
+	Value string
+}
+
+// Error implements the error interface.
+//
+// Message: "<value> is not a valid color".
+func (e *ErrInvalidColor) Error() string {
+	return strconv.Quote(e.Value) + " is not a valid color"
+}
+
+// NewErrInvalidColor creates a new error of type *ErrInvalidColor.
+//
+// Parameters:
+//   - value: The string that is not a color.
+//
+// Returns:
+//   - *ErrInvalidColor: The new error. Never nil.
+func NewErrInvalidColor(value string) *ErrInvalidColor {
+	e := &ErrInvalidColor{
+		Value: value,
+	}
+	return e
+}
+
+// ErrUnknownStyle is an error that is returned when a style of a theme
+// inherits from a style that does not exist.
+type ErrUnknownStyle struct {
+	// Name is the name of the style that does not exist.
+	Name string
+}
+
+// Error implements the error interface.
+//
+// Message: "no style named <name>".
+func (e *ErrUnknownStyle) Error() string {
+	return "no style named " + strconv.Quote(e.Name)
+}
+
+// NewErrUnknownStyle creates a new error of type *ErrUnknownStyle.
+//
+// Parameters:
+//   - name: The name of the style that does not exist.
+//
+// Returns:
+//   - *ErrUnknownStyle: The new error. Never nil.
+func NewErrUnknownStyle(name string) *ErrUnknownStyle {
+	e := &ErrUnknownStyle{
+		Name: name,
+	}
+	return e
+}
+
+// ErrInheritanceCycle is an error that is returned when the styles of a
+// theme inherit from each other.
+type ErrInheritanceCycle struct {
+	// Names are the names of the styles of the cycle, in order.
+	Names []string
+}
+
+// Error implements the error interface.
+//
+// Message: "styles inherit from each other: <name> -> <name> -> ...".
+func (e *ErrInheritanceCycle) Error() string {
+	names := make([]string, 0, len(e.Names))
+
+	for _, name := range e.Names {
+		names = append(names, strconv.Quote(name))
+	}
+
+	return "styles inherit from each other: " + strings.Join(names, " -> ")
+}
+
+// NewErrInheritanceCycle creates a new error of type *ErrInheritanceCycle.
+//
+// Parameters:
+//   - names: The names of the styles of the cycle, in order.
+//
+// Returns:
+//   - *ErrInheritanceCycle: The new error. Never nil.
+func NewErrInheritanceCycle(names []string) *ErrInheritanceCycle {
+	e := &ErrInheritanceCycle{
+		Names: names,
+	}
+	return e
+}
+
+// ErrInvalidStyle is an error that is returned when a style of a theme
+// cannot be loaded.
+type ErrInvalidStyle struct {
+	// Name is the name of the style.
+	Name string
+
+	// Reason is the reason why the style is invalid.
+	Reason error
+}
+
+// Error implements the error interface.
+//
+// Message: "style <name> is invalid: <reason>".
+func (e *ErrInvalidStyle) Error() string {
+	var builder strings.Builder
+
+	builder.WriteString("style ")
+	builder.WriteString(strconv.Quote(e.Name))
+	builder.WriteString(" is invalid")
+
+	if e.Reason != nil {
+		builder.WriteString(": ")
+		builder.WriteString(e.Reason.Error())
+	}
+
+	return builder.String()
+}
+
+// Unwrap returns the reason why the style is invalid.
+func (e *ErrInvalidStyle) Unwrap() error {
+	return e.Reason
+}
+
+// NewErrInvalidStyle creates a new error of type *ErrInvalidStyle.
+//
+// Parameters:
+//   - name: The name of the style.
+//   - reason: The reason why the style is invalid.
+//
+// Returns:
+//   - *ErrInvalidStyle: The new error. Never nil.
+func NewErrInvalidStyle(name string, reason error) *ErrInvalidStyle {
+	e := &ErrInvalidStyle{
+		Name:   name,
+		Reason: reason,
+	}
+	return e
+}
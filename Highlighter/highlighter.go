@@ -0,0 +1,248 @@
+package Highlighter
+
+import (
+	"io"
+	"unicode"
+	"unicode/utf8"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// Segment is a part of the source with a single style.
+type Segment struct {
+	// Text is the text of the segment.
+	Text string
+
+	// At is the position of the segment in the source, in bytes.
+	At int
+
+	// Type is the name of the type of the token. Empty if the segment is not
+	// a token, such as a text that no rule matches.
+	Type string
+
+	// Style is the style of the segment.
+	Style Style
+
+	// Error is true if the segment is an error region.
+	Error bool
+}
+
+// Renderer is the interface that writes highlighted segments.
+type Renderer interface {
+	// Render writes the segments.
+	//
+	// Parameters:
+	//   - w: The writer to write to.
+	//   - segments: The segments, in the order of the source.
+	//
+	// Returns:
+	//   - error: An error if the segments could not be written.
+	Render(w io.Writer, segments []*Segment) error
+}
+
+// Highlighter is a highlighter that applies styles to tokens.
+type Highlighter[T gr.TokenTyper] struct {
+	// rules is a map of rules to apply.
+	rules map[T]Style
+
+	// default_style is the default style to apply.
+	default_style Style
+
+	// error_style is the style to apply to errors.
+	error_style Style
+
+	// theme is the theme of the tokens without a rule. Nil if there is none.
+	theme *Theme
+
+	// semantic are the rules that depend on the parse tree, in the order
+	// they were added.
+	semantic []*semantic_rule[T]
+
+	// lexer is the lexer to use.
+	lexer *lx.Lexer[T]
+}
+
+// NewHighlighter creates a new Highlighter.
+//
+// Parameters:
+//   - lexer: The lexer that splits the source into tokens.
+//   - default_style: The style of the tokens without a rule.
+//
+// Returns:
+//   - *Highlighter: The new Highlighter.
+//   - error: An error of type *uc.ErrInvalidParameter if the lexer is nil.
+//
+// Behaviors:
+//   - The error style is the default style until ChangeErrorStyle is
+//     called.
+func NewHighlighter[T gr.TokenTyper](lexer *lx.Lexer[T], default_style Style) (*Highlighter[T], error) {
+	if lexer == nil {
+		return nil, uc.NewErrNilParameter("lexer")
+	}
+
+	h := &Highlighter[T]{
+		rules:         make(map[T]Style),
+		default_style: default_style,
+		error_style:   default_style,
+		lexer:         lexer,
+	}
+	return h, nil
+}
+
+// SpecifyRule adds a rule to the highlighter.
+//
+// Parameters:
+//   - style: The style to apply.
+//   - ids: The IDs to apply the style to.
+func (h *Highlighter[T]) SpecifyRule(style Style, ids ...T) {
+	if h.rules == nil {
+		h.rules = make(map[T]Style)
+	}
+
+	for _, id := range ids {
+		h.rules[id] = style
+	}
+}
+
+// ChangeErrorStyle sets the error style.
+//
+// Parameters:
+//   - style: The style to apply to errors.
+func (h *Highlighter[T]) ChangeErrorStyle(style Style) {
+	h.error_style = style
+}
+
+// style_of is a helper method that returns the style of a type of token.
+//
+// Parameters:
+//   - id: The type of the token.
+//
+// Returns:
+//   - Style: The style of the rule of the type, if any. Otherwise, the
+//     style the theme gives to its name, if there is a theme. Otherwise, the
+//     default style.
+func (h *Highlighter[T]) style_of(id T) Style {
+	style, ok := h.rules[id]
+	if ok {
+		return style
+	}
+
+	if h.theme != nil {
+		return h.theme.StyleOf(id.String())
+	}
+
+	return h.default_style
+}
+
+// error_end is a helper function that returns the end of an error region;
+// that is, the first whitespace character after it.
+//
+// Parameters:
+//   - data: The source.
+//   - at: The start of the error region.
+//
+// Returns:
+//   - int: The end of the error region. Always after at.
+func error_end(data []byte, at int) int {
+	_, size := utf8.DecodeRune(data[at:])
+	at += size
+
+	for at < len(data) {
+		c, size := utf8.DecodeRune(data[at:])
+		if unicode.IsSpace(c) {
+			break
+		}
+
+		at += size
+	}
+
+	return at
+}
+
+// Highlight splits the source into segments styled by the rules.
+//
+// Parameters:
+//   - source: The source to highlight.
+//
+// Returns:
+//   - []*Segment: The segments, in the order of the source. Together, they
+//     cover the whole source.
+//
+// Behaviors:
+//   - The source is lexed without branching. See lx.Lexer.Step.
+//   - When no rule matches, the text up to the next whitespace character is
+//     an error region and lexing resumes after it. The error tokens of the
+//     recovery policy of the lexer are error regions as well.
+func (h *Highlighter[T]) Highlight(source []byte) []*Segment {
+	var segments []*Segment
+
+	modes := lx.NewModeStack()
+
+	for at := 0; at < len(source); {
+		step, err := h.lexer.Step(source, at, modes)
+		if err != nil {
+			end := error_end(source, at)
+
+			segments = append(segments, &Segment{
+				Text:  string(source[at:end]),
+				At:    at,
+				Style: h.error_style,
+				Error: true,
+			})
+
+			at = end
+
+			continue
+		}
+
+		tok := step.Token
+		text := tok.Data.(string)
+
+		seg := &Segment{
+			Text: text,
+			At:   at,
+			Type: tok.ID.String(),
+		}
+
+		if step.Error != nil {
+			seg.Style = h.error_style
+			seg.Error = true
+		} else {
+			seg.Style = h.style_of(tok.ID)
+		}
+
+		segments = append(segments, seg)
+
+		at += len(text)
+		modes = step.Modes
+	}
+
+	return segments
+}
+
+// Render highlights the source and writes it with a renderer.
+//
+// Parameters:
+//   - w: The writer to write to.
+//   - source: The source to highlight.
+//   - r: The renderer to use.
+//
+// Returns:
+//   - error: An error if the output could not be written.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If w or r is nil.
+//   - any error of the renderer.
+func (h *Highlighter[T]) Render(w io.Writer, source []byte, r Renderer) error {
+	if w == nil {
+		return uc.NewErrNilParameter("w")
+	}
+
+	if r == nil {
+		return uc.NewErrNilParameter("r")
+	}
+
+	return r.Render(w, h.Highlight(source))
+}
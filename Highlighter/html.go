@@ -0,0 +1,306 @@
+package Highlighter
+
+import (
+	"html"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultClassPrefix is the prefix of the CSS classes of an HTMLRenderer
+	// when none is given.
+	DefaultClassPrefix string = "lp-"
+)
+
+// HTMLRenderer is a renderer that writes the segments as HTML, where each
+// token is a span with a class derived from the name of its type.
+type HTMLRenderer struct {
+	// prefix is the prefix of the CSS classes and of the anchors.
+	prefix string
+
+	// line_numbers is true if every line starts with its number.
+	line_numbers bool
+
+	// anchors is true if every line has an anchor.
+	anchors bool
+}
+
+// NewHTMLRenderer creates a new HTMLRenderer.
+//
+// Parameters:
+//   - prefix: The prefix of the CSS classes and of the anchors. Empty uses
+//     DefaultClassPrefix.
+//
+// Returns:
+//   - *HTMLRenderer: The new renderer. Never nil.
+func NewHTMLRenderer(prefix string) *HTMLRenderer {
+	if prefix == "" {
+		prefix = DefaultClassPrefix
+	}
+
+	r := &HTMLRenderer{
+		prefix: prefix,
+	}
+
+	return r
+}
+
+// SetLineNumbers enables or disables the line numbers and the anchors.
+//
+// Parameters:
+//   - numbers: True to start every line with its number.
+//   - anchors: True to give every line an id, <prefix>L<n>, that can be
+//     linked to. With numbers, the number is a link to its line.
+func (r *HTMLRenderer) SetLineNumbers(numbers, anchors bool) {
+	r.line_numbers = numbers
+	r.anchors = anchors
+}
+
+// ClassOf returns the CSS class of a token type.
+//
+// Parameters:
+//   - name: The name of the token type.
+//
+// Returns:
+//   - string: The class; that is, the prefix, "t-", then the name where
+//     every byte other than an ASCII letter, a digit and '_' is written as
+//     '-' followed by its two upper-case hexadecimal digits. For example,
+//     "KW.IF" is "lp-t-KW-2EIF" and "KW-IF" is "lp-t-KW-2DIF".
+//
+// Behaviors:
+//   - Two different names never have the same class, and the classes of
+//     the token types never clash with those of the renderer itself, such
+//     as <prefix>code or <prefix>error.
+func (r *HTMLRenderer) ClassOf(name string) string {
+	const hex = "0123456789ABCDEF"
+
+	var builder strings.Builder
+
+	builder.WriteString(r.prefix)
+	builder.WriteString("t-")
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+
+		if c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			builder.WriteByte(c)
+		} else {
+			builder.WriteByte('-')
+			builder.WriteByte(hex[c>>4])
+			builder.WriteByte(hex[c&0x0F])
+		}
+	}
+
+	return builder.String()
+}
+
+// classes_of is a helper method that returns the classes of a segment.
+//
+// Parameters:
+//   - seg: The segment.
+//
+// Returns:
+//   - string: The classes, separated by spaces. Empty if the segment has
+//     none.
+func (r *HTMLRenderer) classes_of(seg *Segment) string {
+	var classes []string
+
+	if seg.Type != "" {
+		classes = append(classes, r.ClassOf(seg.Type))
+	}
+
+	if seg.Error {
+		classes = append(classes, r.prefix+"error")
+	}
+
+	return strings.Join(classes, " ")
+}
+
+// start_line is a helper method that writes the start of a line.
+//
+// Parameters:
+//   - builder: The builder to write to.
+//   - n: The number of the line, starting at 1.
+func (r *HTMLRenderer) start_line(builder *strings.Builder, n int) {
+	if !r.line_numbers && !r.anchors {
+		return
+	}
+
+	num := strconv.Itoa(n)
+	id := r.prefix + "L" + num
+
+	builder.WriteString(`<span class="` + r.prefix + `line"`)
+
+	if r.anchors {
+		builder.WriteString(` id="` + id + `"`)
+	}
+
+	builder.WriteByte('>')
+
+	if !r.line_numbers {
+		return
+	}
+
+	if r.anchors {
+		builder.WriteString(`<a class="` + r.prefix + `ln" href="#` + id + `">` + num + `</a>`)
+	} else {
+		builder.WriteString(`<span class="` + r.prefix + `ln">` + num + `</span>`)
+	}
+}
+
+// end_line is a helper method that writes the end of a line.
+//
+// Parameters:
+//   - builder: The builder to write to.
+func (r *HTMLRenderer) end_line(builder *strings.Builder) {
+	if r.line_numbers || r.anchors {
+		builder.WriteString("</span>")
+	}
+}
+
+// Render implements the Renderer interface.
+//
+// Format:
+//
+//	<pre class="<prefix>code"><code>...</code></pre>
+//
+// Behaviors:
+//   - The text is escaped. A span that contains a line break is closed
+//     before it and opened again after it, so every line is well formed.
+//   - Error regions have the <prefix>error class as well.
+func (r *HTMLRenderer) Render(w io.Writer, segments []*Segment) error {
+	var builder strings.Builder
+
+	builder.WriteString(`<pre class="` + r.prefix + `code"><code>`)
+
+	line := 1
+	r.start_line(&builder, line)
+
+	for _, seg := range segments {
+		classes := r.classes_of(seg)
+
+		for i, part := range strings.Split(seg.Text, "\n") {
+			if i > 0 {
+				r.end_line(&builder)
+				builder.WriteByte('\n')
+
+				line++
+				r.start_line(&builder, line)
+			}
+
+			if part == "" {
+				continue
+			}
+
+			if classes == "" {
+				builder.WriteString(html.EscapeString(part))
+				continue
+			}
+
+			builder.WriteString(`<span class="` + classes + `">`)
+			builder.WriteString(html.EscapeString(part))
+			builder.WriteString("</span>")
+		}
+	}
+
+	r.end_line(&builder)
+
+	builder.WriteString("</code></pre>\n")
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// css_of is a helper function that returns the CSS declarations of a style.
+//
+// Parameters:
+//   - style: The style.
+//
+// Returns:
+//   - string: The declarations, separated by spaces. Empty if the style is
+//     plain.
+func css_of(style Style) string {
+	var decls []string
+
+	if !style.Fg.IsDefault() {
+		decls = append(decls, "color: "+style.Fg.Hex()+";")
+	}
+
+	if !style.Bg.IsDefault() {
+		decls = append(decls, "background-color: "+style.Bg.Hex()+";")
+	}
+
+	if style.Bold {
+		decls = append(decls, "font-weight: bold;")
+	}
+
+	if style.Italic {
+		decls = append(decls, "font-style: italic;")
+	}
+
+	if style.Underline {
+		decls = append(decls, "text-decoration: underline;")
+	}
+
+	return strings.Join(decls, " ")
+}
+
+// Stylesheet returns the CSS stylesheet that matches the classes of the
+// renderer.
+//
+// Parameters:
+//   - theme: The theme to use.
+//
+// Returns:
+//   - string: The stylesheet. One rule per line, with the token types
+//     sorted by class, so the output is stable. Since every token type has
+//     its own class (see ClassOf), no selector is written twice.
+//
+// Behaviors:
+//   - The patterns of the theme are ignored, since a class cannot be
+//     derived from them. Use Theme.Resolve or Highlighter.GetTheme to get a
+//     theme with a style for every token type.
+func (r *HTMLRenderer) Stylesheet(theme *Theme) string {
+	var builder strings.Builder
+
+	write_rule := func(selector string, style Style) {
+		css := css_of(style)
+		if css == "" {
+			return
+		}
+
+		builder.WriteString(selector + " { " + css + " }\n")
+	}
+
+	write_rule("."+r.prefix+"code", theme.Default)
+
+	classes := make([]string, 0, len(theme.Styles))
+	styles := make(map[string]Style, len(theme.Styles))
+
+	for name, style := range theme.Styles {
+		if is_pattern(name) {
+			continue
+		}
+
+		class := r.ClassOf(name)
+
+		classes = append(classes, class)
+		styles[class] = style
+	}
+
+	slices.Sort(classes)
+
+	for _, class := range classes {
+		write_rule("."+class, styles[class])
+	}
+
+	write_rule("."+r.prefix+"error", theme.Error)
+
+	if r.line_numbers {
+		builder.WriteString("." + r.prefix + "ln { display: inline-block; min-width: 3em; padding-right: 1em; text-align: right; user-select: none; opacity: 0.6; color: inherit; text-decoration: none; }\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,124 @@
+package Highlighter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLRenderer(t *testing.T) {
+	segments := []*Segment{
+		{Text: "if", Type: "KW_IF"},
+		{Text: " ", Type: "WS"},
+		{Text: "a<b\n&", Type: "EXPR.1"},
+		{Text: "$", Error: true},
+	}
+
+	tests := []struct {
+		numbers bool
+		anchors bool
+		want    string
+	}{
+		{
+			want: `<pre class="lp-code"><code><span class="lp-t-KW_IF">if</span><span class="lp-t-WS"> </span><span class="lp-t-EXPR-2E1">a&lt;b</span>` + "\n" + `<span class="lp-t-EXPR-2E1">&amp;</span><span class="lp-error">$</span></code></pre>` + "\n",
+		},
+		{
+			numbers: true,
+			anchors: true,
+			want:    `<pre class="lp-code"><code><span class="lp-line" id="lp-L1"><a class="lp-ln" href="#lp-L1">1</a><span class="lp-t-KW_IF">if</span><span class="lp-t-WS"> </span><span class="lp-t-EXPR-2E1">a&lt;b</span></span>` + "\n" + `<span class="lp-line" id="lp-L2"><a class="lp-ln" href="#lp-L2">2</a><span class="lp-t-EXPR-2E1">&amp;</span><span class="lp-error">$</span></span></code></pre>` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		r := NewHTMLRenderer("")
+		r.SetLineNumbers(test.numbers, test.anchors)
+
+		var builder strings.Builder
+
+		err := r.Render(&builder, segments)
+		if err != nil {
+			t.Fatalf("Render failed: %s", err.Error())
+		}
+
+		if builder.String() != test.want {
+			t.Errorf("expected %q, got %q", test.want, builder.String())
+		}
+	}
+}
+
+func TestStylesheet(t *testing.T) {
+	theme := NewTheme(Style{})
+	theme.Styles["KW_IF"] = Style{Fg: ColorRGB(255, 0, 0), Bold: true}
+	theme.Styles["WS"] = Style{}
+	theme.Error = Style{Underline: true}
+
+	want := ".lp-t-KW_IF { color: #ff0000; font-weight: bold; }\n.lp-error { text-decoration: underline; }\n"
+
+	got := NewHTMLRenderer("").Stylesheet(theme)
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestClassOf(t *testing.T) {
+	r := NewHTMLRenderer("")
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"KW_IF", "lp-t-KW_IF"},
+		{"KW.IF", "lp-t-KW-2EIF"},
+		{"KW-IF", "lp-t-KW-2DIF"},
+		{"KW-2EIF", "lp-t-KW-2D2EIF"},
+		{"é", "lp-t--C3-A9"},
+		{"error", "lp-t-error"},
+		{"line", "lp-t-line"},
+		{"ln", "lp-t-ln"},
+		{"code", "lp-t-code"},
+	}
+
+	classes := make(map[string]string, len(tests))
+
+	for _, test := range tests {
+		got := r.ClassOf(test.name)
+		if got != test.want {
+			t.Errorf("%q: expected %q, got %q", test.name, test.want, got)
+		}
+
+		other, ok := classes[got]
+		if ok {
+			t.Errorf("%q and %q have the same class %q", test.name, other, got)
+		}
+
+		classes[got] = test.name
+	}
+
+	// The classes of the renderer itself are not those of any token type.
+	for _, own := range []string{"lp-error", "lp-line", "lp-ln", "lp-code"} {
+		name, ok := classes[own]
+		if ok {
+			t.Errorf("the token type %q has the class %q of the renderer", name, own)
+		}
+	}
+}
+
+func TestStylesheetCollisions(t *testing.T) {
+	theme := NewTheme(Style{})
+	theme.Styles["KW.IF"] = Style{Bold: true}
+	theme.Styles["KW-IF"] = Style{Italic: true}
+	theme.Styles["error"] = Style{Fg: ColorRGB(0, 0, 255)}
+	theme.Error = Style{Underline: true}
+
+	want := ".lp-t-KW-2DIF { font-style: italic; }\n" +
+		".lp-t-KW-2EIF { font-weight: bold; }\n" +
+		".lp-t-error { color: #0000ff; }\n" +
+		".lp-error { text-decoration: underline; }\n"
+
+	// The output does not depend on the order of the map.
+	for i := 0; i < 10; i++ {
+		got := NewHTMLRenderer("").Stylesheet(theme)
+		if got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package Highlighter
+
+import (
+	"io"
+	"slices"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// semantic_rule is a rule that styles the tokens of a type by their place in
+// the parse tree.
+type semantic_rule[T gr.TokenTyper] struct {
+	// path are the closest ancestors of the token, from the outermost to the
+	// parent.
+	path []T
+
+	// id is the type of the tokens.
+	id T
+
+	// style is the style to apply.
+	style Style
+}
+
+// matches is a helper method that checks whether the rule applies to a
+// token.
+//
+// Parameters:
+//   - id: The type of the token.
+//   - ancestors: The ancestors of the token, from the root to the parent.
+//
+// Returns:
+//   - bool: True if the rule applies, false otherwise.
+func (r *semantic_rule[T]) matches(id T, ancestors []T) bool {
+	if id != r.id || len(r.path) > len(ancestors) {
+		return false
+	}
+
+	return slices.Equal(r.path, ancestors[len(ancestors)-len(r.path):])
+}
+
+// SpecifySemanticRule adds a rule that styles the tokens by their place in
+// the parse tree. For instance, the rule with the path [fieldCls] and the
+// type WORD styles the WORD tokens whose parent is a fieldCls.
+//
+// Parameters:
+//   - style: The style to apply.
+//   - path: The closest ancestors of the tokens, from the outermost to the
+//     parent. Empty applies to every token of the types.
+//   - ids: The types of the tokens to apply the style to.
+//
+// Behaviors:
+//   - The rules only apply to HighlightTree and RenderTree. When several
+//     rules apply to a token, the one with the longest path wins; ties go
+//     to the rule added first.
+//   - A semantic rule that applies wins over the rules added with
+//     SpecifyRule and over the theme.
+func (h *Highlighter[T]) SpecifySemanticRule(style Style, path []T, ids ...T) {
+	for _, id := range ids {
+		h.semantic = append(h.semantic, &semantic_rule[T]{
+			path:  slices.Clone(path),
+			id:    id,
+			style: style,
+		})
+	}
+}
+
+// semantic_style is a helper method that returns the style of the most
+// specific semantic rule that applies to a token.
+//
+// Parameters:
+//   - id: The type of the token.
+//   - ancestors: The ancestors of the token, from the root to the parent.
+//
+// Returns:
+//   - Style: The style of the rule.
+//   - bool: False if no rule applies.
+func (h *Highlighter[T]) semantic_style(id T, ancestors []T) (Style, bool) {
+	var best *semantic_rule[T]
+
+	for _, rule := range h.semantic {
+		if !rule.matches(id, ancestors) {
+			continue
+		}
+
+		if best == nil || len(rule.path) > len(best.path) {
+			best = rule
+		}
+	}
+
+	if best == nil {
+		return Style{}, false
+	}
+
+	return best.style, true
+}
+
+// tree_leaf is a leaf of a parse tree with its ancestors.
+type tree_leaf[T gr.TokenTyper] struct {
+	// id is the type of the leaf.
+	id T
+
+	// ancestors are the types of the ancestors, from the root to the parent.
+	ancestors []T
+}
+
+// collect_leaves is a helper function that collects the leaves of a parse
+// tree by position.
+//
+// Parameters:
+//   - node: The node to visit.
+//   - ancestors: The types of the ancestors of the node.
+//   - leaves: The leaves collected so far.
+func collect_leaves[T gr.TokenTyper](node *gr.Token[T], ancestors []T, leaves map[int]*tree_leaf[T]) {
+	switch data := node.Data.(type) {
+	case string:
+		leaves[node.At] = &tree_leaf[T]{
+			id:        node.ID,
+			ancestors: slices.Clone(ancestors),
+		}
+	case []*gr.Token[T]:
+		ancestors = append(ancestors, node.ID)
+
+		for _, child := range data {
+			collect_leaves(child, ancestors, leaves)
+		}
+	}
+}
+
+// apply_semantic is a helper method that applies the semantic rules to the
+// segments of a source.
+//
+// Parameters:
+//   - segments: The segments of the source.
+//   - root: The parse tree of the source.
+func (h *Highlighter[T]) apply_semantic(segments []*Segment, root *gr.Token[T]) {
+	leaves := make(map[int]*tree_leaf[T])
+	collect_leaves(root, nil, leaves)
+
+	for _, seg := range segments {
+		if seg.Error {
+			continue
+		}
+
+		leaf, ok := leaves[seg.At]
+		if !ok || leaf.id.String() != seg.Type {
+			continue
+		}
+
+		style, ok := h.semantic_style(leaf.id, leaf.ancestors)
+		if ok {
+			seg.Style = style
+		}
+	}
+}
+
+// HighlightTree is like Highlight, but it also applies the semantic rules
+// with the parse tree of the source.
+//
+// Parameters:
+//   - source: The source to highlight.
+//   - root: The parse tree of the source. Nil is the same as Highlight.
+//
+// Returns:
+//   - []*Segment: The segments, in the order of the source.
+//
+// Behaviors:
+//   - A segment takes the place of a leaf of the tree when they start at the
+//     same position and have the same type. The other segments, such as the
+//     skipped tokens and the error regions, keep their style.
+func (h *Highlighter[T]) HighlightTree(source []byte, root *gr.Token[T]) []*Segment {
+	segments := h.Highlight(source)
+
+	if root != nil && len(h.semantic) > 0 {
+		h.apply_semantic(segments, root)
+	}
+
+	return segments
+}
+
+// RenderTree is like Render, but it also applies the semantic rules with the
+// parse tree of the source.
+//
+// Parameters:
+//   - w: The writer to write to.
+//   - source: The source to highlight.
+//   - root: The parse tree of the source. Nil is the same as Render.
+//   - r: The renderer to use.
+//
+// Returns:
+//   - error: An error if the output could not be written.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If w or r is nil.
+//   - any error of the renderer.
+func (h *Highlighter[T]) RenderTree(w io.Writer, source []byte, root *gr.Token[T], r Renderer) error {
+	if w == nil {
+		return uc.NewErrNilParameter("w")
+	}
+
+	if r == nil {
+		return uc.NewErrNilParameter("r")
+	}
+
+	return r.Render(w, h.HighlightTree(source, root))
+}
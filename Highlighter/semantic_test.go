@@ -0,0 +1,270 @@
+package Highlighter
+
+import (
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+	ps "github.com/PlayerR9/LyneParser/Parser"
+	"github.com/gdamore/tcell"
+)
+
+type SemanticTokenType int
+
+const (
+	StWord SemanticTokenType = iota
+	StDot
+	StField
+	StCall
+	StExpr
+)
+
+func (t SemanticTokenType) IsTerminal() bool {
+	return t <= StDot
+}
+
+func (t SemanticTokenType) String() string {
+	return [...]string{
+		"WORD",
+		"DOT",
+		"field",
+		"call",
+		"expr",
+	}[t]
+}
+
+func TestHighlightTree(t *testing.T) {
+	// a.b(c) where b is a field called with c as argument.
+	leaf := func(id SemanticTokenType, at int, text string) *gr.Token[SemanticTokenType] {
+		return &gr.Token[SemanticTokenType]{ID: id, At: at, Data: text}
+	}
+
+	node := func(id SemanticTokenType, children ...*gr.Token[SemanticTokenType]) *gr.Token[SemanticTokenType] {
+		return &gr.Token[SemanticTokenType]{ID: id, At: children[0].At, Data: children}
+	}
+
+	root := node(StExpr,
+		node(StCall,
+			node(StField, leaf(StWord, 0, "a"), leaf(StDot, 1, "."), leaf(StWord, 2, "b")),
+			node(StExpr, leaf(StWord, 4, "c")),
+		),
+	)
+
+	segments := []*Segment{
+		{Text: "a", At: 0, Type: "WORD"},
+		{Text: ".", At: 1, Type: "DOT"},
+		{Text: "b", At: 2, Type: "WORD"},
+		{Text: "(", At: 3, Error: true},
+		{Text: "c", At: 4, Type: "WORD"},
+		{Text: ")", At: 5, Error: true},
+	}
+
+	field := Style{Bold: true}
+	call_field := Style{Italic: true}
+	word := Style{Underline: true}
+
+	h := &Highlighter[SemanticTokenType]{}
+	h.SpecifySemanticRule(word, nil, StWord)
+	h.SpecifySemanticRule(call_field, []SemanticTokenType{StCall, StField}, StWord)
+	h.SpecifySemanticRule(field, []SemanticTokenType{StField}, StWord)
+
+	h.apply_semantic(segments, root)
+
+	want := []Style{call_field, {}, call_field, {}, word, {}}
+
+	for i, seg := range segments {
+		if seg.Style != want[i] {
+			t.Errorf("segment %d (%q): expected %+v, got %+v", i, seg.Text, want[i], seg.Style)
+		}
+	}
+}
+
+type AssignTokenType int
+
+const (
+	AtEof AssignTokenType = iota
+	AtWord
+	AtEq
+	AtWs
+	AtSource
+	AtStmt
+	AtName
+	AtValue
+)
+
+func (t AssignTokenType) IsTerminal() bool {
+	return t <= AtWs
+}
+
+func (t AssignTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"WORD",
+		"EQ",
+		"WS",
+		gr.StartSymbolID,
+		"stmt",
+		"name",
+		"value",
+	}[t]
+}
+
+// new_assign_language creates the lexer and the parser of assignments such
+// as "x = y". The spaces are skipped.
+//
+//	source -> stmt EOF
+//	stmt -> name EQ value
+//	name -> WORD
+//	value -> WORD
+func new_assign_language(t *testing.T) (*lx.Lexer[AssignTokenType], *ps.Parser[AssignTokenType]) {
+	lexer_grammar := lx.NewGrammar([]AssignTokenType{AtWs})
+
+	lexer_rules := []struct {
+		lhs   AssignTokenType
+		regex string
+	}{
+		{AtWord, `[a-z]+`},
+		{AtEq, `=`},
+		{AtWs, ` +`},
+	}
+
+	for _, rule := range lexer_rules {
+		err := lexer_grammar.AddRule(rule.lhs, rule.regex)
+		if err != nil {
+			t.Fatalf("AddRule(%s) failed: %s", rule.lhs, err.Error())
+		}
+	}
+
+	lexer := lx.NewLexer(lexer_grammar)
+	lexer.SetEOF(AtEof)
+
+	parser_grammar, err := ps.NewGrammar[AssignTokenType]()
+	if err != nil {
+		t.Fatalf("NewGrammar failed: %s", err.Error())
+	}
+
+	parser_rules := []struct {
+		lhs AssignTokenType
+		rhs []AssignTokenType
+	}{
+		{AtSource, []AssignTokenType{AtStmt, AtEof}},
+		{AtStmt, []AssignTokenType{AtName, AtEq, AtValue}},
+		{AtName, []AssignTokenType{AtWord}},
+		{AtValue, []AssignTokenType{AtWord}},
+	}
+
+	for _, rule := range parser_rules {
+		err := parser_grammar.AddRule(rule.lhs, rule.rhs)
+		if err != nil {
+			t.Fatalf("AddRule failed: %s", err.Error())
+		}
+	}
+
+	parser, err := ps.NewParser(parser_grammar)
+	if err != nil {
+		t.Fatalf("NewParser failed: %s", err.Error())
+	}
+
+	return lexer, parser
+}
+
+func TestHighlightParsedSource(t *testing.T) {
+	const data = `{
+		"default": { "fg": "#d0d0d0" },
+		"error": { "fg": "red", "underline": true },
+		"styles": {
+			"WORD": { "fg": "blue" },
+			"EQ": { "bold": true }
+		}
+	}`
+
+	source := []byte("x = y")
+
+	lexer, parser := new_assign_language(t)
+
+	stream, err := lexer.Lex(source).Consume()
+	if err != nil {
+		t.Fatalf("Lex failed: %s", err.Error())
+	}
+
+	err = ps.Parse(parser, stream)
+	if err != nil {
+		t.Fatalf("Parse failed: %s", err.Error())
+	}
+
+	evals, err := parser.GetEvals()
+	if err != nil {
+		t.Fatalf("GetEvals failed: %s", err.Error())
+	}
+
+	root, _ := evals[0].GetStack().Peek()
+
+	theme, err := ParseTheme([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseTheme failed: %s", err.Error())
+	}
+
+	h, err := NewHighlighter(lexer, Style{})
+	if err != nil {
+		t.Fatalf("NewHighlighter failed: %s", err.Error())
+	}
+
+	err = h.ApplyTheme(theme)
+	if err != nil {
+		t.Fatalf("ApplyTheme failed: %s", err.Error())
+	}
+
+	// The assigned name stands out from the value.
+	h.SpecifySemanticRule(Style{Fg: ColorIndex(3), Bold: true}, []AssignTokenType{AtName}, AtWord)
+
+	fg := ColorRGB(0xd0, 0xd0, 0xd0)
+	word := Style{Fg: ColorIndex(4)}
+	name := Style{Fg: ColorIndex(3), Bold: true}
+	eq := Style{Fg: fg, Bold: true}
+	ws := Style{Fg: fg}
+
+	tests := []struct {
+		name     string
+		segments []*Segment
+		want     []Style
+	}{
+		{"Highlight", h.Highlight(source), []Style{word, ws, eq, ws, word}},
+		{"HighlightTree", h.HighlightTree(source, root), []Style{name, ws, eq, ws, word}},
+	}
+
+	for _, test := range tests {
+		if len(test.segments) != len(test.want) {
+			t.Fatalf("%s: expected %d segments, got %d", test.name, len(test.want), len(test.segments))
+		}
+
+		for i, seg := range test.segments {
+			if seg.Style != test.want[i] {
+				t.Errorf("%s: segment %d (%q): expected %+v, got %+v", test.name, i, seg.Text, test.want[i], seg.Style)
+			}
+		}
+	}
+
+	// An error region takes the error style of the theme.
+	segments := h.HighlightTree([]byte("x = #"), nil)
+
+	last := segments[len(segments)-1]
+	if !last.Error || last.Style != theme.Error {
+		t.Errorf("expected the error region to have the error style, got %+v", last.Style)
+	}
+
+	// The same styles drive the terminal applications.
+	fg_tcell, bg_tcell, attrs := segments[0].Style.Tcell().Decompose()
+	if fg_tcell != tcell.Color(4) || bg_tcell != tcell.ColorDefault || attrs != tcell.AttrNone {
+		t.Errorf("unexpected tcell style for %q: %v %v %v", segments[0].Text, fg_tcell, bg_tcell, attrs)
+	}
+
+	fg_tcell, _, attrs = eq.Tcell().Decompose()
+	if fg_tcell != tcell.NewRGBColor(0xd0, 0xd0, 0xd0) || attrs != tcell.AttrBold {
+		t.Errorf("unexpected tcell style for \"=\": %v %v", fg_tcell, attrs)
+	}
+
+	fg_tcell, _, attrs = theme.Error.Tcell().Decompose()
+	if fg_tcell != tcell.Color(1) || attrs != tcell.AttrUnderline {
+		t.Errorf("unexpected tcell style for the errors: %v %v", fg_tcell, attrs)
+	}
+}
@@ -0,0 +1,309 @@
+package Highlighter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// color_kind is the kind of a color.
+type color_kind int
+
+const (
+	// default_color is the default color of the terminal.
+	default_color color_kind = iota
+
+	// indexed_color is a color of the 256-color palette.
+	indexed_color
+
+	// rgb_color is a 24-bit color.
+	rgb_color
+)
+
+// Color is the color of the foreground or of the background of a text. The
+// zero value is the default color of the output.
+type Color struct {
+	// kind is the kind of the color.
+	kind color_kind
+
+	// index is the index in the 256-color palette. Only used by
+	// indexed_color.
+	index uint8
+
+	// r, g and b are the components of a 24-bit color. Only used by
+	// rgb_color.
+	r, g, b uint8
+}
+
+// String implements the fmt.Stringer interface.
+//
+// Format:
+//
+//	default | <index> | #rrggbb
+func (c Color) String() string {
+	switch c.kind {
+	case indexed_color:
+		return strconv.Itoa(int(c.index))
+	case rgb_color:
+		return c.Hex()
+	default:
+		return "default"
+	}
+}
+
+// DefaultColor is the default color of the output.
+var DefaultColor Color
+
+// ColorIndex creates a color of the 256-color palette.
+//
+// Parameters:
+//   - index: The index in the palette.
+//
+// Returns:
+//   - Color: The color.
+func ColorIndex(index uint8) Color {
+	return Color{
+		kind:  indexed_color,
+		index: index,
+	}
+}
+
+// ColorRGB creates a 24-bit color.
+//
+// Parameters:
+//   - r: The red component.
+//   - g: The green component.
+//   - b: The blue component.
+//
+// Returns:
+//   - Color: The color.
+func ColorRGB(r, g, b uint8) Color {
+	return Color{
+		kind: rgb_color,
+		r:    r,
+		g:    g,
+		b:    b,
+	}
+}
+
+// IsDefault checks whether the color is the default color of the output.
+//
+// Returns:
+//   - bool: True if the color is the default one, false otherwise.
+func (c Color) IsDefault() bool {
+	return c.kind == default_color
+}
+
+// RGB returns the components of the color.
+//
+// Returns:
+//   - uint8: The red component.
+//   - uint8: The green component.
+//   - uint8: The blue component.
+//
+// Behaviors:
+//   - The colors of the palette are converted with the usual xterm values.
+//     The default color is black.
+func (c Color) RGB() (uint8, uint8, uint8) {
+	switch c.kind {
+	case rgb_color:
+		return c.r, c.g, c.b
+	case indexed_color:
+		return palette_rgb(c.index)
+	default:
+		return 0, 0, 0
+	}
+}
+
+// Hex returns the color in the #rrggbb notation.
+//
+// Returns:
+//   - string: The color. Empty for the default color.
+func (c Color) Hex() string {
+	if c.kind == default_color {
+		return ""
+	}
+
+	const digits = "0123456789abcdef"
+
+	r, g, b := c.RGB()
+
+	buf := []byte{'#', 0, 0, 0, 0, 0, 0}
+
+	for i, v := range [...]uint8{r, g, b} {
+		buf[1+2*i] = digits[v>>4]
+		buf[2+2*i] = digits[v&0xf]
+	}
+
+	return string(buf)
+}
+
+// cube_levels are the levels of the components of the 6x6x6 color cube of
+// the 256-color palette.
+var cube_levels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// system_colors are the xterm values of the first 16 colors of the palette.
+var system_colors = [16][3]uint8{
+	{0, 0, 0}, {128, 0, 0}, {0, 128, 0}, {128, 128, 0},
+	{0, 0, 128}, {128, 0, 128}, {0, 128, 128}, {192, 192, 192},
+	{128, 128, 128}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{0, 0, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// palette_rgb is a helper function that returns the components of a color
+// of the 256-color palette.
+//
+// Parameters:
+//   - index: The index in the palette.
+//
+// Returns:
+//   - uint8: The red component.
+//   - uint8: The green component.
+//   - uint8: The blue component.
+func palette_rgb(index uint8) (uint8, uint8, uint8) {
+	switch {
+	case index < 16:
+		c := system_colors[index]
+		return c[0], c[1], c[2]
+	case index < 232:
+		i := index - 16
+		return cube_levels[i/36], cube_levels[(i/6)%6], cube_levels[i%6]
+	default:
+		v := 8 + 10*(index-232)
+		return v, v, v
+	}
+}
+
+// to_256 is a helper method that returns the closest color of the
+// 256-color palette.
+//
+// Returns:
+//   - uint8: The index in the palette.
+//
+// Behaviors:
+//   - Only the color cube and the grayscale ramp are considered, since the
+//     first 16 colors depend on the theme of the terminal.
+func (c Color) to_256() uint8 {
+	if c.kind == indexed_color {
+		return c.index
+	}
+
+	r, g, b := c.RGB()
+
+	cube_index := func(v uint8) uint8 {
+		switch {
+		case v < 48:
+			return 0
+		case v < 115:
+			return 1
+		default:
+			return (v - 35) / 40
+		}
+	}
+
+	ri, gi, bi := cube_index(r), cube_index(g), cube_index(b)
+	cube := 16 + 36*ri + 6*gi + bi
+
+	avg := (int(r) + int(g) + int(b)) / 3
+
+	gray := uint8(232)
+	if avg > 238 {
+		gray = 255
+	} else if avg > 8 {
+		gray = uint8(232 + (avg-3)/10)
+	}
+
+	distance := func(index uint8) int {
+		pr, pg, pb := palette_rgb(index)
+
+		dr := int(pr) - int(r)
+		dg := int(pg) - int(g)
+		db := int(pb) - int(b)
+
+		return dr*dr + dg*dg + db*db
+	}
+
+	if distance(gray) < distance(cube) {
+		return gray
+	}
+
+	return cube
+}
+
+// Style is the way a text is displayed.
+type Style struct {
+	// Fg is the color of the text.
+	Fg Color
+
+	// Bg is the color of the background.
+	Bg Color
+
+	// Bold is true if the text is bold.
+	Bold bool
+
+	// Italic is true if the text is in italics.
+	Italic bool
+
+	// Underline is true if the text is underlined.
+	Underline bool
+}
+
+// IsPlain checks whether the style changes nothing to the text.
+//
+// Returns:
+//   - bool: True if the style is plain, false otherwise.
+func (s Style) IsPlain() bool {
+	return s == Style{}
+}
+
+// color_names are the names of the first 16 colors of the palette.
+var color_names = map[string]uint8{
+	"black": 0, "red": 1, "green": 2, "yellow": 3,
+	"blue": 4, "magenta": 5, "cyan": 6, "white": 7,
+	"bright-black": 8, "bright-red": 9, "bright-green": 10, "bright-yellow": 11,
+	"bright-blue": 12, "bright-magenta": 13, "bright-cyan": 14, "bright-white": 15,
+}
+
+// ParseColor parses a color.
+//
+// Format:
+//
+//	default | <index> | #rrggbb | <name>
+//
+// where <name> is one of the 16 colors of the palette, such as "red" or
+// "bright-blue".
+//
+// Parameters:
+//   - str: The string to parse.
+//
+// Returns:
+//   - Color: The color.
+//   - error: An error of type *ErrInvalidColor if the string is not a color.
+func ParseColor(str string) (Color, error) {
+	if str == "default" {
+		return DefaultColor, nil
+	}
+
+	if index, ok := color_names[str]; ok {
+		return ColorIndex(index), nil
+	}
+
+	if strings.HasPrefix(str, "#") {
+		if len(str) != 7 {
+			return DefaultColor, NewErrInvalidColor(str)
+		}
+
+		v, err := strconv.ParseUint(str[1:], 16, 32)
+		if err != nil {
+			return DefaultColor, NewErrInvalidColor(str)
+		}
+
+		return ColorRGB(uint8(v>>16), uint8(v>>8), uint8(v)), nil
+	}
+
+	index, err := strconv.ParseUint(str, 10, 8)
+	if err != nil {
+		return DefaultColor, NewErrInvalidColor(str)
+	}
+
+	return ColorIndex(uint8(index)), nil
+}
@@ -0,0 +1,37 @@
+package Highlighter
+
+import (
+	"github.com/gdamore/tcell"
+)
+
+// tcell_color is a helper method that converts the color to a color of
+// tcell.
+//
+// Returns:
+//   - tcell.Color: The color.
+func (c Color) tcell_color() tcell.Color {
+	switch c.kind {
+	case indexed_color:
+		return tcell.Color(c.index)
+	case rgb_color:
+		return tcell.NewRGBColor(int32(c.r), int32(c.g), int32(c.b))
+	default:
+		return tcell.ColorDefault
+	}
+}
+
+// Tcell converts the style to a style of tcell, so that the same theme
+// drives the terminal applications.
+//
+// Returns:
+//   - tcell.Style: The style.
+func (s Style) Tcell() tcell.Style {
+	style := tcell.StyleDefault.
+		Foreground(s.Fg.tcell_color()).
+		Background(s.Bg.tcell_color()).
+		Bold(s.Bold).
+		Italic(s.Italic).
+		Underline(s.Underline)
+
+	return style
+}
@@ -0,0 +1,462 @@
+package Highlighter
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// Theme is a set of styles keyed by the names of the token types, so it does
+// not depend on the type of the tokens of a grammar.
+type Theme struct {
+	// Default is the style of the tokens without a style.
+	Default Style
+
+	// Error is the style of the error regions.
+	Error Style
+
+	// Styles is the style of each token type, by name. A key that contains
+	// one of the characters '*', '?' or '[' is a pattern with the syntax of
+	// path.Match, such as "KW_*", that applies to every type it matches.
+	Styles map[string]Style
+}
+
+// NewTheme creates an empty theme.
+//
+// Parameters:
+//   - default_style: The style of the tokens without a style. It is also
+//     the style of the error regions.
+//
+// Returns:
+//   - *Theme: The new theme. Never nil.
+func NewTheme(default_style Style) *Theme {
+	t := &Theme{
+		Default: default_style,
+		Error:   default_style,
+		Styles:  make(map[string]Style),
+	}
+
+	return t
+}
+
+// is_pattern is a helper function that checks whether a key of a theme is a
+// pattern.
+//
+// Parameters:
+//   - key: The key.
+//
+// Returns:
+//   - bool: True if the key is a pattern, false otherwise.
+func is_pattern(key string) bool {
+	return strings.ContainsAny(key, "*?[")
+}
+
+// specificity is a helper function that returns how specific a pattern is;
+// that is, the number of characters that are not wildcards.
+//
+// Parameters:
+//   - pattern: The pattern.
+//
+// Returns:
+//   - int: The specificity.
+func specificity(pattern string) int {
+	return len(pattern) - strings.Count(pattern, "*") - strings.Count(pattern, "?")
+}
+
+// StyleOf returns the style of a token type.
+//
+// Parameters:
+//   - name: The name of the token type.
+//
+// Returns:
+//   - Style: The style. The default style if the type has none.
+//
+// Behaviors:
+//   - A key equal to the name wins over the patterns. Among the patterns
+//     that match, the most specific one wins; ties go to the longest
+//     pattern, then to the first one in lexicographic order.
+func (t *Theme) StyleOf(name string) Style {
+	style, ok := t.Styles[name]
+	if ok {
+		return style
+	}
+
+	var best string
+	found := false
+
+	for key := range t.Styles {
+		if !is_pattern(key) {
+			continue
+		}
+
+		ok, err := path.Match(key, name)
+		if err != nil || !ok {
+			continue
+		}
+
+		if found {
+			s1, s2 := specificity(key), specificity(best)
+
+			if s1 < s2 || (s1 == s2 && (len(key) < len(best) || (len(key) == len(best) && key > best))) {
+				continue
+			}
+		}
+
+		best = key
+		found = true
+	}
+
+	if !found {
+		return t.Default
+	}
+
+	return t.Styles[best]
+}
+
+// Resolve returns the theme without patterns.
+//
+// Parameters:
+//   - names: The names of the token types the patterns are applied to.
+//
+// Returns:
+//   - *Theme: The new theme. It has a style for every name and for every
+//     key of the theme that is not a pattern. Never nil.
+func (t *Theme) Resolve(names ...string) *Theme {
+	resolved := &Theme{
+		Default: t.Default,
+		Error:   t.Error,
+		Styles:  make(map[string]Style, len(t.Styles)+len(names)),
+	}
+
+	for key, style := range t.Styles {
+		if !is_pattern(key) {
+			resolved.Styles[key] = style
+		}
+	}
+
+	for _, name := range names {
+		resolved.Styles[name] = t.StyleOf(name)
+	}
+
+	return resolved
+}
+
+// style_entry is a style of a theme file.
+type style_entry struct {
+	// Inherits is the name of the style this one starts from. Empty starts
+	// from the default style.
+	Inherits string `json:"inherits"`
+
+	// Fg is the color of the text. Nil keeps the inherited one.
+	Fg *string `json:"fg"`
+
+	// Bg is the color of the background. Nil keeps the inherited one.
+	Bg *string `json:"bg"`
+
+	// Bold, Italic and Underline are the attributes. Nil keeps the inherited
+	// ones.
+	Bold      *bool `json:"bold"`
+	Italic    *bool `json:"italic"`
+	Underline *bool `json:"underline"`
+}
+
+// apply is a helper method that applies the entry to a style.
+//
+// Parameters:
+//   - base: The inherited style.
+//
+// Returns:
+//   - Style: The style.
+//   - error: An error of type *ErrInvalidColor if a color is invalid.
+func (e *style_entry) apply(base Style) (Style, error) {
+	style := base
+
+	if e.Fg != nil {
+		c, err := ParseColor(*e.Fg)
+		if err != nil {
+			return style, err
+		}
+
+		style.Fg = c
+	}
+
+	if e.Bg != nil {
+		c, err := ParseColor(*e.Bg)
+		if err != nil {
+			return style, err
+		}
+
+		style.Bg = c
+	}
+
+	if e.Bold != nil {
+		style.Bold = *e.Bold
+	}
+
+	if e.Italic != nil {
+		style.Italic = *e.Italic
+	}
+
+	if e.Underline != nil {
+		style.Underline = *e.Underline
+	}
+
+	return style, nil
+}
+
+// theme_file is the content of a theme file.
+type theme_file struct {
+	// Default is the style of the tokens without a style.
+	Default *style_entry `json:"default"`
+
+	// Error is the style of the error regions.
+	Error *style_entry `json:"error"`
+
+	// Styles are the styles of the token types, by name or pattern.
+	Styles map[string]*style_entry `json:"styles"`
+}
+
+// theme_loader is the state of the resolution of the inheritance of a theme
+// file.
+type theme_loader struct {
+	// file is the theme file.
+	file *theme_file
+
+	// default_style is the resolved default style.
+	default_style Style
+
+	// resolved are the styles resolved so far.
+	resolved map[string]Style
+
+	// visiting are the styles being resolved, in order.
+	visiting []string
+}
+
+// resolve is a helper method that resolves a style of the theme file.
+//
+// Parameters:
+//   - name: The name of the style. Must be a key of the styles.
+//
+// Returns:
+//   - Style: The style.
+//   - error: An error if the style is invalid.
+func (l *theme_loader) resolve(name string) (Style, error) {
+	style, ok := l.resolved[name]
+	if ok {
+		return style, nil
+	}
+
+	idx := slices.Index(l.visiting, name)
+	if idx >= 0 {
+		cycle := append(slices.Clone(l.visiting[idx:]), name)
+
+		return Style{}, NewErrInheritanceCycle(cycle)
+	}
+
+	l.visiting = append(l.visiting, name)
+	defer func() {
+		l.visiting = l.visiting[:len(l.visiting)-1]
+	}()
+
+	style, err := l.apply(name, l.file.Styles[name])
+	if err != nil {
+		return Style{}, err
+	}
+
+	l.resolved[name] = style
+
+	return style, nil
+}
+
+// apply is a helper method that applies an entry of the theme file to the
+// style it inherits from.
+//
+// Parameters:
+//   - name: The name of the entry.
+//   - entry: The entry. Nil is an empty entry.
+//
+// Returns:
+//   - Style: The style.
+//   - error: An error if the style is invalid.
+func (l *theme_loader) apply(name string, entry *style_entry) (Style, error) {
+	if entry == nil {
+		return l.default_style, nil
+	}
+
+	base := l.default_style
+
+	if entry.Inherits != "" {
+		_, ok := l.file.Styles[entry.Inherits]
+		if !ok {
+			return Style{}, NewErrInvalidStyle(name, NewErrUnknownStyle(entry.Inherits))
+		}
+
+		var err error
+
+		base, err = l.resolve(entry.Inherits)
+		if err != nil {
+			return Style{}, err
+		}
+	}
+
+	style, err := entry.apply(base)
+	if err != nil {
+		return Style{}, NewErrInvalidStyle(name, err)
+	}
+
+	return style, nil
+}
+
+// ParseTheme parses a theme in JSON.
+//
+// Format:
+//
+//	{
+//	  "default": { "fg": "#d0d0d0", "bg": "default" },
+//	  "error":   { "fg": "red", "underline": true },
+//	  "styles": {
+//	    "KW_*":  { "fg": "#ff8700", "bold": true },
+//	    "KW_IF": { "inherits": "KW_*", "italic": true },
+//	    "WORD":  { "fg": "75" }
+//	  }
+//	}
+//
+// A color is written as in ParseColor. Every field of a style is optional.
+//
+// Parameters:
+//   - data: The JSON to parse.
+//
+// Returns:
+//   - *Theme: The theme.
+//   - error: An error if the theme is invalid.
+//
+// Errors:
+//   - *ErrInvalidStyle: If a color is invalid, a pattern is malformed, the
+//     default style inherits, or a style inherits from one that does not
+//     exist.
+//   - *ErrInheritanceCycle: If styles inherit from each other.
+//   - any error of json.Unmarshal.
+//
+// Behaviors:
+//   - "inherits" names a key of "styles". A style without it starts from
+//     the default style, which starts from the plain style and cannot
+//     inherit.
+func ParseTheme(data []byte) (*Theme, error) {
+	var file theme_file
+
+	err := json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &theme_loader{
+		file:     &file,
+		resolved: make(map[string]Style, len(file.Styles)),
+	}
+
+	if file.Default != nil {
+		if file.Default.Inherits != "" {
+			return nil, NewErrInvalidStyle("default", errors.New("the default style cannot inherit"))
+		}
+
+		l.default_style, err = file.Default.apply(Style{})
+		if err != nil {
+			return nil, NewErrInvalidStyle("default", err)
+		}
+	}
+
+	t := NewTheme(l.default_style)
+
+	t.Error, err = l.apply("error", file.Error)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range file.Styles {
+		if is_pattern(name) {
+			_, err := path.Match(name, "")
+			if err != nil {
+				return nil, NewErrInvalidStyle(name, err)
+			}
+		}
+
+		t.Styles[name], err = l.resolve(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// LoadTheme reads a theme from a JSON file.
+//
+// Parameters:
+//   - loc: The location of the file.
+//
+// Returns:
+//   - *Theme: The theme.
+//   - error: An error if the file cannot be read or the theme is invalid.
+//     See ParseTheme.
+func LoadTheme(loc string) (*Theme, error) {
+	data, err := os.ReadFile(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseTheme(data)
+}
+
+// ApplyTheme sets the styles of the highlighter from a theme.
+//
+// Parameters:
+//   - theme: The theme to use.
+//
+// Returns:
+//   - error: An error of type *uc.ErrInvalidParameter if the theme is nil.
+//
+// Behaviors:
+//   - The default style and the error style are the ones of the theme.
+//   - The rules added with SpecifyRule take precedence over the theme.
+func (h *Highlighter[T]) ApplyTheme(theme *Theme) error {
+	if theme == nil {
+		return uc.NewErrNilParameter("theme")
+	}
+
+	h.theme = theme
+	h.default_style = theme.Default
+	h.error_style = theme.Error
+
+	return nil
+}
+
+// GetTheme returns the styles of the highlighter as a theme without
+// patterns.
+//
+// Returns:
+//   - *Theme: The theme, with a style for every type of token of the
+//     lexer. Never nil.
+func (h *Highlighter[T]) GetTheme() *Theme {
+	types := h.lexer.GetTypes()
+
+	t := &Theme{
+		Default: h.default_style,
+		Error:   h.error_style,
+		Styles:  make(map[string]Style, len(types)+len(h.rules)),
+	}
+
+	for _, id := range types {
+		t.Styles[id.String()] = h.style_of(id)
+	}
+
+	for id, style := range h.rules {
+		t.Styles[id.String()] = style
+	}
+
+	return t
+}
@@ -0,0 +1,85 @@
+package Highlighter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTheme(t *testing.T) {
+	const data = `{
+		"default": { "fg": "#d0d0d0" },
+		"error": { "inherits": "KW_*", "underline": true },
+		"styles": {
+			"KW_*": { "fg": "red", "bold": true },
+			"KW_I*": { "fg": "blue" },
+			"KW_IF": { "inherits": "KW_*", "italic": true },
+			"WORD": { "bg": "42" }
+		}
+	}`
+
+	theme, err := ParseTheme([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseTheme failed: %s", err.Error())
+	}
+
+	fg := ColorRGB(0xd0, 0xd0, 0xd0)
+
+	tests := []struct {
+		name string
+		want Style
+	}{
+		{"KW_IF", Style{Fg: ColorIndex(1), Bold: true, Italic: true}},
+		{"KW_IN", Style{Fg: ColorIndex(4)}},
+		{"KW_ELSE", Style{Fg: ColorIndex(1), Bold: true}},
+		{"WORD", Style{Fg: fg, Bg: ColorIndex(42)}},
+		{"WS", Style{Fg: fg}},
+	}
+
+	for _, test := range tests {
+		got := theme.StyleOf(test.name)
+		if got != test.want {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.want, got)
+		}
+	}
+
+	want := Style{Fg: ColorIndex(1), Bold: true, Underline: true}
+	if theme.Error != want {
+		t.Errorf("error: expected %+v, got %+v", want, theme.Error)
+	}
+}
+
+func TestParseThemeErrors(t *testing.T) {
+	tests := []struct {
+		data  string
+		check func(err error) bool
+	}{
+		{
+			data: `{"styles": {"A": {"inherits": "B"}, "B": {"inherits": "A"}}}`,
+			check: func(err error) bool {
+				var target *ErrInheritanceCycle
+				return errors.As(err, &target)
+			},
+		},
+		{
+			data: `{"styles": {"A": {"inherits": "C"}}}`,
+			check: func(err error) bool {
+				var target *ErrUnknownStyle
+				return errors.As(err, &target)
+			},
+		},
+		{
+			data: `{"styles": {"A": {"fg": "#12"}}}`,
+			check: func(err error) bool {
+				var target *ErrInvalidColor
+				return errors.As(err, &target)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		_, err := ParseTheme([]byte(test.data))
+		if err == nil || !test.check(err) {
+			t.Errorf("%s: unexpected error %v", test.data, err)
+		}
+	}
+}
@@ -0,0 +1,630 @@
+package Incremental
+
+import (
+	"errors"
+	"sort"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+	ps "github.com/PlayerR9/LyneParser/Parser"
+	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// lexed is a token of the document together with the state of the lexer
+// before it.
+type lexed[T gr.TokenTyper] struct {
+	// tok is the token.
+	tok *gr.Token[T]
+
+	// modes is the mode stack before the token.
+	modes *lx.ModeStack
+
+	// err is the syntax error of an error token. Nil for the other tokens.
+	err lx.SyntaxErrorer
+
+	// skipped is true if the token is not given to the parser.
+	skipped bool
+}
+
+// end is a helper method that returns the position after the token.
+//
+// Returns:
+//   - int: The position after the token.
+func (l *lexed[T]) end() int {
+	return l.tok.At + len(l.tok.Data.(string))
+}
+
+// moved is a helper method that returns a copy of the token at another
+// position. The token itself is never modified since it may be a leaf of a
+// previous parse tree.
+//
+// Parameters:
+//   - delta: The difference between the new and the old positions.
+//
+// Returns:
+//   - *lexed: The moved token.
+func (l *lexed[T]) moved(delta int) *lexed[T] {
+	tok := gr.NewToken(l.tok.ID, l.tok.Data, l.tok.At+delta, nil)
+	tok.Mode = l.tok.Mode
+
+	l_copy := &lexed[T]{
+		tok:     tok,
+		modes:   l.modes,
+		skipped: l.skipped,
+	}
+
+	return l_copy
+}
+
+// same_as is a helper method that checks whether a token lexed again is the
+// same as the old one, so the old one can be kept.
+//
+// Parameters:
+//   - step: The token lexed again.
+//   - modes: The mode stack before it.
+//
+// Returns:
+//   - bool: True if they are the same, false otherwise.
+func (l *lexed[T]) same_as(step *lx.LexStep[T], modes *lx.ModeStack) bool {
+	return l.tok.ID == step.Token.ID &&
+		l.tok.At == step.Token.At &&
+		l.tok.Data == step.Token.Data &&
+		l.err == nil && step.Error == nil &&
+		l.modes.Equal(modes)
+}
+
+// Change is the result of an edit of a document.
+type Change[T gr.TokenTyper] struct {
+	// Relexed are the tokens that were lexed again, skipped ones included.
+	Relexed []*gr.Token[T]
+
+	// Added are the nodes of the parse tree that were not in the previous
+	// one, leaves included.
+	Added []*gr.Token[T]
+
+	// Removed are the nodes of the previous parse tree that are not in the
+	// new one, leaves included.
+	Removed []*gr.Token[T]
+}
+
+// Document is a text together with its tokens and its parse tree, kept up
+// to date as the text is edited. Only the tokens around an edit are lexed
+// again, the parse resumes from the last state before the edit and the
+// unaffected subtrees of the previous parse tree are kept; so the nodes
+// that did not change keep their identity and can be used as cache keys.
+type Document[T gr.TokenTyper] struct {
+	// lexer is the lexer of the document.
+	lexer *lx.Lexer[T]
+
+	// parser is the parser of the document.
+	parser *ps.Parser[T]
+
+	// eof is the EOF symbol of the grammar of the parser.
+	eof T
+
+	// source is the text of the document.
+	source []byte
+
+	// tokens are the tokens of the text, skipped ones included.
+	tokens []*lexed[T]
+
+	// stream are the tokens given to the parser, with the EOF token.
+	stream []*gr.Token[T]
+
+	// checkpoints are the checkpoints of the last successful parse.
+	checkpoints []*ps.Checkpoint[T]
+
+	// root is the root of the parse tree. Nil if the last parse failed.
+	root *gr.Token[T]
+}
+
+// NewDocument creates a document and parses its text.
+//
+// Parameters:
+//   - lexer: The lexer to use.
+//   - parser: The parser to use. Its grammar must have an EOF symbol. It is
+//     copied.
+//   - source: The text of the document. It is copied.
+//
+// Returns:
+//   - *Document: The new document. Nil if the text could not be lexed.
+//   - error: An error if the text could not be lexed or parsed.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If lexer or parser is nil.
+//   - any error of Lexer.Step.
+//   - any error of the parser. The document is still returned, without a
+//     parse tree.
+//
+// Behaviors:
+//   - The checkpoints are enabled on the copy of the parser only, so the
+//     given parser can still be used elsewhere.
+//   - When several tokens or parse trees are possible, the first ones are
+//     kept.
+func NewDocument[T gr.TokenTyper](lexer *lx.Lexer[T], parser *ps.Parser[T], source []byte) (*Document[T], error) {
+	if lexer == nil {
+		return nil, uc.NewErrNilParameter("lexer")
+	}
+
+	if parser == nil {
+		return nil, uc.NewErrNilParameter("parser")
+	}
+
+	eof, ok := parser.GetEOF()
+	if !ok {
+		return nil, uc.NewErrInvalidParameter(
+			"parser",
+			errors.New("the grammar has no "+gr.EOFTokenID+" symbol"),
+		)
+	}
+
+	parser = parser.Copy().(*ps.Parser[T])
+	parser.SetCheckpoints(true)
+
+	d := &Document[T]{
+		lexer:  lexer,
+		parser: parser,
+		eof:    eof,
+		source: make([]byte, len(source)),
+	}
+
+	copy(d.source, source)
+
+	tokens, _, err := d.relex(d.source, 0, 0, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	d.tokens = tokens
+
+	_, _, err = d.reparse()
+
+	return d, err
+}
+
+// GetSource returns the text of the document.
+//
+// Returns:
+//   - []byte: The text. It must not be modified.
+func (d *Document[T]) GetSource() []byte {
+	return d.source
+}
+
+// GetTokens returns the tokens of the document.
+//
+// Returns:
+//   - []*gr.Token: The tokens, skipped ones included. Without the EOF token.
+func (d *Document[T]) GetTokens() []*gr.Token[T] {
+	toks := make([]*gr.Token[T], 0, len(d.tokens))
+
+	for _, l := range d.tokens {
+		toks = append(toks, l.tok)
+	}
+
+	return toks
+}
+
+// GetErrors returns the syntax errors of the error tokens of the document.
+//
+// Returns:
+//   - []lx.SyntaxErrorer: The errors, in the order of the text. Nil if
+//     there are none.
+func (d *Document[T]) GetErrors() []lx.SyntaxErrorer {
+	var errs []lx.SyntaxErrorer
+
+	for _, l := range d.tokens {
+		if l.err != nil {
+			errs = append(errs, l.err)
+		}
+	}
+
+	return errs
+}
+
+// GetTree returns the root of the parse tree of the document. The children
+// of a node are its data, as a []*gr.Token. The nodes that a later edit does
+// not change are shared with the new tree, and their lookaheads are updated
+// in place; see Edit.
+//
+// Returns:
+//   - *gr.Token: The root. Nil if the last parse failed.
+func (d *Document[T]) GetTree() *gr.Token[T] {
+	return d.root
+}
+
+// Edit replaces a range of the text.
+//
+// Parameters:
+//   - start: The start of the range, in bytes.
+//   - end: The end of the range, in bytes. Not included.
+//   - text: The text to put in place of the range.
+//
+// Returns:
+//   - *Change: What the edit changed. Nil if the edit was not applied.
+//   - error: An error if the edit could not be applied or the new text could
+//     not be parsed.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If the range is not in the text.
+//   - any error of Lexer.Step. The edit is not applied.
+//   - any error of the parser. The edit is applied and the change is
+//     returned, but the document has no parse tree until a later edit fixes
+//     the text.
+//
+// Behaviors:
+//   - Lexing starts one token before the first one that ends at or after
+//     the start of the range; an edit right after a token can make it
+//     longer and the token before can depend on it as well.
+//   - Lexing stops as soon as, past the edit, a token would start where an
+//     old token started, with the same mode stack.
+//   - The tokens after the edit are moved by replacing them with copies;
+//     so the tokens and nodes of a previous GetTokens or GetTree keep their
+//     positions.
+//   - The tokens and nodes that did not change are shared with the new
+//     tree, so their lookaheads are updated in place: in a previous tree, the
+//     last leaf before the edit now points to the first new token. Copying
+//     them instead would copy every token before the edit, since each one
+//     points to the next, and the parse could not resume from a checkpoint.
+//   - The parse resumes from the last checkpoint whose tokens did not
+//     change; so the choices made for the unchanged prefix of an ambiguous
+//     text are kept.
+func (d *Document[T]) Edit(start, end int, text string) (*Change[T], error) {
+	if start < 0 || start > len(d.source) {
+		return nil, uc.NewErrInvalidParameter(
+			"start",
+			uc.NewErrOutOfBounds(start, 0, len(d.source)+1),
+		)
+	}
+
+	if end < start || end > len(d.source) {
+		return nil, uc.NewErrInvalidParameter(
+			"end",
+			uc.NewErrOutOfBounds(end, start, len(d.source)+1),
+		)
+	}
+
+	source := make([]byte, 0, len(d.source)-(end-start)+len(text))
+	source = append(source, d.source[:start]...)
+	source = append(source, text...)
+	source = append(source, d.source[end:]...)
+
+	i := sort.Search(len(d.tokens), func(k int) bool {
+		return d.tokens[k].end() >= start
+	})
+
+	if i > 0 {
+		i--
+	}
+
+	delta := len(text) - (end - start)
+
+	relexed, j, err := d.relex(source, i, start, end, delta)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := d.tokens[j:]
+
+	if delta != 0 {
+		kept = make([]*lexed[T], 0, len(d.tokens)-j)
+
+		for _, l := range d.tokens[j:] {
+			kept = append(kept, l.moved(delta))
+		}
+	}
+
+	tokens := make([]*lexed[T], 0, i+len(relexed)+len(kept))
+	tokens = append(tokens, d.tokens[:i]...)
+	tokens = append(tokens, relexed...)
+	tokens = append(tokens, kept...)
+
+	change := &Change[T]{}
+
+	for k, l := range relexed {
+		if i+k >= j || d.tokens[i+k] != l {
+			change.Relexed = append(change.Relexed, l.tok)
+		}
+	}
+
+	d.source = source
+	d.tokens = tokens
+
+	added, removed, err := d.reparse()
+
+	change.Added = added
+	change.Removed = removed
+
+	return change, err
+}
+
+// relex is a helper method that lexes the text again from a token until the
+// lexer state is the same as before the edit.
+//
+// Parameters:
+//   - source: The new text.
+//   - i: The index of the first token to lex again.
+//   - start: The start of the edited range, in the old text.
+//   - end: The end of the edited range, in the old text.
+//   - delta: The difference between the new and the old lengths.
+//
+// Returns:
+//   - []*lexed: The tokens that replace the old ones from i to the returned
+//     index. The old tokens that are the same are kept.
+//   - int: The index of the first old token that is kept after the edit.
+//   - error: An error if a token could not be lexed.
+//
+// Behaviors:
+//   - When the edit moves the tokens after it, lexing does not stop before
+//     the last error token; since its syntax error holds its position, it
+//     cannot be moved.
+func (d *Document[T]) relex(source []byte, i, start, end, delta int) ([]*lexed[T], int, error) {
+	at := 0
+	modes := lx.NewModeStack()
+
+	if i < len(d.tokens) {
+		at = d.tokens[i].tok.At
+		modes = d.tokens[i].modes
+	}
+
+	j := sort.Search(len(d.tokens), func(k int) bool {
+		return d.tokens[k].tok.At >= end
+	})
+
+	new_end := end + delta
+
+	last_err := -1
+
+	if delta != 0 {
+		for k, l := range d.tokens {
+			if l.err != nil {
+				last_err = k
+			}
+		}
+	}
+
+	var relexed []*lexed[T]
+
+	for at < len(source) {
+		if at >= new_end {
+			k := j + sort.Search(len(d.tokens)-j, func(k int) bool {
+				return d.tokens[j+k].tok.At >= at-delta
+			})
+
+			if k < len(d.tokens) && d.tokens[k].tok.At == at-delta && d.tokens[k].modes.Equal(modes) && k > last_err {
+				return relexed, k, nil
+			}
+		}
+
+		step, err := d.lexer.Step(source, at, modes)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		l := &lexed[T]{
+			tok:     step.Token,
+			modes:   modes,
+			err:     step.Error,
+			skipped: step.Skipped,
+		}
+
+		// The old token is kept if it is before the edit and did not change.
+		if idx := i + len(relexed); idx < j {
+			old := d.tokens[idx]
+
+			if old.end() <= start && old.same_as(step, modes) {
+				l = old
+			}
+		}
+
+		relexed = append(relexed, l)
+
+		at = l.end()
+		modes = step.Modes
+	}
+
+	return relexed, len(d.tokens), nil
+}
+
+// reparse is a helper method that parses the tokens again, resuming from the
+// last checkpoint before the first token that changed.
+//
+// Returns:
+//   - []*gr.Token: The nodes of the new tree that are not in the old one.
+//   - []*gr.Token: The nodes of the old tree that are not in the new one.
+//   - error: An error if the tokens could not be parsed.
+func (d *Document[T]) reparse() ([]*gr.Token[T], []*gr.Token[T], error) {
+	stream := make([]*gr.Token[T], 0, len(d.tokens)+1)
+
+	for _, l := range d.tokens {
+		if !l.skipped {
+			stream = append(stream, l.tok)
+		}
+	}
+
+	// The EOF token is kept if it did not move, so that it is not reported
+	// as a change.
+	var eof_tok *gr.Token[T]
+
+	if len(d.stream) > 0 && d.stream[len(d.stream)-1].At == len(d.source) {
+		eof_tok = d.stream[len(d.stream)-1]
+	} else {
+		eof_tok = gr.NewToken(d.eof, "", len(d.source), nil)
+	}
+
+	stream = append(stream, eof_tok)
+
+	// The kept tokens are the leaves of the previous tree as well; they are
+	// relinked in place.
+	for k := 0; k+1 < len(stream); k++ {
+		stream[k].SetLookahead(stream[k+1])
+	}
+
+	first_changed := 0
+
+	for first_changed < len(stream) && first_changed < len(d.stream) && stream[first_changed] == d.stream[first_changed] {
+		first_changed++
+	}
+
+	n := sort.Search(len(d.checkpoints), func(k int) bool {
+		return d.checkpoints[k].Index >= first_changed
+	})
+
+	d.stream = stream
+
+	old_root := d.root
+
+	old_nodes := make(map[*gr.Token[T]]bool)
+	parent_of := make(map[*gr.Token[T]]*gr.Token[T])
+
+	walk_tree(old_root, nil, func(node, parent *gr.Token[T]) {
+		old_nodes[node] = true
+
+		if parent != nil {
+			parent_of[node] = parent
+		}
+	})
+
+	err := ps.ParseFrom(d.parser, cds.NewStream(stream), d.checkpoints[:n])
+	if err != nil {
+		d.root = nil
+		d.checkpoints = nil
+
+		return nil, nodes_not_in(old_root, nil), err
+	}
+
+	evals, err := d.parser.GetEvals()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root, _ := evals[0].GetStack().Peek()
+
+	d.root = intern(root, old_nodes, parent_of)
+	d.checkpoints = evals[0].GetCheckpoints()
+
+	fix_positions(d.root)
+
+	new_nodes := make(map[*gr.Token[T]]bool, len(old_nodes))
+
+	walk_tree(d.root, nil, func(node, parent *gr.Token[T]) {
+		new_nodes[node] = true
+	})
+
+	return nodes_not_in(d.root, old_nodes), nodes_not_in(old_root, new_nodes), nil
+}
+
+// children_of is a helper function that returns the children of a node.
+//
+// Parameters:
+//   - node: The node.
+//
+// Returns:
+//   - []*gr.Token: The children. Nil for a leaf.
+func children_of[T gr.TokenTyper](node *gr.Token[T]) []*gr.Token[T] {
+	children, _ := node.Data.([]*gr.Token[T])
+	return children
+}
+
+// walk_tree is a helper function that visits the nodes of a tree in
+// pre-order.
+//
+// Parameters:
+//   - node: The root of the tree. Nil visits nothing.
+//   - parent: The parent of the root. Nil for the root of the whole tree.
+//   - visit: The function to call for every node, with its parent.
+func walk_tree[T gr.TokenTyper](node, parent *gr.Token[T], visit func(node, parent *gr.Token[T])) {
+	if node == nil {
+		return
+	}
+
+	visit(node, parent)
+
+	for _, child := range children_of(node) {
+		walk_tree(child, node, visit)
+	}
+}
+
+// fix_positions is a helper function that sets the position and the
+// lookahead of every inner node from its children, since a kept node may end
+// with a leaf whose lookahead changed. The kept nodes are updated in place.
+//
+// Parameters:
+//   - node: The root of the tree.
+func fix_positions[T gr.TokenTyper](node *gr.Token[T]) {
+	children := children_of(node)
+	if len(children) == 0 {
+		return
+	}
+
+	for _, child := range children {
+		fix_positions(child)
+	}
+
+	node.At = children[0].At
+	node.SetLookahead(children[len(children)-1].GetLookahead())
+}
+
+// intern is a helper function that replaces, bottom-up, the new nodes of a
+// tree by the old ones that have the same type and the same children.
+//
+// Parameters:
+//   - node: The root of the new tree.
+//   - old_nodes: The nodes of the old tree.
+//   - parent_of: The parent of each node of the old tree.
+//
+// Returns:
+//   - *gr.Token: The node to use in place of the given one.
+func intern[T gr.TokenTyper](node *gr.Token[T], old_nodes map[*gr.Token[T]]bool, parent_of map[*gr.Token[T]]*gr.Token[T]) *gr.Token[T] {
+	if old_nodes[node] {
+		return node
+	}
+
+	children := children_of(node)
+	if len(children) == 0 {
+		return node
+	}
+
+	for k, child := range children {
+		children[k] = intern(child, old_nodes, parent_of)
+	}
+
+	old, ok := parent_of[children[0]]
+	if !ok || old.ID != node.ID {
+		return node
+	}
+
+	old_children := children_of(old)
+	if len(old_children) != len(children) {
+		return node
+	}
+
+	for k, child := range children {
+		if old_children[k] != child {
+			return node
+		}
+	}
+
+	return old
+}
+
+// nodes_not_in is a helper function that returns the nodes of a tree that
+// are not in a set.
+//
+// Parameters:
+//   - root: The root of the tree. May be nil.
+//   - other: The set. May be nil.
+//
+// Returns:
+//   - []*gr.Token: The nodes that are not in other, in pre-order.
+func nodes_not_in[T gr.TokenTyper](root *gr.Token[T], other map[*gr.Token[T]]bool) []*gr.Token[T] {
+	var diff []*gr.Token[T]
+
+	walk_tree(root, nil, func(node, parent *gr.Token[T]) {
+		if !other[node] {
+			diff = append(diff, node)
+		}
+	})
+
+	return diff
+}
@@ -0,0 +1,271 @@
+package Incremental
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+	ps "github.com/PlayerR9/LyneParser/Parser"
+	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
+)
+
+type DocTokenType int
+
+const (
+	DtEof DocTokenType = iota
+	DtWord
+	DtComma
+	DtSpace
+	DtSource
+	DtList
+)
+
+func (t DocTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"WORD",
+		"COMMA",
+		"SPACE",
+		gr.StartSymbolID,
+		"list",
+	}[t]
+}
+
+func (t DocTokenType) IsTerminal() bool {
+	return t <= DtSpace
+}
+
+// new_doc_lexer creates a lexer of words and commas. The spaces are skipped.
+func new_doc_lexer(t *testing.T) *lx.Lexer[DocTokenType] {
+	grammar := lx.NewGrammar([]DocTokenType{DtSpace})
+
+	rules := []struct {
+		lhs   DocTokenType
+		regex string
+	}{
+		{DtWord, `[a-z]+`},
+		{DtComma, `,`},
+		{DtSpace, ` +`},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.regex)
+		if err != nil {
+			t.Fatalf("AddRule(%s) failed: %s", rule.lhs, err.Error())
+		}
+	}
+
+	return lx.NewLexer(grammar)
+}
+
+// new_doc_parser creates a parser of the grammar:
+//
+//	source -> list EOF
+//	list -> WORD
+//	list -> WORD COMMA list
+func new_doc_parser(t *testing.T) *ps.Parser[DocTokenType] {
+	grammar, err := ps.NewGrammar[DocTokenType]()
+	if err != nil {
+		t.Fatalf("NewGrammar failed: %s", err.Error())
+	}
+
+	rules := []struct {
+		lhs DocTokenType
+		rhs []DocTokenType
+	}{
+		{DtSource, []DocTokenType{DtList, DtEof}},
+		{DtList, []DocTokenType{DtWord}},
+		{DtList, []DocTokenType{DtWord, DtComma, DtList}},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.rhs)
+		if err != nil {
+			t.Fatalf("AddRule failed: %s", err.Error())
+		}
+	}
+
+	p, err := ps.NewParser(grammar)
+	if err != nil {
+		t.Fatalf("NewParser failed: %s", err.Error())
+	}
+
+	return p
+}
+
+// dump writes a tree as "type@at(child ...)", with the data of the leaves.
+func dump(node *gr.Token[DocTokenType]) string {
+	children := children_of(node)
+	if len(children) == 0 {
+		str, _ := node.Data.(string)
+		return node.ID.String() + "@" + strconv.Itoa(node.At) + "(" + str + ")"
+	}
+
+	values := make([]string, 0, len(children))
+
+	for _, child := range children {
+		values = append(values, dump(child))
+	}
+
+	return node.ID.String() + "@" + strconv.Itoa(node.At) + "(" + strings.Join(values, " ") + ")"
+}
+
+func TestDocumentEdit(t *testing.T) {
+	lexer := new_doc_lexer(t)
+	parser := new_doc_parser(t)
+
+	d, err := NewDocument(lexer, parser, []byte("a, b, cc, d"))
+	if err != nil {
+		t.Fatalf("NewDocument failed: %s", err.Error())
+	}
+
+	old_tokens := d.GetTokens()
+	old_root := d.GetTree()
+	old_d := old_tokens[len(old_tokens)-1]
+
+	d.parser.SetTracing(true)
+
+	// "cc" becomes "x": only that word is lexed again.
+	change, err := d.Edit(6, 8, "x")
+	if err != nil {
+		t.Fatalf("Edit failed: %s", err.Error())
+	}
+
+	if len(change.Relexed) != 1 || change.Relexed[0].Data != "x" {
+		t.Fatalf("expected only \"x\" to be lexed again, got %d tokens", len(change.Relexed))
+	}
+
+	// The parse resumed from the checkpoint after "a, b", so it made fewer
+	// decisions than a parse of the whole text.
+	traces, err := d.parser.GetTraces()
+	if err != nil {
+		t.Fatalf("GetTraces failed: %s", err.Error())
+	}
+
+	resumed := len(traces[0])
+
+	full_parser := new_doc_parser(t)
+	full_parser.SetTracing(true)
+
+	full, err := NewDocument(lexer, full_parser, []byte("a, b, x, d"))
+	if err != nil {
+		t.Fatalf("NewDocument failed: %s", err.Error())
+	}
+
+	traces, _ = full.parser.GetTraces()
+	if resumed >= len(traces[0]) {
+		t.Errorf("expected fewer than %d decisions, got %d", len(traces[0]), resumed)
+	}
+
+	// The result is the same as a full parse of the new text.
+	if dump(d.GetTree()) != dump(full.GetTree()) {
+		t.Errorf("expected %s, got %s", dump(full.GetTree()), dump(d.GetTree()))
+	}
+
+	// The tokens of the previous tree did not move.
+	if old_d.At != 10 || old_tokens[0] != d.GetTokens()[0] {
+		t.Errorf("expected the old tokens to be left as they were")
+	}
+
+	if d.GetTokens()[len(d.GetTokens())-1].At != 9 {
+		t.Errorf("expected \"d\" to move to 9")
+	}
+
+	if dump(old_root) != "source@0(list@0(WORD@0(a) COMMA@1(,) list@3(WORD@3(b) COMMA@4(,) list@6(WORD@6(cc) COMMA@8(,) list@10(WORD@10(d))))) EOF@11())" {
+		t.Errorf("expected the old tree to be left as it was, got %s", dump(old_root))
+	}
+
+	if len(change.Added) == 0 || len(change.Removed) == 0 {
+		t.Errorf("expected nodes to be added and removed")
+	}
+}
+
+func TestDocumentParser(t *testing.T) {
+	parser := new_doc_parser(t)
+
+	_, err := NewDocument(new_doc_lexer(t), parser, []byte("a"))
+	if err != nil {
+		t.Fatalf("NewDocument failed: %s", err.Error())
+	}
+
+	// The checkpoints are only enabled on the copy of the parser.
+	eof := gr.NewToken(DtEof, "", 1, nil)
+
+	err = ps.Parse(parser, cds.NewStream([]*gr.Token[DocTokenType]{
+		gr.NewToken(DtWord, "a", 0, eof),
+		eof,
+	}))
+	if err != nil {
+		t.Fatalf("Parse failed: %s", err.Error())
+	}
+
+	evals, _ := parser.GetEvals()
+	if len(evals) != 1 || len(evals[0].GetCheckpoints()) != 0 {
+		t.Errorf("expected a parse without checkpoints")
+	}
+
+	_, err = NewDocument(nil, parser, []byte("a"))
+	if err == nil {
+		t.Errorf("expected an error for a nil lexer")
+	}
+}
+
+// leaves returns the leaves of a tree, in order.
+func leaves(root *gr.Token[DocTokenType]) []*gr.Token[DocTokenType] {
+	var result []*gr.Token[DocTokenType]
+
+	walk_tree(root, nil, func(node, parent *gr.Token[DocTokenType]) {
+		if len(children_of(node)) == 0 {
+			result = append(result, node)
+		}
+	})
+
+	return result
+}
+
+func TestDocumentEditSharesTrees(t *testing.T) {
+	d, err := NewDocument(new_doc_lexer(t), new_doc_parser(t), []byte("a, b, cc, d"))
+	if err != nil {
+		t.Fatalf("NewDocument failed: %s", err.Error())
+	}
+
+	// "cc" is the fifth leaf; the first edit does not move the tokens after
+	// it, the second one does.
+	edits := []string{"yy", "x"}
+
+	for _, text := range edits {
+		old_root := d.GetTree()
+		old_dump := dump(old_root)
+		old_leaves := leaves(old_root)
+
+		_, err := d.Edit(6, 6+len(old_leaves[4].Data.(string)), text)
+		if err != nil {
+			t.Fatalf("Edit(%q) failed: %s", text, err.Error())
+		}
+
+		new_leaves := leaves(d.GetTree())
+
+		// The positions of the old tree are kept.
+		if dump(old_root) != old_dump {
+			t.Errorf("%q: expected the old tree to keep its positions, got %s", text, dump(old_root))
+		}
+
+		// The leaves before the edit are shared, so the last of them is
+		// relinked to the new token in the old tree as well.
+		for k := 0; k < 4; k++ {
+			if old_leaves[k] != new_leaves[k] {
+				t.Errorf("%q: expected leaf %d to be shared", text, k)
+			}
+		}
+
+		if new_leaves[4].Data != text || old_leaves[3].Lookahead != new_leaves[4] {
+			t.Errorf("%q: expected the comma before the edit to point to the new token", text)
+		}
+
+		if old_leaves[4].Lookahead != old_leaves[5] {
+			t.Errorf("%q: expected the replaced token to be left as it was", text)
+		}
+	}
+}
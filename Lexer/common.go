@@ -2,22 +2,41 @@ package Lexer
 
 import (
 	"errors"
+	"slices"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 
 	gr "github.com/PlayerR9/LyneParser/Grammar"
+	tc "github.com/PlayerR9/LyneParser/Tracer"
 	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
 	uc "github.com/PlayerR9/MyGoLib/Units/common"
 )
 
 // Lexer is a lexer that uses a grammar to tokenize a string.
 type Lexer[T gr.TokenTyper] struct {
-	// productions are the production rules to use.
-	productions []*gr.RegProduction[T]
+	// rules are the rules of each mode.
+	rules map[string][]*Rule[T]
 
 	// to_skip are the tokens to skip.
 	to_skip []T
+
+	// hook is the hook that receives the events of the lexer. May be nil.
+	hook tc.Hooker
+
+	// recovery is the policy to follow when no rule matches. Nil if lexing
+	// must fail instead.
+	recovery *Recovery[T]
+
+	// eof is the type of the end-of-file token. Only valid if has_eof is true.
+	eof T
+
+	// has_eof is true if the tokenizations end with an end-of-file token.
+	has_eof bool
+
+	// layout is the layout pass that Lex runs on each tokenization. Nil if
+	// there is none.
+	layout *Layout[T]
 }
 
 // NewLexer creates a new lexer.
@@ -62,67 +81,234 @@ func NewLexer[T gr.TokenTyper](grammar *Grammar[T]) *Lexer[T] {
 		return lex
 	}
 
-	lex.productions = grammar.GetRegexProds()
+	lex.rules = grammar.GetRules()
 	lex.to_skip = grammar.GetToSkip()
 
 	return lex
 }
 
-// Lex is the main function of the lexer. This can be parallelized.
+// SetHook sets the hook that receives the events of the lexer.
 //
 // Parameters:
-//   - source: The source to lex.
-//   - logger: A verbose logger.
+//   - hook: The hook to use. Nil disables the events.
+func (l *Lexer[T]) SetHook(hook tc.Hooker) {
+	l.hook = hook
+}
+
+// SetEOF sets the type of the end-of-file token that ends every tokenization
+// returned by Lex and by the paths of LexLattice.
 //
-// Returns:
-//   - Lexer: The active lexer. Nil if there are no tokens to lex or
-//     grammar is invalid.
+// Parameters:
+//   - eof: The type of the end-of-file token.
 //
-// Errors:
-//   - *ErrNoTokensToLex: There are no tokens to lex.
-//   - *ErrNoMatches: No matches are found in the source.
-//   - *ErrAllMatchesFailed: All matches failed.
-//   - *gr.ErrNoProductionRulesFound: No production rules are found in the grammar.
-func (l *Lexer[T]) Lex(input []byte, logger *Verbose) *LexerIterator[T] {
-	prod_copy := make([]*gr.RegProduction[T], len(l.productions))
-	copy(prod_copy, l.productions)
-	to_skip := make([]T, len(l.to_skip))
-	copy(to_skip, l.to_skip)
+// Behaviors:
+//   - Without it, the tokenizations do not end with an end-of-file token.
+//   - The end-of-file token has empty data and the size of the source as
+//     position.
+func (l *Lexer[T]) SetEOF(eof T) {
+	l.eof = eof
+	l.has_eof = true
+}
 
-	stream := cds.NewStream(input)
+// SetRecovery sets the policy to follow when no rule matches.
+//
+// Parameters:
+//   - recovery: The policy. Nil to fail with *ErrAllMatchesFailed instead.
+//
+// Behaviors:
+//   - With a policy, the skipped text becomes an error token and a syntax
+//     error is recorded for it; see LexerIterator.GetErrors. The error is a
+//     *ClosestSyntaxError if the skipped word is close to a literal of the
+//     grammar and an *UnrecognizedSyntaxError otherwise.
+func (l *Lexer[T]) SetRecovery(recovery *Recovery[T]) {
+	l.recovery = recovery
+}
 
-	si := newSourceIterator(stream, prod_copy, logger)
+// SetLayout sets the layout pass that Lex runs on each tokenization; that
+// is, the off-side rule.
+//
+// Parameters:
+//   - layout: The layout pass. Nil disables it.
+//
+// Behaviors:
+//   - If the layout pass fails on a tokenization, the tokenization is
+//     returned without the synthesized tokens and the error is returned by
+//     LexerIterator.GetErrors.
+func (l *Lexer[T]) SetLayout(layout *Layout[T]) {
+	l.layout = layout
+}
 
-	lr := &leaves_result[T]{
-		leaves: nil,
+// Lex is the main function of the lexer. It lexes the input into a token
+// lattice (see LexLattice) and iterates over its paths.
+//
+// Parameters:
+//   - input: The input to lex.
+//
+// Returns:
+//   - *LexerIterator: The iterator over the tokenizations. Never nil.
+//
+// Behaviors:
+//   - If the input has no tokenization, the first call to Consume fails
+//     with *ErrAllMatchesFailed.
+//   - With a recovery policy (see SetRecovery), the text that no rule
+//     matches becomes an error token and lexing carries on; the syntax
+//     errors of each tokenization are returned by LexerIterator.GetErrors.
+//   - With a layout pass (see SetLayout), each tokenization gets the
+//     synthesized tokens of the off-side rule.
+func (l *Lexer[T]) Lex(input []byte) *LexerIterator[T] {
+	tl, err := l.LexLattice(input)
+	if err != nil {
+		li := &LexerIterator[T]{
+			err: err,
+		}
+
+		return li
 	}
 
 	li := &LexerIterator[T]{
-		to_skip:          to_skip,
-		source_iter:      si,
-		completed_leaves: lr,
+		lattice: tl,
+		paths:   tl.Paths(nil),
+		layout:  l.layout,
 	}
 
 	return li
 }
 
+// IsSkipped checks whether the tokens of the given type are skipped.
+//
+// Parameters:
+//   - id: The type of the tokens.
+//
+// Returns:
+//   - bool: True if the tokens are skipped, false otherwise.
+func (l *Lexer[T]) IsSkipped(id T) bool {
+	return slices.Contains(l.to_skip, id)
+}
+
+// GetTypes returns the types of the tokens the lexer can produce.
+//
+// Returns:
+//   - []T: The types, without duplicates, in the order of the rules. The
+//     modes are visited in the order of their names.
+func (l *Lexer[T]) GetTypes() []T {
+	modes := make([]string, 0, len(l.rules))
+
+	for mode := range l.rules {
+		modes = append(modes, mode)
+	}
+
+	slices.Sort(modes)
+
+	var types []T
+
+	for _, mode := range modes {
+		for _, rule := range l.rules[mode] {
+			id := rule.production.GetLhs()
+
+			if !slices.Contains(types, id) {
+				types = append(types, id)
+			}
+		}
+	}
+
+	return types
+}
+
+// MatchAt matches a single token at the given position, only trying the
+// rules whose left-hand side is allowed. This is meant for context-aware
+// scanning, where the parser tells the lexer which terminals it accepts in
+// its current state.
+//
+// Parameters:
+//   - input: The whole input.
+//   - at: The position of the token.
+//   - modes: The mode stack before the token. Nil is the same as a stack
+//     that only contains DefaultMode.
+//   - allowed: The types of the tokens that are allowed. Nil allows every
+//     type. The types to skip are always allowed.
+//
+// Returns:
+//   - []*LexStep: The longest matches, after the ties are resolved, then
+//     the shorter matches of the same rules; each with the mode stack after
+//     it. The lookahead of their tokens is not set.
+//   - error: An error if no allowed rule matches.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If at is out of bounds.
+//   - *ErrNoMatches: If no allowed rule of the active mode matches.
+//   - *ErrCannotPopMode: If every match pops the last mode.
+//
+// Behaviors:
+//   - Only the rules of the mode on top of modes are tried. A match whose
+//     mode action fails is dropped.
+//   - The recovery policy is not used.
+func (l *Lexer[T]) MatchAt(input []byte, at int, modes *ModeStack, allowed []T) ([]*LexStep[T], error) {
+	if modes == nil {
+		modes = NewModeStack()
+	}
+
+	mode := modes.Top()
+	rules := l.rules[mode]
+
+	if allowed != nil {
+		rules = slices.DeleteFunc(slices.Clone(rules), func(rule *Rule[T]) bool {
+			lhs := rule.production.GetLhs()
+
+			return !slices.Contains(allowed, lhs) && !slices.Contains(l.to_skip, lhs)
+		})
+	}
+
+	matches, err := match_from(cds.NewStream(input), at, productions_of(rules), mode)
+	if err != nil {
+		return nil, err
+	}
+
+	matches = select_best_matches(matches, rules, l.hook)
+
+	var first_err error
+
+	steps := make([]*LexStep[T], 0, len(matches))
+
+	for _, match := range matches {
+		next, err := rules[match.RuleIndex].GetAction().Apply(modes)
+		if err != nil {
+			if first_err == nil {
+				first_err = err
+			}
+
+			continue
+		}
+
+		steps = append(steps, &LexStep[T]{
+			Token:   match.Matched,
+			Modes:   next,
+			Skipped: slices.Contains(l.to_skip, match.Matched.ID),
+		})
+	}
+
+	if len(steps) == 0 {
+		return nil, first_err
+	}
+
+	return steps, nil
+}
+
 // FullLexer is a convenience function that creates a new lexer, lexes the content,
 // and returns the token streams.
 //
 // Parameters:
 //   - grammar: The grammar to use.
 //   - input: The input to lex.
-//   - logger: A verbose logger.
 //
 // Returns:
 //   - *LexerIterator: The lexer iterator.
-func FullLexer[T gr.TokenTyper](grammar *Grammar[T], input []byte, logger *Verbose) *LexerIterator[T] {
+func FullLexer[T gr.TokenTyper](grammar *Grammar[T], input []byte) *LexerIterator[T] {
 	lexer := NewLexer(grammar)
 	if lexer == nil {
 		return nil
 	}
 
-	iter := lexer.Lex(input, logger)
+	iter := lexer.Lex(input)
 
 	return iter
 }
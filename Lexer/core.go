@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	gr "github.com/PlayerR9/LyneParser/Grammar"
+	tc "github.com/PlayerR9/LyneParser/Tracer"
 	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
 	uc "github.com/PlayerR9/MyGoLib/Units/common"
 	tr "github.com/PlayerR9/tree/tree"
@@ -37,7 +38,7 @@ func last_of_branch[T gr.TokenTyper](branch []*gr.Token[T]) int {
 	return last.At
 }
 
-func Lex[T gr.TokenTyper](s *cds.Stream[byte], productions []*gr.RegProduction[T], v *Verbose) error {
+func Lex[T gr.TokenTyper](s *cds.Stream[byte], productions []*gr.RegProduction[T], hook tc.Hooker) error {
 	f := func(tn *TokenNode[T]) ([]*TokenNode[T], error) {
 		// data := tn.GetData()
 
@@ -55,7 +56,7 @@ func Lex[T gr.TokenTyper](s *cds.Stream[byte], productions []*gr.RegProduction[T
 		// }
 
 		// Get the longest match.
-		// results = selectBestMatches(results, v)
+		// results = selectBestMatches(results, hook)
 
 		// children := make([]*TreeNode[T], 0, len(results))
 
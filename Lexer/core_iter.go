@@ -115,7 +115,7 @@ func (it *CoreIter[T]) Consume() ([][]*gr.Token[T], error) {
 		errs := errs.GetErrors()
 
 		return nil, uc.NewErrPossibleError(
-			NewErrAllMatchesFailed(),
+			NewErrAllMatchesFailed(nil),
 			errs[0],
 		)
 	}
@@ -108,22 +108,99 @@ func NewErrNoMatches() *ErrNoMatches {
 
 // ErrAllMatchesFailed is an error that is returned when all matches
 // fail.
-type ErrAllMatchesFailed struct{}
+type ErrAllMatchesFailed struct {
+	// Reason is the reason the furthest match failed. Nil if it is unknown.
+	Reason error
+}
 
-// Error returns the error message: "all matches failed".
+// Error returns the error message: "all matches failed", followed by
+// ": <reason>" if the reason is known.
 //
 // Returns:
 //   - string: The error message.
 func (e *ErrAllMatchesFailed) Error() string {
-	return "all matches failed"
+	if e.Reason == nil {
+		return "all matches failed"
+	}
+
+	return "all matches failed: " + e.Reason.Error()
+}
+
+// Unwrap returns the reason the furthest match failed.
+//
+// Returns:
+//   - error: The reason. Nil if it is unknown.
+func (e *ErrAllMatchesFailed) Unwrap() error {
+	return e.Reason
 }
 
 // NewErrAllMatchesFailed creates a new error of type *ErrAllMatchesFailed.
 //
+// Parameters:
+//   - reason: The reason the furthest match failed. Nil if it is unknown.
+//
 // Returns:
 //   - *ErrAllMatchesFailed: The new error.
-func NewErrAllMatchesFailed() *ErrAllMatchesFailed {
-	return &ErrAllMatchesFailed{}
+func NewErrAllMatchesFailed(reason error) *ErrAllMatchesFailed {
+	e := &ErrAllMatchesFailed{
+		Reason: reason,
+	}
+	return e
+}
+
+// ErrSyntax is an error that is returned when no rule matches and there is
+// no recovery policy. It holds the syntax error that a recovery policy would
+// have recorded, so that its "did you mean" suggestion is not lost.
+type ErrSyntax struct {
+	// Syntax is the syntax error. Either a *ClosestSyntaxError or an
+	// *UnrecognizedSyntaxError.
+	Syntax SyntaxErrorer
+}
+
+// Error implements the error interface.
+//
+// Message: "no matches at <at>", followed by "; did you mean <closest>?" if
+// there is a suggestion.
+func (e *ErrSyntax) Error() string {
+	return "no matches at " + strconv.Itoa(e.Syntax.GetPosition()) + suggestion_of(e.Syntax)
+}
+
+// Unwrap returns an *ErrNoMatches, so that errors.As finds the same error
+// type as with a bare failure.
+func (e *ErrSyntax) Unwrap() error {
+	return NewErrNoMatches()
+}
+
+// NewErrSyntax creates a new error of type *ErrSyntax.
+//
+// Parameters:
+//   - syntax: The syntax error.
+//
+// Returns:
+//   - *ErrSyntax: The new error.
+func NewErrSyntax(syntax SyntaxErrorer) *ErrSyntax {
+	e := &ErrSyntax{
+		Syntax: syntax,
+	}
+	return e
+}
+
+// suggestion_of is a helper function that formats the suggestion of a syntax
+// error for an error message.
+//
+// Parameters:
+//   - syntax: The syntax error. May be nil.
+//
+// Returns:
+//   - string: "; did you mean <closest>?" if the syntax error is a
+//     *ClosestSyntaxError. Otherwise, an empty string.
+func suggestion_of(syntax SyntaxErrorer) string {
+	cse, ok := syntax.(*ClosestSyntaxError)
+	if !ok {
+		return ""
+	}
+
+	return "; did you mean " + strconv.Quote(cse.closest) + "?"
 }
 
 // ErrInvalidElement is an error that is returned when an invalid element
@@ -146,6 +223,157 @@ func NewErrInvalidElement() *ErrInvalidElement {
 	return &ErrInvalidElement{}
 }
 
+// ErrCannotPopMode is an error that is returned when a rule tries to leave
+// the default lexer mode.
+type ErrCannotPopMode struct{}
+
+// Error returns the error message: "cannot pop the default mode".
+//
+// Returns:
+//   - string: The error message.
+func (e *ErrCannotPopMode) Error() string {
+	return "cannot pop the default mode"
+}
+
+// NewErrCannotPopMode creates a new error of type *ErrCannotPopMode.
+//
+// Returns:
+//   - *ErrCannotPopMode: The new error.
+func NewErrCannotPopMode() *ErrCannotPopMode {
+	return &ErrCannotPopMode{}
+}
+
+// ErrUnknownMode is an error that is returned when the lexer enters a mode
+// that has no rules.
+type ErrUnknownMode struct {
+	// Mode is the name of the mode.
+	Mode string
+}
+
+// Error implements the error interface.
+//
+// Message: "mode <mode> has no rules".
+func (e *ErrUnknownMode) Error() string {
+	return "mode " + strconv.Quote(e.Mode) + " has no rules"
+}
+
+// NewErrUnknownMode creates a new error of type *ErrUnknownMode.
+//
+// Parameters:
+//   - mode: The name of the mode.
+//
+// Returns:
+//   - *ErrUnknownMode: The new error.
+func NewErrUnknownMode(mode string) *ErrUnknownMode {
+	e := &ErrUnknownMode{
+		Mode: mode,
+	}
+	return e
+}
+
+// ErrUnknownRule is an error that is returned when no rule of the grammar
+// has the given left-hand side.
+type ErrUnknownRule struct {
+	// Lhs is the left-hand side.
+	Lhs string
+}
+
+// Error implements the error interface.
+//
+// Message: "no rule has the left-hand side <lhs>".
+func (e *ErrUnknownRule) Error() string {
+	return "no rule has the left-hand side " + strconv.Quote(e.Lhs)
+}
+
+// NewErrUnknownRule creates a new error of type *ErrUnknownRule.
+//
+// Parameters:
+//   - lhs: The left-hand side.
+//
+// Returns:
+//   - *ErrUnknownRule: The new error.
+func NewErrUnknownRule(lhs string) *ErrUnknownRule {
+	e := &ErrUnknownRule{
+		Lhs: lhs,
+	}
+	return e
+}
+
+// ErrTokenTooLong is an error that is returned by a StreamLexer when a token
+// does not fit in its window.
+type ErrTokenTooLong struct {
+	// Pos is the position where the token starts.
+	Pos Position
+
+	// Limit is the size of the window, in bytes.
+	Limit int
+}
+
+// Error implements the error interface.
+//
+// Message: "token at <pos> is longer than <limit> bytes".
+func (e *ErrTokenTooLong) Error() string {
+	return "token at " + e.Pos.String() + " is longer than " + strconv.Itoa(e.Limit) + " bytes"
+}
+
+// NewErrTokenTooLong creates a new error of type *ErrTokenTooLong.
+//
+// Parameters:
+//   - pos: The position where the token starts.
+//   - limit: The size of the window, in bytes.
+//
+// Returns:
+//   - *ErrTokenTooLong: The new error.
+func NewErrTokenTooLong(pos Position, limit int) *ErrTokenTooLong {
+	e := &ErrTokenTooLong{
+		Pos:   pos,
+		Limit: limit,
+	}
+	return e
+}
+
+// ErrNoMatchesAt is an error that is returned by a StreamLexer when no rule
+// matches at a position and there is no recovery policy.
+type ErrNoMatchesAt struct {
+	// Pos is the position where no rule matches.
+	Pos Position
+
+	// Syntax is the syntax error that a recovery policy would have recorded.
+	// Nil if it is unknown.
+	Syntax SyntaxErrorer
+}
+
+// Error implements the error interface.
+//
+// Message: "no matches at <pos>", followed by "; did you mean <closest>?"
+// if there is a suggestion.
+func (e *ErrNoMatchesAt) Error() string {
+	return "no matches at " + e.Pos.String() + suggestion_of(e.Syntax)
+}
+
+// Unwrap returns an *ErrNoMatches, so that errors.As finds the same error
+// type as with the other lexing functions.
+func (e *ErrNoMatchesAt) Unwrap() error {
+	return NewErrNoMatches()
+}
+
+// NewErrNoMatchesAt creates a new error of type *ErrNoMatchesAt.
+//
+// Parameters:
+//   - pos: The position where no rule matches.
+//   - syntax: The syntax error that a recovery policy would have recorded.
+//     Nil if it is unknown.
+//
+// Returns:
+//   - *ErrNoMatchesAt: The new error.
+func NewErrNoMatchesAt(pos Position, syntax SyntaxErrorer) *ErrNoMatchesAt {
+	e := &ErrNoMatchesAt{
+		Pos:    pos,
+		Syntax: syntax,
+	}
+	return e
+}
+
 // IsDone checks if an error is a completion error or nil.
 //
 // Parameters:
@@ -46,7 +46,7 @@ func (cse *ClosestSyntaxError) Display() []string {
 	var reason_msg string
 
 	if cse.reason == nil {
-		reason_msg = "found an unexpected character"
+		reason_msg = strconv.Quote(cse.actual) + " is not recognized"
 	} else {
 		reason_msg = cse.reason.Error()
 	}
@@ -58,10 +58,8 @@ func (cse *ClosestSyntaxError) Display() []string {
 
 	var suggestion strings.Builder
 
-	suggestion.WriteString("\tDid you mean ")
+	suggestion.WriteString("\tdid you mean ")
 	suggestion.WriteString(strconv.Quote(cse.closest))
-	suggestion.WriteString(" instead of ")
-	suggestion.WriteString(strconv.Quote(cse.actual))
 	suggestion.WriteString("?")
 
 	lines := []string{
@@ -79,10 +77,10 @@ func (cse *ClosestSyntaxError) Display() []string {
 // NewClosestSyntaxError creates a new ClosestSyntaxError.
 //
 // Parameters:
-//   - unexpected_char: The unexpected character.
-//   - previous_char: The previous character.
-//   - got_char: The got character.
-//   - end_idx: The end index.
+//   - reason: The unexpected character. Nil if the whole word is unexpected.
+//   - closest: The closest keyword.
+//   - actual: The actual keyword.
+//   - end_idx: The position of the error.
 //
 // Returns:
 //   - *ClosestSyntaxError: The new ClosestSyntaxError.
@@ -94,11 +92,6 @@ func NewClosestSyntaxError(reason *ErrUnexpectedChar, closest, actual string, en
 		actual:  actual,
 	}
 
-	// closest, err := laven_table.GetClosest(f.data[f.idx:cse.end_idx])
-	// uc.Assert(err == nil, "In GetClosest, no error should occur")
-
-	// actual := f.data[f.idx:cse.end_idx]
-
 	return cse
 }
 
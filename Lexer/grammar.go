@@ -4,13 +4,17 @@ import (
 	"slices"
 
 	gr "github.com/PlayerR9/LyneParser/Grammar"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
 	us "github.com/PlayerR9/MyGoLib/Units/slice"
 )
 
 // Grammar represents a context-free grammar.
 type Grammar[T gr.TokenTyper] struct {
-	// productions is a slice of productions in the grammar.
-	productions []*gr.RegProduction[T]
+	// productions is a slice of productions in the grammar, of every mode.
+	productions []gr.TokenMatcher[T]
+
+	// rules are the rules of each mode, in declaration order.
+	rules map[string][]*Rule[T]
 
 	// lhs_to_skip is a slice of productions to skip.
 	lhs_to_skip []T
@@ -26,7 +30,7 @@ func (g *Grammar[T]) Fix() error {
 	g.lhs_to_skip = us.SliceFilter(
 		g.lhs_to_skip,
 		func(lhs T) bool {
-			filter_production_with_lhs := func(p *gr.RegProduction[T]) bool {
+			filter_production_with_lhs := func(p gr.TokenMatcher[T]) bool {
 				return p != nil && p.GetLhs() == lhs
 			}
 
@@ -55,12 +59,13 @@ func NewGrammar[T gr.TokenTyper](to_skip []T) *Grammar[T] {
 
 	g := &Grammar[T]{
 		lhs_to_skip: to_skip,
+		rules:       make(map[string][]*Rule[T]),
 	}
 
 	return g
 }
 
-// AddRule adds a new rule to the grammar.
+// AddRule adds a new rule to the default mode of the grammar.
 //
 // Parameters:
 //   - lhs: The left-hand side of the production.
@@ -69,6 +74,38 @@ func NewGrammar[T gr.TokenTyper](to_skip []T) *Grammar[T] {
 // Returns:
 //   - error: An error if there was a problem adding the rule.
 func (g *Grammar[T]) AddRule(lhs T, regex string) error {
+	return g.AddModeRule(DefaultMode, lhs, regex, nil)
+}
+
+// AddModeRule adds a new rule to a mode of the grammar. The mode is created
+// if it does not exist yet.
+//
+// Parameters:
+//   - mode: The mode the rule belongs to.
+//   - lhs: The left-hand side of the production.
+//   - regex: The regular expression of the production.
+//   - action: The change to the mode stack when the rule matches. Nil if
+//     the mode does not change.
+//
+// Returns:
+//   - error: An error if there was a problem adding the rule.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If mode is empty or the action enters an
+//     empty mode.
+//   - any error returned by the compilation of the regular expression.
+//
+// Behaviors:
+//   - Rules of different modes can have the same left-hand side.
+func (g *Grammar[T]) AddModeRule(mode string, lhs T, regex string, action *ModeAction) error {
+	if mode == "" {
+		return uc.NewErrInvalidParameter("mode", uc.NewErrEmpty(mode))
+	}
+
+	if action != nil && action.Kind != PopModeAction && action.Mode == "" {
+		return uc.NewErrInvalidParameter("action", uc.NewErrEmpty(action.Mode))
+	}
+
 	production := gr.NewRegProduction(lhs, regex)
 
 	err := production.Compile()
@@ -76,8 +113,181 @@ func (g *Grammar[T]) AddRule(lhs T, regex string) error {
 		return err
 	}
 
+	g.add_rule(mode, production, action, nil)
+
+	return nil
+}
+
+// AddLiteral adds a new rule to the default mode of the grammar that
+// matches a fixed string, such as a keyword. See AddModeLiteral.
+//
+// Parameters:
+//   - lhs: The left-hand side of the production.
+//   - literal: The string to match.
+//   - fold: True if the match is case-insensitive.
+//
+// Returns:
+//   - error: An error of type *uc.ErrInvalidParameter if the literal is
+//     empty.
+func (g *Grammar[T]) AddLiteral(lhs T, literal string, fold bool) error {
+	return g.AddModeLiteral(DefaultMode, lhs, literal, fold, nil)
+}
+
+// AddModeLiteral is like AddModeRule but it matches a fixed string instead
+// of a regular expression.
+//
+// Parameters:
+//   - mode: The mode the rule belongs to.
+//   - lhs: The left-hand side of the production.
+//   - literal: The string to match.
+//   - fold: True if the match is case-insensitive.
+//   - action: The change to the mode stack when the rule matches. Nil if
+//     the mode does not change.
+//
+// Returns:
+//   - error: An error if there was a problem adding the rule.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If mode or the literal is empty or the action
+//     enters an empty mode.
+//
+// Behaviors:
+//   - The rule has the tie break ByPriority(LiteralPriority). Thus, the
+//     keyword "if" wins over an identifier regex that matches the same text;
+//     but not over one that matches a longer text, such as "iffy".
+func (g *Grammar[T]) AddModeLiteral(mode string, lhs T, literal string, fold bool, action *ModeAction) error {
+	if mode == "" {
+		return uc.NewErrInvalidParameter("mode", uc.NewErrEmpty(mode))
+	}
+
+	if literal == "" {
+		return uc.NewErrInvalidParameter("literal", uc.NewErrEmpty(literal))
+	}
+
+	if action != nil && action.Kind != PopModeAction && action.Mode == "" {
+		return uc.NewErrInvalidParameter("action", uc.NewErrEmpty(action.Mode))
+	}
+
+	production := gr.NewLitProduction(lhs, literal, fold)
+
+	g.add_rule(mode, production, action, ByPriority(LiteralPriority))
+
+	return nil
+}
+
+// SetTieBreak changes the way the rules with the given left-hand side take
+// part in the resolution of ties; that is, when several rules of the same
+// mode match the same longest text.
+//
+// Parameters:
+//   - lhs: The left-hand side of the rules, in every mode.
+//   - tie_break: The tie break. Nil is the same as ByLongestMatch().
+//
+// Returns:
+//   - error: An error of type *ErrUnknownRule if no rule has the left-hand
+//     side.
+//
+// Behaviors:
+//   - The tie break is composed with the current one of each rule: ByPriority
+//     only changes the priority, ByLongestMatch and ByDeclarationOrder only
+//     change the kind. Thus, a literal stays ByPriority(LiteralPriority)
+//     unless its priority is changed with ByPriority.
+func (g *Grammar[T]) SetTieBreak(lhs T, tie_break *TieBreak) error {
+	var found bool
+
+	for _, bucket := range g.rules {
+		for _, rule := range bucket {
+			if rule.production.GetLhs() == lhs {
+				rule.tie_break = rule.tie_break.compose(tie_break)
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return NewErrUnknownRule(lhs.String())
+	}
+
+	return nil
+}
+
+// AddMatcher adds a new rule to the default mode of the grammar that uses
+// a hand-written matcher instead of a regular expression.
+//
+// Parameters:
+//   - lhs: The left-hand side of the production.
+//   - matcher: The matcher of the production.
+//
+// Returns:
+//   - error: An error of type *uc.ErrInvalidParameter if the matcher is nil.
+func (g *Grammar[T]) AddMatcher(lhs T, matcher gr.Matcher) error {
+	return g.AddModeMatcher(DefaultMode, lhs, matcher, nil)
+}
+
+// AddModeMatcher is like AddModeRule but it uses a hand-written matcher
+// instead of a regular expression.
+//
+// Parameters:
+//   - mode: The mode the rule belongs to.
+//   - lhs: The left-hand side of the production.
+//   - matcher: The matcher of the production.
+//   - action: The change to the mode stack when the rule matches. Nil if
+//     the mode does not change.
+//
+// Returns:
+//   - error: An error if there was a problem adding the rule.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If mode is empty, the matcher is nil or the
+//     action enters an empty mode.
+//
+// Behaviors:
+//   - Matchers take part in the longest-match selection and in branching
+//     exactly like regular expressions, through their longest match. If it
+//     is kept, each shorter length the matcher reports gives its own branch
+//     as well.
+func (g *Grammar[T]) AddModeMatcher(mode string, lhs T, matcher gr.Matcher, action *ModeAction) error {
+	if mode == "" {
+		return uc.NewErrInvalidParameter("mode", uc.NewErrEmpty(mode))
+	}
+
+	if matcher == nil {
+		return uc.NewErrNilParameter("matcher")
+	}
+
+	if action != nil && action.Kind != PopModeAction && action.Mode == "" {
+		return uc.NewErrInvalidParameter("action", uc.NewErrEmpty(action.Mode))
+	}
+
+	production := gr.NewFuncProduction(lhs, matcher)
+
+	g.add_rule(mode, production, action, nil)
+
+	return nil
+}
+
+// add_rule is a helper method that adds a rule to a mode of the grammar.
+//
+// Parameters:
+//   - mode: The mode the rule belongs to.
+//   - production: The production of the rule.
+//   - action: The change to the mode stack when the rule matches.
+//   - tie_break: The tie break of the rule. May be nil.
+func (g *Grammar[T]) add_rule(mode string, production gr.TokenMatcher[T], action *ModeAction, tie_break *TieBreak) {
 	g.productions = append(g.productions, production)
 
+	if g.rules == nil {
+		g.rules = make(map[string][]*Rule[T])
+	}
+
+	rule := &Rule[T]{
+		production: production,
+		action:     action,
+		tie_break:  tie_break,
+	}
+
+	g.rules[mode] = append(g.rules[mode], rule)
+
 	tmp := production.GetSymbols()
 
 	for _, t := range tmp {
@@ -86,8 +296,6 @@ func (g *Grammar[T]) AddRule(lhs T, regex string) error {
 			g.symbols = slices.Insert(g.symbols, pos, t)
 		}
 	}
-
-	return nil
 }
 
 // GetSymbols returns a slice of symbols in the grammar.
@@ -101,17 +309,88 @@ func (g *Grammar[T]) GetSymbols() []T {
 	return symbols
 }
 
-// GetRegexProds returns a slice of RegProduction in the grammar.
+// GetRegexProds returns a slice of RegProduction in the default mode of
+// the grammar. Rules that use a matcher are not included.
 //
 // Returns:
 //   - []*RegProduction: A slice of RegProduction in the grammar.
 func (g *Grammar[T]) GetRegexProds() []*gr.RegProduction[T] {
-	reg_prods := make([]*gr.RegProduction[T], len(g.productions))
-	copy(reg_prods, g.productions)
+	var reg_prods []*gr.RegProduction[T]
+
+	for _, rule := range g.rules[DefaultMode] {
+		p, ok := rule.production.(*gr.RegProduction[T])
+		if ok {
+			reg_prods = append(reg_prods, p)
+		}
+	}
 
 	return reg_prods
 }
 
+// GetProductions returns the productions in the default mode of the
+// grammar, both regular expressions and matchers, in declaration order.
+//
+// Returns:
+//   - []gr.TokenMatcher: The productions.
+func (g *Grammar[T]) GetProductions() []gr.TokenMatcher[T] {
+	return productions_of(g.rules[DefaultMode])
+}
+
+// GetLiterals returns the string matched by the literal rules of the
+// grammar, of every mode. They can be given to the parser as the spellings of
+// the terminals, for its "did you mean" suggestions.
+//
+// Returns:
+//   - map[T]string: The literal of each left-hand side. If a left-hand side
+//     has several literal rules, the first declared one is used.
+func (g *Grammar[T]) GetLiterals() map[T]string {
+	literals := make(map[T]string)
+
+	for _, p := range g.productions {
+		lp, ok := p.(*gr.LitProduction[T])
+		if !ok {
+			continue
+		}
+
+		_, ok = literals[lp.GetLhs()]
+		if !ok {
+			literals[lp.GetLhs()] = lp.GetLiteral()
+		}
+	}
+
+	return literals
+}
+
+// GetModes returns the names of the modes of the grammar, sorted.
+//
+// Returns:
+//   - []string: The names of the modes.
+func (g *Grammar[T]) GetModes() []string {
+	modes := make([]string, 0, len(g.rules))
+
+	for mode := range g.rules {
+		modes = append(modes, mode)
+	}
+
+	slices.Sort(modes)
+
+	return modes
+}
+
+// GetRules returns a copy of the rules of every mode of the grammar.
+//
+// Returns:
+//   - map[string][]*Rule: The rules of each mode, in declaration order.
+func (g *Grammar[T]) GetRules() map[string][]*Rule[T] {
+	rules := make(map[string][]*Rule[T], len(g.rules))
+
+	for mode, bucket := range g.rules {
+		rules[mode] = slices.Clone(bucket)
+	}
+
+	return rules
+}
+
 // GetToSkip returns a slice of LHSs to skip.
 //
 // Returns:
@@ -1,11 +1,13 @@
 package Lexer
 
 import (
+	"slices"
+
 	gr "github.com/PlayerR9/LyneParser/Grammar"
+	tc "github.com/PlayerR9/LyneParser/Tracer"
 	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
 	uc "github.com/PlayerR9/MyGoLib/Units/common"
 	us "github.com/PlayerR9/MyGoLib/Units/slice"
-	tr "github.com/PlayerR9/tree/tree"
 )
 
 // match_weight_func is a weight function that returns the length of the match.
@@ -35,45 +37,84 @@ func filter_error_leaves[T gr.TokenTyper](h *TokenNode[T]) bool {
 // Usually, the best matches' euristic is the longest match.
 //
 // Parameters:
-//   - matches: The list of matches.
-//   - logger: A verbose logger.
+//   - matches: The list of matches, as returned by match_from.
+//   - rules: The rules of the mode, in declaration order.
+//   - hook: The hook that receives the events. May be nil.
 //
 // Returns:
-//   - []*gr.MatchedResult: The best matches.
-func select_best_matches[T gr.TokenTyper](matches []*gr.MatchedResult[T], logger *Verbose) []*gr.MatchedResult[T] {
-	logger.DoIf(func(p *Printer) {
-		p.Print("Selecting best matches...")
-	})
-
+//   - []*gr.MatchedResult: The best matches. The longest ones come first.
+//
+// Behaviors:
+//   - The ties between the longest matches are resolved (see resolve_ties).
+//     Then, the shorter matches of the rules that survive are kept too, so
+//     that each length a matcher reports gives its own branch.
+//   - A token matched event is fired for each of the best matches and, if
+//     there is more than one, a branch forked event is fired as well.
+func select_best_matches[T gr.TokenTyper](matches []*gr.MatchedResult[T], rules []*Rule[T], hook tc.Hooker) []*gr.MatchedResult[T] {
 	weights := us.ApplyWeightFunc(matches, match_weight_func)
 	pairs := us.FilterByPositiveWeight(weights)
 
-	results := us.ExtractResults(pairs)
+	longest := us.ExtractResults(pairs)
+
+	results := resolve_ties(longest, rules)
 
-	logger.DoIf(func(p *Printer) {
-		p.Print("The best matches are:")
+	for _, match := range matches[len(longest):] {
+		survives := slices.ContainsFunc(results, func(elem *gr.MatchedResult[T]) bool {
+			return elem.RuleIndex == match.RuleIndex
+		})
 
-		for _, elem := range results {
-			p.Printf("\t%+v", elem.Matched.Data)
+		if survives {
+			results = append(results, match)
 		}
-	})
+	}
+
+	if hook == nil {
+		return results
+	}
+
+	for _, elem := range results {
+		data, _ := elem.Matched.Data.(string)
+
+		tc.Fire(hook, &tc.TokenMatchedEvent{
+			Type: elem.Matched.ID.String(),
+			Data: data,
+			At:   elem.Matched.At,
+		})
+	}
+
+	if len(results) > 1 {
+		tc.Fire(hook, &tc.BranchForkedEvent{
+			Origin: tc.FromLexer,
+			At:     results[0].Matched.At,
+			Count:  len(results),
+		})
+	}
 
 	return results
 }
 
-// SetEOFToken sets the end-of-file token in the token stream.
+// set_eof_token is a helper function that appends an end-of-file token to
+// the tokens. If the last token is already an end-of-file token, it is not
+// added again.
+//
+// Parameters:
+//   - tokens: The tokens.
+//   - eof: The type of the end-of-file token.
+//   - at: The position of the end-of-file token; that is, the size of the
+//     source.
 //
-// If the end-of-file token is already present, it will not be added again.
-func set_eof_token[T gr.TokenTyper](tokens []*gr.Token[T]) []*gr.Token[T] {
-	if len(tokens) != 0 && tokens[len(tokens)-1].ID.String() == gr.EOFTokenID {
+// Returns:
+//   - []*gr.Token: The tokens, ending with the end-of-file token. Its
+//     lookahead is not set.
+func set_eof_token[T gr.TokenTyper](tokens []*gr.Token[T], eof T, at int) []*gr.Token[T] {
+	if len(tokens) != 0 && tokens[len(tokens)-1].ID == eof {
 		// EOF token is already present
 		return tokens
 	}
 
-	// tok := gr.EofToken()
-	//
-	// return append(tokens, tok)
-	panic("FIXME: EOF token is not implemented")
+	tok := gr.NewToken(eof, "", at, nil)
+
+	return append(tokens, tok)
 }
 
 // set_lookahead sets the lookahead token for all the tokens in the stream.
@@ -85,56 +126,13 @@ func set_lookahead[T gr.TokenTyper](tokens []*gr.Token[T]) {
 	}
 }
 
-// convert_branch_to_token_stream converts a branch to a token stream.
-//
-// Parameters:
-//   - branch: The branch to convert.
-//   - toSkip: The tokens to skip.
-//
-// Returns:
-//   - *cds.Stream[*LeafToken]: The token stream.
-func convert_branch_to_token_stream[T gr.TokenTyper](branch []tr.Noder, toSkip []T) *cds.Stream[*gr.Token[T]] {
-	branch = branch[1:]
-
-	for _, elem := range toSkip {
-		filter_token_different_id := func(n tr.Noder) bool {
-			tn, ok := n.(*TokenNode[T])
-			if !ok {
-				return false
-			}
-
-			token := tr.Token
-
-			return token.ID != elem
-		}
-
-		branch = us.SliceFilter(branch, filter_token_different_id)
-	}
-
-	var ts []*gr.Token[T]
-
-	for _, elem := range branch {
-		tn, ok := elem.(*TokenNode[T])
-		uc.Assert(ok, "Must be a *TokenNode[T]")
-
-		ts = append(ts, tr.Token)
-	}
-
-	ts = set_eof_token(ts)
-
-	set_lookahead(ts)
-
-	stream := cds.NewStream(ts)
-
-	return stream
-}
-
 // match_from matches the source stream from a given index with a list of production rules.
 //
 // Parameters:
 //   - s: The source stream to match.
 //   - from: The index to start matching from.
-//   - ps: The production rules to match.
+//   - ps: The production rules to match. Either regular expressions or matchers.
+//   - mode: The mode of the rules. It is recorded on the tokens.
 //
 // Returns:
 //   - matches: A slice of MatchedResult that match the input token.
@@ -143,7 +141,14 @@ func convert_branch_to_token_stream[T gr.TokenTyper](branch []tr.Noder, toSkip [
 // Errors:
 //   - *uc.ErrInvalidParameter: The from index is out of bounds.
 //   - *ErrNoMatches: No matches are found.
-func match_from[T gr.TokenTyper](s *cds.Stream[byte], from int, ps []*gr.RegProduction[T]) ([]*gr.MatchedResult[T], error) {
+//
+// Behaviors:
+//   - Only the rules with the longest match take part. If several rules
+//     match with the same length, all of them do.
+//   - Each rule that takes part gives a match for every length it reports:
+//     first the longest matches, in the order of the rules, then the shorter
+//     ones, in the order of the rules and from the longest to the shortest.
+func match_from[T gr.TokenTyper](s *cds.Stream[byte], from int, ps []gr.TokenMatcher[T], mode string) ([]*gr.MatchedResult[T], error) {
 	size := s.Size()
 
 	if from < 0 || from >= size {
@@ -153,86 +158,52 @@ func match_from[T gr.TokenTyper](s *cds.Stream[byte], from int, ps []*gr.RegProd
 		)
 	}
 
-	type Result struct {
-		subset  []byte
-		matches []*gr.MatchedResult[T]
-	}
+	input := s.GetItems()
 
-	var prev_result *Result
+	all_lengths := make([][]int, len(ps))
 
-	for i := 1; i <= size; i++ {
-		subset, err := s.Get(from, i)
-		uc.AssertF(err == nil, "Get failed: %s", err.Error())
+	var longest int
 
-		var matches []*gr.MatchedResult[T]
-
-		for i, p := range ps {
-			matched, ok := p.MatchRegProd(from, subset)
-			if ok {
-				res := gr.NewMatchResult(matched, i)
-				matches = append(matches, res)
-			}
+	for i, p := range ps {
+		lengths := p.MatchLengths(input, from)
+		if len(lengths) == 0 {
+			continue
 		}
 
-		if len(matches) != 0 {
-			result := &Result{
-				subset:  subset,
-				matches: matches,
-			}
-
-			prev_result = result
-		}
+		all_lengths[i] = lengths
+		longest = max(longest, lengths[len(lengths)-1])
 	}
 
-	if prev_result == nil {
+	if longest == 0 {
 		return nil, NewErrNoMatches()
 	}
 
-	return prev_result.matches, nil
-}
-
-// filter_leaves processes the leaves in the tree evaluator.
-//
-// Parameters:
-//   - source: The source stream to match.
-//   - productions: The production rules to match.
-//   - logger: A verbose logger.
-//
-// Returns:
-//   - bool: True if all leaves are complete, false otherwise.
-//   - error: An error of type *ErrAllMatchesFailed if all matches failed.
-func filter_leaves[T gr.TokenTyper](source *cds.Stream[byte], productions []*gr.RegProduction[T], logger *Verbose) uc.EvalManyFunc[tr.Noder, tr.Noder] {
-	filter_func := func(ld tr.Noder) ([]tr.Noder, error) {
-		// data := ld.GetData()
-
-		// var nextAt int
+	new_match := func(i, length int) *gr.MatchedResult[T] {
+		matched := gr.NewToken(ps[i].GetLhs(), string(input[from:from+length]), from, nil)
+		matched.Mode = mode
 
-		// if data.ID.String() == gr.RootTokenID {
-		// 	nextAt = 0
-		// } else {
-		// 	nextAt = data.GetPos() + len(data.Data.(string))
-		// }
-
-		// if nextAt >= source.Size() {
-		// 	ld.ChangeStatus(EvalComplete)
-		// 	return nil, nil
-		// }
+		return gr.NewMatchResult(matched, i)
+	}
 
-		// matches, err := matchFrom(source, nextAt, productions)
-		// if err != nil {
-		// 	ld.ChangeStatus(EvalError)
-		// 	return nil, nil
-		// }
+	var matches []*gr.MatchedResult[T]
 
-		// children := generateEvalTrees(matches, logger)
-		// ld.ChangeStatus(EvalComplete)
+	for i, lengths := range all_lengths {
+		if len(lengths) > 0 && lengths[len(lengths)-1] == longest {
+			matches = append(matches, new_match(i, longest))
+		}
+	}
 
-		// return children, nil
+	for i, lengths := range all_lengths {
+		if len(lengths) == 0 || lengths[len(lengths)-1] != longest {
+			continue
+		}
 
-		panic("FIXME: filterLeaves is not implemented")
+		for j := len(lengths) - 2; j >= 0; j-- {
+			matches = append(matches, new_match(i, lengths[j]))
+		}
 	}
 
-	return filter_func
+	return matches, nil
 }
 
 /*
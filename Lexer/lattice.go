@@ -0,0 +1,703 @@
+package Lexer
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	tc "github.com/PlayerR9/LyneParser/Tracer"
+	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// LatticeNode is a node of a token lattice: a position in the source,
+// together with the mode stack of the lexer at that position.
+type LatticeNode struct {
+	// At is the position in the source.
+	At int
+
+	// Modes is the mode stack of the lexer at the position.
+	Modes *ModeStack
+}
+
+// LatticeEdge is an edge of a token lattice: a candidate token that goes from
+// the position where it starts to the position where it ends.
+type LatticeEdge[T gr.TokenTyper] struct {
+	// Token is the candidate token. It is shared by every path that uses the
+	// edge, so it must not be modified.
+	Token *gr.Token[T]
+
+	// From is the index of the node where the token starts.
+	From int
+
+	// To is the index of the node where the token ends.
+	To int
+
+	// Error is the syntax error of the text covered by the token. Only set
+	// for the error tokens emitted by the recovery policy.
+	Error SyntaxErrorer
+}
+
+// TokenLattice is a directed acyclic graph of every tokenization of a source.
+// Every position is lexed only once, so tokenizations that share a suffix
+// share its nodes and edges. Lexer.Lex iterates over its paths.
+//
+// Without lexer modes, there is exactly one node per reachable position. With
+// modes, there is one node per reachable position and mode stack.
+type TokenLattice[T gr.TokenTyper] struct {
+	// source is the source that was lexed.
+	source []byte
+
+	// to_skip are the types of the tokens that paths leave out.
+	to_skip []T
+
+	// nodes are the nodes of the lattice. The first one is the start node.
+	nodes []*LatticeNode
+
+	// out are the outgoing edges of each node, in rule order.
+	out [][]*LatticeEdge[T]
+
+	// index maps the key of a node to its index.
+	index map[string]int
+
+	// eof is the type of the end-of-file token. Only valid if has_eof is true.
+	eof T
+
+	// has_eof is true if the paths end with an end-of-file token.
+	has_eof bool
+}
+
+// node_key is a helper function that returns the key of a node.
+//
+// Parameters:
+//   - at: The position of the node.
+//   - modes: The mode stack of the node.
+//
+// Returns:
+//   - string: The key.
+//
+// Behaviors:
+//   - Each mode is written after the length of its name, so two different
+//     mode stacks never have the same key, whatever their names contain.
+func node_key(at int, modes *ModeStack) string {
+	var builder strings.Builder
+
+	builder.WriteString(strconv.Itoa(at))
+
+	for _, mode := range modes.Slice() {
+		builder.WriteRune('|')
+		builder.WriteString(strconv.Itoa(len(mode)))
+		builder.WriteRune(':')
+		builder.WriteString(mode)
+	}
+
+	return builder.String()
+}
+
+// get_node is a helper method that returns the node with the given position
+// and mode stack, creating it if it does not exist yet.
+//
+// Parameters:
+//   - at: The position of the node.
+//   - modes: The mode stack of the node.
+//
+// Returns:
+//   - int: The index of the node.
+//   - bool: True if the node was created, false if it already existed.
+func (tl *TokenLattice[T]) get_node(at int, modes *ModeStack) (int, bool) {
+	key := node_key(at, modes)
+
+	idx, ok := tl.index[key]
+	if ok {
+		return idx, false
+	}
+
+	idx = len(tl.nodes)
+
+	tl.nodes = append(tl.nodes, &LatticeNode{
+		At:    at,
+		Modes: modes,
+	})
+	tl.out = append(tl.out, nil)
+	tl.index[key] = idx
+
+	return idx, true
+}
+
+// is_final is a helper method that checks whether a node is at the end of
+// the source.
+//
+// Parameters:
+//   - node: The index of the node.
+//
+// Returns:
+//   - bool: True if the node is at the end of the source, false otherwise.
+func (tl *TokenLattice[T]) is_final(node int) bool {
+	return tl.nodes[node].At >= len(tl.source)
+}
+
+// IsFinal checks whether a node is at the end of the source; that is,
+// whether the paths that reach it are complete.
+//
+// Parameters:
+//   - node: The index of the node.
+//
+// Returns:
+//   - bool: True if the node is final, false otherwise or if the index is
+//     out of bounds.
+func (tl *TokenLattice[T]) IsFinal(node int) bool {
+	return node >= 0 && node < len(tl.nodes) && tl.is_final(node)
+}
+
+// IsSkipped checks whether the paths leave out the tokens of an edge.
+//
+// Parameters:
+//   - edge: The edge.
+//
+// Returns:
+//   - bool: True if the type of the token is one of the types to skip.
+func (tl *TokenLattice[T]) IsSkipped(edge *LatticeEdge[T]) bool {
+	return slices.Contains(tl.to_skip, edge.Token.ID)
+}
+
+// NodeCount returns the number of nodes of the lattice.
+//
+// Returns:
+//   - int: The number of nodes.
+func (tl *TokenLattice[T]) NodeCount() int {
+	return len(tl.nodes)
+}
+
+// EdgeCount returns the number of edges of the lattice.
+//
+// Returns:
+//   - int: The number of edges.
+func (tl *TokenLattice[T]) EdgeCount() int {
+	var count int
+
+	for _, edges := range tl.out {
+		count += len(edges)
+	}
+
+	return count
+}
+
+// GetNode returns a node of the lattice.
+//
+// Parameters:
+//   - node: The index of the node. 0 is the start node.
+//
+// Returns:
+//   - *LatticeNode: The node. Nil if the index is out of bounds.
+func (tl *TokenLattice[T]) GetNode(node int) *LatticeNode {
+	if node < 0 || node >= len(tl.nodes) {
+		return nil
+	}
+
+	return tl.nodes[node]
+}
+
+// GetEdges returns the outgoing edges of a node of the lattice.
+//
+// Parameters:
+//   - node: The index of the node. 0 is the start node.
+//
+// Returns:
+//   - []*LatticeEdge: The edges, in rule order. Nil if the index is out of
+//     bounds.
+func (tl *TokenLattice[T]) GetEdges(node int) []*LatticeEdge[T] {
+	if node < 0 || node >= len(tl.out) {
+		return nil
+	}
+
+	return slices.Clone(tl.out[node])
+}
+
+// by_position is a helper method that returns the indices of the nodes,
+// sorted by decreasing position. Since every edge goes forward in the
+// source, this is a reverse topological order.
+//
+// Returns:
+//   - []int: The indices of the nodes.
+func (tl *TokenLattice[T]) by_position() []int {
+	order := make([]int, len(tl.nodes))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return tl.nodes[b].At - tl.nodes[a].At
+	})
+
+	return order
+}
+
+// Count returns the number of complete paths of the lattice; that is, the
+// number of tokenizations of the source. It does not enumerate them.
+//
+// Returns:
+//   - uint64: The number of paths. math.MaxUint64 if there are more.
+func (tl *TokenLattice[T]) Count() uint64 {
+	if len(tl.nodes) == 0 {
+		return 0
+	}
+
+	counts := make([]uint64, len(tl.nodes))
+
+	for _, node := range tl.by_position() {
+		if tl.is_final(node) {
+			counts[node] = 1
+			continue
+		}
+
+		var total uint64
+
+		for _, edge := range tl.out[node] {
+			c := counts[edge.To]
+
+			if total > math.MaxUint64-c {
+				total = math.MaxUint64
+				break
+			}
+
+			total += c
+		}
+
+		counts[node] = total
+	}
+
+	return counts[0]
+}
+
+// Prune removes the edges that the callback rejects, then every edge and
+// node that is no longer on a complete path.
+//
+// Parameters:
+//   - keep: The callback. It returns false for the edges to remove.
+//
+// Returns:
+//   - int: The number of removed edges.
+func (tl *TokenLattice[T]) Prune(keep func(edge *LatticeEdge[T]) bool) int {
+	var removed int
+
+	if keep != nil {
+		for i, edges := range tl.out {
+			kept := slices.DeleteFunc(edges, func(edge *LatticeEdge[T]) bool {
+				return !keep(edge)
+			})
+
+			removed += len(edges) - len(kept)
+			tl.out[i] = kept
+		}
+	}
+
+	return removed + tl.remove_dead()
+}
+
+// remove_dead is a helper method that removes the edges that lead to nodes
+// from which the end of the source cannot be reached. The start node is
+// never removed, even if it is dead; in which case the lattice has no paths.
+//
+// Returns:
+//   - int: The number of removed edges.
+func (tl *TokenLattice[T]) remove_dead() int {
+	alive := make([]bool, len(tl.nodes))
+
+	var removed int
+
+	for _, node := range tl.by_position() {
+		edges := tl.out[node]
+
+		kept := slices.DeleteFunc(edges, func(edge *LatticeEdge[T]) bool {
+			return !alive[edge.To]
+		})
+
+		removed += len(edges) - len(kept)
+		tl.out[node] = kept
+
+		alive[node] = tl.is_final(node) || len(kept) > 0
+	}
+
+	if len(tl.nodes) == 0 {
+		return removed
+	}
+
+	// Drop the nodes that are dead or unreachable from the start node.
+	reachable := make([]bool, len(tl.nodes))
+	reachable[0] = true
+
+	order := tl.by_position()
+
+	for i := len(order) - 1; i >= 0; i-- {
+		node := order[i]
+		if !reachable[node] {
+			continue
+		}
+
+		for _, edge := range tl.out[node] {
+			reachable[edge.To] = true
+		}
+	}
+
+	remap := make([]int, len(tl.nodes))
+
+	var nodes []*LatticeNode
+	var out [][]*LatticeEdge[T]
+
+	for i, node := range tl.nodes {
+		if i != 0 && (!alive[i] || !reachable[i]) {
+			remap[i] = -1
+			continue
+		}
+
+		remap[i] = len(nodes)
+		nodes = append(nodes, node)
+		out = append(out, tl.out[i])
+	}
+
+	tl.index = make(map[string]int, len(nodes))
+
+	for i, node := range nodes {
+		tl.index[node_key(node.At, node.Modes)] = i
+
+		for _, edge := range out[i] {
+			edge.From = i
+			edge.To = remap[edge.To]
+		}
+	}
+
+	tl.nodes = nodes
+	tl.out = out
+
+	return removed
+}
+
+// Paths returns an iterator over the complete paths of the lattice. The paths
+// are computed lazily, in rule order.
+//
+// Parameters:
+//   - keep: A callback that is called every time a path is extended. It
+//     receives the tokens of the path so far, without the skipped ones, and
+//     returns false to abandon every path that starts with them. Nil keeps
+//     every path.
+//
+// Returns:
+//   - *LatticePathIterator: The iterator. Never nil.
+func (tl *TokenLattice[T]) Paths(keep func(prefix []*gr.Token[T]) bool) *LatticePathIterator[T] {
+	iter := &LatticePathIterator[T]{
+		lattice: tl,
+		keep:    keep,
+	}
+
+	return iter
+}
+
+// path_frame is a node of the depth-first search of LatticePathIterator.
+type path_frame struct {
+	// node is the index of the node.
+	node int
+
+	// next is the index of the next edge to follow.
+	next int
+
+	// emitted is true if the path that ends at the node was returned.
+	emitted bool
+}
+
+// LatticePathIterator is a lazy iterator over the complete paths of a token
+// lattice.
+type LatticePathIterator[T gr.TokenTyper] struct {
+	// lattice is the lattice.
+	lattice *TokenLattice[T]
+
+	// keep is the callback that abandons prefixes. May be nil.
+	keep func(prefix []*gr.Token[T]) bool
+
+	// frames is the stack of the depth-first search.
+	frames []path_frame
+
+	// path are the edges from the start node to the top frame.
+	path []*LatticeEdge[T]
+
+	// started is true once the search has begun.
+	started bool
+}
+
+// tokens_of is a helper method that returns the tokens of a path, without
+// the skipped ones.
+//
+// Parameters:
+//   - path: The edges of the path.
+//
+// Returns:
+//   - []*gr.Token: The tokens. They are shared with the lattice.
+func (iter *LatticePathIterator[T]) tokens_of(path []*LatticeEdge[T]) []*gr.Token[T] {
+	tokens := make([]*gr.Token[T], 0, len(path))
+
+	for _, edge := range path {
+		if !iter.lattice.IsSkipped(edge) {
+			tokens = append(tokens, edge.Token)
+		}
+	}
+
+	return tokens
+}
+
+// next_path is a helper method that advances the search to the next
+// complete path.
+//
+// Returns:
+//   - []*LatticeEdge: The edges of the path.
+//   - bool: False if there are no more paths.
+func (iter *LatticePathIterator[T]) next_path() ([]*LatticeEdge[T], bool) {
+	tl := iter.lattice
+
+	if !iter.started {
+		iter.started = true
+
+		if len(tl.nodes) > 0 {
+			iter.frames = append(iter.frames, path_frame{})
+		}
+	}
+
+	for len(iter.frames) > 0 {
+		top := &iter.frames[len(iter.frames)-1]
+
+		if tl.is_final(top.node) && !top.emitted {
+			top.emitted = true
+			return slices.Clone(iter.path), true
+		}
+
+		edges := tl.out[top.node]
+
+		if top.next >= len(edges) {
+			iter.frames = iter.frames[:len(iter.frames)-1]
+
+			if len(iter.path) > 0 {
+				iter.path = iter.path[:len(iter.path)-1]
+			}
+
+			continue
+		}
+
+		edge := edges[top.next]
+		top.next++
+
+		iter.path = append(iter.path, edge)
+
+		if iter.keep != nil && !iter.keep(iter.tokens_of(iter.path)) {
+			iter.path = iter.path[:len(iter.path)-1]
+			continue
+		}
+
+		iter.frames = append(iter.frames, path_frame{node: edge.To})
+	}
+
+	return nil, false
+}
+
+// Consume implements the common.Iterater interface.
+//
+// The tokens of every path are fresh copies, so their lookaheads do not
+// interfere with those of other paths. If the lexer has an end-of-file type
+// (see Lexer.SetEOF), the path ends with an end-of-file token.
+//
+// Errors:
+//   - *uc.ErrExhaustedIter: If there are no more paths.
+func (iter *LatticePathIterator[T]) Consume() (*cds.Stream[*gr.Token[T]], error) {
+	_, stream, err := iter.consume_path()
+	if err != nil {
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+// consume_path is a helper method that consumes the next path.
+//
+// Returns:
+//   - []*LatticeEdge: The edges of the path, including the skipped ones.
+//   - *cds.Stream: The tokens of the path; see Consume.
+//   - error: An error of type *uc.ErrExhaustedIter if there are no more
+//     paths.
+func (iter *LatticePathIterator[T]) consume_path() ([]*LatticeEdge[T], *cds.Stream[*gr.Token[T]], error) {
+	path, ok := iter.next_path()
+	if !ok {
+		return nil, nil, uc.NewErrExhaustedIter()
+	}
+
+	shared := iter.tokens_of(path)
+
+	tokens := make([]*gr.Token[T], 0, len(shared)+1)
+
+	for _, tok := range shared {
+		tok_copy := gr.NewToken(tok.ID, tok.Data, tok.At, nil)
+		tok_copy.Mode = tok.Mode
+
+		tokens = append(tokens, tok_copy)
+	}
+
+	tl := iter.lattice
+
+	if tl.has_eof {
+		tokens = set_eof_token(tokens, tl.eof, len(tl.source))
+	}
+
+	set_lookahead(tokens)
+
+	return path, cds.NewStream(tokens), nil
+}
+
+// Restart implements the common.Iterater interface.
+func (iter *LatticePathIterator[T]) Restart() {
+	iter.frames = nil
+	iter.path = nil
+	iter.started = false
+}
+
+// LexLattice lexes the input into a token lattice. Each position is lexed once
+// with the rules of the current mode; ties are resolved with the tie breaks
+// of the rules (see Grammar.SetTieBreak).
+//
+// Parameters:
+//   - input: The input to lex.
+//
+// Returns:
+//   - *TokenLattice: The lattice, without the nodes and edges that are not on
+//     a complete path. Never nil.
+//   - error: An error of type *ErrAllMatchesFailed if there is no complete
+//     path. Its reason is the failure that is the furthest in the input.
+//
+// Behaviors:
+//   - When no rule matches and there is a recovery policy, the error token is
+//     added as the only edge of the node.
+//   - When no rule matches and there is no recovery policy, the failure is
+//     an *ErrSyntax, whose syntax error suggests the closest literal of the
+//     mode, if any.
+func (l *Lexer[T]) LexLattice(input []byte) (*TokenLattice[T], error) {
+	tl := &TokenLattice[T]{
+		source:  input,
+		to_skip: slices.Clone(l.to_skip),
+		index:   make(map[string]int),
+		eof:     l.eof,
+		has_eof: l.has_eof,
+	}
+
+	stream := cds.NewStream(input)
+
+	start, _ := tl.get_node(0, NewModeStack())
+
+	var furthest furthest_failure
+
+	queue := []int{start}
+
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+
+		if tl.is_final(from) {
+			continue
+		}
+
+		node := tl.nodes[from]
+
+		mode := node.Modes.Top()
+		mode_rules := l.rules[mode]
+
+		matches, err := match_from(stream, node.At, productions_of(mode_rules), mode)
+		if err != nil {
+			if l.recovery == nil {
+				err := no_match_error(input, node.At, 0, literals_of(mode_rules))
+
+				tc.Fire(l.hook, &tc.BranchKilledEvent{
+					Origin: tc.FromLexer,
+					At:     node.At,
+					Reason: err,
+				})
+
+				furthest.record(node.At, err)
+
+				continue
+			}
+
+			tok, serr := l.recovery.recover_at(input, node.At, 0, literals_of(mode_rules))
+			tok.Mode = mode
+
+			to, created := tl.get_node(node.At+len(tok.Data.(string)), node.Modes)
+			if created {
+				queue = append(queue, to)
+			}
+
+			tl.out[from] = append(tl.out[from], &LatticeEdge[T]{
+				Token: tok,
+				From:  from,
+				To:    to,
+				Error: serr,
+			})
+
+			continue
+		}
+
+		matches = select_best_matches(matches, mode_rules, l.hook)
+
+		for _, match := range matches {
+			action := mode_rules[match.RuleIndex].GetAction()
+
+			modes, err := action.Apply(node.Modes)
+			if err != nil {
+				tc.Fire(l.hook, &tc.BranchKilledEvent{
+					Origin: tc.FromLexer,
+					At:     match.Matched.At,
+					Reason: err,
+				})
+
+				furthest.record(match.Matched.At, err)
+
+				continue
+			}
+
+			to, created := tl.get_node(node.At+len(match.Matched.Data.(string)), modes)
+			if created {
+				queue = append(queue, to)
+			}
+
+			tl.out[from] = append(tl.out[from], &LatticeEdge[T]{
+				Token: match.Matched,
+				From:  from,
+				To:    to,
+			})
+		}
+	}
+
+	tl.remove_dead()
+
+	if tl.Count() == 0 {
+		return tl, NewErrAllMatchesFailed(furthest.err)
+	}
+
+	return tl, nil
+}
+
+// furthest_failure is the failure that is the furthest in the input.
+type furthest_failure struct {
+	// at is the position of the failure. Only valid if err is not nil.
+	at int
+
+	// err is the reason of the failure. Nil if nothing failed.
+	err error
+}
+
+// record is a helper method that records a failure if it is further in the
+// input than the current one. On a tie, the first one is kept.
+//
+// Parameters:
+//   - at: The position of the failure.
+//   - err: The reason of the failure.
+func (ff *furthest_failure) record(at int, err error) {
+	if ff.err == nil || at > ff.at {
+		ff.at = at
+		ff.err = err
+	}
+}
@@ -0,0 +1,223 @@
+package Lexer
+
+import (
+	"strings"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+type LatticeTokenType int
+
+const (
+	LkEof LatticeTokenType = iota
+	LkWord
+	LkKeyword
+	LkColon
+	LkSpace
+)
+
+func (t LatticeTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"word",
+		"keyword",
+		"colon",
+		"space",
+	}[t]
+}
+
+func (t LatticeTokenType) IsTerminal() bool {
+	return true
+}
+
+// new_lattice_lexer creates a lexer where "ab" is both a word and a keyword,
+// so that "ab:" has two tokenizations. The spaces are skipped.
+func new_lattice_lexer(t *testing.T) *Lexer[LatticeTokenType] {
+	grammar := NewGrammar([]LatticeTokenType{LkSpace})
+
+	rules := []struct {
+		lhs   LatticeTokenType
+		regex string
+	}{
+		{LkWord, `[a-z]+`},
+		{LkKeyword, `ab`},
+		{LkColon, `:`},
+		{LkSpace, ` +`},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.regex)
+		if err != nil {
+			t.Fatalf("AddRule(%s) failed: %s", rule.lhs, err.Error())
+		}
+	}
+
+	lexer := NewLexer(grammar)
+	lexer.SetEOF(LkEof)
+
+	return lexer
+}
+
+// all_paths consumes the iterator and returns the paths as "type(data) ..."
+// strings. It checks that the tokens of each path are linked by their
+// lookaheads.
+func all_paths(t *testing.T, iter *LatticePathIterator[LatticeTokenType]) []string {
+	var paths []string
+
+	for {
+		stream, err := iter.Consume()
+		if err != nil {
+			if !uc.Is[*uc.ErrExhaustedIter](err) {
+				t.Fatalf("expected *uc.ErrExhaustedIter, got %s", err.Error())
+			}
+
+			return paths
+		}
+
+		tokens := stream.GetItems()
+
+		var values []string
+
+		for i, tok := range tokens {
+			values = append(values, tok.ID.String()+"("+tok.Data.(string)+")")
+
+			if i+1 < len(tokens) && tok.Lookahead != tokens[i+1] {
+				t.Errorf("token %d of %q: wrong lookahead", i, strings.Join(values, " "))
+			}
+		}
+
+		paths = append(paths, strings.Join(values, " "))
+	}
+}
+
+func TestLexLattice(t *testing.T) {
+	lexer := new_lattice_lexer(t)
+
+	tl, err := lexer.LexLattice([]byte("ab:"))
+	if err != nil {
+		t.Fatalf("LexLattice failed: %s", err.Error())
+	}
+
+	if tl.NodeCount() != 3 || tl.EdgeCount() != 3 {
+		t.Fatalf("expected 3 nodes and 3 edges, got %d and %d", tl.NodeCount(), tl.EdgeCount())
+	}
+
+	if tl.Count() != 2 {
+		t.Fatalf("expected 2 paths, got %d", tl.Count())
+	}
+
+	expected := "word(ab) colon(:) EOF() | keyword(ab) colon(:) EOF()"
+
+	iter := tl.Paths(nil)
+
+	got := strings.Join(all_paths(t, iter), " | ")
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	iter.Restart()
+
+	got = strings.Join(all_paths(t, iter), " | ")
+	if got != expected {
+		t.Errorf("after Restart: expected %q, got %q", expected, got)
+	}
+
+	// Both paths share the colon edge, but each gets its own token.
+	iter = tl.Paths(nil)
+
+	first, _ := iter.Consume()
+	second, _ := iter.Consume()
+
+	if first.GetItems()[1] == second.GetItems()[1] {
+		t.Errorf("expected fresh tokens for each path")
+	}
+
+	if tl.GetEdges(1)[0].Token.Lookahead != nil {
+		t.Errorf("expected the tokens of the lattice to be left unchanged")
+	}
+}
+
+func TestLexLatticeFilters(t *testing.T) {
+	lexer := new_lattice_lexer(t)
+
+	tl, err := lexer.LexLattice([]byte("ab : ab"))
+	if err != nil {
+		t.Fatalf("LexLattice failed: %s", err.Error())
+	}
+
+	if tl.Count() != 4 {
+		t.Fatalf("expected 4 paths, got %d", tl.Count())
+	}
+
+	// The skipped spaces are neither in the paths nor in the prefixes.
+	keep := func(prefix []*gr.Token[LatticeTokenType]) bool {
+		return prefix[len(prefix)-1].ID != LkKeyword
+	}
+
+	got := strings.Join(all_paths(t, tl.Paths(keep)), " | ")
+	if got != "word(ab) colon(:) word(ab) EOF()" {
+		t.Errorf("unexpected paths with a filter: %q", got)
+	}
+
+	removed := tl.Prune(func(edge *LatticeEdge[LatticeTokenType]) bool {
+		return edge.Token.ID != LkWord
+	})
+
+	if removed != 2 || tl.Count() != 1 {
+		t.Errorf("expected 2 removed edges and 1 path; got %d and %d", removed, tl.Count())
+	}
+
+	got = strings.Join(all_paths(t, tl.Paths(nil)), " | ")
+	if got != "keyword(ab) colon(:) keyword(ab) EOF()" {
+		t.Errorf("unexpected paths after pruning: %q", got)
+	}
+}
+
+func TestLexLatticeWithoutEOF(t *testing.T) {
+	grammar := NewGrammar[LatticeTokenType](nil)
+
+	err := grammar.AddRule(LkWord, `[a-z]+`)
+	if err != nil {
+		t.Fatalf("AddRule failed: %s", err.Error())
+	}
+
+	tl, err := NewLexer(grammar).LexLattice([]byte("ab"))
+	if err != nil {
+		t.Fatalf("LexLattice failed: %s", err.Error())
+	}
+
+	got := strings.Join(all_paths(t, tl.Paths(nil)), " | ")
+	if got != "word(ab)" {
+		t.Errorf("expected no EOF token, got %q", got)
+	}
+
+	_, err = NewLexer(grammar).LexLattice([]byte("ab:"))
+
+	if !uc.Is[*ErrAllMatchesFailed](err) {
+		t.Errorf("expected *ErrAllMatchesFailed, got %v", err)
+	}
+}
+
+func TestNodeKey(t *testing.T) {
+	// Both stacks are written "default > a > b".
+	nested := NewModeStack().Push("a").Push("b")
+	joined := NewModeStack().Push("a > b")
+
+	if nested.String() != joined.String() {
+		t.Fatalf("expected the same names, got %q and %q", nested.String(), joined.String())
+	}
+
+	if node_key(0, nested) == node_key(0, joined) {
+		t.Errorf("expected different keys for different mode stacks")
+	}
+
+	if node_key(0, nested) != node_key(0, NewModeStack().Push("a").Push("b")) {
+		t.Errorf("expected the same key for equal mode stacks")
+	}
+
+	if node_key(1, nested) == node_key(10, nested) {
+		t.Errorf("expected different keys for different positions")
+	}
+}
@@ -0,0 +1,456 @@
+package Lexer
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// TabPolicy tells the layout pass how tabs in the indentation are counted.
+type TabPolicy int
+
+const (
+	// TabExpand advances the column to the next multiple of the tab width.
+	TabExpand TabPolicy = iota
+
+	// TabAsSpace counts a tab as a single column.
+	TabAsSpace
+
+	// TabForbidden reports a syntax error for every tab in the indentation.
+	TabForbidden
+
+	// TabNoMix reports a syntax error when a line is indented with both tabs
+	// and spaces. Otherwise, tabs are expanded like TabExpand.
+	TabNoMix
+)
+
+// String implements the fmt.Stringer interface.
+func (tp TabPolicy) String() string {
+	return [...]string{
+		"expand",
+		"as space",
+		"forbidden",
+		"no mix",
+	}[tp]
+}
+
+// DedentSyntaxError is a syntax error that is reported when a line is
+// dedented to a column that does not match any enclosing indentation level.
+type DedentSyntaxError struct {
+	// at is the position of the first token of the line.
+	at int
+
+	// column is the indentation of the line.
+	column int
+
+	// levels are the enclosing indentation levels.
+	levels []int
+}
+
+// GetPosition implements the SyntaxErrorer interface.
+func (dse *DedentSyntaxError) GetPosition() int {
+	return dse.at
+}
+
+// Display implements the SyntaxErrorer interface.
+func (dse *DedentSyntaxError) Display() []string {
+	levels := make([]string, 0, len(dse.levels))
+
+	for _, level := range dse.levels {
+		levels = append(levels, strconv.Itoa(level))
+	}
+
+	var error_detail strings.Builder
+
+	error_detail.WriteString("\tunindent to column ")
+	error_detail.WriteString(strconv.Itoa(dse.column))
+	error_detail.WriteString(" does not match any outer indentation level")
+
+	var suggestion strings.Builder
+
+	suggestion.WriteString("\tIndent the line to one of the columns: ")
+	suggestion.WriteString(strings.Join(levels, ", "))
+
+	lines := []string{
+		"Syntax Error:",
+		error_detail.String(),
+		"",
+		"",
+		"Suggestion:",
+		suggestion.String(),
+	}
+
+	return lines
+}
+
+// NewDedentSyntaxError creates a new DedentSyntaxError.
+//
+// Parameters:
+//   - at: The position of the first token of the line.
+//   - column: The indentation of the line.
+//   - levels: The enclosing indentation levels.
+//
+// Returns:
+//   - *DedentSyntaxError: The new DedentSyntaxError.
+func NewDedentSyntaxError(at, column int, levels []int) *DedentSyntaxError {
+	dse := &DedentSyntaxError{
+		at:     at,
+		column: column,
+		levels: levels,
+	}
+	return dse
+}
+
+// BracketSyntaxError is a syntax error that is reported when a closing
+// bracket does not close the innermost open bracket.
+type BracketSyntaxError struct {
+	// at is the position of the closing bracket.
+	at int
+
+	// got is the closing bracket.
+	got string
+
+	// expected is the type of the closing bracket of the innermost open
+	// bracket. Empty if no bracket is open.
+	expected string
+}
+
+// GetPosition implements the SyntaxErrorer interface.
+func (bse *BracketSyntaxError) GetPosition() int {
+	return bse.at
+}
+
+// Display implements the SyntaxErrorer interface.
+func (bse *BracketSyntaxError) Display() []string {
+	var error_detail strings.Builder
+
+	error_detail.WriteString("\tclosing bracket ")
+	error_detail.WriteString(strconv.Quote(bse.got))
+
+	var suggestion strings.Builder
+
+	if bse.expected == "" {
+		error_detail.WriteString(" does not close any bracket")
+
+		suggestion.WriteString("\tRemove the bracket or open it before")
+	} else {
+		error_detail.WriteString(" does not match the open bracket")
+
+		suggestion.WriteString("\tClose the open bracket with ")
+		suggestion.WriteString(bse.expected)
+		suggestion.WriteString(" first")
+	}
+
+	lines := []string{
+		"Syntax Error:",
+		error_detail.String(),
+		"",
+		"",
+		"Suggestion:",
+		suggestion.String(),
+	}
+
+	return lines
+}
+
+// NewBracketSyntaxError creates a new BracketSyntaxError.
+//
+// Parameters:
+//   - at: The position of the closing bracket.
+//   - got: The closing bracket.
+//   - expected: The type of the closing bracket of the innermost open
+//     bracket. Empty if no bracket is open.
+//
+// Returns:
+//   - *BracketSyntaxError: The new BracketSyntaxError.
+func NewBracketSyntaxError(at int, got, expected string) *BracketSyntaxError {
+	bse := &BracketSyntaxError{
+		at:       at,
+		got:      got,
+		expected: expected,
+	}
+	return bse
+}
+
+// Layout is an optional pass that runs on the tokens produced by the lexer
+// and synthesizes the tokens of the off-side rule: NEWLINE at the end of
+// each logical line, INDENT when a line is more indented than the previous
+// one and DEDENT for each indentation level that a line closes.
+type Layout[T gr.TokenTyper] struct {
+	// indent is the ID of the INDENT tokens.
+	indent T
+
+	// dedent is the ID of the DEDENT tokens.
+	dedent T
+
+	// newline is the ID of the NEWLINE tokens.
+	newline T
+
+	// tab_policy is the policy for tabs in the indentation.
+	tab_policy TabPolicy
+
+	// tab_width is the width of a tab for TabExpand and TabNoMix.
+	tab_width int
+
+	// opens are the IDs of the opening brackets.
+	opens []T
+
+	// closes are the IDs of the closing brackets.
+	closes []T
+}
+
+// NewLayout creates a new layout pass that expands tabs to multiples of 8.
+//
+// Parameters:
+//   - indent: The ID of the INDENT tokens.
+//   - dedent: The ID of the DEDENT tokens.
+//   - newline: The ID of the NEWLINE tokens.
+//
+// Returns:
+//   - *Layout: The new layout pass. Never nil.
+func NewLayout[T gr.TokenTyper](indent, dedent, newline T) *Layout[T] {
+	l := &Layout[T]{
+		indent:     indent,
+		dedent:     dedent,
+		newline:    newline,
+		tab_policy: TabExpand,
+		tab_width:  8,
+	}
+
+	return l
+}
+
+// SetTabPolicy changes how tabs in the indentation are counted.
+//
+// Parameters:
+//   - policy: The policy.
+//   - width: The width of a tab. Only used by TabExpand and TabNoMix.
+//
+// Returns:
+//   - error: An error of type *uc.ErrInvalidParameter if width is not
+//     positive.
+func (l *Layout[T]) SetTabPolicy(policy TabPolicy, width int) error {
+	if width <= 0 {
+		return uc.NewErrInvalidParameter("width", uc.NewErrGT(0))
+	}
+
+	l.tab_policy = policy
+	l.tab_width = width
+
+	return nil
+}
+
+// AddBrackets declares a pair of brackets. No layout token is synthesized
+// between an opening bracket and its closing bracket.
+//
+// Parameters:
+//   - open: The ID of the opening bracket.
+//   - close: The ID of the closing bracket.
+func (l *Layout[T]) AddBrackets(open, close T) {
+	l.opens = append(l.opens, open)
+	l.closes = append(l.closes, close)
+}
+
+// indentation is a helper method that measures the indentation of the line
+// that contains the given position.
+//
+// Parameters:
+//   - source: The source.
+//   - at: A position in the line. Only what is before it is measured.
+//
+// Returns:
+//   - int: The column of the first non-blank character of the line.
+//   - SyntaxErrorer: An error if the tab policy is violated.
+func (l *Layout[T]) indentation(source []byte, at int) (int, SyntaxErrorer) {
+	if at > len(source) {
+		at = len(source)
+	}
+
+	start := at
+	for start > 0 && source[start-1] != '\n' {
+		start--
+	}
+
+	var column int
+	var has_tab, has_space bool
+
+	for i := start; i < at && (source[i] == ' ' || source[i] == '\t'); i++ {
+		if source[i] == ' ' {
+			has_space = true
+			column++
+
+			continue
+		}
+
+		has_tab = true
+
+		switch l.tab_policy {
+		case TabForbidden:
+			return 0, NewGenericSyntaxError(i, "tab in indentation", "Indent with spaces only")
+		case TabAsSpace:
+			column++
+		default:
+			column += l.tab_width - column%l.tab_width
+		}
+	}
+
+	if l.tab_policy == TabNoMix && has_tab && has_space {
+		return 0, NewGenericSyntaxError(start, "indentation mixes tabs and spaces", "Indent with either tabs or spaces, not both")
+	}
+
+	return column, nil
+}
+
+// Apply runs the layout pass on a branch of the lexer.
+//
+// Parameters:
+//   - source: The source that was lexed.
+//   - tokens: The tokens of the branch, without the skipped ones. If the
+//     last one is the EOF token, the closing tokens are placed before it.
+//
+// Returns:
+//   - []*gr.Token: The tokens with the synthesized ones. The lookaheads are
+//     updated. Nil if an error occurred.
+//   - SyntaxErrorer: An error if the indentation is inconsistent, the tab
+//     policy is violated or a closing bracket does not close the innermost
+//     open bracket.
+//
+// Behaviors:
+//   - A NEWLINE is emitted before the first token of every line except the
+//     first one and at the end of the input, then the INDENT or DEDENT tokens
+//     of the new line.
+//   - Blank lines and lines that only hold skipped tokens are ignored.
+//   - Inside brackets, no layout token is emitted. The brackets are matched
+//     in pairs, so "(]" is a *BracketSyntaxError.
+//   - The synthesized tokens have empty data and the position of the token
+//     that caused them.
+func (l *Layout[T]) Apply(source []byte, tokens []*gr.Token[T]) ([]*gr.Token[T], SyntaxErrorer) {
+	var eof *gr.Token[T]
+
+	if len(tokens) > 0 && tokens[len(tokens)-1].ID.String() == gr.EOFTokenID {
+		eof = tokens[len(tokens)-1]
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	result := make([]*gr.Token[T], 0, len(tokens)+2)
+
+	levels := []int{0}
+	var open []int
+	prev_end := -1
+
+	for _, tok := range tokens {
+		at := tok.GetPos()
+
+		new_line := prev_end >= 0 && len(open) == 0 &&
+			at <= len(source) && prev_end <= at &&
+			slices.Contains(source[prev_end:at], '\n')
+
+		if prev_end < 0 || new_line {
+			column, err := l.indentation(source, at)
+			if err != nil {
+				return nil, err
+			}
+
+			if new_line {
+				result = append(result, gr.NewToken(l.newline, "", at, nil))
+			}
+
+			top := levels[len(levels)-1]
+
+			if column > top {
+				if prev_end >= 0 {
+					levels = append(levels, column)
+					result = append(result, gr.NewToken(l.indent, "", at, nil))
+				} else {
+					// The first line sets the base indentation.
+					levels[0] = column
+				}
+			} else if column < top {
+				for len(levels) > 1 && column < levels[len(levels)-1] {
+					levels = levels[:len(levels)-1]
+					result = append(result, gr.NewToken(l.dedent, "", at, nil))
+				}
+
+				if levels[len(levels)-1] != column {
+					return nil, NewDedentSyntaxError(at, column, slices.Clone(levels))
+				}
+			}
+		}
+
+		result = append(result, tok)
+
+		err := l.match_bracket(tok, &open)
+		if err != nil {
+			return nil, err
+		}
+
+		str, _ := tok.Data.(string)
+		prev_end = at + len(str)
+	}
+
+	if len(result) > 0 {
+		at := prev_end
+		if eof != nil {
+			at = eof.GetPos()
+		}
+
+		result = append(result, gr.NewToken(l.newline, "", at, nil))
+
+		for i := len(levels) - 1; i > 0; i-- {
+			result = append(result, gr.NewToken(l.dedent, "", at, nil))
+		}
+	}
+
+	if eof != nil {
+		result = append(result, eof)
+	}
+
+	set_lookahead(result)
+
+	return result, nil
+}
+
+// match_bracket is a helper method that keeps track of the open brackets.
+//
+// Parameters:
+//   - tok: The token.
+//   - open: The indices of the pairs of the open brackets, innermost last.
+//
+// Returns:
+//   - SyntaxErrorer: A *BracketSyntaxError if the token is a closing bracket
+//     that does not close the innermost open bracket.
+//
+// Behaviors:
+//   - A token that closes the innermost open bracket closes it, even if it
+//     is an opening bracket as well.
+func (l *Layout[T]) match_bracket(tok *gr.Token[T], open *[]int) SyntaxErrorer {
+	if len(*open) > 0 && l.closes[(*open)[len(*open)-1]] == tok.ID {
+		*open = (*open)[:len(*open)-1]
+
+		return nil
+	}
+
+	idx := slices.Index(l.opens, tok.ID)
+	if idx != -1 {
+		*open = append(*open, idx)
+
+		return nil
+	}
+
+	if !slices.Contains(l.closes, tok.ID) {
+		return nil
+	}
+
+	str, _ := tok.Data.(string)
+
+	var expected string
+
+	if len(*open) > 0 {
+		expected = l.closes[(*open)[len(*open)-1]].String()
+	}
+
+	return NewBracketSyntaxError(tok.GetPos(), str, expected)
+}
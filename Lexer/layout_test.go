@@ -0,0 +1,274 @@
+package Lexer
+
+import (
+	"strings"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+type LayoutTokenType int
+
+const (
+	LtEof LayoutTokenType = iota
+	LtWord
+	LtColon
+	LtOpParen
+	LtClParen
+	LtIndent
+	LtDedent
+	LtNewline
+	LtOpBrack
+	LtClBrack
+	LtSpace
+)
+
+func (t LayoutTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"word",
+		"colon",
+		"op_paren",
+		"cl_paren",
+		"INDENT",
+		"DEDENT",
+		"NEWLINE",
+		"op_brack",
+		"cl_brack",
+		"space",
+	}[t]
+}
+
+func (t LayoutTokenType) IsTerminal() bool {
+	return true
+}
+
+// lex_layout_words is a tiny lexer for the layout tests: words, colons,
+// parentheses and square brackets separated by blanks.
+func lex_layout_words(source string) []*gr.Token[LayoutTokenType] {
+	var tokens []*gr.Token[LayoutTokenType]
+
+	for i := 0; i < len(source); {
+		switch c := source[i]; c {
+		case ' ', '\t', '\n':
+			i++
+		case ':', '(', ')', '[', ']':
+			id := map[byte]LayoutTokenType{
+				':': LtColon, '(': LtOpParen, ')': LtClParen, '[': LtOpBrack, ']': LtClBrack,
+			}[c]
+			tokens = append(tokens, gr.NewToken(id, string(c), i, nil))
+			i++
+		default:
+			j := i
+			for j < len(source) && !strings.ContainsRune(" \t\n:()[]", rune(source[j])) {
+				j++
+			}
+
+			tokens = append(tokens, gr.NewToken(LtWord, source[i:j], i, nil))
+			i = j
+		}
+	}
+
+	tokens = append(tokens, gr.NewToken(LtEof, "", len(source), nil))
+
+	return tokens
+}
+
+func TestLayout(t *testing.T) {
+	const (
+		Source string = "a:\n  b\n\n  c (d\n e)\n    f\ng\n"
+
+		Expected string = "word colon NEWLINE INDENT word NEWLINE word op_paren word word cl_paren " +
+			"NEWLINE INDENT word NEWLINE DEDENT DEDENT word NEWLINE EOF"
+	)
+
+	layout := NewLayout(LtIndent, LtDedent, LtNewline)
+	layout.AddBrackets(LtOpParen, LtClParen)
+
+	tokens, err := layout.Apply([]byte(Source), lex_layout_words(Source))
+	if err != nil {
+		t.Fatalf("Apply() returned an error: %s", strings.Join(err.Display(), "\n"))
+	}
+
+	values := make([]string, 0, len(tokens))
+
+	for _, tok := range tokens {
+		values = append(values, tok.ID.String())
+	}
+
+	str := strings.Join(values, " ")
+	if str != Expected {
+		t.Errorf("Apply() =\n%s, want\n%s", str, Expected)
+	}
+
+	if tokens[0].Lookahead != tokens[1] {
+		t.Errorf("Apply() did not update the lookaheads")
+	}
+}
+
+func TestLayoutErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		policy TabPolicy
+		at     int
+	}{
+		{"inconsistent dedent", "a\n    b\n  c\n", TabExpand, 10},
+		{"forbidden tab", "a\n\tb\n", TabForbidden, 2},
+		{"mixed tabs and spaces", "a\n \tb\n", TabNoMix, 2},
+	}
+
+	for _, test := range tests {
+		layout := NewLayout(LtIndent, LtDedent, LtNewline)
+
+		err := layout.SetTabPolicy(test.policy, 4)
+		if err != nil {
+			t.Fatalf("SetTabPolicy() returned an error: %s", err.Error())
+		}
+
+		_, serr := layout.Apply([]byte(test.source), lex_layout_words(test.source))
+		if serr == nil {
+			t.Errorf("%s: Apply() returned no error", test.name)
+		} else if serr.GetPosition() != test.at {
+			t.Errorf("%s: GetPosition() = %d, want %d", test.name, serr.GetPosition(), test.at)
+		}
+	}
+}
+
+// layout_ids returns the types of the tokens, separated by spaces.
+func layout_ids(tokens []*gr.Token[LayoutTokenType]) string {
+	values := make([]string, 0, len(tokens))
+
+	for _, tok := range tokens {
+		values = append(values, tok.ID.String())
+	}
+
+	return strings.Join(values, " ")
+}
+
+func TestLayoutBrackets(t *testing.T) {
+	layout := NewLayout(LtIndent, LtDedent, LtNewline)
+	layout.AddBrackets(LtOpParen, LtClParen)
+	layout.AddBrackets(LtOpBrack, LtClBrack)
+
+	const Source string = "a (b [c\n d] e)\nf\n"
+
+	tokens, serr := layout.Apply([]byte(Source), lex_layout_words(Source))
+	if serr != nil {
+		t.Fatalf("Apply() returned an error: %s", strings.Join(serr.Display(), "\n"))
+	}
+
+	expected := "word op_paren word op_brack word word cl_brack word cl_paren NEWLINE word NEWLINE EOF"
+
+	if got := layout_ids(tokens); got != expected {
+		t.Errorf("Apply() =\n%s, want\n%s", got, expected)
+	}
+
+	tests := []struct {
+		source   string
+		at       int
+		expected string
+	}{
+		{"(]", 1, "cl_paren"},
+		{"a ( [ b ) ]", 8, "cl_brack"},
+		{"a\n)", 2, ""},
+	}
+
+	for _, test := range tests {
+		_, serr := layout.Apply([]byte(test.source), lex_layout_words(test.source))
+
+		bse, ok := serr.(*BracketSyntaxError)
+		if !ok {
+			t.Errorf("%q: expected *BracketSyntaxError, got %T", test.source, serr)
+			continue
+		}
+
+		if bse.GetPosition() != test.at || bse.expected != test.expected {
+			t.Errorf("%q: expected %q at %d, got %q at %d", test.source, test.expected, test.at, bse.expected, bse.GetPosition())
+		}
+	}
+}
+
+// new_layout_lexer creates a lexer for the layout tests that skips the
+// blanks and runs the layout pass.
+func new_layout_lexer(t *testing.T) *Lexer[LayoutTokenType] {
+	grammar := NewGrammar([]LayoutTokenType{LtSpace})
+
+	rules := []struct {
+		lhs   LayoutTokenType
+		regex string
+	}{
+		{LtWord, `[a-z]+`},
+		{LtColon, `:`},
+		{LtOpParen, `\(`},
+		{LtClParen, `\)`},
+		{LtSpace, `[ \t\n]+`},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.regex)
+		if err != nil {
+			t.Fatalf("AddRule(%s) failed: %s", rule.lhs, err.Error())
+		}
+	}
+
+	layout := NewLayout(LtIndent, LtDedent, LtNewline)
+	layout.AddBrackets(LtOpParen, LtClParen)
+
+	lexer := NewLexer(grammar)
+	lexer.SetEOF(LtEof)
+	lexer.SetLayout(layout)
+
+	return lexer
+}
+
+func TestLexLayout(t *testing.T) {
+	lexer := new_layout_lexer(t)
+
+	iter := lexer.Lex([]byte("a:\n  b (c\n d)\ne\n"))
+
+	stream, err := iter.Consume()
+	if err != nil {
+		t.Fatalf("Consume failed: %s", err.Error())
+	}
+
+	tokens := stream.GetItems()
+
+	expected := "word colon NEWLINE INDENT word op_paren word word cl_paren NEWLINE DEDENT word NEWLINE EOF"
+
+	if got := layout_ids(tokens); got != expected {
+		t.Errorf("Lex() =\n%s, want\n%s", got, expected)
+	}
+
+	for i := 0; i < len(tokens)-1; i++ {
+		if tokens[i].Lookahead != tokens[i+1] {
+			t.Errorf("token %d: wrong lookahead", i)
+		}
+	}
+
+	if len(iter.GetErrors()) != 0 {
+		t.Errorf("expected no errors, got %d", len(iter.GetErrors()))
+	}
+
+	// On an error, the tokens are returned without the layout.
+	iter = lexer.Lex([]byte("a\n    b\n  c\n"))
+
+	stream, err = iter.Consume()
+	if err != nil {
+		t.Fatalf("Consume failed: %s", err.Error())
+	}
+
+	if got := layout_ids(stream.GetItems()); got != "word word word EOF" {
+		t.Errorf("expected the tokens without the layout, got %s", got)
+	}
+
+	errs := iter.GetErrors()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(errs))
+	}
+
+	_, ok := errs[0].(*DedentSyntaxError)
+	if !ok || errs[0].GetPosition() != 10 {
+		t.Errorf("expected a *DedentSyntaxError at 10, got %T at %d", errs[0], errs[0].GetPosition())
+	}
+}
@@ -1,366 +1,111 @@
 package Lexer
 
 import (
-	"fmt"
+	"math"
 
 	gr "github.com/PlayerR9/LyneParser/Grammar"
 	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
-	ffs "github.com/PlayerR9/MyGoLib/Formatting/FString"
-	uc "github.com/PlayerR9/MyGoLib/Units/common"
-	us "github.com/PlayerR9/MyGoLib/Units/slice"
-	tr "github.com/PlayerR9/tree/tree"
 )
 
-type leaves_result[T gr.TokenTyper] struct {
-	leaves []*TokenNode[T]
-}
-
-func new_leaves_result[T gr.TokenTyper](nodes []tr.Noder) *leaves_result[T] {
-	var valid_nodes []*TokenNode[T]
-
-	for _, node := range nodes {
-		n, ok := node.(*TokenNode[T])
-		if !ok {
-			continue
-		}
-
-		valid_nodes = append(valid_nodes, n)
-	}
-
-	lr := &leaves_result[T]{
-		leaves: valid_nodes,
-	}
-
-	return lr
-}
-
-func (lr *leaves_result[T]) Size() int {
-	return len(lr.leaves)
-}
-
-func (lr *leaves_result[T]) get_first() *TokenNode[T] {
-	if len(lr.leaves) == 0 {
-		return nil
-	}
-
-	first := lr.leaves[0]
-	lr.leaves = lr.leaves[1:]
-
-	return first
-}
-
-// SourceIterator is an iterator that uses a grammar to tokenize a string.
-type SourceIterator[T gr.TokenTyper] struct {
-	// source is the source to lex.
-	source *cds.Stream[byte]
-
-	// tree is the tree to use.
-	tree *tr.Tree
+// LexerIterator is an iterator over the tokenizations of a source. Each
+// tokenization is a path of the token lattice of the source; see
+// Lexer.LexLattice.
+type LexerIterator[T gr.TokenTyper] struct {
+	// lattice is the token lattice of the source. Nil if lexing failed.
+	lattice *TokenLattice[T]
 
-	// productions are the production rules to use.
-	productions []*gr.RegProduction[T]
+	// paths is the iterator over the paths of the lattice. Nil if lexing
+	// failed.
+	paths *LatticePathIterator[T]
 
-	// can_continue is a flag that indicates if the lexer can continue.
-	can_continue bool
+	// err is the reason lexing failed. Nil if it succeeded.
+	err error
 
-	// err_branches are the branches that have errors.
-	err_branches []*tr.Branch
+	// layout is the layout pass to run on each branch. May be nil.
+	layout *Layout[T]
 
-	// logger is a flag that indicates if the lexer should be verbose.
-	logger *Verbose
+	// errors are the syntax errors of the last branch.
+	errors []SyntaxErrorer
 }
 
 // Size implements the Iterater interface.
 //
-// Size is calculated by the number of leaves in the tree.
-// of course, this is just an approximation as, to get the exact size,
-// we would need to traverse the entire tree.
-func (si *SourceIterator[T]) Size() (count int) {
-	count = si.tree.Size()
-
-	return
-}
-
-// generate_eval_trees adds the matches to a root tree as leaves.
-//
-// Parameters:
-//   - matches: The matches to add to the tree evaluator.
-//   - logger: A verbose logger.
-func generate_eval_trees[T gr.TokenTyper](matches []*gr.MatchedResult[T], logger *Verbose) []*TokenNode[T] {
-	logger.DoIf(func(p *Printer) {
-		// DEBUG: Display the matches
-		p.Print("Matches:")
-
-		for _, match := range matches {
-			p.Printf("\t%+v", match.Matched)
-		}
-	})
-
-	// Get the longest match.
-	matches = select_best_matches(matches, logger)
-
-	children := make([]*TokenNode[T], 0, len(matches))
+// Size is the number of tokenizations of the source, including the ones
+// that were already consumed. It is capped at math.MaxInt.
+func (li *LexerIterator[T]) Size() (count int) {
+	if li.lattice == nil {
+		return 0
+	}
 
-	for _, match := range matches {
-		tn := NewTokenNode(match.Matched)
+	paths := li.lattice.Count()
 
-		children = append(children, tn)
+	if paths > math.MaxInt {
+		count = math.MaxInt
+	} else {
+		count = int(paths)
 	}
 
-	return children
+	return
 }
 
-// lex_one lexes one branch of the tree.
-//
-// Parameters:
-//   - logger: A verbose logger.
+// Consume implements the Iterater interface.
 //
-// Returns:
-//   - error: An error if lexing fails.
-func (si *SourceIterator[T]) lex_one(logger *Verbose) error {
-	p := filter_leaves(si.source, si.productions, logger)
+// Errors:
+//   - *ErrAllMatchesFailed: If the source has no tokenization.
+//   - *uc.ErrExhaustedIter: If there are no more tokenizations.
+func (li *LexerIterator[T]) Consume() (*cds.Stream[*gr.Token[T]], error) {
+	if li.err != nil {
+		return nil, li.err
+	}
 
-	err := si.tree.ProcessLeaves(p)
+	path, branch, err := li.paths.consume_path()
 	if err != nil {
-		return fmt.Errorf("failed to process leaves: %w", err)
+		return nil, err
 	}
 
-	logger.DoIf(func(p *Printer) {
-		// DEBUG: Display the resulting tree
-		p.Print("Resulting Tree:")
-
-		printer, trav := ffs.NewStdPrinter(
-			ffs.NewFormatter(ffs.NewIndentConfig("   ", 0)),
-		)
+	li.errors = li.errors[:0]
 
-		err = si.tree.FString(trav)
-		uc.AssertF(err == nil, "FString failed: %s", err.Error())
-
-		pages := ffs.Stringfy(printer.GetPages(), 1)
-
-		p.Print(pages[0])
-	})
-
-	leaves := si.tree.GetLeaves()
-
-	var success []*TokenNode[T]
-	var failed []*TokenNode[T]
-
-	for _, leaf := range leaves {
-		tn, ok := leaf.(*TokenNode[T])
-		uc.Assert(ok, "Must be a *TokenNode[T]")
-
-		if tr.Status == EvalError {
-			failed = append(failed, tn)
-		} else {
-			success = append(success, tn)
+	for _, edge := range path {
+		if edge.Error != nil {
+			li.errors = append(li.errors, edge.Error)
 		}
 	}
 
-	// Add the failed branches to the error branches.
-	for i, leaf := range failed {
-		branch, err := si.tree.ExtractBranch(leaf, true)
-		if err != nil {
-			return uc.NewErrWhileAt("extracting", i+1, "branch", err)
-		}
-
-		if branch != nil {
-			si.err_branches = append(si.err_branches, branch)
-		}
+	if li.layout == nil {
+		return branch, nil
 	}
 
-	/*
-		cmd2 := tr.NewPruneTreeCmd(filterErrorLeaves)
-		err = si.tree.ExecuteCommand(cmd2)
-		if err != nil {
-			si.tree.UndoLastCommand()
-			return err
-		}
-
-		si.tree.Accept()
-	*/
-
-	return nil
-}
-
-// Consume implements the Iterater interface.
-func (si *SourceIterator[T]) Consume() (*leaves_result[T], error) {
-	var result *leaves_result[T]
-
-	for {
-		if !si.can_continue {
-			return nil, uc.NewErrExhaustedIter()
-		}
-
-		var leaves []tr.Noder
-
-		err := si.lex_one(si.logger)
-		if err != nil {
-			si.can_continue = false
-
-			leaves = si.tree.GetLeaves()
-		} else {
-			leaves, si.can_continue = si.get_completed_branch()
-		}
+	tokens, serr := li.layout.Apply(li.lattice.source, branch.GetItems())
+	if serr != nil {
+		li.errors = append(li.errors, serr)
 
-		// Ignore error leaves.
-		f := func(leaf tr.Noder) bool {
-			tn, ok := leaf.(*TokenNode[T])
-			if !ok {
-				return false
-			}
-
-			return tr.Status != EvalError
-		}
-
-		leaves = us.SliceFilter(leaves, f)
-
-		if len(leaves) > 0 {
-			result = new_leaves_result[T](leaves)
-			break
-		}
+		return branch, nil
 	}
 
-	return result, nil
+	return cds.NewStream(tokens), nil
 }
 
-// Restart implements the Iterater interface.
-func (si *SourceIterator[T]) Restart() {
-	si.can_continue = true
-
-	//rootNode := gr.RootToken()
-
-	// p := tr.NewStatusInfo(rootNode, EvalIncomplete)
-
-	// si.tree = tr.NewTree(p)
-
-	panic("SourceIterator.Restart() not implemented")
-}
-
-// newSourceIterator creates a new source iterator.
-//
-// Parameters:
-//   - source: The source to use.
-//   - productions: The production rules to use.
-//   - logger: A verbose logger.
+// GetErrors returns the syntax errors of the last branch returned by
+// Consume: those of the text that was skipped by the recovery policy and,
+// last, the one of the layout pass.
 //
 // Returns:
-//   - *SourceIterator: The new source iterator.
-func newSourceIterator[T gr.TokenTyper](source *cds.Stream[byte], productions []*gr.RegProduction[T], logger *Verbose) *SourceIterator[T] {
-	// rootNode := gr.RootToken()
-
-	// p := tr.NewStatusInfo(rootNode, EvalIncomplete)
-
-	// tree := tr.NewTree(p)
-
-	// si := &SourceIterator[T]{
-	// 	source:      source,
-	// 	productions: productions,
-	// 	tree:        tree,
-	// 	canContinue: true,
-	// 	logger:      logger,
-	// }
-
-	// return si
-
-	panic("newSourceIterator() not implemented")
-}
-
-// get_completed_branch gets the completed branch of the tree.
-//
-// Returns:
-//   - []Tree.Noder: The completed branch.
-//   - bool: True if the branch can continue, false otherwise.
-func (si *SourceIterator[T]) get_completed_branch() ([]tr.Noder, bool) {
-	leaves := si.tree.GetLeaves()
-
-	can_continue := false
-
-	var completed_leaves []tr.Noder
-
-	for _, leaf := range leaves {
-		tn, ok := leaf.(*TokenNode[T])
-		uc.Assert(ok, "Must be a *TokenNode[T]")
-
-		switch tr.Status {
-		case EvalComplete:
-			completed_leaves = append(completed_leaves, leaf)
-		case EvalIncomplete:
-			can_continue = true
-		}
-	}
-
-	return completed_leaves, can_continue
-}
+//   - []SyntaxErrorer: The syntax errors. Those of the recovery policy are
+//     in the order of the source.
+func (li *LexerIterator[T]) GetErrors() []SyntaxErrorer {
+	errs := make([]SyntaxErrorer, len(li.errors))
+	copy(errs, li.errors)
 
-// delete_branch deletes a branch from the tree.
-//
-// Parameters:
-//   - leaf: The leaf to delete.
-func (si *SourceIterator[T]) delete_branch(leaf tr.Noder) {
-	err := si.tree.DeleteBranchContaining(leaf)
-	uc.AssertF(err == nil, "DeleteBranchContaining failed: %s", err.Error())
-}
-
-// LexerIterator is an iterator that uses a grammar to tokenize a string.
-type LexerIterator[T gr.TokenTyper] struct {
-	// to_skip are the tokens to skip.
-	to_skip []T
-
-	// completed_leaves are the leaves that have been completed.
-	completed_leaves *leaves_result[T]
-
-	// source_iter is the source iterator.
-	source_iter *SourceIterator[T]
-}
-
-// Size implements the Iterater interface.
-//
-// Size is calculated by the number of leaves in the source iterator
-// and the number of completed leaves.
-func (li *LexerIterator[T]) Size() (count int) {
-	count = li.source_iter.Size()
-
-	count += li.completed_leaves.Size()
-
-	return
-}
-
-// Consume implements the Iterater interface.
-func (li *LexerIterator[T]) Consume() (*cds.Stream[*gr.Token[T]], error) {
-	var branch *cds.Stream[*gr.Token[T]]
-
-	for {
-		if li.completed_leaves.Size() == 0 {
-			res, err := li.source_iter.Consume()
-			if err != nil {
-				return nil, err
-			}
-
-			li.completed_leaves = res
-		}
-
-		// Extract the entire branch
-		leaf := li.completed_leaves.get_first()
-
-		anch := leaf.GetAncestors()
-		anch = append(anch, leaf)
-
-		li.source_iter.delete_branch(leaf)
-
-		branch = convert_branch_to_token_stream(anch, li.to_skip)
-		if branch.Size() > 0 {
-			break
-		}
-	}
-
-	return branch, nil
+	return errs
 }
 
 // Restart implements the Iterater interface.
 func (li *LexerIterator[T]) Restart() {
-	li.completed_leaves = nil
-	li.source_iter.Restart()
+	li.errors = nil
+
+	if li.paths != nil {
+		li.paths.Restart()
+	}
 }
 
 /*
@@ -34,10 +34,7 @@ func TestLex(t *testing.T) {
 
 	lexer := NewLexer(TestGrammar)
 
-	v := NewVerbose(true)
-	defer v.Close()
-
-	iter := lexer.Lex([]byte(Source), v)
+	iter := lexer.Lex([]byte(Source))
 
 	var branch *cds.Stream[*gr.Token]
 	var err error
@@ -78,10 +75,7 @@ func TestSyntaxError(t *testing.T) {
 
 	lexer := NewLexer(TestGrammar)
 
-	v := NewVerbose(true)
-	defer v.Close()
-
-	iter := lexer.Lex([]byte(Source), v)
+	iter := lexer.Lex([]byte(Source))
 
 	branch, err := iter.Consume()
 	if err != nil {
@@ -0,0 +1,131 @@
+package Lexer
+
+import (
+	"strings"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// lengths_at returns a matcher that reports the given lengths at the start
+// of the input and no match elsewhere.
+func lengths_at(lengths ...int) gr.Matcher {
+	return gr.MatcherFunc(func(input []byte, offset int) []int {
+		if offset != 0 {
+			return nil
+		}
+
+		return lengths
+	})
+}
+
+func TestLexMatchers(t *testing.T) {
+	tests := []struct {
+		name    string
+		regex   string
+		lengths []int
+		want    string
+	}{
+		{
+			// The matcher is longer than the regex; its shorter match gives
+			// its own branch.
+			name:    "beats",
+			regex:   `[a-z]`,
+			lengths: []int{2, 3},
+			want:    "keyword(abc) EOF() | keyword(ab) word(c) EOF()",
+		},
+		{
+			name:    "loses",
+			regex:   `[a-z]+`,
+			lengths: []int{1, 2},
+			want:    "word(abc) EOF()",
+		},
+		{
+			name:    "ties",
+			regex:   `[a-z]+`,
+			lengths: []int{3},
+			want:    "word(abc) EOF() | keyword(abc) EOF()",
+		},
+	}
+
+	for _, test := range tests {
+		grammar := NewGrammar[LatticeTokenType](nil)
+
+		err := grammar.AddRule(LkWord, test.regex)
+		if err != nil {
+			t.Fatalf("%s: AddRule failed: %s", test.name, err.Error())
+		}
+
+		err = grammar.AddMatcher(LkKeyword, lengths_at(test.lengths...))
+		if err != nil {
+			t.Fatalf("%s: AddMatcher failed: %s", test.name, err.Error())
+		}
+
+		lexer := NewLexer(grammar)
+		lexer.SetEOF(LkEof)
+
+		tl, err := lexer.LexLattice([]byte("abc"))
+		if err != nil {
+			t.Fatalf("%s: LexLattice failed: %s", test.name, err.Error())
+		}
+
+		got := strings.Join(all_paths(t, tl.Paths(nil)), " | ")
+		if got != test.want {
+			t.Errorf("%s: expected %q, got %q", test.name, test.want, got)
+		}
+	}
+}
+
+func TestMatchAtMatchers(t *testing.T) {
+	grammar := NewGrammar[LatticeTokenType](nil)
+
+	err := grammar.AddModeMatcher(DefaultMode, LkKeyword, lengths_at(1, 3), PushMode("inner"))
+	if err != nil {
+		t.Fatalf("AddModeMatcher failed: %s", err.Error())
+	}
+
+	err = grammar.AddRule(LkWord, `[a-z]{2}`)
+	if err != nil {
+		t.Fatalf("AddRule failed: %s", err.Error())
+	}
+
+	steps, err := NewLexer(grammar).MatchAt([]byte("abc"), 0, nil, nil)
+	if err != nil {
+		t.Fatalf("MatchAt failed: %s", err.Error())
+	}
+
+	// The longest match first, then the shorter one of the same matcher;
+	// both enter the mode of the rule.
+	want := []string{"abc", "a"}
+
+	if len(steps) != len(want) {
+		t.Fatalf("expected %d steps, got %d", len(want), len(steps))
+	}
+
+	for i, step := range steps {
+		if step.Token.ID != LkKeyword || step.Token.Data != want[i] || step.Modes.Top() != "inner" {
+			t.Errorf("step %d: expected keyword(%s) in the inner mode, got %s(%v) in %s", i, want[i], step.Token.ID, step.Token.Data, step.Modes)
+		}
+	}
+
+	// Step does not branch: it keeps the longest match.
+	step, err := NewLexer(grammar).Step([]byte("abc"), 0, nil)
+	if err != nil {
+		t.Fatalf("Step failed: %s", err.Error())
+	}
+
+	if step.Token.Data != "abc" {
+		t.Errorf("expected keyword(abc), got %v", step.Token.Data)
+	}
+
+	err = grammar.AddModeMatcher("", LkKeyword, lengths_at(1), nil)
+	if !uc.Is[*uc.ErrInvalidParameter](err) {
+		t.Errorf("expected *uc.ErrInvalidParameter for an empty mode, got %v", err)
+	}
+
+	err = grammar.AddMatcher(LkKeyword, nil)
+	if err == nil {
+		t.Errorf("expected an error for a nil matcher")
+	}
+}
@@ -0,0 +1,372 @@
+package Lexer
+
+import (
+	"strings"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+const (
+	// DefaultMode is the name of the mode the lexer starts in. Rules added
+	// with Grammar.AddRule belong to this mode.
+	DefaultMode string = "default"
+)
+
+// ModeStack is an immutable stack of lexer modes. Like gr.TokenStack, every
+// operation returns a new stack that shares its tail with the receiver, so
+// each branch of the lexer can keep its own stack at no cost.
+//
+// The bottom of the stack is always DefaultMode and it can never be popped.
+type ModeStack struct {
+	// top is the current mode.
+	top string
+
+	// below is the rest of the stack. Nil for the bottom of the stack.
+	below *ModeStack
+
+	// size is the number of modes in the stack.
+	size int
+}
+
+// String implements the fmt.Stringer interface.
+//
+// Format:
+//
+//	default > string > template
+func (ms *ModeStack) String() string {
+	return strings.Join(ms.Slice(), " > ")
+}
+
+// NewModeStack creates a stack that only contains DefaultMode.
+//
+// Returns:
+//   - *ModeStack: The new stack. Never nil.
+func NewModeStack() *ModeStack {
+	ms := &ModeStack{
+		top:  DefaultMode,
+		size: 1,
+	}
+
+	return ms
+}
+
+// Top returns the current mode.
+//
+// Returns:
+//   - string: The current mode. DefaultMode if the receiver is nil.
+func (ms *ModeStack) Top() string {
+	if ms == nil {
+		return DefaultMode
+	}
+
+	return ms.top
+}
+
+// Push returns a new stack with the mode on top of the receiver.
+//
+// Parameters:
+//   - mode: The mode to enter.
+//
+// Returns:
+//   - *ModeStack: The new stack. Never nil.
+func (ms *ModeStack) Push(mode string) *ModeStack {
+	if ms == nil {
+		ms = NewModeStack()
+	}
+
+	new_stack := &ModeStack{
+		top:   mode,
+		below: ms,
+		size:  ms.size + 1,
+	}
+
+	return new_stack
+}
+
+// Pop returns the stack without its current mode.
+//
+// Returns:
+//   - *ModeStack: The new stack. Nil if an error occurred.
+//   - error: An error of type *ErrCannotPopMode if only DefaultMode is left.
+func (ms *ModeStack) Pop() (*ModeStack, error) {
+	if ms == nil || ms.below == nil {
+		return nil, NewErrCannotPopMode()
+	}
+
+	return ms.below, nil
+}
+
+// Switch returns a new stack where the current mode is replaced by the
+// given one.
+//
+// Parameters:
+//   - mode: The mode to switch to.
+//
+// Returns:
+//   - *ModeStack: The new stack. Never nil.
+func (ms *ModeStack) Switch(mode string) *ModeStack {
+	if ms == nil {
+		ms = NewModeStack()
+	}
+
+	new_stack := &ModeStack{
+		top:   mode,
+		below: ms.below,
+		size:  ms.size,
+	}
+
+	return new_stack
+}
+
+// Equal checks whether two stacks hold the same modes.
+//
+// Parameters:
+//   - other: The other stack.
+//
+// Returns:
+//   - bool: True if the stacks are equal, false otherwise.
+//
+// Behaviors:
+//   - A nil stack is equal to a stack that only contains DefaultMode.
+func (ms *ModeStack) Equal(other *ModeStack) bool {
+	if ms == nil {
+		ms = NewModeStack()
+	}
+
+	if other == nil {
+		other = NewModeStack()
+	}
+
+	if ms.size != other.size {
+		return false
+	}
+
+	for ms != other {
+		if ms.top != other.top {
+			return false
+		}
+
+		ms = ms.below
+		other = other.below
+	}
+
+	return true
+}
+
+// Slice returns the modes of the stack, from the bottom to the top.
+//
+// Returns:
+//   - []string: The modes of the stack.
+func (ms *ModeStack) Slice() []string {
+	if ms == nil {
+		return []string{DefaultMode}
+	}
+
+	slice := make([]string, ms.size)
+
+	i := ms.size - 1
+
+	for current := ms; current != nil; current = current.below {
+		slice[i] = current.top
+		i--
+	}
+
+	return slice
+}
+
+// ModeActionKind is the kind of change a rule applies to the mode stack.
+type ModeActionKind int
+
+const (
+	// PushModeAction enters a mode; the previous one is restored by a pop.
+	PushModeAction ModeActionKind = iota
+
+	// PopModeAction leaves the current mode.
+	PopModeAction
+
+	// SwitchModeAction replaces the current mode.
+	SwitchModeAction
+)
+
+// String implements the fmt.Stringer interface.
+func (k ModeActionKind) String() string {
+	return [...]string{
+		"push",
+		"pop",
+		"switch",
+	}[k]
+}
+
+// ModeAction is a change to the mode stack applied when a rule matches.
+type ModeAction struct {
+	// Kind is the kind of the change.
+	Kind ModeActionKind
+
+	// Mode is the mode to enter. Empty for PopModeAction.
+	Mode string
+}
+
+// String implements the fmt.Stringer interface.
+//
+// Format:
+//
+//	push(<mode>) | pop | switch(<mode>)
+func (ma *ModeAction) String() string {
+	if ma.Kind == PopModeAction {
+		return ma.Kind.String()
+	}
+
+	return ma.Kind.String() + "(" + ma.Mode + ")"
+}
+
+// PushMode creates an action that enters the given mode.
+//
+// Parameters:
+//   - mode: The mode to enter.
+//
+// Returns:
+//   - *ModeAction: The new action. Never nil.
+func PushMode(mode string) *ModeAction {
+	ma := &ModeAction{
+		Kind: PushModeAction,
+		Mode: mode,
+	}
+
+	return ma
+}
+
+// PopMode creates an action that leaves the current mode.
+//
+// Returns:
+//   - *ModeAction: The new action. Never nil.
+func PopMode() *ModeAction {
+	ma := &ModeAction{
+		Kind: PopModeAction,
+	}
+
+	return ma
+}
+
+// SwitchMode creates an action that replaces the current mode.
+//
+// Parameters:
+//   - mode: The mode to switch to.
+//
+// Returns:
+//   - *ModeAction: The new action. Never nil.
+func SwitchMode(mode string) *ModeAction {
+	ma := &ModeAction{
+		Kind: SwitchModeAction,
+		Mode: mode,
+	}
+
+	return ma
+}
+
+// Apply applies the action to a mode stack.
+//
+// Parameters:
+//   - ms: The mode stack.
+//
+// Returns:
+//   - *ModeStack: The new mode stack.
+//   - error: An error of type *ErrCannotPopMode if the action pops the
+//     last mode.
+//
+// Behaviors:
+//   - A nil action leaves the stack unchanged.
+func (ma *ModeAction) Apply(ms *ModeStack) (*ModeStack, error) {
+	if ma == nil {
+		return ms, nil
+	}
+
+	switch ma.Kind {
+	case PushModeAction:
+		return ms.Push(ma.Mode), nil
+	case PopModeAction:
+		return ms.Pop()
+	default:
+		return ms.Switch(ma.Mode), nil
+	}
+}
+
+// Rule is a rule of a lexer mode: a production and the change to the mode
+// stack applied when the production matches.
+type Rule[T gr.TokenTyper] struct {
+	// production is the production of the rule. Either a regular expression
+	// or a matcher.
+	production gr.TokenMatcher[T]
+
+	// action is the change to the mode stack. Nil if the mode does not change.
+	action *ModeAction
+
+	// tie_break is the way the rule takes part in the resolution of ties.
+	// Nil is the same as ByLongestMatch.
+	tie_break *TieBreak
+}
+
+// GetProduction returns the production of the rule.
+//
+// Returns:
+//   - gr.TokenMatcher: The production of the rule.
+func (r *Rule[T]) GetProduction() gr.TokenMatcher[T] {
+	return r.production
+}
+
+// GetAction returns the change to the mode stack applied when the rule
+// matches.
+//
+// Returns:
+//   - *ModeAction: The change to the mode stack. Nil if the mode does not
+//     change.
+func (r *Rule[T]) GetAction() *ModeAction {
+	return r.action
+}
+
+// GetTieBreak returns the way the rule takes part in the resolution of ties.
+//
+// Returns:
+//   - *TieBreak: The tie break. Nil if the rule is resolved by the longest
+//     match; that is, the same as ByLongestMatch().
+func (r *Rule[T]) GetTieBreak() *TieBreak {
+	return r.tie_break
+}
+
+// productions_of is a helper function that returns the productions of a
+// list of rules.
+//
+// Parameters:
+//   - rules: The rules.
+//
+// Returns:
+//   - []gr.TokenMatcher: The productions, in the same order as the rules.
+func productions_of[T gr.TokenTyper](rules []*Rule[T]) []gr.TokenMatcher[T] {
+	prods := make([]gr.TokenMatcher[T], 0, len(rules))
+
+	for _, rule := range rules {
+		prods = append(prods, rule.production)
+	}
+
+	return prods
+}
+
+// literals_of is a helper function that returns the strings matched by the
+// literal rules of a list of rules.
+//
+// Parameters:
+//   - rules: The rules.
+//
+// Returns:
+//   - []string: The literals, in the same order as the rules.
+func literals_of[T gr.TokenTyper](rules []*Rule[T]) []string {
+	var literals []string
+
+	for _, rule := range rules {
+		p, ok := rule.production.(*gr.LitProduction[T])
+		if ok {
+			literals = append(literals, p.GetLiteral())
+		}
+	}
+
+	return literals
+}
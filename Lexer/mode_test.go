@@ -0,0 +1,324 @@
+package Lexer
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+type ModeTokenType int
+
+const (
+	MtEof ModeTokenType = iota
+	MtWord
+	MtQuote
+	MtText
+	MtOpen
+	MtClose
+	MtWs
+)
+
+func (t ModeTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"word",
+		"quote",
+		"text",
+		"open",
+		"close",
+		"ws",
+	}[t]
+}
+
+func (t ModeTokenType) IsTerminal() bool {
+	return true
+}
+
+// new_mode_lexer creates a lexer of strings with interpolations, such as
+// `a "x{b}y" c`. The quotes of both modes share the same type.
+//
+//	default: word, quote -> push(string), close -> pop, ws (skipped)
+//	string:  text, quote -> pop, open -> push(default)
+func new_mode_lexer(t *testing.T) *Lexer[ModeTokenType] {
+	grammar := NewGrammar([]ModeTokenType{MtWs})
+
+	rules := []struct {
+		mode   string
+		lhs    ModeTokenType
+		regex  string
+		action *ModeAction
+	}{
+		{DefaultMode, MtWord, `[a-z]+`, nil},
+		{DefaultMode, MtQuote, `"`, PushMode("string")},
+		{DefaultMode, MtClose, `\}`, PopMode()},
+		{DefaultMode, MtWs, ` +`, nil},
+		{"string", MtText, `[^"{]+`, nil},
+		{"string", MtQuote, `"`, PopMode()},
+		{"string", MtOpen, `\{`, PushMode(DefaultMode)},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddModeRule(rule.mode, rule.lhs, rule.regex, rule.action)
+		if err != nil {
+			t.Fatalf("AddModeRule(%s) failed: %s", rule.lhs, err.Error())
+		}
+	}
+
+	return NewLexer(grammar)
+}
+
+// step_all lexes the whole input with Step and returns the steps, including
+// the skipped ones.
+func step_all(t *testing.T, lexer *Lexer[ModeTokenType], input string) []*LexStep[ModeTokenType] {
+	var steps []*LexStep[ModeTokenType]
+	var modes *ModeStack
+
+	for at := 0; at < len(input); {
+		step, err := lexer.Step([]byte(input), at, modes)
+		if err != nil {
+			t.Fatalf("%q: Step(%d) failed: %s", input, at, err.Error())
+		}
+
+		steps = append(steps, step)
+
+		at += len(step.Token.Data.(string))
+		modes = step.Modes
+	}
+
+	return steps
+}
+
+func TestModeStack(t *testing.T) {
+	ms := NewModeStack()
+
+	pushed := ms.Push("string").Push("template")
+	if pushed.String() != "default > string > template" {
+		t.Errorf("expected default > string > template, got %s", pushed.String())
+	}
+
+	popped, err := pushed.Pop()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if popped.Top() != "string" {
+		t.Errorf("expected string on top, got %s", popped.Top())
+	}
+
+	// The stacks are immutable.
+	if pushed.Top() != "template" {
+		t.Errorf("expected Pop to leave the stack unchanged, got %s", pushed.Top())
+	}
+
+	switched := popped.Switch("comment")
+	if !slices.Equal(switched.Slice(), []string{DefaultMode, "comment"}) {
+		t.Errorf("expected [default comment], got %v", switched.Slice())
+	}
+
+	_, err = ms.Pop()
+
+	var target *ErrCannotPopMode
+
+	if !errors.As(err, &target) {
+		t.Errorf("expected *ErrCannotPopMode, got %v", err)
+	}
+
+	var nil_stack *ModeStack
+
+	if nil_stack.Top() != DefaultMode || nil_stack.Push("string").Top() != "string" {
+		t.Errorf("expected a nil stack to behave like NewModeStack")
+	}
+
+	// Equal compares the modes, not the stacks.
+	equals := []struct {
+		a, b     *ModeStack
+		expected bool
+	}{
+		{popped, ms.Push("string"), true},
+		{nil_stack, ms, true},
+		{pushed, popped, false},
+		{switched, ms.Push("string"), false},
+		{ms.Push("a").Push("b"), ms.Push("b").Push("a"), false},
+	}
+
+	for _, test := range equals {
+		if test.a.Equal(test.b) != test.expected {
+			t.Errorf("%s and %s: expected Equal to be %t", test.a.String(), test.b.String(), test.expected)
+		}
+	}
+}
+
+func TestStep(t *testing.T) {
+	lexer := new_mode_lexer(t)
+
+	input := []byte(`a "x{b}y" c`)
+
+	// Lexing again from the middle of a string, with its mode stack.
+	modes := NewModeStack().Push("string")
+
+	step, err := lexer.Step(input, 7, modes)
+	if err != nil {
+		t.Fatalf("Step failed: %s", err.Error())
+	}
+
+	if step.Token.ID != MtText || step.Token.Data != "y" || step.Token.At != 7 || !step.Modes.Equal(modes) {
+		t.Errorf("expected the text \"y\" at 7, got %s", step.Token.GoString())
+	}
+
+	step, err = lexer.Step(input, 8, modes)
+	if err != nil {
+		t.Fatalf("Step failed: %s", err.Error())
+	}
+
+	if step.Token.ID != MtQuote || !step.Modes.Equal(NewModeStack()) {
+		t.Errorf("expected the quote to pop the string mode, got %s", step.Modes.String())
+	}
+
+	step, err = lexer.Step(input, 9, step.Modes)
+	if err != nil {
+		t.Fatalf("Step failed: %s", err.Error())
+	}
+
+	if !step.Skipped || step.Error != nil {
+		t.Errorf("expected the space to be skipped")
+	}
+
+	_, err = lexer.Step(input, len(input), nil)
+	if err == nil {
+		t.Errorf("expected an error for a position out of bounds")
+	}
+}
+
+func TestLexModes(t *testing.T) {
+	lexer := new_mode_lexer(t)
+
+	const Input string = `a "x{b}y" c`
+
+	type want struct {
+		id   ModeTokenType
+		text string
+		mode string
+	}
+
+	expected := []want{
+		{MtWord, "a", DefaultMode},
+		{MtWs, " ", DefaultMode},
+		{MtQuote, `"`, DefaultMode},
+		{MtText, "x", "string"},
+		{MtOpen, "{", "string"},
+		{MtWord, "b", DefaultMode},
+		{MtClose, "}", DefaultMode},
+		{MtText, "y", "string"},
+		{MtQuote, `"`, "string"},
+		{MtWs, " ", DefaultMode},
+		{MtWord, "c", DefaultMode},
+	}
+
+	steps := step_all(t, lexer, Input)
+	if len(steps) != len(expected) {
+		t.Fatalf("expected %d tokens, got %d", len(expected), len(steps))
+	}
+
+	for i, step := range steps {
+		got := want{step.Token.ID, step.Token.Data.(string), step.Token.Mode}
+		if got != expected[i] {
+			t.Errorf("token %d: expected %+v, got %+v", i, expected[i], got)
+		}
+
+		if step.Skipped != (step.Token.ID == MtWs) {
+			t.Errorf("token %d: expected Skipped to be %t", i, !step.Skipped)
+		}
+	}
+
+	// The mode stack after the opening brace has both modes of the
+	// interpolation.
+	if steps[4].Modes.String() != "default > string > default" {
+		t.Errorf("expected default > string > default, got %s", steps[4].Modes.String())
+	}
+
+	if !steps[len(steps)-1].Modes.Equal(NewModeStack()) {
+		t.Errorf("expected to end in the default mode, got %s", steps[len(steps)-1].Modes.String())
+	}
+}
+
+func TestLexModesRules(t *testing.T) {
+	lexer := new_mode_lexer(t)
+
+	// "x y" is a single text in the string mode but two words and a space in
+	// the default one.
+	steps := step_all(t, lexer, `"x y"`)
+	if len(steps) != 3 || steps[1].Token.ID != MtText || steps[1].Token.Data != "x y" {
+		t.Errorf(`expected the text "x y", got %d tokens`, len(steps))
+	}
+
+	// A closing brace is only a rule of the default mode and pops it; in the
+	// outermost mode there is nothing to pop.
+	_, err := lexer.Step([]byte("}"), 0, nil)
+
+	var target *ErrCannotPopMode
+
+	if !errors.As(err, &target) {
+		t.Errorf("expected *ErrCannotPopMode, got %v", err)
+	}
+
+	// An opening brace has no rule in the default mode.
+	_, err = lexer.Step([]byte("{"), 0, nil)
+	if err == nil {
+		t.Errorf("expected an error, got nil")
+	}
+}
+
+func TestMatchAtModes(t *testing.T) {
+	lexer := new_mode_lexer(t)
+
+	input := []byte(`"x y"`)
+
+	// In the default mode, "x" is a word; in the string mode, "x y" is a
+	// single text.
+	steps, err := lexer.MatchAt(input, 1, nil, nil)
+	if err != nil {
+		t.Fatalf("MatchAt failed: %s", err.Error())
+	}
+
+	if len(steps) != 1 || steps[0].Token.ID != MtWord || steps[0].Token.Data != "x" {
+		t.Errorf("expected the word \"x\", got %d tokens", len(steps))
+	}
+
+	string_mode := NewModeStack().Push("string")
+
+	steps, err = lexer.MatchAt(input, 1, string_mode, nil)
+	if err != nil {
+		t.Fatalf("MatchAt failed: %s", err.Error())
+	}
+
+	if len(steps) != 1 || steps[0].Token.ID != MtText || steps[0].Token.Data != "x y" || steps[0].Token.Mode != "string" {
+		t.Errorf("expected the text \"x y\", got %d tokens", len(steps))
+	}
+
+	// The closing quote pops the string mode.
+	steps, err = lexer.MatchAt(input, 4, string_mode, nil)
+	if err != nil {
+		t.Fatalf("MatchAt failed: %s", err.Error())
+	}
+
+	if len(steps) != 1 || !steps[0].Modes.Equal(NewModeStack()) {
+		t.Errorf("expected the quote to pop the string mode")
+	}
+
+	// The text is not allowed, and no other rule of the string mode matches.
+	_, err = lexer.MatchAt(input, 1, string_mode, []ModeTokenType{MtWord})
+	if err == nil {
+		t.Errorf("expected an error, got nil")
+	}
+
+	// A closing brace pops the last mode.
+	_, err = lexer.MatchAt([]byte("}"), 0, nil, nil)
+
+	var target *ErrCannotPopMode
+
+	if !errors.As(err, &target) {
+		t.Errorf("expected *ErrCannotPopMode, got %v", err)
+	}
+}
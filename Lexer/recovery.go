@@ -0,0 +1,250 @@
+package Lexer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+// RecoveryKind is the way the lexer skips the text that no rule matches.
+type RecoveryKind int
+
+const (
+	// SkipRuneRecovery skips a single rune.
+	SkipRuneRecovery RecoveryKind = iota
+
+	// SkipToWhitespaceRecovery skips up to the next whitespace.
+	SkipToWhitespaceRecovery
+
+	// SkipToSyncRecovery skips up to the next match of a regular expression.
+	SkipToSyncRecovery
+)
+
+// String implements the fmt.Stringer interface.
+func (k RecoveryKind) String() string {
+	return [...]string{
+		"skip rune",
+		"skip to whitespace",
+		"skip to sync",
+	}[k]
+}
+
+// Recovery is the policy the lexer follows when no rule matches. Instead of
+// failing, the lexer emits an error token that covers the skipped text,
+// records a syntax error and carries on.
+type Recovery[T gr.TokenTyper] struct {
+	// kind is the way the text is skipped.
+	kind RecoveryKind
+
+	// error_id is the ID of the error tokens.
+	error_id T
+
+	// sync is the regular expression for SkipToSyncRecovery.
+	sync *regexp.Regexp
+}
+
+// NewSkipRuneRecovery creates a policy that skips one rune at a time.
+//
+// Parameters:
+//   - error_id: The ID of the error tokens.
+//
+// Returns:
+//   - *Recovery: The new policy. Never nil.
+func NewSkipRuneRecovery[T gr.TokenTyper](error_id T) *Recovery[T] {
+	r := &Recovery[T]{
+		kind:     SkipRuneRecovery,
+		error_id: error_id,
+	}
+
+	return r
+}
+
+// NewSkipToWhitespaceRecovery creates a policy that skips up to the next
+// whitespace.
+//
+// Parameters:
+//   - error_id: The ID of the error tokens.
+//
+// Returns:
+//   - *Recovery: The new policy. Never nil.
+func NewSkipToWhitespaceRecovery[T gr.TokenTyper](error_id T) *Recovery[T] {
+	r := &Recovery[T]{
+		kind:     SkipToWhitespaceRecovery,
+		error_id: error_id,
+	}
+
+	return r
+}
+
+// NewSkipToSyncRecovery creates a policy that skips up to the next match of
+// a regular expression. The match itself is not skipped.
+//
+// Parameters:
+//   - error_id: The ID of the error tokens.
+//   - sync: The regular expression.
+//
+// Returns:
+//   - *Recovery: The new policy. Nil if an error occurred.
+//   - error: An error if the regular expression cannot be compiled.
+func NewSkipToSyncRecovery[T gr.TokenTyper](error_id T, sync string) (*Recovery[T], error) {
+	rxp, err := regexp.Compile(sync)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recovery[T]{
+		kind:     SkipToSyncRecovery,
+		error_id: error_id,
+		sync:     rxp,
+	}
+
+	return r, nil
+}
+
+// GetKind returns the way the text is skipped.
+//
+// Returns:
+//   - RecoveryKind: The way the text is skipped.
+func (r *Recovery[T]) GetKind() RecoveryKind {
+	return r.kind
+}
+
+// GetErrorID returns the ID of the error tokens.
+//
+// Returns:
+//   - T: The ID of the error tokens.
+func (r *Recovery[T]) GetErrorID() T {
+	return r.error_id
+}
+
+// skip is a helper method that computes how much text to skip.
+//
+// Parameters:
+//   - input: The whole input.
+//   - at: The position where no rule matched. Must be in the input.
+//
+// Returns:
+//   - int: The number of bytes to skip. Always at least one rune.
+func (r *Recovery[T]) skip(input []byte, at int) int {
+	_, size := utf8.DecodeRune(input[at:])
+
+	switch r.kind {
+	case SkipToWhitespaceRecovery:
+		for at+size < len(input) {
+			c, s := utf8.DecodeRune(input[at+size:])
+			if unicode.IsSpace(c) {
+				break
+			}
+
+			size += s
+		}
+	case SkipToSyncRecovery:
+		loc := r.sync.FindIndex(input[at+size:])
+		if loc == nil {
+			size = len(input) - at
+		} else {
+			size += loc[0]
+		}
+	}
+
+	return size
+}
+
+// recover_at is a helper method that creates the error token and the syntax
+// error for the text that no rule matched.
+//
+// Parameters:
+//   - input: The whole input, or the part of it that is in memory.
+//   - at: The position where no rule matched. Must be in the input.
+//   - offset: The position of the first byte of input in the whole input.
+//     The token and the error are placed relative to the whole input.
+//   - literals: The literals of the current mode, used for suggestions.
+//
+// Returns:
+//   - *gr.Token: The error token that covers the skipped text.
+//   - SyntaxErrorer: A *ClosestSyntaxError if the word at the position is
+//     close to one of the literals. Otherwise, an *UnrecognizedSyntaxError.
+func (r *Recovery[T]) recover_at(input []byte, at, offset int, literals []string) (*gr.Token[T], SyntaxErrorer) {
+	size := r.skip(input, at)
+
+	tok := gr.NewToken(r.error_id, string(input[at:at+size]), offset+at, nil)
+
+	return tok, syntax_error_at(input, at, offset, size, literals)
+}
+
+// syntax_error_at is a helper function that creates the syntax error for the
+// text that no rule matched.
+//
+// Parameters:
+//   - input: The whole input, or the part of it that is in memory.
+//   - at: The position where no rule matched. Must be in the input.
+//   - offset: The position of the first byte of input in the whole input.
+//   - size: The size of the text to compare with the literals when there is
+//     no word at the position.
+//   - literals: The literals of the current mode, used for suggestions.
+//
+// Returns:
+//   - SyntaxErrorer: A *ClosestSyntaxError if the word at the position is
+//     close to one of the literals. Otherwise, an *UnrecognizedSyntaxError.
+func syntax_error_at(input []byte, at, offset, size int, literals []string) SyntaxErrorer {
+	word := word_at(input, at)
+	if word == "" {
+		word = string(input[at : at+size])
+	}
+
+	closest, ok := gr.Closest(word, literals)
+	if ok {
+		return NewClosestSyntaxError(nil, closest, word, offset+at)
+	}
+
+	char, _ := utf8.DecodeRune(input[at:])
+
+	return NewUnrecognizedSyntaxError(char, offset+at)
+}
+
+// no_match_error is a helper function that creates the error for the text
+// that no rule matched when there is no recovery policy.
+//
+// Parameters:
+//   - input: The whole input, or the part of it that is in memory.
+//   - at: The position where no rule matched. Must be in the input.
+//   - offset: The position of the first byte of input in the whole input.
+//   - literals: The literals of the current mode, used for suggestions.
+//
+// Returns:
+//   - *ErrSyntax: The error, with the syntax error that a recovery policy
+//     that skips one rune would have recorded. Never nil.
+func no_match_error(input []byte, at, offset int, literals []string) *ErrSyntax {
+	_, size := utf8.DecodeRune(input[at:])
+
+	return NewErrSyntax(syntax_error_at(input, at, offset, size, literals))
+}
+
+// word_at is a helper function that returns the word that starts at the
+// given position; that is, the longest run of letters, digits and
+// underscores.
+//
+// Parameters:
+//   - input: The whole input.
+//   - at: The position of the word.
+//
+// Returns:
+//   - string: The word. Empty if there is no word at the position.
+func word_at(input []byte, at int) string {
+	var builder strings.Builder
+
+	for at < len(input) {
+		c, size := utf8.DecodeRune(input[at:])
+		if c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			break
+		}
+
+		builder.WriteRune(c)
+		at += size
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,317 @@
+package Lexer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+type RecoveryTokenType int
+
+const (
+	RtEof RecoveryTokenType = iota
+	RtWord
+	RtSemi
+	RtSpace
+	RtLet
+	RtError
+)
+
+func (t RecoveryTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"word",
+		"semi",
+		"space",
+		"let",
+		"ERROR",
+	}[t]
+}
+
+func (t RecoveryTokenType) IsTerminal() bool {
+	return true
+}
+
+// new_recovery_lexer creates a lexer of lowercase words, semicolons and the
+// keyword "let", with the given recovery policy. The spaces are skipped.
+func new_recovery_lexer(t *testing.T, recovery *Recovery[RecoveryTokenType]) *Lexer[RecoveryTokenType] {
+	grammar := NewGrammar([]RecoveryTokenType{RtSpace})
+
+	err := grammar.AddLiteral(RtLet, "let", false)
+	if err != nil {
+		t.Fatalf("AddLiteral failed: %s", err.Error())
+	}
+
+	rules := []struct {
+		lhs   RecoveryTokenType
+		regex string
+	}{
+		{RtWord, `[a-z]+`},
+		{RtSemi, `;`},
+		{RtSpace, ` +`},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.regex)
+		if err != nil {
+			t.Fatalf("AddRule(%s) failed: %s", rule.lhs, err.Error())
+		}
+	}
+
+	lexer := NewLexer(grammar)
+	lexer.SetEOF(RtEof)
+	lexer.SetRecovery(recovery)
+
+	return lexer
+}
+
+// lex_one lexes the input and returns its only tokenization as a
+// "type(data) ..." string, together with its syntax errors.
+func lex_one(t *testing.T, lexer *Lexer[RecoveryTokenType], input string) (string, []SyntaxErrorer) {
+	iter := lexer.Lex([]byte(input))
+
+	stream, err := iter.Consume()
+	if err != nil {
+		t.Fatalf("%q: Consume failed: %s", input, err.Error())
+	}
+
+	var values []string
+
+	for _, tok := range stream.GetItems() {
+		values = append(values, tok.ID.String()+"("+tok.Data.(string)+")")
+	}
+
+	errs := iter.GetErrors()
+
+	_, err = iter.Consume()
+	if !uc.Is[*uc.ErrExhaustedIter](err) {
+		t.Errorf("%q: expected a single tokenization, got %v", input, err)
+	}
+
+	return strings.Join(values, " "), errs
+}
+
+func TestRecoverySkipKinds(t *testing.T) {
+	sync, err := NewSkipToSyncRecovery(RtError, `;`)
+	if err != nil {
+		t.Fatalf("NewSkipToSyncRecovery failed: %s", err.Error())
+	}
+
+	tests := []struct {
+		name     string
+		recovery *Recovery[RecoveryTokenType]
+		expected string
+		errors   []int
+	}{
+		{
+			name:     "skip rune",
+			recovery: NewSkipRuneRecovery(RtError),
+			expected: "word(ab) ERROR(1) ERROR(2) word(x) ERROR(3) word(cd) semi(;) EOF()",
+			errors:   []int{3, 4, 6},
+		},
+		{
+			name:     "skip to whitespace",
+			recovery: NewSkipToWhitespaceRecovery(RtError),
+			expected: "word(ab) ERROR(12x3) word(cd) semi(;) EOF()",
+			errors:   []int{3},
+		},
+		{
+			name:     "skip to sync",
+			recovery: sync,
+			expected: "word(ab) ERROR(12x3 cd) semi(;) EOF()",
+			errors:   []int{3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, errs := lex_one(t, new_recovery_lexer(t, test.recovery), "ab 12x3 cd;")
+			if got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+
+			if len(errs) != len(test.errors) {
+				t.Fatalf("expected %d errors, got %d", len(test.errors), len(errs))
+			}
+
+			for i, serr := range errs {
+				_, ok := serr.(*UnrecognizedSyntaxError)
+				if !ok {
+					t.Errorf("error %d: expected *UnrecognizedSyntaxError, got %T", i, serr)
+				}
+
+				if serr.GetPosition() != test.errors[i] {
+					t.Errorf("error %d: expected position %d, got %d", i, test.errors[i], serr.GetPosition())
+				}
+			}
+		})
+	}
+}
+
+func TestRecoverySyncAtEnd(t *testing.T) {
+	sync, err := NewSkipToSyncRecovery(RtError, `;`)
+	if err != nil {
+		t.Fatalf("NewSkipToSyncRecovery failed: %s", err.Error())
+	}
+
+	// Without a match of the sync expression, the rest of the input is
+	// skipped.
+	got, _ := lex_one(t, new_recovery_lexer(t, sync), "ab 1 cd")
+	if got != "word(ab) ERROR(1 cd) EOF()" {
+		t.Errorf("unexpected tokens: %q", got)
+	}
+
+	_, err = NewSkipToSyncRecovery(RtError, `(`)
+	if err == nil {
+		t.Errorf("expected an error for an invalid sync expression")
+	}
+}
+
+func TestRecoverAt(t *testing.T) {
+	recovery := NewSkipToWhitespaceRecovery(RtError)
+
+	literals := []string{"let", "func"}
+
+	// "lte" is close to "let".
+	tok, serr := recovery.recover_at([]byte("x lte"), 2, 10, literals)
+
+	if tok.ID != RtError || tok.Data != "lte" || tok.At != 12 {
+		t.Errorf("unexpected error token: %s", tok.GoString())
+	}
+
+	closest, ok := serr.(*ClosestSyntaxError)
+	if !ok {
+		t.Fatalf("expected *ClosestSyntaxError, got %T", serr)
+	}
+
+	if closest.closest != "let" || closest.actual != "lte" || closest.GetPosition() != 12 {
+		t.Errorf("unexpected suggestion %q for %q at %d", closest.closest, closest.actual, closest.GetPosition())
+	}
+
+	// "#!" is not a word, so it is compared as a whole and is close to
+	// nothing.
+	tok, serr = recovery.recover_at([]byte("#!"), 0, 0, literals)
+
+	if tok.Data != "#!" {
+		t.Errorf("expected the error token to cover %q, got %q", "#!", tok.Data)
+	}
+
+	unrecognized, ok := serr.(*UnrecognizedSyntaxError)
+	if !ok {
+		t.Fatalf("expected *UnrecognizedSyntaxError, got %T", serr)
+	}
+
+	if unrecognized.char != '#' || unrecognized.GetPosition() != 0 {
+		t.Errorf("expected '#' at 0, got %q at %d", unrecognized.char, unrecognized.GetPosition())
+	}
+}
+
+func TestLexGetErrors(t *testing.T) {
+	lexer := new_recovery_lexer(t, NewSkipRuneRecovery(RtError))
+
+	// Each "#" is skipped on its own.
+	iter := lexer.Lex([]byte("# let #"))
+
+	if len(iter.GetErrors()) != 0 {
+		t.Errorf("expected no errors before Consume")
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := iter.Consume()
+		if err != nil {
+			t.Fatalf("Consume %d failed: %s", i, err.Error())
+		}
+
+		errs := iter.GetErrors()
+		if len(errs) != 2 || errs[0].GetPosition() != 0 || errs[1].GetPosition() != 6 {
+			t.Errorf("expected errors at 0 and 6, got %d errors", len(errs))
+		}
+
+		iter.Restart()
+
+		if len(iter.GetErrors()) != 0 {
+			t.Errorf("expected no errors after Restart")
+		}
+	}
+
+	// The literals of the grammar are suggested.
+	_, errs := lex_one(t, new_recovery_lexer(t, NewSkipToWhitespaceRecovery(RtError)), "1et;")
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(errs))
+	}
+
+	closest, ok := errs[0].(*ClosestSyntaxError)
+	if !ok || closest.closest != "let" {
+		t.Errorf("expected a suggestion of \"let\", got %T", errs[0])
+	}
+
+	// Without a policy, lexing fails.
+	_, err := new_recovery_lexer(t, nil).Lex([]byte("ab 1")).Consume()
+	if !uc.Is[*ErrAllMatchesFailed](err) {
+		t.Errorf("expected *ErrAllMatchesFailed, got %v", err)
+	}
+}
+
+func TestNoRecoverySuggestions(t *testing.T) {
+	lexer := new_recovery_lexer(t, nil)
+
+	const Suggestion string = `; did you mean "let"?`
+
+	// Lex fails with the furthest failure as reason.
+	_, err := lexer.Lex([]byte("ab 1et")).Consume()
+
+	var serr *ErrSyntax
+
+	if !uc.Is[*ErrAllMatchesFailed](err) || !errors.As(err, &serr) {
+		t.Fatalf("expected *ErrAllMatchesFailed with an *ErrSyntax, got %v", err)
+	}
+
+	closest, ok := serr.Syntax.(*ClosestSyntaxError)
+	if !ok || closest.closest != "let" || closest.GetPosition() != 3 {
+		t.Errorf("expected a suggestion of \"let\" at 3, got %T", serr.Syntax)
+	}
+
+	if err.Error() != "all matches failed: no matches at 3"+Suggestion {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+
+	if !uc.Is[*ErrNoMatches](err) {
+		t.Errorf("expected the error to wrap *ErrNoMatches")
+	}
+
+	// Without a close literal, the character is reported.
+	_, err = lexer.Lex([]byte("ab #")).Consume()
+
+	if !errors.As(err, &serr) {
+		t.Fatalf("expected an *ErrSyntax, got %v", err)
+	}
+
+	_, ok = serr.Syntax.(*UnrecognizedSyntaxError)
+	if !ok || err.Error() != "all matches failed: no matches at 3" {
+		t.Errorf("expected an *UnrecognizedSyntaxError at 3, got %T: %q", serr.Syntax, err.Error())
+	}
+
+	// Step and the stream lexer have the suggestion too.
+	_, err = lexer.Step([]byte("ab 1et"), 3, nil)
+	if !errors.As(err, &serr) || err.Error() != "no matches at 3"+Suggestion {
+		t.Errorf("Step: expected an *ErrSyntax with a suggestion, got %v", err)
+	}
+
+	sl, err := lexer.LexReader(strings.NewReader("ab 1et"), 8)
+	if err != nil {
+		t.Fatalf("LexReader failed: %s", err.Error())
+	}
+
+	sl.Next()
+
+	_, err = sl.Next()
+
+	var at *ErrNoMatchesAt
+
+	if !errors.As(err, &at) || at.Syntax == nil || err.Error() != "no matches at 1:4"+Suggestion {
+		t.Errorf("Next: expected an *ErrNoMatchesAt with a suggestion, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package Lexer
+
+import (
+	"slices"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	tc "github.com/PlayerR9/LyneParser/Tracer"
+	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// LexStep is a single token lexed without branching, together with the
+// state of the lexer after it.
+type LexStep[T gr.TokenTyper] struct {
+	// Token is the token. Its lookahead is not set.
+	Token *gr.Token[T]
+
+	// Modes is the mode stack after the token.
+	Modes *ModeStack
+
+	// Error is the syntax error of an error token created by the recovery
+	// policy. Nil for the other tokens.
+	Error SyntaxErrorer
+
+	// Skipped is true if the token is of a type to skip.
+	Skipped bool
+}
+
+// lex_step is a helper function that lexes the token at the start of the
+// input.
+//
+// Parameters:
+//   - rules: The rules of each mode.
+//   - to_skip: The types of the tokens to skip.
+//   - recovery: The recovery policy. Nil if lexing must fail instead.
+//   - hook: The hook that receives the events. May be nil.
+//   - input: The input, starting at the token.
+//   - offset: The position of the first byte of input in the whole input.
+//   - modes: The mode stack before the token.
+//
+// Returns:
+//   - *LexStep: The token. Nil if an error occurred.
+//   - error: An error if no token could be lexed.
+//
+// Errors:
+//   - *ErrSyntax: No rule matches and there is no recovery policy. It
+//     wraps an *ErrNoMatches.
+//   - *ErrCannotPopMode: The rule that matches pops the last mode.
+//
+// Behaviors:
+//   - When several rules match the same longest text after the ties are
+//     resolved, the one declared first is used.
+func lex_step[T gr.TokenTyper](rules map[string][]*Rule[T], to_skip []T, recovery *Recovery[T], hook tc.Hooker, input []byte, offset int, modes *ModeStack) (*LexStep[T], error) {
+	mode := modes.Top()
+	mode_rules := rules[mode]
+
+	matches, err := match_from(cds.NewStream(input), 0, productions_of(mode_rules), mode)
+	if err != nil {
+		if recovery == nil {
+			return nil, no_match_error(input, 0, offset, literals_of(mode_rules))
+		}
+
+		tok, serr := recovery.recover_at(input, 0, offset, literals_of(mode_rules))
+		tok.Mode = mode
+
+		step := &LexStep[T]{
+			Token: tok,
+			Modes: modes,
+			Error: serr,
+		}
+
+		return step, nil
+	}
+
+	for _, match := range matches {
+		match.Matched.At = offset
+	}
+
+	matches = select_best_matches(matches, mode_rules, hook)
+
+	match := matches[0]
+
+	next, err := mode_rules[match.RuleIndex].GetAction().Apply(modes)
+	if err != nil {
+		return nil, err
+	}
+
+	step := &LexStep[T]{
+		Token:   match.Matched,
+		Modes:   next,
+		Skipped: slices.Contains(to_skip, match.Matched.ID),
+	}
+
+	return step, nil
+}
+
+// Step lexes the single token at the given position, without branching.
+// Together with the mode stack of the result, this allows to lex any part
+// of an input again, for example after an edit.
+//
+// Parameters:
+//   - input: The whole input.
+//   - at: The position of the token.
+//   - modes: The mode stack before the token. Nil is the same as a stack
+//     that only contains DefaultMode.
+//
+// Returns:
+//   - *LexStep: The token. Nil if an error occurred.
+//   - error: An error if no token could be lexed.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If at is out of bounds.
+//   - *ErrSyntax: No rule matches and there is no recovery policy. It
+//     wraps an *ErrNoMatches.
+//   - *ErrCannotPopMode: The rule that matches pops the last mode.
+//
+// Behaviors:
+//   - When several rules match the same longest text after the ties are
+//     resolved, the one declared first is used.
+//   - The skipped tokens are returned as well, with Skipped set.
+func (l *Lexer[T]) Step(input []byte, at int, modes *ModeStack) (*LexStep[T], error) {
+	if at < 0 || at >= len(input) {
+		return nil, uc.NewErrInvalidParameter(
+			"at",
+			uc.NewErrOutOfBounds(at, 0, len(input)),
+		)
+	}
+
+	if modes == nil {
+		modes = NewModeStack()
+	}
+
+	step, err := lex_step(l.rules, l.to_skip, l.recovery, l.hook, input[at:], at, modes)
+	if err != nil {
+		tc.Fire(l.hook, &tc.BranchKilledEvent{
+			Origin: tc.FromLexer,
+			At:     at,
+			Reason: err,
+		})
+
+		return nil, err
+	}
+
+	return step, nil
+}
@@ -0,0 +1,348 @@
+package Lexer
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	tc "github.com/PlayerR9/LyneParser/Tracer"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+const (
+	// DefaultMaxTokenLength is the size of the window of a StreamLexer, in
+	// bytes, when the longest token of the grammar is not bounded and no
+	// size is given.
+	DefaultMaxTokenLength int = 64 * 1024
+
+	// max_empty_reads is the number of reads in a row that return no data
+	// after which the reader is considered stuck.
+	max_empty_reads int = 100
+)
+
+// Position is a position in the input.
+type Position struct {
+	// Offset is the offset in bytes from the start of the input.
+	Offset int
+
+	// Line is the line number, starting at 1.
+	Line int
+
+	// Column is the column number in runes, starting at 1.
+	Column int
+}
+
+// String implements the fmt.Stringer interface.
+//
+// Format:
+//
+//	<line>:<column>
+func (p Position) String() string {
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// advance is a helper method that computes the position after the text.
+//
+// Parameters:
+//   - text: The text that starts at the position.
+//
+// Returns:
+//   - Position: The position after the text.
+func (p Position) advance(text string) Position {
+	p.Offset += len(text)
+
+	for _, c := range text {
+		if c == '\n' {
+			p.Line++
+			p.Column = 1
+		} else {
+			p.Column++
+		}
+	}
+
+	return p
+}
+
+// StreamToken is a token produced by a StreamLexer.
+type StreamToken[T gr.TokenTyper] struct {
+	// Token is the token. Its At is the offset from the start of the input
+	// and its lookahead is not set.
+	Token *gr.Token[T]
+
+	// Pos is the position where the token starts.
+	Pos Position
+
+	// Error is the syntax error of an error token created by the recovery
+	// policy. Nil for the other tokens.
+	Error SyntaxErrorer
+}
+
+// StreamLexer is a lexer that reads its input from an io.Reader and emits
+// the tokens one at a time. Only a window of the input is kept in memory,
+// so large files and pipes can be lexed without reading them first.
+//
+// Unlike Lex, a StreamLexer does not branch: when several rules still match
+// the same longest text after the ties are resolved, the one declared first
+// is used.
+type StreamLexer[T gr.TokenTyper] struct {
+	// rules are the rules of each mode.
+	rules map[string][]*Rule[T]
+
+	// to_skip are the tokens to skip.
+	to_skip []T
+
+	// hook is the hook that receives the events of the lexer. May be nil.
+	hook tc.Hooker
+
+	// recovery is the policy to follow when no rule matches. Nil if lexing
+	// must fail instead.
+	recovery *Recovery[T]
+
+	// reader is the source of the input.
+	reader io.Reader
+
+	// buf holds the part of the input that is in memory. Its capacity is
+	// twice the window so that reading never needs more memory.
+	buf []byte
+
+	// start is the index in buf of the first byte that is not lexed yet.
+	start int
+
+	// pos is the position of buf[start] in the input.
+	pos Position
+
+	// window is the length of the longest token, in bytes.
+	window int
+
+	// modes is the mode stack.
+	modes *ModeStack
+
+	// eof is true once the reader has no more data.
+	eof bool
+
+	// read_err is the error of the reader, if it is not io.EOF.
+	read_err error
+}
+
+// longest_token is a helper function that computes the length of the
+// longest token that a set of rules can match.
+//
+// Parameters:
+//   - rules: The rules of each mode.
+//
+// Returns:
+//   - int: The length in bytes.
+//   - bool: False if a rule can match a text of any length.
+//
+// Behaviors:
+//   - Only literal rules have a bounded length. A case-insensitive literal
+//     can match runes of another size, so its length is counted as if every
+//     rune took utf8.UTFMax bytes.
+func longest_token[T gr.TokenTyper](rules map[string][]*Rule[T]) (int, bool) {
+	var longest int
+
+	for _, mode_rules := range rules {
+		for _, rule := range mode_rules {
+			p, ok := rule.production.(*gr.LitProduction[T])
+			if !ok {
+				return 0, false
+			}
+
+			size := len(p.GetLiteral())
+			if p.IsCaseInsensitive() {
+				size = utf8.RuneCountInString(p.GetLiteral()) * utf8.UTFMax
+			}
+
+			longest = max(longest, size)
+		}
+	}
+
+	return longest, true
+}
+
+// LexReader creates a lexer that reads its input from a reader.
+//
+// Parameters:
+//   - reader: The source of the input.
+//   - max_token: The length of the longest token, in bytes. 0 or less uses
+//     the longest token of the grammar or, if it is not bounded,
+//     DefaultMaxTokenLength.
+//
+// Returns:
+//   - *StreamLexer: The new lexer. Nil if an error occurred.
+//   - error: An error of type *uc.ErrInvalidParameter if reader is nil.
+//
+// Behaviors:
+//   - The window is never larger than the longest token of the grammar, when
+//     it is bounded. At most twice the window is kept in memory.
+//   - The hook and the recovery policy of the lexer are used.
+func (l *Lexer[T]) LexReader(reader io.Reader, max_token int) (*StreamLexer[T], error) {
+	if reader == nil {
+		return nil, uc.NewErrNilParameter("reader")
+	}
+
+	window := max_token
+	if window <= 0 {
+		window = DefaultMaxTokenLength
+	}
+
+	longest, ok := longest_token(l.rules)
+	if ok && longest > 0 {
+		window = min(window, longest)
+	}
+
+	rules_copy := make(map[string][]*Rule[T], len(l.rules))
+	for mode, rules := range l.rules {
+		rules_copy[mode] = slices.Clone(rules)
+	}
+
+	sl := &StreamLexer[T]{
+		rules:    rules_copy,
+		to_skip:  slices.Clone(l.to_skip),
+		hook:     l.hook,
+		recovery: l.recovery,
+		reader:   reader,
+		buf:      make([]byte, 0, 2*(window+1)),
+		pos:      Position{Line: 1, Column: 1},
+		window:   window,
+		modes:    NewModeStack(),
+	}
+
+	return sl, nil
+}
+
+// GetWindow returns the length of the longest token the lexer accepts.
+//
+// Returns:
+//   - int: The length in bytes.
+func (sl *StreamLexer[T]) GetWindow() int {
+	return sl.window
+}
+
+// GetPosition returns the position of the next token.
+//
+// Returns:
+//   - Position: The position.
+func (sl *StreamLexer[T]) GetPosition() Position {
+	return sl.pos
+}
+
+// fill is a helper method that reads until the buffer holds one byte more
+// than the window past start, or until the reader has no more data.
+func (sl *StreamLexer[T]) fill() {
+	if sl.eof || len(sl.buf)-sl.start > sl.window {
+		return
+	}
+
+	if cap(sl.buf)-sl.start <= sl.window {
+		n := copy(sl.buf, sl.buf[sl.start:])
+		sl.buf = sl.buf[:n]
+		sl.start = 0
+	}
+
+	var empty int
+
+	for !sl.eof && len(sl.buf)-sl.start <= sl.window {
+		n, err := sl.reader.Read(sl.buf[len(sl.buf):cap(sl.buf)])
+		sl.buf = sl.buf[:len(sl.buf)+n]
+
+		if err == io.EOF {
+			sl.eof = true
+		} else if err != nil {
+			sl.eof = true
+			sl.read_err = err
+		} else if n > 0 {
+			empty = 0
+		} else {
+			empty++
+
+			if empty >= max_empty_reads {
+				sl.eof = true
+				sl.read_err = io.ErrNoProgress
+			}
+		}
+	}
+}
+
+// consume is a helper method that moves past the text of a token.
+//
+// Parameters:
+//   - text: The text of the token.
+func (sl *StreamLexer[T]) consume(text string) {
+	sl.start += len(text)
+	sl.pos = sl.pos.advance(text)
+}
+
+// Next lexes the next token.
+//
+// Returns:
+//   - *StreamToken: The token. Nil if an error occurred.
+//   - error: io.EOF at the end of the input. Otherwise, an error if the
+//     token could not be lexed.
+//
+// Errors:
+//   - io.EOF: There are no more tokens.
+//   - *ErrNoMatchesAt: No rule matches and there is no recovery policy.
+//   - *ErrTokenTooLong: The longest match does not fit in the window.
+//   - *ErrCannotPopMode: A rule pops the last mode.
+//   - any error of the reader, once the data read before it is lexed.
+//
+// Behaviors:
+//   - The skipped tokens are not returned.
+//   - A rule is only matched against the window; so a regular expression
+//     whose longest match does not fit may match a shorter text instead.
+//   - After an error other than an error of the reader, calling Next again
+//     retries at the same position.
+func (sl *StreamLexer[T]) Next() (*StreamToken[T], error) {
+	for {
+		sl.fill()
+
+		input := sl.buf[sl.start:]
+
+		if len(input) == 0 {
+			if sl.read_err != nil {
+				return nil, sl.read_err
+			}
+
+			return nil, io.EOF
+		}
+
+		step, err := lex_step(sl.rules, sl.to_skip, sl.recovery, sl.hook, input, sl.pos.Offset, sl.modes)
+		var serr *ErrSyntax
+
+		if errors.As(err, &serr) {
+			err = NewErrNoMatchesAt(sl.pos, serr.Syntax)
+
+			tc.Fire(sl.hook, &tc.BranchKilledEvent{
+				Origin: tc.FromLexer,
+				At:     sl.pos.Offset,
+				Reason: err,
+			})
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		text := step.Token.Data.(string)
+		if step.Error == nil && len(text) > sl.window {
+			return nil, NewErrTokenTooLong(sl.pos, sl.window)
+		}
+
+		st := &StreamToken[T]{
+			Token: step.Token,
+			Pos:   sl.pos,
+			Error: step.Error,
+		}
+
+		sl.modes = step.Modes
+		sl.consume(text)
+
+		if !step.Skipped {
+			return st, nil
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package Lexer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// new_stream_lexer creates a lexer of words, colons and newlines where the
+// spaces are skipped.
+func new_stream_lexer(t *testing.T) *Lexer[LayoutTokenType] {
+	grammar := NewGrammar([]LayoutTokenType{LtIndent})
+
+	rules := []struct {
+		lhs   LayoutTokenType
+		regex string
+	}{
+		{LtWord, `[a-zé]+`},
+		{LtColon, `:`},
+		{LtNewline, `\n`},
+		{LtIndent, ` +`},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.regex)
+		if err != nil {
+			t.Fatalf("AddRule(%s) failed: %s", rule.lhs, err.Error())
+		}
+	}
+
+	return NewLexer(grammar)
+}
+
+func TestStreamLexer(t *testing.T) {
+	var builder strings.Builder
+
+	for i := 0; i < 500; i++ {
+		builder.WriteString("café: x\n")
+	}
+
+	source := builder.String()
+
+	lexer := new_stream_lexer(t)
+
+	// One byte at a time, to test the refills of the window.
+	sl, err := lexer.LexReader(iotest.OneByteReader(strings.NewReader(source)), 8)
+	if err != nil {
+		t.Fatalf("LexReader failed: %s", err.Error())
+	}
+
+	columns := []int{1, 5, 7, 8}
+
+	for i := 0; ; i++ {
+		st, err := sl.Next()
+		if err == io.EOF {
+			if i != 2000 {
+				t.Fatalf("expected 2000 tokens, got %d", i)
+			}
+
+			break
+		} else if err != nil {
+			t.Fatalf("Next failed: %s", err.Error())
+		}
+
+		data := st.Token.Data.(string)
+
+		if source[st.Token.At:st.Token.At+len(data)] != data {
+			t.Fatalf("token %q has the wrong offset %d", data, st.Token.At)
+		}
+
+		if st.Pos.Line != i/4+1 || st.Pos.Column != columns[i%4] {
+			t.Fatalf("token %q is at %s, expected %d:%d", data, st.Pos, i/4+1, columns[i%4])
+		}
+	}
+
+	if cap(sl.buf) > 2*(sl.GetWindow()+1) {
+		t.Errorf("the buffer grew to %d bytes", cap(sl.buf))
+	}
+
+	sl, _ = lexer.LexReader(strings.NewReader("abcdefghijk"), 8)
+
+	_, err = sl.Next()
+
+	var too_long *ErrTokenTooLong
+
+	if !errors.As(err, &too_long) {
+		t.Errorf("expected *ErrTokenTooLong, got %v", err)
+	}
+
+	sl, _ = lexer.LexReader(strings.NewReader("ab\n?"), 8)
+
+	sl.Next()
+	sl.Next()
+
+	_, err = sl.Next()
+	if err == nil || err.Error() != "no matches at 2:1" {
+		t.Errorf("expected no matches at 2:1, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package Lexer
+
+import (
+	"strconv"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+// TieBreakKind is the way a rule takes part in the resolution of ties, that
+// is, when several rules match the same longest text.
+type TieBreakKind int
+
+const (
+	// LongestMatchTieBreak does not resolve ties: every rule that matches
+	// the longest text gives its own branch.
+	LongestMatchTieBreak TieBreakKind = iota
+
+	// DeclarationOrderTieBreak resolves ties in favor of the rule that was
+	// declared first.
+	DeclarationOrderTieBreak
+
+	// PriorityTieBreak resolves ties in favor of the rule with the highest
+	// priority. Rules of the same priority keep their own branch.
+	PriorityTieBreak
+)
+
+// String implements the fmt.Stringer interface.
+func (k TieBreakKind) String() string {
+	return [...]string{
+		"longest match",
+		"declaration order",
+		"priority",
+	}[k]
+}
+
+const (
+	// LiteralPriority is the priority of the rules added with AddLiteral
+	// and AddModeLiteral. Since the other rules have priority 0 by default,
+	// literals win ties over them.
+	LiteralPriority int = 1
+)
+
+// TieBreak is the way a rule takes part in the resolution of ties.
+type TieBreak struct {
+	// Kind is the kind of the tie break.
+	Kind TieBreakKind
+
+	// Priority is the priority of the rule. Whatever the kind, ties are
+	// first resolved in favor of the rules with the highest priority.
+	Priority int
+}
+
+// String implements the fmt.Stringer interface.
+//
+// Format:
+//
+//	longest match | declaration order | priority(<n>)
+//
+// A longest match or declaration order tie break with a priority other
+// than 0 is followed by ", priority(<n>)".
+func (tb *TieBreak) String() string {
+	priority := "priority(" + strconv.Itoa(tb.Priority) + ")"
+
+	if tb.Kind == PriorityTieBreak {
+		return priority
+	}
+
+	if tb.Priority == 0 {
+		return tb.Kind.String()
+	}
+
+	return tb.Kind.String() + ", " + priority
+}
+
+// ByLongestMatch creates a tie break that keeps every tied rule.
+//
+// Returns:
+//   - *TieBreak: The new tie break. Never nil.
+func ByLongestMatch() *TieBreak {
+	tb := &TieBreak{
+		Kind: LongestMatchTieBreak,
+	}
+
+	return tb
+}
+
+// ByDeclarationOrder creates a tie break that favors the rule declared
+// first.
+//
+// Returns:
+//   - *TieBreak: The new tie break. Never nil.
+func ByDeclarationOrder() *TieBreak {
+	tb := &TieBreak{
+		Kind: DeclarationOrderTieBreak,
+	}
+
+	return tb
+}
+
+// ByPriority creates a tie break that favors the rule with the highest
+// priority.
+//
+// Parameters:
+//   - priority: The priority of the rule.
+//
+// Returns:
+//   - *TieBreak: The new tie break. Never nil.
+func ByPriority(priority int) *TieBreak {
+	tb := &TieBreak{
+		Kind:     PriorityTieBreak,
+		Priority: priority,
+	}
+
+	return tb
+}
+
+// priority is a helper method that returns the priority of a rule with the
+// tie break.
+//
+// Returns:
+//   - int: The priority. 0 if the receiver is nil.
+func (tb *TieBreak) priority() int {
+	if tb == nil {
+		return 0
+	}
+
+	return tb.Priority
+}
+
+// compose is a helper method that applies a tie break on top of the
+// receiver.
+//
+// Parameters:
+//   - other: The tie break to apply. Nil is the same as ByLongestMatch().
+//
+// Returns:
+//   - *TieBreak: The composed tie break. Never nil.
+//
+// Behaviors:
+//   - A PriorityTieBreak only changes the priority; the other kinds only
+//     change the kind. Thus, ByDeclarationOrder() on a literal keeps its
+//     LiteralPriority.
+func (tb *TieBreak) compose(other *TieBreak) *TieBreak {
+	composed := &TieBreak{
+		Kind:     LongestMatchTieBreak,
+		Priority: tb.priority(),
+	}
+
+	if tb != nil {
+		composed.Kind = tb.Kind
+	}
+
+	switch {
+	case other == nil:
+		composed.Kind = LongestMatchTieBreak
+	case other.Kind == PriorityTieBreak:
+		composed.Priority = other.Priority
+	default:
+		composed.Kind = other.Kind
+	}
+
+	return composed
+}
+
+// resolve_ties is a helper function that resolves the ties between the
+// matches of the same length.
+//
+// Parameters:
+//   - matches: The matches, all of the same length. Their RuleIndex is the
+//     index of the rule in rules.
+//   - rules: The rules of the mode, in declaration order.
+//
+// Returns:
+//   - []*gr.MatchedResult: The matches that survive.
+//
+// Behaviors:
+//   - Only the matches of the highest priority survive. Rules without a tie
+//     break have priority 0.
+//   - Then, the first survivor that uses DeclarationOrderTieBreak wins over
+//     every survivor declared after it, whatever their tie break. The
+//     survivors declared before it keep their own branch.
+func resolve_ties[T gr.TokenTyper](matches []*gr.MatchedResult[T], rules []*Rule[T]) []*gr.MatchedResult[T] {
+	if len(matches) < 2 {
+		return matches
+	}
+
+	best := rules[matches[0].RuleIndex].tie_break.priority()
+
+	for _, match := range matches[1:] {
+		p := rules[match.RuleIndex].tie_break.priority()
+		if p > best {
+			best = p
+		}
+	}
+
+	var survivors []*gr.MatchedResult[T]
+
+	// The matches are in declaration order, so the ones after the first
+	// DeclarationOrderTieBreak were declared after it.
+	for _, match := range matches {
+		tb := rules[match.RuleIndex].tie_break
+
+		if tb.priority() != best {
+			continue
+		}
+
+		survivors = append(survivors, match)
+
+		if tb != nil && tb.Kind == DeclarationOrderTieBreak {
+			break
+		}
+	}
+
+	return survivors
+}
@@ -0,0 +1,238 @@
+package Lexer
+
+import (
+	"strings"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+type TieTokenType int
+
+const (
+	TtEof TieTokenType = iota
+	TtIdent
+	TtIf
+	TtName
+	TtAssign
+	TtEqual
+	TtSpace
+)
+
+func (t TieTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"ident",
+		"if",
+		"name",
+		"assign",
+		"equal",
+		"space",
+	}[t]
+}
+
+func (t TieTokenType) IsTerminal() bool {
+	return true
+}
+
+// new_tie_grammar creates a grammar where "if" is matched by three rules, in
+// this order: the identifiers, the keyword "if" (case-insensitive) and the
+// names. The spaces are skipped.
+func new_tie_grammar(t *testing.T) *Grammar[TieTokenType] {
+	grammar := NewGrammar([]TieTokenType{TtSpace})
+
+	err := grammar.AddRule(TtIdent, `[a-zA-Z]+`)
+	if err != nil {
+		t.Fatalf("AddRule failed: %s", err.Error())
+	}
+
+	err = grammar.AddLiteral(TtIf, "if", true)
+	if err != nil {
+		t.Fatalf("AddLiteral failed: %s", err.Error())
+	}
+
+	rules := []struct {
+		lhs   TieTokenType
+		regex string
+	}{
+		{TtName, `[a-zA-Z]+`},
+		{TtAssign, `=`},
+		{TtEqual, `==`},
+		{TtSpace, ` +`},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.regex)
+		if err != nil {
+			t.Fatalf("AddRule(%s) failed: %s", rule.lhs, err.Error())
+		}
+	}
+
+	return grammar
+}
+
+// lex_tie_paths lexes the input and returns every tokenization as a
+// "type(data) ..." string.
+func lex_tie_paths(t *testing.T, grammar *Grammar[TieTokenType], input string) []string {
+	lexer := NewLexer(grammar)
+	lexer.SetEOF(TtEof)
+
+	iter := lexer.Lex([]byte(input))
+
+	var paths []string
+
+	for {
+		stream, err := iter.Consume()
+		if err != nil {
+			break
+		}
+
+		var values []string
+
+		for _, tok := range stream.GetItems() {
+			values = append(values, tok.ID.String()+"("+tok.Data.(string)+")")
+		}
+
+		paths = append(paths, strings.Join(values, " "))
+	}
+
+	return paths
+}
+
+func TestKeywordVsIdentifier(t *testing.T) {
+	grammar := new_tie_grammar(t)
+
+	// The keyword wins the tie on "if" and "IF", but not on "iffy", which the
+	// identifiers match with a longer text.
+	paths := lex_tie_paths(t, grammar, "if IF iffy")
+
+	expected := "if(if) if(IF) ident(iffy) EOF() | if(if) if(IF) name(iffy) EOF()"
+
+	if got := strings.Join(paths, " | "); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestLongestMatch(t *testing.T) {
+	grammar := new_tie_grammar(t)
+
+	// "==" is a single token, even though "=" is declared first.
+	paths := lex_tie_paths(t, grammar, "a == b")
+	if len(paths) != 4 {
+		t.Fatalf("expected 4 tokenizations, got %d", len(paths))
+	}
+
+	for _, path := range paths {
+		if !strings.Contains(path, " equal(==) ") {
+			t.Errorf("expected a single equal token, got %q", path)
+		}
+	}
+
+	// Without tie breaks, every rule that matches the longest text gives its
+	// own branch.
+	paths = lex_tie_paths(t, grammar, "=")
+	if strings.Join(paths, " | ") != "assign(=) EOF()" {
+		t.Errorf("unexpected tokenizations: %q", paths)
+	}
+}
+
+// tie_setup is a call to Grammar.SetTieBreak.
+type tie_setup struct {
+	lhs       TieTokenType
+	tie_break *TieBreak
+}
+
+func TestTieBreaks(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     []tie_setup
+		expected  string
+		tie_break string
+	}{
+		{
+			name:      "literal priority",
+			expected:  "if",
+			tie_break: "priority(1)",
+		},
+		{
+			name:      "declaration order keeps the literal priority",
+			setup:     []tie_setup{{TtIf, ByDeclarationOrder()}},
+			expected:  "if",
+			tie_break: "declaration order, priority(1)",
+		},
+		{
+			name:      "longest match keeps the literal priority",
+			setup:     []tie_setup{{TtIf, nil}},
+			expected:  "if",
+			tie_break: "longest match, priority(1)",
+		},
+		{
+			name:      "priority 0",
+			setup:     []tie_setup{{TtIf, ByPriority(0)}},
+			expected:  "ident if name",
+			tie_break: "priority(0)",
+		},
+		{
+			name:      "higher priority",
+			setup:     []tie_setup{{TtName, ByPriority(2)}},
+			expected:  "name",
+			tie_break: "priority(1)",
+		},
+		{
+			name:      "declaration order wins over the rules after it",
+			setup:     []tie_setup{{TtIf, ByPriority(0)}, {TtIdent, ByDeclarationOrder()}},
+			expected:  "ident",
+			tie_break: "priority(0)",
+		},
+		{
+			name:      "declaration order keeps the rules before it",
+			setup:     []tie_setup{{TtIf, ByPriority(0)}, {TtIf, ByDeclarationOrder()}},
+			expected:  "ident if",
+			tie_break: "declaration order",
+		},
+		{
+			name:      "priority after declaration order",
+			setup:     []tie_setup{{TtIf, ByDeclarationOrder()}, {TtIf, ByPriority(0)}},
+			expected:  "ident if",
+			tie_break: "declaration order",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grammar := new_tie_grammar(t)
+
+			for _, setup := range test.setup {
+				err := grammar.SetTieBreak(setup.lhs, setup.tie_break)
+				if err != nil {
+					t.Fatalf("SetTieBreak(%s) failed: %s", setup.lhs, err.Error())
+				}
+			}
+
+			steps, err := NewLexer(grammar).MatchAt([]byte("if"), 0, nil, nil)
+			if err != nil {
+				t.Fatalf("MatchAt failed: %s", err.Error())
+			}
+
+			var ids []string
+
+			for _, step := range steps {
+				ids = append(ids, step.Token.ID.String())
+			}
+
+			if got := strings.Join(ids, " "); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+
+			tie_break := grammar.GetRules()[DefaultMode][1].GetTieBreak()
+			if tie_break.String() != test.tie_break {
+				t.Errorf("expected the tie break of the literal to be %q, got %q", test.tie_break, tie_break.String())
+			}
+		})
+	}
+
+	err := new_tie_grammar(t).SetTieBreak(TtEof, ByDeclarationOrder())
+	if err == nil {
+		t.Errorf("expected an error for a type without rules")
+	}
+}
@@ -0,0 +1,137 @@
+package Parser
+
+import (
+	"errors"
+	"slices"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// Checkpoint is the state of an evaluation just after a shift. Since the
+// decisions of the parser only depend on the stack and on the lookahead of
+// its top, a parse can resume from a checkpoint as long as the tokens up to
+// the lookahead are the same.
+type Checkpoint[T gr.TokenTyper] struct {
+	// Stack is the stack after the shift. Its top is the shifted token.
+	Stack *gr.TokenStack[T]
+
+	// Index is the index, in the input stream, of the lookahead of the
+	// shifted token. The tokens from 0 to Index (included) must not change
+	// for the checkpoint to stay valid.
+	Index int
+}
+
+// checkpoint_list is a persistent list of checkpoints. Like stack_history,
+// it is shared between the copies of an evaluation.
+type checkpoint_list[T gr.TokenTyper] struct {
+	// cp is the last checkpoint.
+	cp *Checkpoint[T]
+
+	// prev are the checkpoints before this one.
+	prev *checkpoint_list[T]
+}
+
+// add_checkpoint is a helper method that records the current state as a
+// checkpoint. Does nothing if checkpoints are disabled.
+func (ce *CurrentEval[T]) add_checkpoint() {
+	if !ce.keep_checkpoints {
+		return
+	}
+
+	ce.checkpoints = &checkpoint_list[T]{
+		cp: &Checkpoint[T]{
+			Stack: ce.stack,
+			Index: ce.current_index,
+		},
+		prev: ce.checkpoints,
+	}
+}
+
+// GetCheckpoints returns the checkpoints of the evaluation. Only available
+// when checkpoints are enabled.
+//
+// Returns:
+//   - []*Checkpoint: The checkpoints, from the first shift to the last one.
+func (ce *CurrentEval[T]) GetCheckpoints() []*Checkpoint[T] {
+	var cps []*Checkpoint[T]
+
+	for c := ce.checkpoints; c != nil; c = c.prev {
+		cps = append(cps, c.cp)
+	}
+
+	slices.Reverse(cps)
+
+	return cps
+}
+
+// SetCheckpoints enables or disables the checkpoints. When they are enabled,
+// every evaluation records a checkpoint after each shift; so that, after an
+// edit of the input, ParseFrom can resume from the last one before the edit.
+//
+// Parameters:
+//   - keep: True to enable the checkpoints, false to disable them.
+func (p *Parser[T]) SetCheckpoints(keep bool) {
+	p.checkpoints = keep
+}
+
+// ParseFrom parses the input stream, resuming from a checkpoint of a
+// previous parse instead of from the start.
+//
+// Parameters:
+//   - p: The parser to use.
+//   - source: The input stream to parse.
+//   - checkpoints: The checkpoints of the previous parse that are still
+//     valid, from the first to the last one. The parse resumes from the last
+//     one. Usually, a prefix of the result of GetCheckpoints.
+//
+// Returns:
+//   - error: An error if the input stream could not be parsed.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If p is nil or the last checkpoint is past
+//     the end of the input stream.
+//   - any error of Parse.
+//
+// Behaviors:
+//   - Without checkpoints, this is the same as Parse.
+//   - When checkpoints are enabled, the evaluations start with the given
+//     checkpoints, so they can be used for the next edit as well.
+func ParseFrom[T gr.TokenTyper](p *Parser[T], source *cds.Stream[*gr.Token[T]], checkpoints []*Checkpoint[T]) error {
+	if len(checkpoints) == 0 {
+		return Parse(p, source)
+	}
+
+	if p == nil {
+		return uc.NewErrNilParameter("parser")
+	}
+
+	if p.dt == nil {
+		return errors.New("no grammar was set")
+	}
+
+	last := checkpoints[len(checkpoints)-1]
+
+	if source == nil || last.Index >= source.Size() {
+		return uc.NewErrInvalidParameter(
+			"checkpoints",
+			errors.New("the last checkpoint is past the end of the input stream"),
+		)
+	}
+
+	ce_root := p.new_root()
+	ce_root.stack = last.Stack
+	ce_root.current_index = last.Index
+
+	if ce_root.keep_checkpoints {
+		for _, cp := range checkpoints {
+			ce_root.checkpoints = &checkpoint_list[T]{
+				cp:   cp,
+				prev: ce_root.checkpoints,
+			}
+		}
+	}
+
+	return p.run(source, ce_root)
+}
@@ -0,0 +1,95 @@
+package Parser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseFrom(t *testing.T) {
+	p := new_test_parser(t)
+	p.SetCheckpoints(true)
+	p.SetTracing(true)
+
+	parse_all(t, p, "a,b,c")
+
+	evals, err := p.GetEvals()
+	if err != nil {
+		t.Fatalf("GetEvals failed: %s", err.Error())
+	}
+
+	checkpoints := evals[0].GetCheckpoints()
+
+	// A checkpoint after each shift, the EOF included.
+	if len(checkpoints) != 6 {
+		t.Fatalf("expected 6 checkpoints, got %d", len(checkpoints))
+	}
+
+	for i, cp := range checkpoints {
+		if cp.Index != i+1 || cp.Stack.Size() == 0 {
+			t.Errorf("checkpoint %d: expected the index %d, got %d", i, i+1, cp.Index)
+		}
+	}
+
+	// "c" is the token at 4; the checkpoints whose tokens end before it are
+	// still valid.
+	valid := checkpoints[:3]
+
+	expected := parse_all(t, p, "a,b,d")
+
+	traces, _ := p.GetTraces()
+	full := len(traces[0])
+
+	err = ParseFrom(p, new_test_source("a,b,d"), valid)
+	if err != nil {
+		t.Fatalf("ParseFrom failed: %s", err.Error())
+	}
+
+	evals, _ = p.GetEvals()
+
+	var got []string
+
+	for _, eval := range evals {
+		root, _ := eval.GetStack().Peek()
+		got = append(got, dump(root))
+	}
+
+	// The choices made for "a,b" are kept, so only the parses that share
+	// them are found.
+	if len(got) == 0 || len(got) > len(expected) {
+		t.Fatalf("expected at most %d parses, got %v", len(expected), got)
+	}
+
+	for _, tree := range got {
+		if !slices.Contains(expected, tree) {
+			t.Errorf("expected %s to be a parse of the whole input %v", tree, expected)
+		}
+	}
+
+	// The decisions before the checkpoint are not made again.
+	traces, _ = p.GetTraces()
+	if len(traces[0]) >= full {
+		t.Errorf("expected fewer than %d steps, got %d", full, len(traces[0]))
+	}
+
+	// The checkpoints of the resumed parse start with the given ones.
+	resumed := evals[0].GetCheckpoints()
+	if len(resumed) != len(checkpoints) || !slices.Equal(resumed[:3], valid) {
+		t.Errorf("expected the checkpoints to be kept, got %d", len(resumed))
+	}
+
+	err = ParseFrom(p, new_test_source("a"), valid)
+	if err == nil {
+		t.Errorf("expected an error for a checkpoint past the end of the input")
+	}
+
+	// Without checkpoints, it is a full parse.
+	err = ParseFrom(p, new_test_source("a,b,d"), nil)
+	if err != nil {
+		t.Fatalf("ParseFrom failed: %s", err.Error())
+	}
+
+	evals, _ = p.GetEvals()
+	if len(evals) != len(expected) {
+		t.Errorf("expected %d parses, got %d", len(expected), len(evals))
+	}
+}
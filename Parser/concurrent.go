@@ -0,0 +1,339 @@
+package Parser
+
+import (
+	"slices"
+	"sync"
+
+	cs "github.com/PlayerR9/LyneParser/ConflictSolver"
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
+	us "github.com/PlayerR9/MyGoLib/Units/slice"
+)
+
+// branch is a CurrentEval together with the decisions that led to it.
+type branch[T gr.TokenTyper] struct {
+	// eval is the evaluation of the branch.
+	eval *CurrentEval[T]
+
+	// path is the index of the chosen alternative at every fork, from the
+	// root to the branch. It gives a total order on the branches that does not
+	// depend on the scheduling of the goroutines.
+	path []int
+
+	// depth is the number of steps taken by the branch.
+	depth float64
+}
+
+// fork is a helper function that creates the i-th child of the branch.
+//
+// Parameters:
+//   - eval: The evaluation of the child.
+//   - i: The index of the alternative that the child took.
+//
+// Returns:
+//   - *branch: The child. Never nil.
+func (b *branch[T]) fork(eval *CurrentEval[T], i int) *branch[T] {
+	path := make([]int, len(b.path), len(b.path)+1)
+	copy(path, b.path)
+
+	child := &branch[T]{
+		eval:  eval,
+		path:  append(path, i),
+		depth: b.depth + 1.0,
+	}
+
+	return child
+}
+
+// compare_paths compares two branch paths lexicographically. A path that is
+// a prefix of another one comes first.
+//
+// Parameters:
+//   - a: The first path.
+//   - b: The second path.
+//
+// Returns:
+//   - int: -1 if a < b, 0 if a == b, 1 if a > b.
+func compare_paths(a, b []int) int {
+	return slices.Compare(a, b)
+}
+
+// branch_result is the outcome of a branch that stopped.
+type branch_result[T gr.TokenTyper] struct {
+	// branch is the branch that stopped.
+	branch *branch[T]
+
+	// reason is nil if the branch accepted, the reason it failed otherwise.
+	reason error
+}
+
+// is_complete is a helper method that checks whether the branch accepted the
+// input stream with only the root of the parse tree left on the stack. Only
+// those parses count towards max_accepted, since an acceptance that leaves
+// other tokens on the stack is always outweighed by a complete one.
+//
+// Returns:
+//   - bool: True if the branch is a complete parse, false otherwise.
+func (res *branch_result[T]) is_complete() bool {
+	return res.reason == nil && res.branch.eval.stack.Size() == 1
+}
+
+// branch_pool is the shared state of the workers of evaluate_concurrent.
+type branch_pool[T gr.TokenTyper] struct {
+	// mu protects every field below.
+	mu sync.Mutex
+
+	// cond is signaled whenever a branch is queued or a branch is done.
+	cond *sync.Cond
+
+	// queue is the list of branches that are waiting for a worker.
+	queue []*branch[T]
+
+	// pending is the number of branches that are either queued or being
+	// evaluated by a worker.
+	pending int
+
+	// accepted are the paths of the accepted branches, sorted. At most
+	// max_accepted paths are kept.
+	accepted [][]int
+
+	// max_accepted is the number of accepted parses after which the
+	// evaluation stops. 0 means no limit.
+	max_accepted int
+
+	// results are the outcomes of the branches that stopped.
+	results []*branch_result[T]
+}
+
+// push is a helper method that queues branches.
+//
+// Parameters:
+//   - branches: The branches to queue.
+func (bp *branch_pool[T]) push(branches ...*branch[T]) {
+	if len(branches) == 0 {
+		return
+	}
+
+	bp.mu.Lock()
+	bp.queue = append(bp.queue, branches...)
+	bp.pending += len(branches)
+	bp.mu.Unlock()
+
+	bp.cond.Broadcast()
+}
+
+// pop is a helper method that waits for a queued branch.
+//
+// Returns:
+//   - *branch: The branch. Nil if there is nothing left to evaluate.
+func (bp *branch_pool[T]) pop() *branch[T] {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	for len(bp.queue) == 0 && bp.pending > 0 {
+		bp.cond.Wait()
+	}
+
+	if len(bp.queue) == 0 {
+		return nil
+	}
+
+	b := bp.queue[len(bp.queue)-1]
+	bp.queue = bp.queue[:len(bp.queue)-1]
+
+	return b
+}
+
+// done is a helper method that marks a branch, previously obtained with pop,
+// as finished.
+//
+// Parameters:
+//   - result: The outcome of the branch. Nil if the branch was pruned.
+func (bp *branch_pool[T]) done(result *branch_result[T]) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	if result != nil {
+		bp.results = append(bp.results, result)
+
+		if result.is_complete() && bp.max_accepted > 0 {
+			pos, _ := slices.BinarySearchFunc(bp.accepted, result.branch.path, compare_paths)
+			bp.accepted = slices.Insert(bp.accepted, pos, result.branch.path)
+
+			if len(bp.accepted) > bp.max_accepted {
+				bp.accepted = bp.accepted[:bp.max_accepted]
+			}
+		}
+	}
+
+	bp.pending--
+
+	bp.cond.Broadcast()
+}
+
+// is_pruned is a helper method that checks whether a branch can no longer
+// produce one of the first max_accepted accepted parses.
+//
+// Parameters:
+//   - b: The branch to check.
+//
+// Returns:
+//   - bool: True if the branch can be cancelled, false otherwise.
+//
+// Behaviors:
+//   - All the descendants of a branch have the path of the branch as prefix.
+//     Thus, once max_accepted parses have been accepted, every branch that comes
+//     after the last of them can be cancelled.
+func (bp *branch_pool[T]) is_pruned(b *branch[T]) bool {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	if bp.max_accepted <= 0 || len(bp.accepted) < bp.max_accepted {
+		return false
+	}
+
+	bound := bp.accepted[len(bp.accepted)-1]
+
+	return compare_paths(b.path, bound) > 0
+}
+
+// run is a helper method that evaluates a branch until it stops. The first
+// alternative of every fork is kept by the worker while the others are queued
+// for any idle worker.
+//
+// Parameters:
+//   - dt: The decision table to use. It is only read.
+//   - source: The input stream. It is only read.
+//   - b: The branch to evaluate.
+//
+// Returns:
+//   - *branch_result: The outcome of the branch. Nil if the branch was pruned.
+func (bp *branch_pool[T]) run(dt *cs.ConflictSolver[T], source *cds.Stream[*gr.Token[T]], b *branch[T]) *branch_result[T] {
+	for {
+		if b.eval.Accept() {
+			return &branch_result[T]{branch: b}
+		}
+
+		if bp.is_pruned(b) {
+			b.eval.fire_killed(NewErrBranchPruned())
+
+			return nil
+		}
+
+		nexts, err := b.eval.Parse(source, dt)
+		if err != nil {
+			return &branch_result[T]{branch: b, reason: err}
+		}
+
+		switch len(nexts) {
+		case 0:
+			return &branch_result[T]{branch: b, reason: NewErrNoAccept()}
+		case 1:
+			b.eval = nexts[0]
+			b.depth++
+		default:
+			children := make([]*branch[T], 0, len(nexts)-1)
+
+			for i, next := range nexts[1:] {
+				children = append(children, b.fork(next, i+1))
+			}
+
+			bp.push(children...)
+
+			b = b.fork(nexts[0], 0)
+		}
+	}
+}
+
+// evaluate_concurrent is the concurrent counterpart of evaluate. It spreads
+// the branches of the evaluation across a bounded pool of goroutines.
+//
+// Parameters:
+//   - dt: The decision table to use. It is shared between the workers and
+//     is never modified.
+//   - source: The input stream.
+//   - elem: The root evaluation.
+//   - workers: The number of goroutines to use. Must be positive.
+//   - max_accepted: The number of accepted parses after which the remaining
+//     branches are cancelled. 0 means no limit.
+//
+// Returns:
+//   - []*us.WeightedHelper: The outcome of every branch that stopped.
+//
+// Behaviors:
+//   - The results are ordered by the decisions taken at each fork; that is,
+//     the same input always gives the same results in the same order,
+//     regardless of the number of workers.
+//   - When max_accepted is reached, only the first max_accepted accepted
+//     parses, in the above order, are returned.
+func evaluate_concurrent[T gr.TokenTyper](dt *cs.ConflictSolver[T], source *cds.Stream[*gr.Token[T]], elem *CurrentEval[T], workers, max_accepted int) []*us.WeightedHelper[*CurrentEval[T]] {
+	bp := &branch_pool[T]{
+		max_accepted: max_accepted,
+	}
+	bp.cond = sync.NewCond(&bp.mu)
+
+	bp.push(&branch[T]{eval: elem})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				b := bp.pop()
+				if b == nil {
+					return
+				}
+
+				res := bp.run(dt, source, b)
+				bp.done(res)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return branch_solutions(bp.results, max_accepted)
+}
+
+// branch_solutions is a helper function that sorts the outcomes of the
+// branches by the decisions taken at each fork and converts them.
+//
+// Parameters:
+//   - results: The outcomes of the branches. They are sorted in place.
+//   - max_accepted: The number of complete parses to keep. 0 means no limit.
+//
+// Returns:
+//   - []*us.WeightedHelper: The outcomes, in order.
+//
+// Behaviors:
+//   - When max_accepted is reached, every outcome that comes after the last
+//     kept complete parse is dropped. Those branches may or may not have run
+//     before being cancelled, depending on the scheduling of the goroutines;
+//     dropping them makes the results deterministic.
+func branch_solutions[T gr.TokenTyper](results []*branch_result[T], max_accepted int) []*us.WeightedHelper[*CurrentEval[T]] {
+	slices.SortFunc(results, func(a, b *branch_result[T]) int {
+		return compare_paths(a.branch.path, b.branch.path)
+	})
+
+	var sols []*us.WeightedHelper[*CurrentEval[T]]
+	var count int
+
+	for _, res := range results {
+		if max_accepted > 0 && count >= max_accepted {
+			break
+		}
+
+		if res.is_complete() {
+			count++
+		}
+
+		h := us.NewWeightedHelper(res.branch.eval, res.reason, res.branch.depth)
+		sols = append(sols, h)
+	}
+
+	return sols
+}
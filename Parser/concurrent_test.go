@@ -0,0 +1,61 @@
+package Parser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestConcurrentMatchesSequential(t *testing.T) {
+	const (
+		Source string = "a,b,c,d"
+	)
+
+	p := new_test_parser(t)
+
+	sequential := parse_all(t, p, Source)
+
+	// The 5 ways of grouping 4 lists.
+	if len(sequential) != 5 {
+		t.Fatalf("expected 5 parses, got %v", sequential)
+	}
+
+	for _, workers := range []int{2, 4, 8} {
+		err := p.SetConcurrency(workers, 0)
+		if err != nil {
+			t.Fatalf("SetConcurrency failed: %s", err.Error())
+		}
+
+		for i := 0; i < 10; i++ {
+			concurrent := parse_all(t, p, Source)
+			if !slices.Equal(concurrent, sequential) {
+				t.Fatalf("%d workers: expected %v, got %v", workers, sequential, concurrent)
+			}
+		}
+	}
+}
+
+func TestMaxAccepted(t *testing.T) {
+	const (
+		Source string = "a,b,c,d"
+	)
+
+	p := new_test_parser(t)
+
+	all := parse_all(t, p, Source)
+
+	for _, workers := range []int{0, 1, 4} {
+		for _, max := range []int{1, 2, 5, 6} {
+			err := p.SetConcurrency(workers, max)
+			if err != nil {
+				t.Fatalf("SetConcurrency failed: %s", err.Error())
+			}
+
+			expected := all[:min(max, len(all))]
+
+			got := parse_all(t, p, Source)
+			if !slices.Equal(got, expected) {
+				t.Errorf("%d workers, at most %d: expected %v, got %v", workers, max, expected, got)
+			}
+		}
+	}
+}
@@ -1,27 +1,70 @@
 package Parser
 
 import (
-	"fmt"
+	"slices"
 
 	cs "github.com/PlayerR9/LyneParser/ConflictSolver"
 	gr "github.com/PlayerR9/LyneParser/Grammar"
+	tc "github.com/PlayerR9/LyneParser/Tracer"
 	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
-	ud "github.com/PlayerR9/MyGoLib/Units/Debugging"
 	uc "github.com/PlayerR9/MyGoLib/Units/common"
-	lls "github.com/PlayerR9/stack/stack"
 	tr "github.com/PlayerR9/tree/tree"
 )
 
+// stack_history is a persistent list of the previous states of a stack.
+type stack_history[T gr.TokenTyper] struct {
+	// stack is the state of the stack.
+	stack *gr.TokenStack[T]
+
+	// current_index is the index of the input stream at that state.
+	current_index int
+
+	// prev is the state before this one.
+	prev *stack_history[T]
+}
+
 // CurrentEval is a struct that represents the current evaluation of the parser.
+//
+// The stack is immutable and structurally shared. Thus, forking an evaluation
+// is O(1) regardless of the size of the stack.
 type CurrentEval[T gr.TokenTyper] struct {
 	// stack represents the stack that the parser will use.
-	stack *ud.History[lls.Stacker[*gr.Token[T]]]
+	stack *gr.TokenStack[T]
+
+	// history is the undo history of the stack. Only kept when tracing is enabled.
+	history *stack_history[T]
+
+	// steps are the steps of the evaluation. Only kept when tracing is enabled.
+	steps *trace_list[T]
+
+	// trace is a flag that represents if the undo history and the steps
+	// should be kept.
+	trace bool
 
 	// current_index is the current index of the input stream.
 	current_index int
 
 	// is_done is a flag that represents if the parser has finished parsing.
 	is_done bool
+
+	// hook is the hook that receives the events of the evaluation. May be nil.
+	hook tc.Hooker
+
+	// spellings are the texts of the terminals that have a fixed one. Used
+	// to suggest a correction when the lookahead is unexpected. May be nil.
+	spellings map[T]string
+
+	// keep_checkpoints is a flag that represents if a checkpoint should be
+	// recorded after every shift.
+	keep_checkpoints bool
+
+	// checkpoints are the checkpoints of the evaluation. Only kept when
+	// keep_checkpoints is true.
+	checkpoints *checkpoint_list[T]
+
+	// failure is the reason the evaluation could not act on the decision of
+	// its fork. Nil if it did not fail.
+	failure error
 }
 
 // Copy creates a copy of the current evaluation.
@@ -30,9 +73,17 @@ type CurrentEval[T gr.TokenTyper] struct {
 //   - uc.Copier: A copy of the current evaluation.
 func (ce *CurrentEval[T]) Copy() uc.Copier {
 	ce_copy := &CurrentEval[T]{
-		stack:         ce.stack.Copy().(*ud.History[lls.Stacker[*gr.Token[T]]]),
-		current_index: ce.current_index,
-		is_done:       ce.is_done,
+		stack:            ce.stack,
+		history:          ce.history,
+		steps:            ce.steps,
+		trace:            ce.trace,
+		current_index:    ce.current_index,
+		is_done:          ce.is_done,
+		hook:             ce.hook,
+		spellings:        ce.spellings,
+		keep_checkpoints: ce.keep_checkpoints,
+		checkpoints:      ce.checkpoints,
+		failure:          ce.failure,
 	}
 	return ce_copy
 }
@@ -47,19 +98,85 @@ func (ce *CurrentEval[T]) Accept() bool {
 
 // NewCurrentEval creates a new current evaluation.
 //
+// Parameters:
+//   - trace: Whether the undo history of the stack should be kept.
+//
 // Returns:
 //   - *CurrentEval: A new current evaluation.
-func NewCurrentEval[T gr.TokenTyper]() *CurrentEval[T] {
+func NewCurrentEval[T gr.TokenTyper](trace bool) *CurrentEval[T] {
 	ce := &CurrentEval[T]{
+		stack:         nil,
+		trace:         trace,
 		current_index: 0,
 		is_done:       false,
 	}
 
-	ce.stack = lls.NewStackWithHistory(lls.NewLinkedStack[*gr.Token[T]]())
-
 	return ce
 }
 
+// set_stack is a helper method that replaces the stack and, if tracing is
+// enabled, records the previous state in the undo history.
+//
+// Parameters:
+//   - stack: The new stack.
+func (ce *CurrentEval[T]) set_stack(stack *gr.TokenStack[T]) {
+	if ce.trace {
+		ce.history = &stack_history[T]{
+			stack:         ce.stack,
+			current_index: ce.current_index,
+			prev:          ce.history,
+		}
+	}
+
+	ce.stack = stack
+}
+
+// Undo reverts the last shift or reduce. Only works when tracing is enabled.
+//
+// Returns:
+//   - bool: True if something was undone, false otherwise.
+func (ce *CurrentEval[T]) Undo() bool {
+	if ce.history == nil {
+		return false
+	}
+
+	ce.stack = ce.history.stack
+	ce.current_index = ce.history.current_index
+	ce.history = ce.history.prev
+	ce.is_done = false
+
+	for ce.checkpoints != nil && ce.checkpoints.cp.Index > ce.current_index {
+		ce.checkpoints = ce.checkpoints.prev
+	}
+
+	return true
+}
+
+// GetHistory returns the previous states of the stack, from the oldest to the
+// most recent one. Only available when tracing is enabled.
+//
+// Returns:
+//   - []*gr.TokenStack: The previous states of the stack.
+func (ce *CurrentEval[T]) GetHistory() []*gr.TokenStack[T] {
+	var states []*gr.TokenStack[T]
+
+	for h := ce.history; h != nil; h = h.prev {
+		states = append(states, h.stack)
+	}
+
+	slices.Reverse(states)
+
+	return states
+}
+
+// GetStack returns the current stack.
+//
+// Returns:
+//   - *gr.TokenStack: The current stack. Nil if the stack is empty.
+func (ce *CurrentEval[T]) GetStack() *gr.TokenStack[T] {
+	return ce.stack
+}
+
 // GetParseTree returns the parse tree that the parser has generated.
 //
 // Parse() must be called before calling this method. If it is not, an error will
@@ -77,13 +194,13 @@ func NewCurrentEval[T gr.TokenTyper]() *CurrentEval[T] {
 func (ce *CurrentEval[T]) GetParseTree() ([]*gr.TokenTree, error) {
 	var forest []*gr.TokenTree
 
+	iter := ce.stack.Iterator()
+
 	for {
-		cmd := lls.NewPop[*gr.Token[T]]()
-		err := ce.stack.ExecuteCommand(cmd)
+		top, err := iter.Consume()
 		if err != nil {
 			break
 		}
-		top := cmd.Value()
 
 		tn := tr.NewTreeNode(top)
 
@@ -108,14 +225,12 @@ func (ce *CurrentEval[T]) shift(source *cds.Stream[*gr.Token[T]]) error {
 		return NewErrNoAccept()
 	}
 
-	cmd := lls.NewPush(toks[0])
-	err = ce.stack.ExecuteCommand(cmd)
-	if err != nil {
-		return fmt.Errorf("could not push token: %s", err.Error())
-	}
+	ce.set_stack(ce.stack.Push(toks[0]))
 
 	ce.current_index++
 
+	ce.add_checkpoint()
+
 	return nil
 }
 
@@ -133,19 +248,18 @@ func (ce *CurrentEval[T]) reduce(rule *gr.Production[T]) error {
 	var lookahead *gr.Token[T]
 	var popped []*gr.Token[T]
 
+	stack := ce.stack
+
 	for {
 		value, err := rhss.Consume()
 		if err != nil {
 			break
 		}
 
-		cmd := lls.NewPop[*gr.Token[T]]()
-		err = ce.stack.ExecuteCommand(cmd)
-		if err != nil {
-			ce.stack.Reject()
+		top, rest, ok := stack.Pop()
+		if !ok {
 			return uc.NewErrAfter(lhs.String(), uc.NewErrUnexpected("", value.String()))
 		}
-		top := cmd.Value()
 
 		popped = append(popped, top)
 
@@ -155,21 +269,49 @@ func (ce *CurrentEval[T]) reduce(rule *gr.Production[T]) error {
 
 		id := top.GetID()
 		if id != value {
-			ce.stack.Reject()
 			return uc.NewErrAfter(lhs.String(), uc.NewErrUnexpected(top.GoString(), value.String()))
 		}
-	}
 
-	ce.stack.Accept()
+		stack = rest
+	}
 
-	tok := gr.NewToken(lhs, popped, 0, lookahead)
+	tok := reduced_token(lhs, popped, lookahead)
 
-	cmd := lls.NewPush(tok)
-	ce.stack.ExecuteCommand(cmd)
+	ce.set_stack(stack.Push(tok))
 
 	return nil
 }
 
+// reduced_token is a helper function that creates the token that replaces the
+// right-hand side of a rule on the stack.
+//
+// Parameters:
+//   - lhs: The left-hand side of the rule.
+//   - popped: The tokens of the right-hand side, in the order they were
+//     popped; that is, from the top of the stack down. It is reversed.
+//   - lookahead: The lookahead of the new token.
+//
+// Returns:
+//   - *gr.Token: The new token. Never nil.
+//
+// Behaviors:
+//   - The children are in the order of the input, like the symbols of the
+//     rule, so that the parse tree reads from left to right.
+//   - The position of the token is the one of its first child, so that a
+//     node of the parse tree points at the source it covers. It is 0 if the
+//     rule has no symbols.
+func reduced_token[T gr.TokenTyper](lhs T, popped []*gr.Token[T], lookahead *gr.Token[T]) *gr.Token[T] {
+	slices.Reverse(popped)
+
+	var at int
+
+	if len(popped) > 0 {
+		at = popped[0].GetPos()
+	}
+
+	return gr.NewToken(lhs, popped, at, lookahead)
+}
+
 // ActOnDecision acts on a decision that the parser has made.
 //
 // Parameters:
@@ -200,9 +342,102 @@ func (ce *CurrentEval[T]) ActOnDecision(decision cs.HelperElem[T], source *cds.S
 		return err
 	}
 
+	if ce.hook != nil {
+		ce.fire_decision(decision)
+	}
+
 	return nil
 }
 
+// fire_decision is a helper method that fires the event of a decision that
+// was successfully acted upon.
+//
+// Parameters:
+//   - decision: The decision.
+func (ce *CurrentEval[T]) fire_decision(decision cs.HelperElem[T]) {
+	top, _ := ce.stack.Peek()
+
+	switch decision := decision.(type) {
+	case *cs.ActShift[T]:
+		tc.Fire(ce.hook, &tc.ShiftEvent{
+			Type: top.GetID().String(),
+			At:   top.GetPos(),
+		})
+	case *cs.ActReduce[T]:
+		tc.Fire(ce.hook, &tc.ReduceEvent{
+			Rule: decision.Original.String(),
+			At:   top.GetPos(),
+		})
+	case *cs.ActAccept[T]:
+		tc.Fire(ce.hook, &tc.ReduceEvent{
+			Rule: decision.Original.String(),
+			At:   top.GetPos(),
+		})
+
+		tc.Fire(ce.hook, &tc.AcceptEvent{
+			Rule: decision.Original.String(),
+		})
+	}
+}
+
+// fire_killed is a helper method that fires a branch killed event.
+//
+// Parameters:
+//   - reason: The reason the branch was killed.
+func (ce *CurrentEval[T]) fire_killed(reason error) {
+	tc.Fire(ce.hook, &tc.BranchKilledEvent{
+		Origin: tc.FromParser,
+		At:     ce.current_index,
+		Reason: reason,
+	})
+}
+
+// unexpected_token is a helper method that explains why the decision table
+// did not match the stack, with the terminals that were expected and, if the
+// lookahead looks like a misspelling of one of them, a suggestion.
+//
+// Parameters:
+//   - dt: The decision table.
+//   - reason: The reason the decision table did not match.
+//
+// Returns:
+//   - error: An error of type *ErrUnexpectedToken. The reason itself if
+//     there is no lookahead or no terminal was expected.
+func (ce *CurrentEval[T]) unexpected_token(dt *cs.ConflictSolver[T], reason error) error {
+	top, ok := ce.stack.Peek()
+	if !ok {
+		return reason
+	}
+
+	la := top.GetLookahead()
+	if la == nil {
+		return reason
+	}
+
+	expected := dt.ExpectedLookaheads(ce.stack)
+	if len(expected) == 0 {
+		return reason
+	}
+
+	names := make([]string, 0, len(expected))
+	var candidates []string
+
+	for _, id := range expected {
+		names = append(names, id.String())
+
+		spelling, ok := ce.spellings[id]
+		if ok {
+			candidates = append(candidates, spelling)
+		}
+	}
+
+	data, _ := la.GetData().(string)
+
+	suggestion, _ := gr.Closest(data, candidates)
+
+	return NewErrUnexpectedToken(la.GetID().String(), data, la.GetPos(), names, suggestion, reason)
+}
+
 // Parse parses the input stream using the parser's decision table.
 //
 // Parameters:
@@ -212,35 +447,85 @@ func (ce *CurrentEval[T]) ActOnDecision(decision cs.HelperElem[T], source *cds.S
 // Returns:
 //   - []*CurrentEval: A slice of current evaluations.
 //   - error: An error if the input stream could not be parsed.
+//
+// Behaviors:
+//   - When the stack matches more than one decision, a copy is returned for
+//     each of them, in order; even the copies that could not act on their
+//     decision, so that their trace is kept. Parsing such a copy returns the
+//     reason it failed.
 func (ce *CurrentEval[T]) Parse(source *cds.Stream[*gr.Token[T]], dt *cs.ConflictSolver[T]) ([]*CurrentEval[T], error) {
-	decisions, err := dt.Match(ce.stack)
-	ce.stack.Reject()
+	if ce.failure != nil {
+		return nil, ce.failure
+	}
+
+	var decisions []cs.HelperElem[T]
+	var rejected []*cs.Rejection[T]
+	var err error
+
+	if ce.trace {
+		decisions, rejected, err = dt.MatchWithRejections(ce.stack)
+	} else {
+		decisions, err = dt.Match(ce.stack)
+	}
+
+	if err != nil {
+		err = ce.unexpected_token(dt, err)
+	} else if len(decisions) == 0 {
+		err = NewErrNoAccept()
+	}
 
 	if err != nil {
+		ce.add_step(ce.new_step(source, nil, nil, rejected, err))
+		ce.fire_killed(err)
+
 		return nil, err
 	}
 
 	switch len(decisions) {
-	case 0:
-		return nil, NewErrNoAccept()
 	case 1:
+		step := ce.new_step(source, decisions[0], nil, rejected, nil)
+
 		err := ce.ActOnDecision(decisions[0], source)
 		if err != nil {
+			if step != nil {
+				step.Err = err
+				ce.add_step(step)
+			}
+
+			ce.fire_killed(err)
+
 			return nil, err
 		}
 
+		ce.add_step(step)
+
 		return []*CurrentEval[T]{ce}, nil
 	default:
+		tc.Fire(ce.hook, &tc.BranchForkedEvent{
+			Origin: tc.FromParser,
+			At:     ce.current_index,
+			Count:  len(decisions),
+		})
+
 		ce_copies := make([]*CurrentEval[T], 0, len(decisions))
 
-		for _, decision := range decisions {
+		for i, decision := range decisions {
 			ce_copy := ce.Copy().(*CurrentEval[T])
 
+			step := ce.new_step(source, decision, without(decisions, i), rejected, nil)
+
 			err := ce_copy.ActOnDecision(decision, source)
 			if err != nil {
-				continue
+				if step != nil {
+					step.Err = err
+				}
+
+				ce_copy.failure = err
+				ce_copy.fire_killed(err)
 			}
 
+			ce_copy.add_step(step)
+
 			ce_copies = append(ce_copies, ce_copy)
 		}
 
@@ -0,0 +1,52 @@
+package Parser
+
+import (
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+func TestReduce(t *testing.T) {
+	eof := gr.NewToken(TtEof, "", 7, nil)
+	word2 := gr.NewToken(TtWord, "b", 6, eof)
+	comma := gr.NewToken(TtComma, ",", 5, word2)
+	word1 := gr.NewToken(TtWord, "a", 4, comma)
+
+	ce := NewCurrentEval[TestTokenType](false)
+
+	for _, tok := range []*gr.Token[TestTokenType]{word1, comma, word2} {
+		ce.stack = ce.stack.Push(tok)
+	}
+
+	err := ce.reduce(gr.NewProduction(TtList, []TestTokenType{TtWord, TtComma, TtWord}))
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	top, ok := ce.stack.Peek()
+	if !ok || ce.stack.Size() != 1 || top.ID != TtList {
+		t.Fatalf("expected only a list on the stack, got %v", ce.stack.Slice())
+	}
+
+	children, _ := top.Data.([]*gr.Token[TestTokenType])
+	if len(children) != 3 || children[0] != word1 || children[1] != comma || children[2] != word2 {
+		t.Errorf("expected the children in the order of the input, got %v", children)
+	}
+
+	if top.At != word1.At {
+		t.Errorf("expected the position of the first child %d, got %d", word1.At, top.At)
+	}
+
+	if top.Lookahead != eof {
+		t.Errorf("expected the lookahead of the last child, got %v", top.Lookahead)
+	}
+
+	err = ce.reduce(gr.NewProduction(TtList, []TestTokenType{TtWord}))
+	if err == nil {
+		t.Errorf("expected an error when the stack does not match the rule")
+	}
+
+	if ce.stack.Size() != 1 {
+		t.Errorf("expected a failed reduction to leave the stack as is")
+	}
+}
@@ -1,6 +1,10 @@
 package Parser
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // ErrNoAccept is an error that is returned when the parser reaches the end of the
 // input stream without accepting the input stream.
@@ -56,3 +60,116 @@ func NewErrUnknownAction(action any) *ErrUnknownAction {
 
 	return e
 }
+
+// ErrBranchPruned is an error that is returned when a branch is cancelled
+// because enough parses have already been accepted.
+type ErrBranchPruned struct{}
+
+// Error is a method of the error interface.
+//
+// Returns:
+//   - string: The error message.
+func (e *ErrBranchPruned) Error() string {
+	return "branch cancelled: enough parses were accepted"
+}
+
+// NewErrBranchPruned creates a new ErrBranchPruned error.
+//
+// Returns:
+//   - *ErrBranchPruned: A pointer to the new ErrBranchPruned error.
+func NewErrBranchPruned() *ErrBranchPruned {
+	e := &ErrBranchPruned{}
+	return e
+}
+
+// ErrUnexpectedToken is an error that is returned when the lookahead of the
+// parser is not one of the terminals the grammar allows at that point.
+type ErrUnexpectedToken struct {
+	// Got is the type of the unexpected token.
+	Got string
+
+	// Data is the text of the unexpected token.
+	Data string
+
+	// At is the position of the unexpected token.
+	At int
+
+	// Expected are the types of the terminals that were expected.
+	Expected []string
+
+	// Suggestion is the spelling of the expected terminal that is the
+	// closest to Data. Empty if there is none.
+	Suggestion string
+
+	// Reason is the reason the decision table did not match.
+	Reason error
+}
+
+// Error is a method of the error interface.
+//
+// Returns:
+//   - string: The error message.
+//
+// Format:
+//
+//	unexpected <got> <data> at <at>, expected one of <expected>; did you mean <suggestion>?
+func (e *ErrUnexpectedToken) Error() string {
+	var builder strings.Builder
+
+	builder.WriteString("unexpected ")
+	builder.WriteString(e.Got)
+
+	if e.Data != "" {
+		builder.WriteRune(' ')
+		builder.WriteString(strconv.Quote(e.Data))
+	}
+
+	builder.WriteString(" at ")
+	builder.WriteString(strconv.Itoa(e.At))
+
+	if len(e.Expected) > 0 {
+		builder.WriteString(", expected one of ")
+		builder.WriteString(strings.Join(e.Expected, ", "))
+	}
+
+	if e.Suggestion != "" {
+		builder.WriteString("; did you mean ")
+		builder.WriteString(strconv.Quote(e.Suggestion))
+		builder.WriteRune('?')
+	}
+
+	return builder.String()
+}
+
+// Unwrap returns the reason the decision table did not match.
+//
+// Returns:
+//   - error: The reason.
+func (e *ErrUnexpectedToken) Unwrap() error {
+	return e.Reason
+}
+
+// NewErrUnexpectedToken creates a new ErrUnexpectedToken error.
+//
+// Parameters:
+//   - got: The type of the unexpected token.
+//   - data: The text of the unexpected token.
+//   - at: The position of the unexpected token.
+//   - expected: The types of the terminals that were expected.
+//   - suggestion: The closest spelling. Empty if there is none.
+//   - reason: The reason the decision table did not match.
+//
+// Returns:
+//   - *ErrUnexpectedToken: A pointer to the new ErrUnexpectedToken error.
+func NewErrUnexpectedToken(got, data string, at int, expected []string, suggestion string, reason error) *ErrUnexpectedToken {
+	e := &ErrUnexpectedToken{
+		Got:        got,
+		Data:       data,
+		At:         at,
+		Expected:   expected,
+		Suggestion: suggestion,
+		Reason:     reason,
+	}
+
+	return e
+}
@@ -0,0 +1,193 @@
+package Parser
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
+)
+
+type StmtTokenType int
+
+const (
+	StEof StmtTokenType = iota
+	StWord
+	StEq
+	StEnd
+	StSource
+	StStmt
+)
+
+func (t StmtTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"WORD",
+		"EQ",
+		"END",
+		gr.StartSymbolID,
+		"stmt",
+	}[t]
+}
+
+func (t StmtTokenType) IsTerminal() bool {
+	return t <= StEnd
+}
+
+// new_stmt_parser creates a parser of the grammar:
+//
+//	source -> stmt EOF
+//	stmt -> WORD EQ WORD END
+//
+// where EQ is spelled "=" and END is spelled "end".
+func new_stmt_parser(t *testing.T) *Parser[StmtTokenType] {
+	grammar, err := NewGrammar[StmtTokenType]()
+	if err != nil {
+		t.Fatalf("NewGrammar failed: %s", err.Error())
+	}
+
+	rules := []struct {
+		lhs StmtTokenType
+		rhs []StmtTokenType
+	}{
+		{StSource, []StmtTokenType{StStmt, StEof}},
+		{StStmt, []StmtTokenType{StWord, StEq, StWord, StEnd}},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.rhs)
+		if err != nil {
+			t.Fatalf("AddRule failed: %s", err.Error())
+		}
+	}
+
+	p, err := NewParser(grammar)
+	if err != nil {
+		t.Fatalf("NewParser failed: %s", err.Error())
+	}
+
+	p.SetSpellings(map[StmtTokenType]string{
+		StEq:  "=",
+		StEnd: "end",
+	})
+
+	return p
+}
+
+// new_stmt_source lexes a source like "x = y end": a token per word, where
+// "=" is an EQ, "end" is an END and anything else is a WORD. An EOF is
+// added at the end.
+func new_stmt_source(source string) *cds.Stream[*gr.Token[StmtTokenType]] {
+	var tokens []*gr.Token[StmtTokenType]
+
+	at := 0
+
+	for _, word := range strings.Fields(source) {
+		id := StWord
+
+		switch word {
+		case "=":
+			id = StEq
+		case "end":
+			id = StEnd
+		}
+
+		at = strings.Index(source[at:], word) + at
+
+		tokens = append(tokens, gr.NewToken(id, word, at, nil))
+
+		at += len(word)
+	}
+
+	tokens = append(tokens, gr.NewToken(StEof, "", len(source), nil))
+
+	for i := 0; i < len(tokens)-1; i++ {
+		tokens[i].SetLookahead(tokens[i+1])
+	}
+
+	return cds.NewStream(tokens)
+}
+
+func TestErrUnexpectedToken(t *testing.T) {
+	p := new_stmt_parser(t)
+
+	tests := []struct {
+		source     string
+		got        string
+		at         int
+		expected   []string
+		suggestion string
+	}{
+		{"x = y ends", "WORD", 6, []string{"EQ", "END"}, "end"},
+		{"x y end", "WORD", 2, []string{"EQ", "END"}, "="},
+		{"x = = end", "EQ", 4, []string{"WORD"}, ""},
+		{"x = y end z", "WORD", 10, []string{gr.EOFTokenID}, ""},
+	}
+
+	for _, test := range tests {
+		err := Parse(p, new_stmt_source(test.source))
+
+		var target *ErrUnexpectedToken
+
+		if !errors.As(err, &target) {
+			t.Errorf("%q: expected *ErrUnexpectedToken, got %v", test.source, err)
+			continue
+		}
+
+		if target.Got != test.got || target.At != test.at {
+			t.Errorf("%q: expected %s at %d, got %s at %d", test.source, test.got, test.at, target.Got, target.At)
+		}
+
+		if !slices.Equal(target.Expected, test.expected) {
+			t.Errorf("%q: expected %v to be expected, got %v", test.source, test.expected, target.Expected)
+		}
+
+		if target.Suggestion != test.suggestion {
+			t.Errorf("%q: expected the suggestion %q, got %q", test.source, test.suggestion, target.Suggestion)
+		}
+	}
+
+	err := Parse(p, new_stmt_source("x = y ends"))
+	if err.Error() != `unexpected WORD "ends" at 6, expected one of EQ, END; did you mean "end"?` {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+
+	// Without the spellings, nothing is suggested.
+	p.SetSpellings(nil)
+
+	err = Parse(p, new_stmt_source("x = y ends"))
+
+	var target *ErrUnexpectedToken
+
+	if !errors.As(err, &target) || target.Suggestion != "" {
+		t.Errorf("expected no suggestion, got %v", err)
+	}
+}
+
+func TestExpectedLookaheads(t *testing.T) {
+	p := new_stmt_parser(t)
+
+	source := new_stmt_source("x = y end").GetItems()
+
+	var stack *gr.TokenStack[StmtTokenType]
+
+	tests := []struct {
+		top      int
+		expected []StmtTokenType
+	}{
+		{0, []StmtTokenType{StEq, StEnd}},
+		{1, []StmtTokenType{StWord}},
+		{2, []StmtTokenType{StEq, StEnd}},
+	}
+
+	for _, test := range tests {
+		stack = stack.Push(source[test.top])
+
+		got := p.dt.ExpectedLookaheads(stack)
+		if !slices.Equal(got, test.expected) {
+			t.Errorf("%s on top: expected %v, got %v", source[test.top].ID, test.expected, got)
+		}
+	}
+}
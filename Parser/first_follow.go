@@ -0,0 +1,173 @@
+package Parser
+
+import (
+	"slices"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+// insert_sorted is a helper function that inserts the values in a sorted
+// slice without duplicates.
+//
+// Parameters:
+//   - set: The sorted slice.
+//   - values: The values to insert.
+//
+// Returns:
+//   - []T: The new slice.
+//   - bool: True if a value was inserted, false otherwise.
+func insert_sorted[T gr.TokenTyper](set []T, values ...T) ([]T, bool) {
+	var changed bool
+
+	for _, value := range values {
+		pos, found := slices.BinarySearch(set, value)
+		if !found {
+			set = slices.Insert(set, pos, value)
+			changed = true
+		}
+	}
+
+	return set, changed
+}
+
+// SymbolSets are the FIRST and FOLLOW sets of the symbols of a grammar.
+type SymbolSets[T gr.TokenTyper] struct {
+	// first are the terminals that can start each non-terminal.
+	first map[T][]T
+
+	// follow are the terminals that can follow each non-terminal.
+	follow map[T][]T
+
+	// nullable are the non-terminals that can derive the empty string.
+	nullable map[T]bool
+}
+
+// NewSymbolSets computes the FIRST and FOLLOW sets of a grammar.
+//
+// Parameters:
+//   - productions: The productions of the grammar.
+//
+// Returns:
+//   - *SymbolSets: The sets. Never nil.
+//
+// Behaviors:
+//   - The FOLLOW sets are only made of the terminals of the grammar; the EOF
+//     symbol is expected to be one of them.
+func NewSymbolSets[T gr.TokenTyper](productions []*gr.Production[T]) *SymbolSets[T] {
+	ss := &SymbolSets[T]{
+		first:    make(map[T][]T),
+		follow:   make(map[T][]T),
+		nullable: make(map[T]bool),
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, p := range productions {
+			lhs := p.GetLhs()
+
+			set, ok := ss.first_of_rhs(p, 0)
+
+			var c bool
+
+			ss.first[lhs], c = insert_sorted(ss.first[lhs], set...)
+			changed = changed || c
+
+			if ok && !ss.nullable[lhs] {
+				ss.nullable[lhs] = true
+				changed = true
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, p := range productions {
+			lhs := p.GetLhs()
+
+			for i := 0; i < p.Size(); i++ {
+				symbol, _ := p.GetRhsAt(i)
+				if symbol.IsTerminal() {
+					continue
+				}
+
+				set, ok := ss.first_of_rhs(p, i+1)
+				if ok {
+					set = append(set, ss.follow[lhs]...)
+				}
+
+				var c bool
+
+				ss.follow[symbol], c = insert_sorted(ss.follow[symbol], set...)
+				changed = changed || c
+			}
+		}
+	}
+
+	return ss
+}
+
+// first_of_rhs is a helper method that computes the FIRST set of the
+// right-hand side of a production, from the given index.
+//
+// Parameters:
+//   - p: The production.
+//   - from: The index of the first symbol.
+//
+// Returns:
+//   - []T: The terminals that can start the symbols.
+//   - bool: True if the symbols can derive the empty string.
+func (ss *SymbolSets[T]) first_of_rhs(p *gr.Production[T], from int) ([]T, bool) {
+	var set []T
+
+	for i := from; i < p.Size(); i++ {
+		symbol, _ := p.GetRhsAt(i)
+
+		set, _ = insert_sorted(set, ss.First(symbol)...)
+
+		if symbol.IsTerminal() || !ss.nullable[symbol] {
+			return set, false
+		}
+	}
+
+	return set, true
+}
+
+// First returns the FIRST set of a symbol.
+//
+// Parameters:
+//   - symbol: The symbol.
+//
+// Returns:
+//   - []T: The terminals that can start the symbol, sorted. The symbol
+//     itself if it is a terminal.
+func (ss *SymbolSets[T]) First(symbol T) []T {
+	if symbol.IsTerminal() {
+		return []T{symbol}
+	}
+
+	return slices.Clone(ss.first[symbol])
+}
+
+// Follow returns the FOLLOW set of a non-terminal.
+//
+// Parameters:
+//   - symbol: The non-terminal.
+//
+// Returns:
+//   - []T: The terminals that can follow the non-terminal, sorted.
+func (ss *SymbolSets[T]) Follow(symbol T) []T {
+	return slices.Clone(ss.follow[symbol])
+}
+
+// IsNullable checks whether a symbol can derive the empty string.
+//
+// Parameters:
+//   - symbol: The symbol.
+//
+// Returns:
+//   - bool: True if the symbol is nullable, false otherwise.
+func (ss *SymbolSets[T]) IsNullable(symbol T) bool {
+	return ss.nullable[symbol]
+}
@@ -0,0 +1,82 @@
+package Parser
+
+import (
+	"slices"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+type SetTokenType int
+
+const (
+	SsEof SetTokenType = iota
+	SsWord
+	SsComma
+	SsOpen
+	SsClose
+	SsSource
+	SsList
+	SsRest
+	SsItem
+)
+
+func (t SetTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"WORD",
+		"COMMA",
+		"OPEN",
+		"CLOSE",
+		gr.StartSymbolID,
+		"list",
+		"rest",
+		"item",
+	}[t]
+}
+
+func (t SetTokenType) IsTerminal() bool {
+	return t <= SsClose
+}
+
+func TestSymbolSets(t *testing.T) {
+	// A list of words and parenthesized lists, separated by commas, where
+	// rest is nullable.
+	productions := []*gr.Production[SetTokenType]{
+		gr.NewProduction(SsSource, []SetTokenType{SsList, SsEof}),
+		gr.NewProduction(SsList, []SetTokenType{SsItem, SsRest}),
+		gr.NewProduction(SsRest, []SetTokenType{SsComma, SsItem, SsRest}),
+		gr.NewProduction(SsRest, []SetTokenType{}),
+		gr.NewProduction(SsItem, []SetTokenType{SsWord}),
+		gr.NewProduction(SsItem, []SetTokenType{SsOpen, SsList, SsClose}),
+	}
+
+	ss := NewSymbolSets(productions)
+
+	tests := []struct {
+		symbol   SetTokenType
+		first    []SetTokenType
+		follow   []SetTokenType
+		nullable bool
+	}{
+		{SsSource, []SetTokenType{SsWord, SsOpen}, nil, false},
+		{SsList, []SetTokenType{SsWord, SsOpen}, []SetTokenType{SsEof, SsClose}, false},
+		{SsRest, []SetTokenType{SsComma}, []SetTokenType{SsEof, SsClose}, true},
+		{SsItem, []SetTokenType{SsWord, SsOpen}, []SetTokenType{SsEof, SsComma, SsClose}, false},
+		{SsComma, []SetTokenType{SsComma}, nil, false},
+	}
+
+	for _, test := range tests {
+		if got := ss.First(test.symbol); !slices.Equal(got, test.first) {
+			t.Errorf("FIRST(%s): expected %v, got %v", test.symbol, test.first, got)
+		}
+
+		if got := ss.Follow(test.symbol); !slices.Equal(got, test.follow) {
+			t.Errorf("FOLLOW(%s): expected %v, got %v", test.symbol, test.follow, got)
+		}
+
+		if ss.IsNullable(test.symbol) != test.nullable {
+			t.Errorf("%s: expected nullable to be %t", test.symbol, test.nullable)
+		}
+	}
+}
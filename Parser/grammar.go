@@ -4,8 +4,6 @@ import (
 	"slices"
 
 	gr "github.com/PlayerR9/LyneParser/Grammar"
-	ud "github.com/PlayerR9/MyGoLib/Units/Debugging"
-	lls "github.com/PlayerR9/stack/stack"
 )
 
 // Grammar represents a context-free grammar.
@@ -83,7 +81,7 @@ func (g *Grammar[T]) GetSymbols() []T {
 //
 // Returns:
 //   - []MatchedResult: A slice of MatchedResult that match the input token.
-func (g *Grammar[T]) ProductionMatch(at int, stack *ud.History[lls.Stacker[*gr.Token[T]]]) []*gr.MatchedResult[T] {
+func (g *Grammar[T]) ProductionMatch(at int, stack *gr.TokenStack[T]) []*gr.MatchedResult[T] {
 	var matches []*gr.MatchedResult[T]
 
 	for i, p := range g.productions {
@@ -14,7 +14,14 @@ import (
 // evaluate evaluates the frontier evaluator given an element.
 //
 // Parameters:
+//   - dt: The decision table to use.
+//   - source: The input stream.
 //   - elem: The element to evaluate.
+//   - max_accepted: The number of accepted parses after which the remaining
+//     branches are cancelled. 0 means no limit.
+//
+// Returns:
+//   - []*us.WeightedHelper: The outcome of every branch that stopped.
 //
 // Behaviors:
 //   - If the element is accepted, the solutions will be set to the element.
@@ -22,56 +29,60 @@ import (
 //   - If the matcher returns an error, the solutions will be set to the error.
 //   - The evaluations assume that, the more the element is elaborated, the more the weight increases.
 //     Thus, it is assumed to be the most likely solution as it is the most elaborated. Euristic: Depth.
-func evaluate[T gr.TokenTyper](dt *cs.ConflictSolver[T], source *cds.Stream[*gr.Token[T]], elem *CurrentEval[T]) []*us.WeightedHelper[*CurrentEval[T]] {
-	ok := elem.Accept()
-	if ok {
-		h := us.NewWeightedHelper(elem, nil, 0.0)
+//   - The branches are explored depth-first, the first alternative of every
+//     fork first. Thus, the results are in the same order, and are cut at
+//     the same place, as the ones of evaluate_concurrent.
+func evaluate[T gr.TokenTyper](dt *cs.ConflictSolver[T], source *cds.Stream[*gr.Token[T]], elem *CurrentEval[T], max_accepted int) []*us.WeightedHelper[*CurrentEval[T]] {
+	var results []*branch_result[T]
+	var accepted int
 
-		sols := []*us.WeightedHelper[*CurrentEval[T]]{h}
-
-		return sols
-	}
-
-	var sols []*us.WeightedHelper[*CurrentEval[T]]
-
-	p := uc.NewPair(elem, 0.0)
-	S := lls.NewArrayStack(p)
+	S := lls.NewArrayStack(&branch[T]{eval: elem})
 
 	for {
-		p, ok := S.Pop()
+		b, ok := S.Pop()
 		if !ok {
 			break
 		}
 
-		nexts, err := p.First.Parse(source, dt)
-		if err != nil {
-			h := us.NewWeightedHelper(p.First, err, p.Second)
-
-			sols = append(sols, h)
+		if max_accepted > 0 && accepted >= max_accepted {
+			b.eval.fire_killed(NewErrBranchPruned())
 			continue
 		}
 
-		new_pairs := make([]uc.Pair[*CurrentEval[T], float64], 0, len(nexts))
+		if b.eval.Accept() {
+			res := &branch_result[T]{branch: b}
+			results = append(results, res)
 
-		for _, next := range nexts {
-			p := uc.NewPair(next, p.Second+1.0)
+			if res.is_complete() {
+				accepted++
+			}
 
-			new_pairs = append(new_pairs, p)
+			continue
 		}
 
-		for _, pair := range new_pairs {
-			ok := pair.First.Accept()
-			if ok {
-				h := us.NewWeightedHelper(pair.First, nil, pair.Second)
+		nexts, err := b.eval.Parse(source, dt)
+		if err != nil {
+			results = append(results, &branch_result[T]{branch: b, reason: err})
+			continue
+		}
 
-				sols = append(sols, h)
-			} else {
-				S.Push(pair)
+		switch len(nexts) {
+		case 0:
+			results = append(results, &branch_result[T]{branch: b, reason: NewErrNoAccept()})
+		case 1:
+			b.eval = nexts[0]
+			b.depth++
+
+			S.Push(b)
+		default:
+			// Pushed backwards, so that the first alternative is popped first.
+			for i := len(nexts) - 1; i >= 0; i-- {
+				S.Push(b.fork(nexts[i], i))
 			}
 		}
 	}
 
-	return sols
+	return branch_solutions(results, max_accepted)
 }
 
 // extract_results gets the results of the frontier evaluator.
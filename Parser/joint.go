@@ -0,0 +1,469 @@
+package Parser
+
+import (
+	"errors"
+	"slices"
+
+	cs "github.com/PlayerR9/LyneParser/ConflictSolver"
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+	tc "github.com/PlayerR9/LyneParser/Tracer"
+	lls "github.com/PlayerR9/stack/stack"
+)
+
+// token_choice is a token that an evaluation has chosen as its lookahead.
+type token_choice[T gr.TokenTyper] struct {
+	// tok is the token. Its lookahead is not set yet.
+	tok *gr.Token[T]
+
+	// to is where the token ends, as understood by the token source. -1 for
+	// the EOF token.
+	to int
+
+	// modes is the mode stack of the lexer after the token. Nil if the token
+	// source does not track the modes.
+	modes *lx.ModeStack
+}
+
+// token_source is where a joint evaluation gets its tokens from.
+type token_source[T gr.TokenTyper] interface {
+	// choices returns the tokens that can start where the previous one ends.
+	//
+	// Parameters:
+	//   - stack: The stack of the evaluation, with the previous token on top
+	//     and without its lookahead. Nil for the first token.
+	//   - from: Where the previous token ends. 0 for the first token.
+	//   - modes: The mode stack of the lexer after the previous token. Nil
+	//     for the first token.
+	//
+	// Returns:
+	//   - []*token_choice: The tokens, without the skipped ones. The EOF
+	//     token, ending at -1, at the end of the input.
+	//   - error: An error if no token can start there.
+	choices(stack *gr.TokenStack[T], from int, modes *lx.ModeStack) ([]*token_choice[T], error)
+}
+
+// shifted_list is a persistent list of the tokens shifted by an evaluation;
+// that is, the tokenization it has chosen so far.
+type shifted_list[T gr.TokenTyper] struct {
+	// tok is the last shifted token.
+	tok *gr.Token[T]
+
+	// prev are the tokens shifted before it.
+	prev *shifted_list[T]
+}
+
+// joint_eval is an evaluation of the parser that chooses the tokenization at
+// the same time as it parses.
+type joint_eval[T gr.TokenTyper] struct {
+	// ce is the evaluation of the parser.
+	ce *CurrentEval[T]
+
+	// next is the lookahead of the top of the stack. Nil once the EOF token
+	// is shifted.
+	next *token_choice[T]
+
+	// shifted are the tokens shifted so far.
+	shifted *shifted_list[T]
+}
+
+// copy is a helper method that copies the evaluation. Since the stack and the
+// lists are persistent, this is O(1).
+//
+// Returns:
+//   - *joint_eval: The copy.
+func (le *joint_eval[T]) copy() *joint_eval[T] {
+	le_copy := &joint_eval[T]{
+		ce:      le.ce.Copy().(*CurrentEval[T]),
+		next:    le.next,
+		shifted: le.shifted,
+	}
+
+	return le_copy
+}
+
+// tokens is a helper method that returns the tokens shifted so far.
+//
+// Returns:
+//   - []*gr.Token: The tokens, from the first to the last one.
+func (le *joint_eval[T]) tokens() []*gr.Token[T] {
+	var toks []*gr.Token[T]
+
+	for s := le.shifted; s != nil; s = s.prev {
+		toks = append(toks, s.tok)
+	}
+
+	slices.Reverse(toks)
+
+	return toks
+}
+
+// shift is a helper method that shifts the lookahead of the evaluation. The
+// evaluation is forked for every token that can follow it, since each of
+// them is a different lookahead for the shifted token.
+//
+// Parameters:
+//   - src: The source of the tokens.
+//
+// Returns:
+//   - []*joint_eval: The evaluations after the shift.
+//   - error: An error of type *ErrNoAccept if the EOF token was already
+//     shifted, or the error of the source if no token can follow.
+func (le *joint_eval[T]) shift(src token_source[T]) ([]*joint_eval[T], error) {
+	if le.next == nil {
+		return nil, NewErrNoAccept()
+	}
+
+	curr := le.next
+
+	if curr.to == -1 {
+		tok := gr.NewToken(curr.tok.ID, curr.tok.Data, curr.tok.At, nil)
+		tok.Mode = curr.tok.Mode
+
+		le.ce.set_stack(le.ce.stack.Push(tok))
+		le.ce.current_index++
+		le.next = nil
+		le.shifted = &shifted_list[T]{
+			tok:  tok,
+			prev: le.shifted,
+		}
+
+		return []*joint_eval[T]{le}, nil
+	}
+
+	top := gr.NewToken(curr.tok.ID, curr.tok.Data, curr.tok.At, nil)
+	top.Mode = curr.tok.Mode
+
+	choices, err := src.choices(le.ce.stack.Push(top), curr.to, curr.modes)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(choices) > 1 {
+		tc.Fire(le.ce.hook, &tc.BranchForkedEvent{
+			Origin: tc.FromParser,
+			At:     curr.tok.At,
+			Count:  len(choices),
+		})
+	}
+
+	nexts := make([]*joint_eval[T], 0, len(choices))
+
+	for _, choice := range choices {
+		// Every fork has its own copy of the token since the lookahead differs.
+		la := gr.NewToken(choice.tok.ID, choice.tok.Data, choice.tok.At, nil)
+		la.Mode = choice.tok.Mode
+
+		tok := gr.NewToken(curr.tok.ID, curr.tok.Data, curr.tok.At, la)
+		tok.Mode = curr.tok.Mode
+
+		next := le.copy()
+
+		next.ce.set_stack(next.ce.stack.Push(tok))
+		next.ce.current_index++
+		next.next = &token_choice[T]{
+			tok:   la,
+			to:    choice.to,
+			modes: choice.modes,
+		}
+		next.shifted = &shifted_list[T]{
+			tok:  tok,
+			prev: le.shifted,
+		}
+
+		nexts = append(nexts, next)
+	}
+
+	return nexts, nil
+}
+
+// act is a helper method that acts on a decision of the parser.
+//
+// Parameters:
+//   - decision: The decision.
+//   - src: The source of the tokens.
+//
+// Returns:
+//   - []*joint_eval: The evaluations after the decision.
+//   - error: An error if the evaluation could not act on the decision.
+func (le *joint_eval[T]) act(decision cs.HelperElem[T], src token_source[T]) ([]*joint_eval[T], error) {
+	var err error
+
+	switch decision := decision.(type) {
+	case *cs.ActShift[T]:
+		nexts, err := le.shift(src)
+		if err != nil {
+			return nil, err
+		}
+
+		if le.ce.hook != nil {
+			for _, next := range nexts {
+				next.ce.fire_decision(decision)
+			}
+		}
+
+		return nexts, nil
+	case *cs.ActReduce[T]:
+		err = le.ce.reduce(decision.Original)
+	case *cs.ActAccept[T]:
+		err = le.ce.reduce(decision.Original)
+		if err == nil {
+			le.ce.is_done = true
+		}
+	default:
+		err = NewErrUnknownAction(decision)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if le.ce.hook != nil {
+		le.ce.fire_decision(decision)
+	}
+
+	return []*joint_eval[T]{le}, nil
+}
+
+// step is a helper method that makes one decision of the parser.
+//
+// Parameters:
+//   - dt: The decision table.
+//   - src: The source of the tokens.
+//
+// Returns:
+//   - []*joint_eval: The evaluations after the decision.
+//   - error: An error if no decision could be made.
+//
+// Behaviors:
+//   - A tokenization that is not valid in the current parser state is
+//     abandoned here, since no action of the decision table matches its
+//     lookahead.
+//   - As with CurrentEval.Parse, the copies of a fork that could not act on
+//     their decision are returned too, and fail on their next step.
+func (le *joint_eval[T]) step(dt *cs.ConflictSolver[T], src token_source[T]) ([]*joint_eval[T], error) {
+	if le.ce.failure != nil {
+		return nil, le.ce.failure
+	}
+
+	var lookahead *gr.Token[T]
+
+	if le.next != nil {
+		lookahead = le.next.tok
+	}
+
+	decisions, rejected, err := dt.MatchWithRejections(le.ce.stack)
+	if err != nil {
+		err = le.ce.unexpected_token(dt, err)
+	} else if len(decisions) == 0 {
+		err = NewErrNoAccept()
+	}
+
+	if err != nil {
+		le.add_step(lookahead, nil, nil, rejected, err)
+		le.ce.fire_killed(err)
+
+		return nil, err
+	}
+
+	var nexts []*joint_eval[T]
+
+	for i, decision := range decisions {
+		curr := le
+		if len(decisions) > 1 {
+			curr = le.copy()
+		}
+
+		curr.add_step(lookahead, decision, without(decisions, i), rejected, nil)
+
+		results, err := curr.act(decision, src)
+		if err != nil {
+			if curr.ce.trace {
+				curr.ce.steps.step.Err = err
+			}
+
+			curr.ce.fire_killed(err)
+
+			if len(decisions) == 1 {
+				return nil, err
+			}
+
+			curr.ce.failure = err
+			nexts = append(nexts, curr)
+
+			continue
+		}
+
+		nexts = append(nexts, results...)
+	}
+
+	return nexts, nil
+}
+
+// add_step is a helper method that records a step of the evaluation. Does
+// nothing if tracing is disabled.
+//
+// Parameters:
+//   - lookahead: The lookahead of the top of the stack.
+//   - chosen: The action taken by the evaluation.
+//   - forked: The other actions taken by sibling evaluations.
+//   - rejected: The actions that did not match.
+//   - err: The reason the evaluation stopped, if any.
+func (le *joint_eval[T]) add_step(lookahead *gr.Token[T], chosen cs.HelperElem[T], forked []cs.HelperElem[T], rejected []*cs.Rejection[T], err error) {
+	if !le.ce.trace {
+		return
+	}
+
+	le.ce.add_step(&TraceStep[T]{
+		Stack:     le.ce.stack,
+		Lookahead: lookahead,
+		Chosen:    chosen,
+		Forked:    forked,
+		Rejected:  rejected,
+		Err:       err,
+	})
+}
+
+// JointParse is a successful parse where the parser chose the tokenization.
+type JointParse[T gr.TokenTyper] struct {
+	// Eval is the evaluation that accepted the input.
+	Eval *CurrentEval[T]
+
+	// Tokens is the tokenization that made the parse succeed, without the
+	// skipped tokens and with the EOF token at the end.
+	Tokens []*gr.Token[T]
+}
+
+// parse_joint is a helper function that runs the joint evaluations until
+// every one of them is accepted or killed.
+//
+// Parameters:
+//   - p: The parser to use. Assumed to be non-nil.
+//   - src: The source of the tokens.
+//
+// Returns:
+//   - []*JointParse: The accepted parses.
+//   - error: An error if none was accepted.
+//
+// Behaviors:
+//   - The evaluations are explored depth-first, trying the first token and
+//     the first alternative at each fork first; so the parses are in the
+//     order of the decisions, as with Parse.
+//   - The exploration stops once the number of parses set with
+//     SetConcurrency is reached. Only the parses that leave the root alone
+//     on the stack count towards it.
+//   - An acceptance that leaves other tokens on the stack is outweighed by
+//     those parses, so it is only returned if there is none.
+func parse_joint[T gr.TokenTyper](p *Parser[T], src token_source[T]) ([]*JointParse[T], error) {
+	if p.dt == nil {
+		return nil, errors.New("no grammar was set")
+	}
+
+	if !p.has_eof {
+		return nil, errors.New("the grammar has no " + gr.EOFTokenID + " symbol")
+	}
+
+	p.evals = nil
+	p.rejected = nil
+
+	ce_root := p.new_root()
+
+	choices, err := src.choices(nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*joint_eval[T]
+	var first_err error
+
+	for _, choice := range choices {
+		le := &joint_eval[T]{
+			ce:   ce_root.Copy().(*CurrentEval[T]),
+			next: choice,
+		}
+
+		nexts, err := le.shift(src)
+		if err != nil {
+			if first_err == nil {
+				first_err = err
+			}
+
+			continue
+		}
+
+		pending = append(pending, nexts...)
+	}
+
+	S := lls.NewArrayStack[*joint_eval[T]]()
+
+	// Pushed backwards, so that the first alternative is popped first.
+	for i := len(pending) - 1; i >= 0; i-- {
+		S.Push(pending[i])
+	}
+
+	var accepted, partial []*JointParse[T]
+	furthest := 0
+
+	for {
+		le, ok := S.Pop()
+		if !ok {
+			break
+		}
+
+		if p.max_accepted > 0 && len(accepted) >= p.max_accepted {
+			le.ce.fire_killed(NewErrBranchPruned())
+			continue
+		}
+
+		if le.ce.Accept() {
+			parse := &JointParse[T]{
+				Eval:   le.ce,
+				Tokens: le.tokens(),
+			}
+
+			if le.ce.stack.Size() == 1 {
+				accepted = append(accepted, parse)
+			} else {
+				partial = append(partial, parse)
+			}
+
+			continue
+		}
+
+		nexts, err := le.step(p.dt, src)
+		if err != nil {
+			p.rejected = append(p.rejected, le.ce)
+
+			if le.ce.current_index > furthest {
+				furthest = le.ce.current_index
+				first_err = err
+			}
+
+			continue
+		}
+
+		for i := len(nexts) - 1; i >= 0; i-- {
+			S.Push(nexts[i])
+		}
+	}
+
+	if len(accepted) == 0 {
+		accepted = partial
+	}
+
+	if len(accepted) == 0 {
+		if first_err == nil {
+			first_err = errors.New("no parse trees were found")
+		}
+
+		return nil, first_err
+	}
+
+	p.evals = make([]*CurrentEval[T], 0, len(accepted))
+
+	for _, parse := range accepted {
+		p.evals = append(p.evals, parse.Eval)
+	}
+
+	return accepted, nil
+}
@@ -0,0 +1,79 @@
+package Parser
+
+import (
+	"slices"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+)
+
+// stream_source is a token source with a single tokenization: the tokens of
+// new_test_source. A token ends at the index of the next one.
+type stream_source struct {
+	tokens []*gr.Token[TestTokenType]
+}
+
+func (ss *stream_source) choices(stack *gr.TokenStack[TestTokenType], from int, modes *lx.ModeStack) ([]*token_choice[TestTokenType], error) {
+	tok := ss.tokens[from]
+
+	to := from + 1
+	if tok.ID == TtEof {
+		to = -1
+	}
+
+	choice := &token_choice[TestTokenType]{
+		tok: gr.NewToken(tok.ID, tok.Data, tok.At, nil),
+		to:  to,
+	}
+
+	return []*token_choice[TestTokenType]{choice}, nil
+}
+
+// parse_joint_all parses a source with parse_joint and returns the dump of
+// every accepted parse, in order.
+func parse_joint_all(t *testing.T, p *Parser[TestTokenType], source string) []string {
+	parses, err := parse_joint(p, &stream_source{tokens: new_test_source(source).GetItems()})
+	if err != nil {
+		t.Fatalf("%q: expected no error, got %s", source, err.Error())
+	}
+
+	var trees []string
+
+	for _, parse := range parses {
+		root, _ := parse.Eval.GetStack().Peek()
+		trees = append(trees, dump(root))
+	}
+
+	return trees
+}
+
+func TestParseJointOrder(t *testing.T) {
+	p := new_test_parser(t)
+
+	const source = "a,b,c,d"
+
+	expected := parse_all(t, p, source)
+	if len(expected) < 3 {
+		t.Fatalf("expected an ambiguous source, got %v", expected)
+	}
+
+	// The parses are in the order of the decisions, as with Parse.
+	trees := parse_joint_all(t, p, source)
+	if !slices.Equal(trees, expected) {
+		t.Errorf("expected %v, got %v", expected, trees)
+	}
+
+	// The cap keeps the first parses, even without workers.
+	for _, max_accepted := range []int{1, 2} {
+		err := p.SetConcurrency(0, max_accepted)
+		if err != nil {
+			t.Fatalf("SetConcurrency failed: %s", err.Error())
+		}
+
+		trees := parse_joint_all(t, p, source)
+		if !slices.Equal(trees, expected[:max_accepted]) {
+			t.Errorf("max %d: expected %v, got %v", max_accepted, expected[:max_accepted], trees)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package Parser
+
+import (
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// lattice_source is a token source that walks a token lattice. The positions
+// are the indices of the nodes of the lattice.
+type lattice_source[T gr.TokenTyper] struct {
+	// lattice is the lattice.
+	lattice *lx.TokenLattice[T]
+
+	// eof is the type of the EOF token.
+	eof T
+}
+
+// choices implements the token_source interface.
+//
+// Behaviors:
+//   - Never returns an error since every node of the lattice that is not
+//     final has outgoing edges.
+//   - The modes are ignored since the nodes of the lattice already tell
+//     them apart.
+func (ls *lattice_source[T]) choices(stack *gr.TokenStack[T], from int, modes *lx.ModeStack) ([]*token_choice[T], error) {
+	return lattice_choices(ls.lattice, from, ls.eof), nil
+}
+
+// lattice_choices is a helper function that returns the tokens that can
+// start at a node of the lattice. The skipped tokens are jumped over.
+//
+// Parameters:
+//   - lattice: The lattice.
+//   - node: The index of the node.
+//   - eof: The type of the EOF token.
+//
+// Returns:
+//   - []*token_choice: The choices, in rule order. The EOF token if the
+//     node is final.
+func lattice_choices[T gr.TokenTyper](lattice *lx.TokenLattice[T], node int, eof T) []*token_choice[T] {
+	if lattice.IsFinal(node) {
+		at := lattice.GetNode(node).At
+
+		choice := &token_choice[T]{
+			tok: gr.NewToken(eof, "", at, nil),
+			to:  -1,
+		}
+
+		return []*token_choice[T]{choice}
+	}
+
+	var choices []*token_choice[T]
+
+	for _, edge := range lattice.GetEdges(node) {
+		if lattice.IsSkipped(edge) {
+			choices = append(choices, lattice_choices(lattice, edge.To, eof)...)
+			continue
+		}
+
+		choices = append(choices, &token_choice[T]{
+			tok: edge.Token,
+			to:  edge.To,
+		})
+	}
+
+	return choices
+}
+
+// ParseLattice parses every tokenization of a token lattice at once. Instead
+// of parsing each path of the lattice in turn, the parser chooses the next
+// token as it goes; so only the tokens that are valid in the current parser
+// state are explored, and the prefixes shared by several tokenizations are
+// parsed once.
+//
+// Parameters:
+//   - p: The parser to use.
+//   - lattice: The lattice produced by Lexer.LexLattice.
+//
+// Returns:
+//   - []*JointParse: The parses, together with the tokenization of each.
+//   - error: An error if no tokenization could be parsed.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If p or lattice is nil.
+//   - any error of the evaluation that went the furthest, if none accepted.
+//
+// Behaviors:
+//   - The grammar of the parser must have an EOF symbol. The EOF token is
+//     added at the end of every tokenization.
+//   - The parses are in the order of the decisions, as with Parse, and
+//     stop at the number of parses set with SetConcurrency. The exploration
+//     itself is never concurrent.
+//   - GetParseTree and GetTraces return the results of the accepted parses,
+//     in the same order.
+func ParseLattice[T gr.TokenTyper](p *Parser[T], lattice *lx.TokenLattice[T]) ([]*JointParse[T], error) {
+	if p == nil {
+		return nil, uc.NewErrNilParameter("parser")
+	}
+
+	if lattice == nil {
+		return nil, uc.NewErrNilParameter("lattice")
+	}
+
+	src := &lattice_source[T]{
+		lattice: lattice,
+		eof:     p.eof,
+	}
+
+	return parse_joint(p, src)
+}
@@ -0,0 +1,174 @@
+package Parser
+
+import (
+	"strings"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+)
+
+type LetTokenType int
+
+const (
+	LtEof LetTokenType = iota
+	LtLet
+	LtWord
+	LtEq
+	LtSpace
+	LtSource
+	LtStmt
+)
+
+func (t LetTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"LET",
+		"WORD",
+		"EQ",
+		"SPACE",
+		gr.StartSymbolID,
+		"stmt",
+	}[t]
+}
+
+func (t LetTokenType) IsTerminal() bool {
+	return t <= LtSpace
+}
+
+// new_let_lexer creates a lexer where "let" is both a LET and a WORD, so
+// that every "let" of the input has two tokenizations. The spaces are
+// skipped.
+func new_let_lexer(t *testing.T) *lx.Lexer[LetTokenType] {
+	grammar := lx.NewGrammar([]LetTokenType{LtSpace})
+
+	rules := []struct {
+		lhs   LetTokenType
+		regex string
+	}{
+		{LtLet, `let`},
+		{LtWord, `[a-z]+`},
+		{LtEq, `=`},
+		{LtSpace, ` +`},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.regex)
+		if err != nil {
+			t.Fatalf("AddRule(%s) failed: %s", rule.lhs, err.Error())
+		}
+	}
+
+	lexer := lx.NewLexer(grammar)
+	lexer.SetEOF(LtEof)
+
+	return lexer
+}
+
+// new_let_parser creates a parser of the grammar:
+//
+//	source -> stmt EOF
+//	stmt -> LET WORD
+//	stmt -> WORD EQ WORD
+func new_let_parser(t *testing.T) *Parser[LetTokenType] {
+	grammar, err := NewGrammar[LetTokenType]()
+	if err != nil {
+		t.Fatalf("NewGrammar failed: %s", err.Error())
+	}
+
+	rules := []struct {
+		lhs LetTokenType
+		rhs []LetTokenType
+	}{
+		{LtSource, []LetTokenType{LtStmt, LtEof}},
+		{LtStmt, []LetTokenType{LtLet, LtWord}},
+		{LtStmt, []LetTokenType{LtWord, LtEq, LtWord}},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.rhs)
+		if err != nil {
+			t.Fatalf("AddRule failed: %s", err.Error())
+		}
+	}
+
+	p, err := NewParser(grammar)
+	if err != nil {
+		t.Fatalf("NewParser failed: %s", err.Error())
+	}
+
+	return p
+}
+
+// joint_tokens returns the tokenization of each parse as "TYPE(data) ...".
+func joint_tokens(parses []*JointParse[LetTokenType]) []string {
+	var result []string
+
+	for _, parse := range parses {
+		var values []string
+
+		for _, tok := range parse.Tokens {
+			values = append(values, tok.ID.String()+"("+tok.Data.(string)+")")
+		}
+
+		result = append(result, strings.Join(values, " "))
+	}
+
+	return result
+}
+
+func TestParseLattice(t *testing.T) {
+	lexer := new_let_lexer(t)
+	p := new_let_parser(t)
+
+	// The "let" of both inputs is a LET or a WORD; only one of them parses.
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"let x", "LET(let) WORD(x) EOF()"},
+		{"let = x", "WORD(let) EQ(=) WORD(x) EOF()"},
+	}
+
+	for _, test := range tests {
+		tl, err := lexer.LexLattice([]byte(test.source))
+		if err != nil {
+			t.Fatalf("%q: LexLattice failed: %s", test.source, err.Error())
+		}
+
+		if tl.Count() != 2 {
+			t.Fatalf("%q: expected 2 tokenizations, got %d", test.source, tl.Count())
+		}
+
+		parses, err := ParseLattice(p, tl)
+		if err != nil {
+			t.Fatalf("%q: ParseLattice failed: %s", test.source, err.Error())
+		}
+
+		got := joint_tokens(parses)
+		if len(got) != 1 || got[0] != test.expected {
+			t.Errorf("%q: expected [%s], got %v", test.source, test.expected, got)
+		}
+
+		trees, err := p.GetParseTree()
+		if err != nil || len(trees) != 1 {
+			t.Errorf("%q: expected 1 parse tree, got %d (%v)", test.source, len(trees), err)
+		}
+	}
+
+	// Neither tokenization of "let" alone parses.
+	tl, err := lexer.LexLattice([]byte("let"))
+	if err != nil {
+		t.Fatalf("LexLattice failed: %s", err.Error())
+	}
+
+	_, err = ParseLattice(p, tl)
+	if err == nil {
+		t.Errorf("expected an error, got nil")
+	}
+
+	_, err = ParseLattice(p, nil)
+	if err == nil {
+		t.Errorf("expected an error for a nil lattice")
+	}
+}
@@ -5,8 +5,10 @@ import (
 
 	cs "github.com/PlayerR9/LyneParser/ConflictSolver"
 	gr "github.com/PlayerR9/LyneParser/Grammar"
+	tc "github.com/PlayerR9/LyneParser/Tracer"
 	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
 	uc "github.com/PlayerR9/MyGoLib/Units/common"
+	us "github.com/PlayerR9/MyGoLib/Units/slice"
 )
 
 // Parser is a parser that uses a stack to parse a stream of tokens.
@@ -14,9 +16,44 @@ type Parser[T gr.TokenTyper] struct {
 	// evals is a list of evaluations that the parser will use.
 	evals []*CurrentEval[T]
 
+	// rejected are the evaluations of the branches that failed during the
+	// last parse.
+	rejected []*CurrentEval[T]
+
 	// decisionFunc represents the function that the parser will use to determine
 	// the next action to take.
 	dt *cs.ConflictSolver[T]
+
+	// trace is a flag that represents if the evaluations should keep their
+	// undo history.
+	trace bool
+
+	// workers is the number of goroutines used to explore the branches of the
+	// parse. 0 or 1 means that the branches are explored sequentially.
+	workers int
+
+	// max_accepted is the number of accepted parses after which the
+	// exploration stops. 0 means no limit.
+	max_accepted int
+
+	// hook is the hook that receives the events of the parser. May be nil.
+	hook tc.Hooker
+
+	// spellings are the texts of the terminals that have a fixed one.
+	spellings map[T]string
+
+	// eof is the EOF symbol of the grammar. Only valid if has_eof is true.
+	eof T
+
+	// has_eof is true if the grammar has an EOF symbol.
+	has_eof bool
+
+	// sets are the FIRST and FOLLOW sets of the grammar.
+	sets *SymbolSets[T]
+
+	// checkpoints is a flag that represents if the evaluations should record
+	// a checkpoint after every shift.
+	checkpoints bool
 }
 
 /////////////////////////////////////////////////////////////
@@ -49,12 +86,182 @@ func NewParser[T gr.TokenTyper](grammar *Grammar[T]) (*Parser[T], error) {
 	}
 
 	p := &Parser[T]{
-		dt: table,
+		dt:   table,
+		sets: NewSymbolSets(productions),
+	}
+
+	for _, symbol := range grammar.GetSymbols() {
+		if symbol.String() == gr.EOFTokenID {
+			p.eof = symbol
+			p.has_eof = true
+
+			break
+		}
 	}
 
 	return p, nil
 }
 
+// Copy implements the common.Copier interface.
+//
+// Behaviors:
+//   - The copy has the same settings and shares the decision table, which
+//     parsing never modifies. The results of the last parse are not copied.
+func (p *Parser[T]) Copy() uc.Copier {
+	p_copy := &Parser[T]{
+		dt:           p.dt,
+		trace:        p.trace,
+		workers:      p.workers,
+		max_accepted: p.max_accepted,
+		hook:         p.hook,
+		spellings:    p.spellings,
+		eof:          p.eof,
+		has_eof:      p.has_eof,
+		sets:         p.sets,
+		checkpoints:  p.checkpoints,
+	}
+
+	return p_copy
+}
+
+// SetHook sets the hook that receives the events of the parser.
+//
+// Parameters:
+//   - hook: The hook to use. Nil disables the events.
+//
+// Behaviors:
+//   - Since the conflicts of the grammar are resolved by NewParser, the
+//     conflict resolved events are fired on the hook when it is set, in the
+//     order of the resolutions.
+func (p *Parser[T]) SetHook(hook tc.Hooker) {
+	p.hook = hook
+
+	if hook == nil || p.dt == nil {
+		return
+	}
+
+	for _, event := range p.dt.GetResolved() {
+		tc.Fire(hook, event)
+	}
+}
+
+// SetTracing enables or disables tracing. When tracing is enabled, every
+// evaluation keeps the undo history of its stack.
+//
+// Parameters:
+//   - trace: True to enable tracing, false to disable it.
+func (p *Parser[T]) SetTracing(trace bool) {
+	p.trace = trace
+}
+
+// SetSpellings sets the texts of the terminals that have a fixed one, such
+// as keywords. When the parser fails on an unexpected token, the expected
+// terminal whose text is the closest to the one of the token is suggested.
+//
+// Parameters:
+//   - spellings: The text of each terminal. Usually, the result of
+//     GetLiterals on the grammar of the lexer. Nil disables the suggestions.
+//
+// Behaviors:
+//   - The map is not copied, so it must not be modified during a parse.
+func (p *Parser[T]) SetSpellings(spellings map[T]string) {
+	p.spellings = spellings
+}
+
+// AllowedTerminals returns the terminals that can be the lookahead of the top
+// of the stack. A lexer can use them to only try the rules that the parser
+// would accept; which resolves the ambiguities of the tokens whose texts
+// overlap, such as keywords that are also valid identifiers.
+//
+// Parameters:
+//   - stack: The stack of an evaluation, with the last shifted token on top.
+//     Its lookahead is ignored.
+//
+// Returns:
+//   - []T: The terminals, sorted and without duplicates. Nil if every
+//     terminal is allowed; an empty non-nil slice if none is.
+//
+// Behaviors:
+//   - The result is an over-approximation: a terminal that is allowed here
+//     may still be rejected by a later decision, but a terminal that is not
+//     allowed is never accepted.
+func (p *Parser[T]) AllowedTerminals(stack *gr.TokenStack[T]) []T {
+	if stack == nil || p.dt == nil {
+		return nil
+	}
+
+	allowed := make([]T, 0)
+
+	for _, act := range p.dt.MatchIgnoringLookahead(stack) {
+		la, ok := act.GetLookahead()
+		if ok {
+			if !la.IsTerminal() && p.sets.IsNullable(la) {
+				return nil
+			}
+
+			allowed, _ = insert_sorted(allowed, p.sets.First(la)...)
+
+			continue
+		}
+
+		switch act := act.(type) {
+		case *cs.ActReduce[T]:
+			allowed, _ = insert_sorted(allowed, p.sets.Follow(act.Original.GetLhs())...)
+		case *cs.ActAccept[T]:
+			if p.has_eof {
+				allowed, _ = insert_sorted(allowed, p.eof)
+			}
+		default:
+			// Without a lookahead, a shift can be followed by anything.
+			return nil
+		}
+	}
+
+	return allowed
+}
+
+// SetConcurrency enables or disables the concurrent exploration of the
+// branches of the parse.
+//
+// Parameters:
+//   - workers: The maximum number of goroutines to use. 0 or 1 disables the
+//     concurrent exploration.
+//   - max_accepted: The number of accepted parses after which the remaining
+//     branches are cancelled, whether the exploration is concurrent or not.
+//     0 means that every branch is explored.
+//
+// Returns:
+//   - error: An error of type *uc.ErrInvalidParameter if workers or
+//     max_accepted is negative.
+//
+// Behaviors:
+//   - The decision table is shared by all goroutines and is never modified.
+//   - Results are ordered by the decisions taken at each conflict, so they do
+//     not depend on the scheduling of the goroutines and are the same as
+//     the ones of the sequential exploration.
+//   - Only the parses that reduce the whole input stream to a single root
+//     count towards max_accepted.
+func (p *Parser[T]) SetConcurrency(workers, max_accepted int) error {
+	if workers < 0 {
+		return uc.NewErrInvalidParameter(
+			"workers",
+			uc.NewErrGTE(0),
+		)
+	}
+
+	if max_accepted < 0 {
+		return uc.NewErrInvalidParameter(
+			"max_accepted",
+			uc.NewErrGTE(0),
+		)
+	}
+
+	p.workers = workers
+	p.max_accepted = max_accepted
+
+	return nil
+}
+
 // Parse parses the input stream using the parser's decision function.
 //
 // Parameters:
@@ -76,14 +283,57 @@ func Parse[T gr.TokenTyper](p *Parser[T], source *cds.Stream[*gr.Token[T]]) erro
 		return errors.New("source is empty")
 	}
 
-	ce_root := NewCurrentEval[T]()
+	ce_root := p.new_root()
 
 	err := ce_root.shift(source)
 	if err != nil {
 		return err
 	}
 
-	sols := evaluate(p.dt, source, ce_root)
+	return p.run(source, ce_root)
+}
+
+// new_root is a helper method that creates the evaluation every parse
+// starts from.
+//
+// Returns:
+//   - *CurrentEval: The evaluation, with the settings of the parser.
+func (p *Parser[T]) new_root() *CurrentEval[T] {
+	ce_root := NewCurrentEval[T](p.trace)
+	ce_root.hook = p.hook
+	ce_root.spellings = p.spellings
+	ce_root.keep_checkpoints = p.checkpoints
+
+	return ce_root
+}
+
+// run is a helper method that explores the evaluations from the root one
+// and keeps the accepted ones.
+//
+// Parameters:
+//   - source: The input stream to parse.
+//   - ce_root: The evaluation to start from.
+//
+// Returns:
+//   - error: An error if no evaluation accepted the input stream.
+func (p *Parser[T]) run(source *cds.Stream[*gr.Token[T]], ce_root *CurrentEval[T]) error {
+	p.evals = nil
+	p.rejected = nil
+
+	var sols []*us.WeightedHelper[*CurrentEval[T]]
+
+	if p.workers > 1 {
+		sols = evaluate_concurrent(p.dt, source, ce_root, p.workers, p.max_accepted)
+	} else {
+		sols = evaluate(p.dt, source, ce_root, p.max_accepted)
+	}
+
+	for _, sol := range sols {
+		data := sol.GetData()
+		if data.Second != nil {
+			p.rejected = append(p.rejected, data.First)
+		}
+	}
 
 	results, err := extract_results(sols)
 	if err != nil {
@@ -99,6 +349,83 @@ func Parse[T gr.TokenTyper](p *Parser[T], source *cds.Stream[*gr.Token[T]]) erro
 	return nil
 }
 
+// GetEvals returns the evaluations that accepted the input stream, in the
+// same order as the parse trees.
+//
+// Returns:
+//   - []*CurrentEval: The evaluations.
+//   - error: An error if nothing was parsed.
+func (p *Parser[T]) GetEvals() ([]*CurrentEval[T], error) {
+	if len(p.evals) == 0 {
+		return nil, errors.New("nothing was parsed. Use Parse() to parse the input stream")
+	}
+
+	return p.evals, nil
+}
+
+// GetEOF returns the EOF symbol of the grammar.
+//
+// Returns:
+//   - T: The EOF symbol. The zero value if there is none.
+//   - bool: True if the grammar has an EOF symbol, false otherwise.
+func (p *Parser[T]) GetEOF() (T, bool) {
+	return p.eof, p.has_eof
+}
+
+// GetTraces returns the trace of every evaluation that the parser has
+// kept, in the same order as the parse trees. If the last parse failed, the
+// traces of the rejected branches are returned instead; see GetRejectedTraces.
+//
+// Parse() must be called with tracing enabled before calling this method.
+//
+// Returns:
+//   - [][]*TraceStep: The trace of each evaluation.
+//   - error: An error if nothing was parsed or tracing is disabled.
+func (p *Parser[T]) GetTraces() ([][]*TraceStep[T], error) {
+	if len(p.evals) == 0 {
+		return p.GetRejectedTraces()
+	}
+
+	if !p.trace {
+		return nil, errors.New("tracing is disabled. Use SetTracing() to enable it")
+	}
+
+	traces := make([][]*TraceStep[T], 0, len(p.evals))
+
+	for _, eval := range p.evals {
+		traces = append(traces, eval.GetTrace())
+	}
+
+	return traces, nil
+}
+
+// GetRejectedTraces returns the trace of every branch that failed during the
+// last parse, whether the parse succeeded or not. The last step of each
+// trace holds the reason the branch failed.
+//
+// Parse() must be called with tracing enabled before calling this method.
+//
+// Returns:
+//   - [][]*TraceStep: The trace of each branch.
+//   - error: An error if no branch failed or tracing is disabled.
+func (p *Parser[T]) GetRejectedTraces() ([][]*TraceStep[T], error) {
+	if len(p.rejected) == 0 {
+		return nil, errors.New("no branch was rejected. Use Parse() to parse the input stream")
+	}
+
+	if !p.trace {
+		return nil, errors.New("tracing is disabled. Use SetTracing() to enable it")
+	}
+
+	traces := make([][]*TraceStep[T], 0, len(p.rejected))
+
+	for _, eval := range p.rejected {
+		traces = append(traces, eval.GetTrace())
+	}
+
+	return traces, nil
+}
+
 // GetParseTree returns the parse tree that the parser has generated.
 //
 // Parse() must be called before calling this method. If it is not, an error will
@@ -0,0 +1,184 @@
+package Parser
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	tc "github.com/PlayerR9/LyneParser/Tracer"
+	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
+)
+
+type TestTokenType int
+
+const (
+	TtEof TestTokenType = iota
+	TtWord
+	TtComma
+	TtSource
+	TtList
+)
+
+func (t TestTokenType) String() string {
+	return [...]string{
+		gr.EOFTokenID,
+		"WORD",
+		"COMMA",
+		gr.StartSymbolID,
+		"list",
+	}[t]
+}
+
+func (t TestTokenType) IsTerminal() bool {
+	return t <= TtComma
+}
+
+// new_test_parser creates a parser of the ambiguous grammar:
+//
+//	source -> list EOF
+//	list -> WORD
+//	list -> list COMMA list
+func new_test_parser(t *testing.T) *Parser[TestTokenType] {
+	grammar, err := NewGrammar[TestTokenType]()
+	if err != nil {
+		t.Fatalf("NewGrammar failed: %s", err.Error())
+	}
+
+	rules := []struct {
+		lhs TestTokenType
+		rhs []TestTokenType
+	}{
+		{TtSource, []TestTokenType{TtList, TtEof}},
+		{TtList, []TestTokenType{TtWord}},
+		{TtList, []TestTokenType{TtList, TtComma, TtList}},
+	}
+
+	for _, rule := range rules {
+		err := grammar.AddRule(rule.lhs, rule.rhs)
+		if err != nil {
+			t.Fatalf("AddRule failed: %s", err.Error())
+		}
+	}
+
+	p, err := NewParser(grammar)
+	if err != nil {
+		t.Fatalf("NewParser failed: %s", err.Error())
+	}
+
+	return p
+}
+
+// new_test_source lexes a source like "a,b,c": a WORD per letter and a
+// COMMA per ','. An EOF is added at the end.
+func new_test_source(source string) *cds.Stream[*gr.Token[TestTokenType]] {
+	var tokens []*gr.Token[TestTokenType]
+
+	for i, c := range source {
+		id := TtWord
+		if c == ',' {
+			id = TtComma
+		}
+
+		tokens = append(tokens, gr.NewToken(id, string(c), i, nil))
+	}
+
+	tokens = append(tokens, gr.NewToken(TtEof, "", len(source), nil))
+
+	for i := 0; i < len(tokens)-1; i++ {
+		tokens[i].SetLookahead(tokens[i+1])
+	}
+
+	return cds.NewStream(tokens)
+}
+
+// dump writes a tree as "(a b c)" with the data of the leaves.
+func dump(tok *gr.Token[TestTokenType]) string {
+	children, ok := tok.Data.([]*gr.Token[TestTokenType])
+	if !ok {
+		str, _ := tok.Data.(string)
+		return str
+	}
+
+	values := make([]string, 0, len(children))
+
+	for _, child := range children {
+		if child.ID != TtEof {
+			values = append(values, dump(child))
+		}
+	}
+
+	return "(" + strings.Join(values, " ") + ")"
+}
+
+// parse_all parses a source and returns the dump of every accepted parse,
+// in order.
+func parse_all(t *testing.T, p *Parser[TestTokenType], source string) []string {
+	err := Parse(p, new_test_source(source))
+	if err != nil {
+		t.Fatalf("%q: expected no error, got %s", source, err.Error())
+	}
+
+	evals, err := p.GetEvals()
+	if err != nil {
+		t.Fatalf("%q: expected no error, got %s", source, err.Error())
+	}
+
+	var trees []string
+
+	for _, eval := range evals {
+		root, _ := eval.GetStack().Peek()
+		trees = append(trees, dump(root))
+	}
+
+	return trees
+}
+
+func TestParseAmbiguous(t *testing.T) {
+	p := new_test_parser(t)
+
+	expected := []string{
+		"(((a) , ((b) , (c))))",
+		"((((a) , (b)) , (c)))",
+	}
+
+	trees := parse_all(t, p, "a,b,c")
+	if !slices.Equal(trees, expected) {
+		t.Errorf("expected %v, got %v", expected, trees)
+	}
+}
+
+func TestSetHook(t *testing.T) {
+	p := new_test_parser(t)
+
+	rec := tc.NewRecorder()
+	p.SetHook(rec)
+
+	resolved := len(p.dt.GetResolved())
+	if rec.Count(tc.KindConflictResolved) != resolved {
+		t.Errorf("expected the %d resolutions to be fired by SetHook, got %d", resolved, rec.Count(tc.KindConflictResolved))
+	}
+
+	rec.Reset()
+
+	parse_all(t, p, "a,b")
+
+	for _, kind := range []tc.EventKind{tc.KindShift, tc.KindReduce, tc.KindAccept} {
+		if rec.Count(kind) == 0 {
+			t.Errorf("expected %s events, got none", kind)
+		}
+	}
+
+	if rec.Count(tc.KindConflictResolved) != 0 {
+		t.Errorf("expected no resolution during the parse, got %d", rec.Count(tc.KindConflictResolved))
+	}
+
+	p.SetHook(nil)
+	rec.Reset()
+
+	parse_all(t, p, "a")
+
+	if len(rec.Events()) != 0 {
+		t.Errorf("expected no event without a hook, got %d", len(rec.Events()))
+	}
+}
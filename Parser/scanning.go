@@ -0,0 +1,132 @@
+package Parser
+
+import (
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// scan_source is a token source that scans the input on demand, only trying
+// the terminals that the parser allows. The positions are byte offsets in
+// the input.
+type scan_source[T gr.TokenTyper] struct {
+	// parser is the parser that tells which terminals are allowed.
+	parser *Parser[T]
+
+	// lexer is the lexer that matches the tokens.
+	lexer *lx.Lexer[T]
+
+	// input is the input to scan.
+	input []byte
+}
+
+// choices implements the token_source interface.
+//
+// Behaviors:
+//   - The skipped tokens are matched whatever the parser allows and jumped
+//     over.
+//   - Only the rules of the active mode are tried, and each choice carries
+//     the mode stack after its token.
+func (ss *scan_source[T]) choices(stack *gr.TokenStack[T], from int, modes *lx.ModeStack) ([]*token_choice[T], error) {
+	if from >= len(ss.input) {
+		choice := &token_choice[T]{
+			tok: gr.NewToken(ss.parser.eof, "", len(ss.input), nil),
+			to:  -1,
+		}
+
+		return []*token_choice[T]{choice}, nil
+	}
+
+	var allowed []T
+
+	if stack != nil {
+		allowed = ss.parser.AllowedTerminals(stack)
+	}
+
+	steps, err := ss.lexer.MatchAt(ss.input, from, modes, allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	var choices []*token_choice[T]
+
+	for _, step := range steps {
+		to := step.Token.At + len(step.Token.Data.(string))
+
+		if !step.Skipped {
+			choices = append(choices, &token_choice[T]{
+				tok:   step.Token,
+				to:    to,
+				modes: step.Modes,
+			})
+
+			continue
+		}
+
+		if to == from {
+			// An empty skipped token would never advance.
+			continue
+		}
+
+		skipped, err := ss.choices(stack, to, step.Modes)
+		if err != nil {
+			continue
+		}
+
+		choices = append(choices, skipped...)
+	}
+
+	if len(choices) == 0 {
+		return nil, lx.NewErrNoMatches()
+	}
+
+	return choices, nil
+}
+
+// ParseScanning parses the input while lexing it. Before matching a token,
+// the lexer asks the parser which terminals it accepts in its current state
+// and only tries those rules; so a text that several rules match, such as a
+// keyword that is also a valid identifier, is tokenized the way the parser
+// expects it.
+//
+// Parameters:
+//   - p: The parser to use.
+//   - lexer: The lexer to use.
+//   - input: The input to parse.
+//
+// Returns:
+//   - []*JointParse: The parses, together with the tokenization of each.
+//   - error: An error if the input could not be parsed.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If p or lexer is nil.
+//   - *lx.ErrNoMatches: If no allowed terminal matches at some position.
+//   - any error of the evaluation that went the furthest, if none accepted.
+//
+// Behaviors:
+//   - The grammar of the parser must have an EOF symbol. The EOF token is
+//     added at the end of every tokenization.
+//   - The modes of the lexer are followed along each tokenization; only the
+//     rules of the active mode are tried. See Lexer.MatchAt.
+//   - The parses are in the order of the decisions, as with Parse, and
+//     stop at the number of parses set with SetConcurrency. The exploration
+//     itself is never concurrent.
+//   - GetParseTree and GetTraces return the results of the accepted parses,
+//     in the same order.
+func ParseScanning[T gr.TokenTyper](p *Parser[T], lexer *lx.Lexer[T], input []byte) ([]*JointParse[T], error) {
+	if p == nil {
+		return nil, uc.NewErrNilParameter("parser")
+	}
+
+	if lexer == nil {
+		return nil, uc.NewErrNilParameter("lexer")
+	}
+
+	src := &scan_source[T]{
+		parser: p,
+		lexer:  lexer,
+		input:  input,
+	}
+
+	return parse_joint(p, src)
+}
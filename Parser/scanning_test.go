@@ -0,0 +1,72 @@
+package Parser
+
+import (
+	"slices"
+	"testing"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+func TestAllowedTerminals(t *testing.T) {
+	p := new_let_parser(t)
+
+	var empty *gr.TokenStack[LetTokenType]
+
+	if p.AllowedTerminals(nil) != nil {
+		t.Errorf("expected every terminal to be allowed without a stack")
+	}
+
+	// The decision table does not look below a WORD on top, so the WORD
+	// may also end a "LET WORD" statement: the result over-approximates.
+	tests := []struct {
+		name     string
+		stack    *gr.TokenStack[LetTokenType]
+		expected []LetTokenType
+	}{
+		{"LET", empty.Push(gr.NewToken(LtLet, "let", 0, nil)), []LetTokenType{LtWord}},
+		{"WORD", empty.Push(gr.NewToken(LtWord, "x", 0, nil)), []LetTokenType{LtEof, LtEq}},
+		{"WORD EQ", empty.Push(gr.NewToken(LtWord, "x", 0, nil)).Push(gr.NewToken(LtEq, "=", 2, nil)), []LetTokenType{LtWord}},
+		{"stmt", empty.Push(gr.NewToken(LtStmt, []*gr.Token[LetTokenType]{}, 0, nil)), []LetTokenType{LtEof}},
+	}
+
+	for _, test := range tests {
+		got := p.AllowedTerminals(test.stack)
+		if !slices.Equal(got, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestParseScanning(t *testing.T) {
+	lexer := new_let_lexer(t)
+	p := new_let_parser(t)
+
+	// After EQ, only a WORD is allowed; so the last "let" is never tried as
+	// a LET, even though both rules match it.
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"let x", "LET(let) WORD(x) EOF()"},
+		{"x = let", "WORD(x) EQ(=) WORD(let) EOF()"},
+		{"let = let", "WORD(let) EQ(=) WORD(let) EOF()"},
+	}
+
+	for _, test := range tests {
+		parses, err := ParseScanning(p, lexer, []byte(test.source))
+		if err != nil {
+			t.Fatalf("%q: ParseScanning failed: %s", test.source, err.Error())
+		}
+
+		got := joint_tokens(parses)
+		if len(got) != 1 || got[0] != test.expected {
+			t.Errorf("%q: expected [%s], got %v", test.source, test.expected, got)
+		}
+	}
+
+	// A "=" is never allowed after a LET.
+	_, err := ParseScanning(p, lexer, []byte("let ="))
+	if err == nil {
+		t.Errorf("expected an error, got nil")
+	}
+}
@@ -0,0 +1,135 @@
+package Parser
+
+import (
+	"slices"
+
+	cs "github.com/PlayerR9/LyneParser/ConflictSolver"
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	cds "github.com/PlayerR9/MyGoLib/CustomData/Stream"
+)
+
+// TraceStep is one step of the trace of an evaluation. It is only recorded
+// when tracing is enabled.
+type TraceStep[T gr.TokenTyper] struct {
+	// Stack is the stack before the step.
+	Stack *gr.TokenStack[T]
+
+	// Lookahead is the next token of the input stream that was not shifted
+	// yet. Nil if the whole input stream was shifted.
+	Lookahead *gr.Token[T]
+
+	// Chosen is the action taken by the evaluation. Nil if no action could be
+	// taken.
+	Chosen cs.HelperElem[T]
+
+	// Forked are the other actions that matched and were taken by sibling
+	// evaluations.
+	Forked []cs.HelperElem[T]
+
+	// Rejected are the actions of the decision table that did not match,
+	// together with the reason they did not.
+	Rejected []*cs.Rejection[T]
+
+	// Err is the reason the evaluation stopped at this step. Nil if it did not.
+	Err error
+}
+
+// trace_list is a persistent list of trace steps. Like stack_history, it is
+// shared between the copies of an evaluation.
+type trace_list[T gr.TokenTyper] struct {
+	// step is the last step.
+	step *TraceStep[T]
+
+	// prev are the steps before this one.
+	prev *trace_list[T]
+}
+
+// add_step is a helper method that records a step of the evaluation. Does
+// nothing if tracing is disabled.
+//
+// Parameters:
+//   - step: The step to record.
+func (ce *CurrentEval[T]) add_step(step *TraceStep[T]) {
+	if !ce.trace {
+		return
+	}
+
+	ce.steps = &trace_list[T]{
+		step: step,
+		prev: ce.steps,
+	}
+}
+
+// GetTrace returns the steps that led to the current evaluation, from the
+// first to the last one. Only available when tracing is enabled.
+//
+// Returns:
+//   - []*TraceStep: The steps of the evaluation.
+func (ce *CurrentEval[T]) GetTrace() []*TraceStep[T] {
+	var steps []*TraceStep[T]
+
+	for s := ce.steps; s != nil; s = s.prev {
+		steps = append(steps, s.step)
+	}
+
+	slices.Reverse(steps)
+
+	return steps
+}
+
+// without is a helper function that returns the elements of a slice except
+// the one at the given index.
+//
+// Parameters:
+//   - elems: The elements.
+//   - index: The index of the element to remove.
+//
+// Returns:
+//   - []cs.HelperElem: The remaining elements. Nil if there are none.
+func without[T gr.TokenTyper](elems []cs.HelperElem[T], index int) []cs.HelperElem[T] {
+	if len(elems) < 2 {
+		return nil
+	}
+
+	others := make([]cs.HelperElem[T], 0, len(elems)-1)
+	others = append(others, elems[:index]...)
+	others = append(others, elems[index+1:]...)
+
+	return others
+}
+
+// new_step is a helper method that creates a step from the current state of
+// the evaluation.
+//
+// Parameters:
+//   - source: The input stream.
+//   - chosen: The action taken by the evaluation.
+//   - forked: The other actions taken by sibling evaluations.
+//   - rejected: The actions that did not match.
+//   - err: The reason the evaluation stopped, if any.
+//
+// Returns:
+//   - *TraceStep: The new step. Nil if tracing is disabled.
+func (ce *CurrentEval[T]) new_step(source *cds.Stream[*gr.Token[T]], chosen cs.HelperElem[T], forked []cs.HelperElem[T], rejected []*cs.Rejection[T], err error) *TraceStep[T] {
+	if !ce.trace {
+		return nil
+	}
+
+	var lookahead *gr.Token[T]
+
+	toks, _ := source.Get(ce.current_index, 1)
+	if len(toks) > 0 {
+		lookahead = toks[0]
+	}
+
+	step := &TraceStep[T]{
+		Stack:     ce.stack,
+		Lookahead: lookahead,
+		Chosen:    chosen,
+		Forked:    forked,
+		Rejected:  rejected,
+		Err:       err,
+	}
+
+	return step
+}
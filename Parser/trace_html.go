@@ -0,0 +1,313 @@
+package Parser
+
+import (
+	"html/template"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	uc "github.com/PlayerR9/MyGoLib/Units/common"
+)
+
+// html_token is the representation of a token in the HTML trace.
+type html_token struct {
+	// Type is the type of the token.
+	Type string `json:"type"`
+
+	// Text is the text of the token. Empty for non-terminals.
+	Text string `json:"text"`
+}
+
+// html_step is the representation of a step in the HTML trace.
+type html_step struct {
+	// Stack are the tokens of the stack, from the bottom to the top.
+	Stack []html_token `json:"stack"`
+
+	// Lookahead is the lookahead token. Nil if there is none.
+	Lookahead *html_token `json:"lookahead"`
+
+	// Start is the offset, in UTF-16 code units, of the lookahead in the
+	// source. -1 if there is no lookahead.
+	Start int `json:"start"`
+
+	// End is the offset, in UTF-16 code units, of the end of the lookahead
+	// in the source.
+	End int `json:"end"`
+
+	// Chosen is the action taken. Empty if none.
+	Chosen string `json:"chosen"`
+
+	// Forked are the actions taken by the sibling evaluations.
+	Forked []string `json:"forked"`
+
+	// Rejected are the rejected actions with the reason of the rejection.
+	Rejected []string `json:"rejected"`
+
+	// Err is the reason the evaluation stopped. Empty if it did not.
+	Err string `json:"err"`
+}
+
+// trace_page is the data of the HTML trace template.
+type trace_page struct {
+	// Source is the source text.
+	Source string
+
+	// Steps are the steps of the trace.
+	Steps []html_step
+}
+
+// utf16_offsets is a helper function that maps every byte offset of the
+// source to the corresponding offset in UTF-16 code units, as used by
+// JavaScript strings.
+//
+// Parameters:
+//   - source: The source text.
+//
+// Returns:
+//   - []int: The offsets. Its length is len(source)+1.
+func utf16_offsets(source []byte) []int {
+	offsets := make([]int, len(source)+1)
+
+	var count int
+
+	for i := 0; i < len(source); {
+		r, size := utf8.DecodeRune(source[i:])
+
+		for j := 0; j < size; j++ {
+			offsets[i+j] = count
+		}
+
+		i += size
+		count += len(utf16.Encode([]rune{r}))
+	}
+
+	offsets[len(source)] = count
+
+	return offsets
+}
+
+// new_html_token is a helper function that converts a token for the HTML
+// trace.
+//
+// Parameters:
+//   - tok: The token to convert.
+//
+// Returns:
+//   - html_token: The converted token.
+func new_html_token[T gr.TokenTyper](tok *gr.Token[T]) html_token {
+	ht := html_token{
+		Type: tok.GetID().String(),
+	}
+
+	str, ok := tok.GetData().(string)
+	if ok {
+		ht.Text = str
+	}
+
+	return ht
+}
+
+// new_html_step is a helper function that converts a step for the HTML trace.
+//
+// Parameters:
+//   - step: The step to convert.
+//   - offsets: The UTF-16 offsets of the source.
+//
+// Returns:
+//   - html_step: The converted step.
+func new_html_step[T gr.TokenTyper](step *TraceStep[T], offsets []int) html_step {
+	hs := html_step{
+		Start: -1,
+	}
+
+	toks := step.Stack.Slice()
+
+	for i := len(toks) - 1; i >= 0; i-- {
+		hs.Stack = append(hs.Stack, new_html_token(toks[i]))
+	}
+
+	if step.Lookahead != nil {
+		ht := new_html_token(step.Lookahead)
+		hs.Lookahead = &ht
+
+		start := step.Lookahead.GetPos()
+		end := start + len(ht.Text)
+
+		if start >= 0 && end < len(offsets) {
+			hs.Start = offsets[start]
+			hs.End = offsets[end]
+		}
+	}
+
+	if step.Chosen != nil {
+		hs.Chosen = step.Chosen.String()
+	}
+
+	for _, act := range step.Forked {
+		hs.Forked = append(hs.Forked, act.String())
+	}
+
+	for _, rej := range step.Rejected {
+		hs.Rejected = append(hs.Rejected, rej.String())
+	}
+
+	if step.Err != nil {
+		hs.Err = step.Err.Error()
+	}
+
+	return hs
+}
+
+// ExportTraceHTML writes a self-contained HTML page that allows to step
+// forwards and backwards through the trace of an evaluation. The source text
+// is shown with the lookahead of the current step highlighted.
+//
+// Parameters:
+//   - w: The writer to write the page to.
+//   - source: The source text that was lexed and parsed.
+//   - steps: The steps of the trace, as returned by CurrentEval.GetTrace().
+//
+// Returns:
+//   - error: An error if the page could not be written.
+//
+// Errors:
+//   - *uc.ErrInvalidParameter: If w is nil.
+//   - any error returned by the writer.
+func ExportTraceHTML[T gr.TokenTyper](w io.Writer, source []byte, steps []*TraceStep[T]) error {
+	if w == nil {
+		return uc.NewErrNilParameter("w")
+	}
+
+	offsets := utf16_offsets(source)
+
+	page := trace_page{
+		Source: string(source),
+		Steps:  make([]html_step, 0, len(steps)),
+	}
+
+	for _, step := range steps {
+		if step != nil {
+			page.Steps = append(page.Steps, new_html_step(step, offsets))
+		}
+	}
+
+	return trace_template.Execute(w, page)
+}
+
+// trace_template is the template of the HTML trace. The data is embedded as
+// JSON by html/template, so it is always correctly escaped.
+var trace_template *template.Template = template.Must(template.New("trace").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Parse trace</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+pre { background: #f6f6f6; padding: .5em; white-space: pre-wrap; }
+mark { background: #ffd54f; }
+#controls button { min-width: 4em; }
+.stack span { display: inline-block; border: 1px solid #999; margin: 2px; padding: 2px 4px; font-family: monospace; }
+.chosen { color: #2e7d32; }
+.forked { color: #1565c0; }
+.rejected { color: #c62828; }
+.error { color: #c62828; font-weight: bold; }
+</style>
+</head>
+<body>
+<div id="controls">
+<button id="first">|&lt;</button>
+<button id="prev">&lt;</button>
+<span id="counter"></span>
+<button id="next">&gt;</button>
+<button id="last">&gt;|</button>
+</div>
+<h3>Source</h3>
+<pre id="source"></pre>
+<h3>Stack (bottom to top)</h3>
+<div id="stack" class="stack"></div>
+<h3>Lookahead</h3>
+<div id="lookahead"></div>
+<h3>Action</h3>
+<div id="chosen" class="chosen"></div>
+<ul id="forked" class="forked"></ul>
+<h3>Rejected</h3>
+<ul id="rejected" class="rejected"></ul>
+<div id="error" class="error"></div>
+<script>
+const source = {{.Source}};
+const steps = {{.Steps}};
+let current = 0;
+
+function text(id, str) {
+	document.getElementById(id).textContent = str;
+}
+
+function list(id, items) {
+	const ul = document.getElementById(id);
+	ul.replaceChildren();
+	for (const item of items || []) {
+		const li = document.createElement("li");
+		li.textContent = item;
+		ul.appendChild(li);
+	}
+}
+
+function tokenString(tok) {
+	return tok.text === "" ? tok.type : tok.type + " " + JSON.stringify(tok.text);
+}
+
+function render() {
+	const pre = document.getElementById("source");
+	pre.replaceChildren();
+
+	text("counter", steps.length === 0 ? "0 / 0" : (current + 1) + " / " + steps.length);
+
+	const step = steps[current];
+	if (!step) {
+		pre.textContent = source;
+		return;
+	}
+
+	if (step.start >= 0) {
+		const mark = document.createElement("mark");
+		mark.textContent = source.slice(step.start, step.end);
+		pre.append(source.slice(0, step.start), mark, source.slice(step.end));
+	} else {
+		pre.textContent = source;
+	}
+
+	const stack = document.getElementById("stack");
+	stack.replaceChildren();
+	for (const tok of step.stack || []) {
+		const span = document.createElement("span");
+		span.textContent = tokenString(tok);
+		stack.appendChild(span);
+	}
+
+	text("lookahead", step.lookahead ? tokenString(step.lookahead) : "(end of input)");
+	text("chosen", step.chosen === "" ? "(none)" : step.chosen);
+	list("forked", (step.forked || []).map(function (a) { return "forked: " + a; }));
+	list("rejected", step.rejected);
+	text("error", step.err);
+}
+
+function go(i) {
+	current = Math.max(0, Math.min(steps.length - 1, i));
+	render();
+}
+
+document.getElementById("first").onclick = function () { go(0); };
+document.getElementById("prev").onclick = function () { go(current - 1); };
+document.getElementById("next").onclick = function () { go(current + 1); };
+document.getElementById("last").onclick = function () { go(steps.length - 1); };
+document.addEventListener("keydown", function (e) {
+	if (e.key === "ArrowLeft") go(current - 1);
+	if (e.key === "ArrowRight") go(current + 1);
+});
+
+render();
+</script>
+</body>
+</html>
+`))
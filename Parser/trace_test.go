@@ -0,0 +1,127 @@
+package Parser
+
+import (
+	"testing"
+
+	cs "github.com/PlayerR9/LyneParser/ConflictSolver"
+)
+
+func TestTracesSuccess(t *testing.T) {
+	p := new_test_parser(t)
+	p.SetTracing(true)
+
+	trees := parse_all(t, p, "a,b,c")
+
+	traces, err := p.GetTraces()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if len(traces) != len(trees) {
+		t.Fatalf("expected %d traces, got %d", len(trees), len(traces))
+	}
+
+	for i, trace := range traces {
+		if len(trace) == 0 {
+			t.Fatalf("trace %d: expected steps, got none", i)
+		}
+
+		for j, step := range trace {
+			if step.Err != nil {
+				t.Errorf("trace %d, step %d: expected no error, got %s", i, j, step.Err.Error())
+			}
+		}
+
+		last := trace[len(trace)-1]
+
+		_, ok := last.Chosen.(*cs.ActAccept[TestTokenType])
+		if !ok {
+			t.Errorf("trace %d: expected to end with an accept, got %v", i, last.Chosen)
+		}
+	}
+
+	// The ambiguous grammar forks, so both trees have a step with the
+	// alternative that the other one took.
+	for i, trace := range traces {
+		var forked bool
+
+		for _, step := range trace {
+			if len(step.Forked) > 0 {
+				forked = true
+			}
+		}
+
+		if !forked {
+			t.Errorf("trace %d: expected a forked step", i)
+		}
+	}
+}
+
+func TestTracesFailure(t *testing.T) {
+	p := new_test_parser(t)
+	p.SetTracing(true)
+
+	parse_all(t, p, "a,b")
+
+	err := Parse(p, new_test_source("a,b,"))
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+
+	_, err = p.GetEvals()
+	if err == nil {
+		t.Errorf("expected the evaluations of the previous parse to be dropped")
+	}
+
+	traces, err := p.GetTraces()
+	if err != nil {
+		t.Fatalf("expected the traces of the rejected branches, got %s", err.Error())
+	}
+
+	if len(traces) == 0 {
+		t.Fatalf("expected at least one trace")
+	}
+
+	var fork_failed bool
+
+	for i, trace := range traces {
+		if len(trace) == 0 {
+			t.Fatalf("trace %d: expected steps, got none", i)
+		}
+
+		last := trace[len(trace)-1]
+		if last.Err == nil {
+			t.Errorf("trace %d: expected the last step to hold the reason", i)
+		}
+
+		if last.Chosen != nil && len(last.Forked) > 0 {
+			fork_failed = true
+		}
+	}
+
+	// The reduction of "list" by the last WORD is an alternative of a fork
+	// that fails on the missing COMMA; its decision must be in the trace.
+	if !fork_failed {
+		t.Errorf("expected the failed decision of a fork to be recorded")
+	}
+
+	rejected, err := p.GetRejectedTraces()
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if len(rejected) != len(traces) {
+		t.Errorf("expected GetTraces to return the %d rejected traces, got %d", len(rejected), len(traces))
+	}
+}
+
+func TestTracesDisabled(t *testing.T) {
+	p := new_test_parser(t)
+
+	parse_all(t, p, "a")
+
+	_, err := p.GetTraces()
+	if err == nil {
+		t.Errorf("expected an error without tracing")
+	}
+}
@@ -0,0 +1,850 @@
+package Playground
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	hl "github.com/PlayerR9/LyneParser/Highlighter"
+	inc "github.com/PlayerR9/LyneParser/Incremental"
+	ps "github.com/PlayerR9/LyneParser/Parser"
+)
+
+// pane is a pane of the playground.
+type pane int
+
+const (
+	// grammar_pane is the pane of the grammar file.
+	grammar_pane pane = iota
+
+	// input_pane is the pane of the input.
+	input_pane
+
+	// output_pane is the pane of the tokens, of the highlighted input and of
+	// the parse tree.
+	output_pane
+)
+
+// output_view is what the output pane shows.
+type output_view int
+
+const (
+	// view_tokens shows the tokens of the input.
+	view_tokens output_view = iota
+
+	// view_highlight shows the highlighted input.
+	view_highlight
+
+	// view_tree shows the parse tree of the input.
+	view_tree
+)
+
+// String implements the fmt.Stringer interface.
+func (v output_view) String() string {
+	return [...]string{
+		"Tokens",
+		"Highlight",
+		"Parse tree",
+	}[v]
+}
+
+// default_palette are the colors the terminals get when there is no theme.
+var default_palette = []hl.Color{
+	hl.ColorIndex(33),
+	hl.ColorIndex(208),
+	hl.ColorIndex(70),
+	hl.ColorIndex(170),
+	hl.ColorIndex(37),
+	hl.ColorIndex(178),
+}
+
+// default_theme is a helper function that creates a theme that gives every
+// terminal a color of the default palette.
+//
+// Parameters:
+//   - lang: The language.
+//
+// Returns:
+//   - *hl.Theme: The theme. Never nil.
+func default_theme(lang *Language) *hl.Theme {
+	theme := hl.NewTheme(hl.Style{})
+	theme.Error = hl.Style{Fg: hl.ColorIndex(196), Underline: true}
+
+	var i int
+
+	for _, t := range lang.GetTerminals() {
+		if lang.GetLexer().IsSkipped(t) {
+			continue
+		}
+
+		theme.Styles[t.String()] = hl.Style{Fg: default_palette[i%len(default_palette)]}
+		i++
+	}
+
+	return theme
+}
+
+// rect is a rectangle of the screen.
+type rect struct {
+	// x and y are the coordinates of the top-left cell.
+	x, y int
+
+	// w and h are the width and the height, in cells.
+	w, h int
+}
+
+// App is an interactive terminal application to develop grammars: one pane
+// loads a grammar file, one edits an input, and one shows the tokens, the
+// highlighted input or the parse tree of the input as it is typed.
+type App struct {
+	// screen is the screen. Nil until Run is called.
+	screen tcell.Screen
+
+	// theme is the theme given by the user. Nil if the default one is used.
+	theme *hl.Theme
+
+	// path is the location of the grammar file.
+	path string
+
+	// grammar_text is the content of the grammar file.
+	grammar_text string
+
+	// grammar_err is the error of the last load of the grammar file. Nil if
+	// the load succeeded.
+	grammar_err error
+
+	// lang is the loaded language. Nil if no grammar was loaded yet.
+	lang *Language
+
+	// highlighter is the highlighter of the language. Nil if lang is nil.
+	highlighter *hl.Highlighter[Symbol]
+
+	// doc is the parsed input. Nil if there is none.
+	doc *inc.Document[Symbol]
+
+	// parse_err is the error of the last parse of the input. Nil if it
+	// succeeded.
+	parse_err error
+
+	// input is the input.
+	input *editor
+
+	// tree is the view of the parse tree.
+	tree *tree_view
+
+	// focus is the pane that receives the keys.
+	focus pane
+
+	// view is what the output pane shows.
+	view output_view
+
+	// grammar_scroll, input_scroll and output_scroll are the first visible
+	// lines of the panes.
+	grammar_scroll, input_scroll, output_scroll int
+
+	// quit is true once the user asked to quit.
+	quit bool
+}
+
+// NewApp creates a playground.
+//
+// Parameters:
+//   - path: The location of the grammar file. Empty if the user types it.
+//   - theme: The theme of the highlighted input. Nil gives every terminal a
+//     color of a default palette.
+//
+// Returns:
+//   - *App: The new playground. Never nil.
+func NewApp(path string, theme *hl.Theme) *App {
+	a := &App{
+		theme: theme,
+		path:  path,
+		input: new_editor(nil),
+		tree:  new_tree_view(),
+	}
+
+	if path == "" {
+		a.focus = grammar_pane
+	} else {
+		a.focus = input_pane
+	}
+
+	return a
+}
+
+// Run shows the playground until the user quits.
+//
+// Returns:
+//   - error: An error if the terminal could not be set up.
+//
+// Behaviors:
+//   - Keys: Tab and Shift-Tab move the focus between the panes; F1, F2 and
+//     F3 choose what the output pane shows; Ctrl-R reloads the grammar file;
+//     Esc and Ctrl-C quit.
+func (a *App) Run() error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+
+	err = screen.Init()
+	if err != nil {
+		return err
+	}
+
+	defer screen.Fini()
+
+	a.screen = screen
+
+	if a.path != "" {
+		a.load()
+	}
+
+	for !a.quit {
+		a.draw()
+
+		switch ev := screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			screen.Sync()
+		case *tcell.EventKey:
+			a.handle_key(ev)
+		}
+	}
+
+	return nil
+}
+
+// load is a helper method that loads the grammar file and parses the input
+// again.
+//
+// Behaviors:
+//   - If the grammar is invalid, the error is shown and the previous
+//     grammar stays in use.
+func (a *App) load() {
+	data, err := os.ReadFile(a.path)
+	if err != nil {
+		a.grammar_err = err
+		return
+	}
+
+	a.grammar_text = string(data)
+	a.grammar_scroll = 0
+
+	lang, err := ParseLanguage(data)
+	if err != nil {
+		a.grammar_err = err
+		return
+	}
+
+	h, err := hl.NewHighlighter(lang.GetLexer(), hl.Style{})
+	if err != nil {
+		a.grammar_err = err
+		return
+	}
+
+	theme := a.theme
+	if theme == nil {
+		theme = default_theme(lang)
+	}
+
+	err = h.ApplyTheme(theme)
+	if err != nil {
+		a.grammar_err = err
+		return
+	}
+
+	a.grammar_err = nil
+	a.lang = lang
+	a.highlighter = h
+
+	a.reparse()
+}
+
+// reparse is a helper method that parses the whole input.
+func (a *App) reparse() {
+	if a.lang == nil {
+		return
+	}
+
+	a.doc, a.parse_err = inc.NewDocument(a.lang.GetLexer(), a.lang.GetParser(), a.input.text)
+
+	a.update_tree()
+}
+
+// update_tree is a helper method that shows the parse tree of the document.
+func (a *App) update_tree() {
+	var root *gr.Token[Symbol]
+
+	if a.doc != nil {
+		root = a.doc.GetTree()
+	}
+
+	a.tree.set_root(root)
+}
+
+// apply is a helper method that applies an edit of the input to the
+// document.
+//
+// Parameters:
+//   - ed: The edit. Nil if the input did not change.
+func (a *App) apply(ed *edit) {
+	if ed == nil || a.lang == nil {
+		return
+	}
+
+	if a.doc == nil {
+		a.reparse()
+		return
+	}
+
+	change, err := a.doc.Edit(ed.start, ed.end, ed.text)
+	if change == nil && err != nil {
+		a.reparse()
+		return
+	}
+
+	a.parse_err = err
+
+	a.update_tree()
+}
+
+// handle_key is a helper method that handles a key.
+//
+// Parameters:
+//   - ev: The key event.
+func (a *App) handle_key(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		a.quit = true
+	case tcell.KeyTab:
+		a.focus = (a.focus + 1) % 3
+	case tcell.KeyBacktab:
+		a.focus = (a.focus + 2) % 3
+	case tcell.KeyF1:
+		a.set_view(view_tokens)
+	case tcell.KeyF2:
+		a.set_view(view_highlight)
+	case tcell.KeyF3:
+		a.set_view(view_tree)
+	case tcell.KeyCtrlR:
+		a.load()
+	default:
+		switch a.focus {
+		case grammar_pane:
+			a.handle_grammar_key(ev)
+		case input_pane:
+			a.handle_input_key(ev)
+		case output_pane:
+			a.handle_output_key(ev)
+		}
+	}
+}
+
+// set_view is a helper method that changes what the output pane shows.
+//
+// Parameters:
+//   - view: The view to show.
+func (a *App) set_view(view output_view) {
+	a.view = view
+	a.output_scroll = 0
+}
+
+// handle_grammar_key is a helper method that handles a key of the grammar
+// pane: the location of the file is typed and Enter loads it.
+//
+// Parameters:
+//   - ev: The key event.
+func (a *App) handle_grammar_key(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyRune:
+		a.path += string(ev.Rune())
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		_, size := utf8.DecodeLastRuneInString(a.path)
+		a.path = a.path[:len(a.path)-size]
+	case tcell.KeyEnter:
+		a.load()
+	case tcell.KeyUp:
+		a.grammar_scroll = max(a.grammar_scroll-1, 0)
+	case tcell.KeyDown:
+		a.grammar_scroll++
+	}
+}
+
+// handle_input_key is a helper method that handles a key of the input pane.
+//
+// Parameters:
+//   - ev: The key event.
+func (a *App) handle_input_key(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyRune:
+		a.apply(a.input.insert(string(ev.Rune())))
+	case tcell.KeyEnter:
+		a.apply(a.input.insert("\n"))
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		a.apply(a.input.backspace())
+	case tcell.KeyDelete:
+		a.apply(a.input.delete())
+	case tcell.KeyLeft:
+		a.input.left()
+	case tcell.KeyRight:
+		a.input.right()
+	case tcell.KeyUp:
+		a.input.up()
+	case tcell.KeyDown:
+		a.input.down()
+	case tcell.KeyHome:
+		a.input.home()
+	case tcell.KeyEnd:
+		a.input.end()
+	}
+}
+
+// handle_output_key is a helper method that handles a key of the output
+// pane: the arrows scroll, or move in the parse tree where Enter and Space
+// collapse or expand a node.
+//
+// Parameters:
+//   - ev: The key event.
+func (a *App) handle_output_key(ev *tcell.EventKey) {
+	if a.view == view_tree {
+		switch ev.Key() {
+		case tcell.KeyUp:
+			a.tree.up()
+		case tcell.KeyDown:
+			a.tree.down()
+		case tcell.KeyLeft:
+			a.tree.set_collapsed(true)
+		case tcell.KeyRight:
+			a.tree.set_collapsed(false)
+		case tcell.KeyEnter:
+			a.tree.toggle()
+		case tcell.KeyRune:
+			if ev.Rune() == ' ' {
+				a.tree.toggle()
+			}
+		}
+
+		return
+	}
+
+	switch ev.Key() {
+	case tcell.KeyUp:
+		a.output_scroll = max(a.output_scroll-1, 0)
+	case tcell.KeyDown:
+		a.output_scroll++
+	case tcell.KeyPgUp:
+		a.output_scroll = max(a.output_scroll-10, 0)
+	case tcell.KeyPgDn:
+		a.output_scroll += 10
+	}
+}
+
+// draw_text is a helper method that writes a text on one line, clipped to a
+// width.
+//
+// Parameters:
+//   - x: The column of the first cell.
+//   - y: The row.
+//   - width: The number of cells available.
+//   - text: The text.
+//   - style: The style of the text.
+func (a *App) draw_text(x, y, width int, text string, style tcell.Style) {
+	var col int
+
+	for _, r := range text {
+		if col >= width {
+			return
+		}
+
+		if r == '\t' {
+			r = ' '
+		}
+
+		a.screen.SetContent(x+col, y, r, nil, style)
+		col++
+	}
+}
+
+// draw_box is a helper method that draws the border of a pane.
+//
+// Parameters:
+//   - r: The area of the pane.
+//   - title: The title of the pane.
+//   - focused: True if the pane has the focus.
+//
+// Returns:
+//   - rect: The area inside the border.
+func (a *App) draw_box(r rect, title string, focused bool) rect {
+	style := tcell.StyleDefault
+	if focused {
+		style = style.Foreground(tcell.ColorYellow).Bold(true)
+	}
+
+	for x := r.x + 1; x < r.x+r.w-1; x++ {
+		a.screen.SetContent(x, r.y, tcell.RuneHLine, nil, style)
+		a.screen.SetContent(x, r.y+r.h-1, tcell.RuneHLine, nil, style)
+	}
+
+	for y := r.y + 1; y < r.y+r.h-1; y++ {
+		a.screen.SetContent(r.x, y, tcell.RuneVLine, nil, style)
+		a.screen.SetContent(r.x+r.w-1, y, tcell.RuneVLine, nil, style)
+	}
+
+	a.screen.SetContent(r.x, r.y, tcell.RuneULCorner, nil, style)
+	a.screen.SetContent(r.x+r.w-1, r.y, tcell.RuneURCorner, nil, style)
+	a.screen.SetContent(r.x, r.y+r.h-1, tcell.RuneLLCorner, nil, style)
+	a.screen.SetContent(r.x+r.w-1, r.y+r.h-1, tcell.RuneLRCorner, nil, style)
+
+	a.draw_text(r.x+2, r.y, r.w-4, " "+title+" ", style)
+
+	inner := rect{
+		x: r.x + 1,
+		y: r.y + 1,
+		w: max(r.w-2, 0),
+		h: max(r.h-2, 0),
+	}
+
+	return inner
+}
+
+// parse_error_range is a helper method that returns the part of the input
+// where the last parse failed.
+//
+// Returns:
+//   - int: The start of the part, in bytes.
+//   - int: The end of the part, in bytes. Not included.
+//   - bool: False if the last parse did not fail on a token.
+func (a *App) parse_error_range() (int, int, bool) {
+	var ut *ps.ErrUnexpectedToken
+
+	if !errors.As(a.parse_err, &ut) {
+		return 0, 0, false
+	}
+
+	return ut.At, ut.At + max(len(ut.Data), 1), true
+}
+
+// source_styles is a helper method that returns the style of every byte of
+// the input, and of the position after its end.
+//
+// Parameters:
+//   - colored: True to use the styles of the highlighter, false to only
+//     show the errors.
+//
+// Returns:
+//   - []tcell.Style: The styles. One more than the bytes of the input.
+//
+// Behaviors:
+//   - The text that the lexer could not match and the token where the parse
+//     failed are underlined.
+func (a *App) source_styles(colored bool) []tcell.Style {
+	src := a.input.text
+
+	error_style := tcell.StyleDefault.Foreground(tcell.ColorRed).Underline(true)
+
+	styles := make([]tcell.Style, len(src)+1)
+	for i := range styles {
+		styles[i] = tcell.StyleDefault
+	}
+
+	if a.highlighter != nil {
+		var root *gr.Token[Symbol]
+
+		if a.doc != nil {
+			root = a.doc.GetTree()
+		}
+
+		for _, seg := range a.highlighter.HighlightTree(src, root) {
+			style := tcell.StyleDefault
+
+			switch {
+			case seg.Error && colored:
+				style = seg.Style.Tcell().Underline(true)
+			case seg.Error:
+				style = error_style
+			case colored:
+				style = seg.Style.Tcell()
+			}
+
+			for i := seg.At; i < seg.At+len(seg.Text); i++ {
+				styles[i] = style
+			}
+		}
+	}
+
+	start, end, ok := a.parse_error_range()
+	if !ok {
+		return styles
+	}
+
+	for i := start; i < end && i < len(styles); i++ {
+		if colored {
+			styles[i] = styles[i].Underline(true)
+		} else {
+			styles[i] = error_style
+		}
+	}
+
+	return styles
+}
+
+// draw_source is a helper method that draws the input.
+//
+// Parameters:
+//   - r: The area to draw in.
+//   - styles: The style of every byte. See source_styles.
+//   - scroll: The first visible line.
+//   - cursor: The position of the cursor, in bytes. -1 if there is none.
+//
+// Behaviors:
+//   - A line break and the end of the input are drawn as a space when they
+//     are styled, so that an error there is visible.
+func (a *App) draw_source(r rect, styles []tcell.Style, scroll, cursor int) {
+	src := a.input.text
+
+	set := func(line, col int, c rune, style tcell.Style) {
+		y := line - scroll
+
+		if y >= 0 && y < r.h && col < r.w {
+			a.screen.SetContent(r.x+col, r.y+y, c, nil, style)
+		}
+	}
+
+	var line, col int
+
+	for at := 0; at <= len(src); {
+		if at == cursor {
+			y := line - scroll
+
+			if y >= 0 && y < r.h && col < r.w {
+				a.screen.ShowCursor(r.x+col, r.y+y)
+			}
+		}
+
+		if at == len(src) {
+			if styles[at] != tcell.StyleDefault {
+				set(line, col, ' ', styles[at])
+			}
+
+			break
+		}
+
+		c, size := utf8.DecodeRune(src[at:])
+
+		switch c {
+		case '\n':
+			if styles[at] != tcell.StyleDefault {
+				set(line, col, ' ', styles[at])
+			}
+
+			line++
+			col = 0
+		case '\t':
+			set(line, col, ' ', styles[at])
+			col++
+		default:
+			set(line, col, c, styles[at])
+			col++
+		}
+
+		at += size
+	}
+}
+
+// draw_grammar is a helper method that draws the grammar pane.
+//
+// Parameters:
+//   - r: The area inside the border.
+func (a *App) draw_grammar(r rect) {
+	if r.h == 0 {
+		return
+	}
+
+	a.draw_text(r.x, r.y, r.w, "File: "+a.path, tcell.StyleDefault.Bold(true))
+
+	if a.focus == grammar_pane {
+		a.screen.ShowCursor(r.x+min(utf8.RuneCountInString("File: "+a.path), r.w-1), r.y)
+	}
+
+	y := r.y + 1
+
+	if a.grammar_err != nil && y < r.y+r.h {
+		a.draw_text(r.x, y, r.w, a.grammar_err.Error(), tcell.StyleDefault.Foreground(tcell.ColorRed))
+		y++
+	}
+
+	lines := strings.Split(a.grammar_text, "\n")
+
+	a.grammar_scroll = min(a.grammar_scroll, max(len(lines)-1, 0))
+
+	for _, line := range lines[a.grammar_scroll:] {
+		if y >= r.y+r.h {
+			break
+		}
+
+		a.draw_text(r.x, y, r.w, line, tcell.StyleDefault)
+		y++
+	}
+}
+
+// draw_input is a helper method that draws the input pane.
+//
+// Parameters:
+//   - r: The area inside the border.
+func (a *App) draw_input(r rect) {
+	line, _ := a.input.position()
+
+	if line < a.input_scroll {
+		a.input_scroll = line
+	} else if r.h > 0 && line >= a.input_scroll+r.h {
+		a.input_scroll = line - r.h + 1
+	}
+
+	cursor := -1
+	if a.focus == input_pane {
+		cursor = a.input.cursor
+	}
+
+	a.draw_source(r, a.source_styles(false), a.input_scroll, cursor)
+}
+
+// draw_tokens is a helper method that draws the tokens of the input.
+//
+// Parameters:
+//   - r: The area to draw in.
+func (a *App) draw_tokens(r rect) {
+	if a.doc == nil {
+		a.draw_text(r.x, r.y, r.w, "no tokens", tcell.StyleDefault.Dim(true))
+		return
+	}
+
+	src := a.doc.GetSource()
+	tokens := a.doc.GetTokens()
+
+	a.output_scroll = min(a.output_scroll, max(len(tokens)-1, 0))
+
+	error_id := SymbolOf(ErrorSymbolName)
+
+	for i, tok := range tokens[a.output_scroll:] {
+		if i >= r.h {
+			break
+		}
+
+		line := bytes.Count(src[:tok.At], []byte{'\n'}) + 1
+		col := utf8.RuneCount(src[bytes.LastIndexByte(src[:tok.At], '\n')+1:tok.At]) + 1
+
+		text, _ := tok.Data.(string)
+
+		row := strconv.Itoa(line) + ":" + strconv.Itoa(col)
+		row += strings.Repeat(" ", max(8-len(row), 1)) + tok.ID.String()
+		row += strings.Repeat(" ", max(24-len(row), 1)) + strconv.Quote(text)
+
+		style := tcell.StyleDefault
+
+		switch {
+		case tok.ID == error_id:
+			style = style.Foreground(tcell.ColorRed).Underline(true)
+		case a.lang.GetLexer().IsSkipped(tok.ID):
+			style = style.Dim(true)
+		}
+
+		a.draw_text(r.x, r.y+i, r.w, row, style)
+	}
+}
+
+// draw_tree is a helper method that draws the parse tree of the input.
+//
+// Parameters:
+//   - r: The area to draw in.
+func (a *App) draw_tree(r rect) {
+	if len(a.tree.rows) == 0 {
+		a.draw_text(r.x, r.y, r.w, "no parse tree", tcell.StyleDefault.Dim(true))
+		return
+	}
+
+	if a.tree.selected < a.output_scroll {
+		a.output_scroll = a.tree.selected
+	} else if r.h > 0 && a.tree.selected >= a.output_scroll+r.h {
+		a.output_scroll = a.tree.selected - r.h + 1
+	}
+
+	for i, row := range a.tree.rows[min(a.output_scroll, len(a.tree.rows)):] {
+		if i >= r.h {
+			break
+		}
+
+		style := tcell.StyleDefault
+
+		if a.output_scroll+i == a.tree.selected && a.focus == output_pane {
+			style = style.Reverse(true)
+		}
+
+		a.draw_text(r.x, r.y+i, r.w, row.label(a.tree.collapsed[row.node]), style)
+	}
+}
+
+// draw_status is a helper method that draws the status line: the error of
+// the last parse, or the keys.
+//
+// Parameters:
+//   - y: The row of the line.
+//   - width: The width of the screen.
+func (a *App) draw_status(y, width int) {
+	if a.parse_err != nil {
+		a.draw_text(0, y, width, a.parse_err.Error(), tcell.StyleDefault.Foreground(tcell.ColorRed))
+		return
+	}
+
+	var msg string
+
+	if a.lang == nil {
+		msg = "No grammar. "
+	} else {
+		msg = "OK. "
+	}
+
+	msg += "Tab: focus  F1/F2/F3: tokens/highlight/tree  Ctrl-R: reload  Esc: quit"
+
+	a.draw_text(0, y, width, msg, tcell.StyleDefault.Dim(true))
+}
+
+// draw is a helper method that draws the whole screen.
+func (a *App) draw() {
+	a.screen.Clear()
+	a.screen.HideCursor()
+
+	width, height := a.screen.Size()
+
+	body := max(height-1, 0)
+	left := width / 2
+
+	grammar := a.draw_box(rect{0, 0, left, body / 2}, "Grammar", a.focus == grammar_pane)
+	a.draw_grammar(grammar)
+
+	input := a.draw_box(rect{0, body / 2, left, body - body/2}, "Input", a.focus == input_pane)
+	a.draw_input(input)
+
+	title := a.view.String() + " (F1 tokens, F2 highlight, F3 tree)"
+
+	output := a.draw_box(rect{left, 0, width - left, body}, title, a.focus == output_pane)
+
+	switch a.view {
+	case view_tokens:
+		a.draw_tokens(output)
+	case view_highlight:
+		a.output_scroll = min(a.output_scroll, bytes.Count(a.input.text, []byte{'\n'}))
+		a.draw_source(output, a.source_styles(true), a.output_scroll, -1)
+	case view_tree:
+		a.draw_tree(output)
+	}
+
+	a.draw_status(height-1, width)
+
+	a.screen.Show()
+}
@@ -0,0 +1,233 @@
+package Playground
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// edit is a change to the text of an editor.
+type edit struct {
+	// start is the start of the replaced range, in bytes.
+	start int
+
+	// end is the end of the replaced range, in bytes. Not included.
+	end int
+
+	// text is the text put in place of the range.
+	text string
+}
+
+// editor is a text buffer with a cursor.
+type editor struct {
+	// text is the text.
+	text []byte
+
+	// cursor is the position of the cursor, in bytes. Always at the start
+	// of a rune.
+	cursor int
+
+	// column is the column the vertical moves try to keep, in runes. -1 if
+	// the column of the cursor is used.
+	column int
+}
+
+// new_editor creates an editor with the cursor at the end of the text.
+//
+// Parameters:
+//   - text: The text. It is copied.
+//
+// Returns:
+//   - *editor: The new editor. Never nil.
+func new_editor(text []byte) *editor {
+	e := &editor{
+		text:   bytes.Clone(text),
+		cursor: len(text),
+		column: -1,
+	}
+
+	return e
+}
+
+// apply is a helper method that applies an edit and moves the cursor to
+// the end of the new text.
+//
+// Parameters:
+//   - ed: The edit.
+//
+// Returns:
+//   - *edit: The edit.
+func (e *editor) apply(ed *edit) *edit {
+	text := make([]byte, 0, len(e.text)-(ed.end-ed.start)+len(ed.text))
+	text = append(text, e.text[:ed.start]...)
+	text = append(text, ed.text...)
+	text = append(text, e.text[ed.end:]...)
+
+	e.text = text
+	e.cursor = ed.start + len(ed.text)
+	e.column = -1
+
+	return ed
+}
+
+// insert inserts a text at the cursor.
+//
+// Parameters:
+//   - text: The text to insert.
+//
+// Returns:
+//   - *edit: The edit. Nil if the text is empty.
+func (e *editor) insert(text string) *edit {
+	if text == "" {
+		return nil
+	}
+
+	return e.apply(&edit{start: e.cursor, end: e.cursor, text: text})
+}
+
+// backspace removes the rune before the cursor.
+//
+// Returns:
+//   - *edit: The edit. Nil if the cursor is at the start of the text.
+func (e *editor) backspace() *edit {
+	if e.cursor == 0 {
+		return nil
+	}
+
+	_, size := utf8.DecodeLastRune(e.text[:e.cursor])
+
+	return e.apply(&edit{start: e.cursor - size, end: e.cursor})
+}
+
+// delete removes the rune after the cursor.
+//
+// Returns:
+//   - *edit: The edit. Nil if the cursor is at the end of the text.
+func (e *editor) delete() *edit {
+	if e.cursor == len(e.text) {
+		return nil
+	}
+
+	_, size := utf8.DecodeRune(e.text[e.cursor:])
+
+	return e.apply(&edit{start: e.cursor, end: e.cursor + size})
+}
+
+// left moves the cursor one rune to the left.
+func (e *editor) left() {
+	if e.cursor > 0 {
+		_, size := utf8.DecodeLastRune(e.text[:e.cursor])
+		e.cursor -= size
+	}
+
+	e.column = -1
+}
+
+// right moves the cursor one rune to the right.
+func (e *editor) right() {
+	if e.cursor < len(e.text) {
+		_, size := utf8.DecodeRune(e.text[e.cursor:])
+		e.cursor += size
+	}
+
+	e.column = -1
+}
+
+// line_start is a helper method that returns the start of the line of a
+// position.
+//
+// Parameters:
+//   - at: The position, in bytes.
+//
+// Returns:
+//   - int: The start of the line, in bytes.
+func (e *editor) line_start(at int) int {
+	return bytes.LastIndexByte(e.text[:at], '\n') + 1
+}
+
+// line_end is a helper method that returns the end of the line of a
+// position; that is, the position of its line break or the end of the text.
+//
+// Parameters:
+//   - at: The position, in bytes.
+//
+// Returns:
+//   - int: The end of the line, in bytes.
+func (e *editor) line_end(at int) int {
+	idx := bytes.IndexByte(e.text[at:], '\n')
+	if idx == -1 {
+		return len(e.text)
+	}
+
+	return at + idx
+}
+
+// home moves the cursor to the start of its line.
+func (e *editor) home() {
+	e.cursor = e.line_start(e.cursor)
+	e.column = -1
+}
+
+// end moves the cursor to the end of its line.
+func (e *editor) end() {
+	e.cursor = e.line_end(e.cursor)
+	e.column = -1
+}
+
+// move_to_column is a helper method that moves the cursor to a column of the
+// line that starts at the given position, or to the end of the line if it
+// is shorter.
+//
+// Parameters:
+//   - start: The start of the line, in bytes.
+func (e *editor) move_to_column(start int) {
+	end := e.line_end(start)
+
+	at := start
+
+	for col := 0; col < e.column && at < end; col++ {
+		_, size := utf8.DecodeRune(e.text[at:end])
+		at += size
+	}
+
+	e.cursor = at
+}
+
+// up moves the cursor to the line above, keeping its column if possible.
+func (e *editor) up() {
+	start := e.line_start(e.cursor)
+	if start == 0 {
+		return
+	}
+
+	if e.column == -1 {
+		_, e.column = e.position()
+	}
+
+	e.move_to_column(e.line_start(start - 1))
+}
+
+// down moves the cursor to the line below, keeping its column if possible.
+func (e *editor) down() {
+	end := e.line_end(e.cursor)
+	if end == len(e.text) {
+		return
+	}
+
+	if e.column == -1 {
+		_, e.column = e.position()
+	}
+
+	e.move_to_column(end + 1)
+}
+
+// position returns the line and the column of the cursor.
+//
+// Returns:
+//   - int: The line, starting at 0.
+//   - int: The column, in runes, starting at 0.
+func (e *editor) position() (int, int) {
+	line := bytes.Count(e.text[:e.cursor], []byte{'\n'})
+	col := utf8.RuneCount(e.text[e.line_start(e.cursor):e.cursor])
+
+	return line, col
+}
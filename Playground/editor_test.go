@@ -0,0 +1,63 @@
+package Playground
+
+import (
+	"testing"
+)
+
+func TestEditor(t *testing.T) {
+	e := new_editor([]byte("héllo\nab\nworld"))
+
+	e.up()
+	e.up()
+
+	line, col := e.position()
+	if line != 0 || col != 5 {
+		t.Fatalf("expected 0:5, got %d:%d", line, col)
+	}
+
+	e.down()
+
+	line, col = e.position()
+	if line != 1 || col != 2 {
+		t.Fatalf("expected 1:2, got %d:%d", line, col)
+	}
+
+	// The column is kept across the shorter line.
+	e.down()
+
+	line, col = e.position()
+	if line != 2 || col != 5 {
+		t.Fatalf("expected 2:5, got %d:%d", line, col)
+	}
+
+	e.up()
+	e.up()
+	e.home()
+	e.right()
+	e.right()
+
+	ed := e.backspace()
+	if ed == nil || ed.start != 1 || ed.end != 3 || ed.text != "" {
+		t.Fatalf("unexpected edit %+v", ed)
+	}
+
+	ed = e.insert("e")
+	if ed == nil || ed.start != 1 || ed.end != 1 || ed.text != "e" {
+		t.Fatalf("unexpected edit %+v", ed)
+	}
+
+	if string(e.text) != "hello\nab\nworld" {
+		t.Fatalf("unexpected text %q", e.text)
+	}
+
+	e.end()
+
+	ed = e.delete()
+	if ed == nil || ed.start != 5 || ed.end != 6 {
+		t.Fatalf("unexpected edit %+v", ed)
+	}
+
+	if string(e.text) != "helloab\nworld" {
+		t.Fatalf("unexpected text %q", e.text)
+	}
+}
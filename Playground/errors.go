@@ -0,0 +1,64 @@
+package Playground
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidLine is an error that is returned when a line of a grammar file
+// is invalid.
+type ErrInvalidLine struct {
+	// Line is the number of the line, starting at 1. 0 if the error is about
+	// the whole file.
+	Line int
+
+	// Reason is the reason why the line is invalid.
+	Reason error
+}
+
+// Error implements the error interface.
+//
+// Message: "line <line>: <reason>", or "grammar: <reason>" when the error
+// is about the whole file.
+func (e *ErrInvalidLine) Error() string {
+	var builder strings.Builder
+
+	if e.Line > 0 {
+		builder.WriteString("line ")
+		builder.WriteString(strconv.Itoa(e.Line))
+	} else {
+		builder.WriteString("grammar")
+	}
+
+	builder.WriteString(": ")
+
+	if e.Reason != nil {
+		builder.WriteString(e.Reason.Error())
+	} else {
+		builder.WriteString("invalid")
+	}
+
+	return builder.String()
+}
+
+// Unwrap returns the reason why the line is invalid.
+func (e *ErrInvalidLine) Unwrap() error {
+	return e.Reason
+}
+
+// NewErrInvalidLine creates a new error of type *ErrInvalidLine.
+//
+// Parameters:
+//   - line: The number of the line, starting at 1. 0 if the error is about
+//     the whole file.
+//   - reason: The reason why the line is invalid.
+//
+// Returns:
+//   - *ErrInvalidLine: The new error. Never nil.
+func NewErrInvalidLine(line int, reason error) *ErrInvalidLine {
+	e := &ErrInvalidLine{
+		Line:   line,
+		Reason: reason,
+	}
+	return e
+}
@@ -0,0 +1,230 @@
+package Playground
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+	ps "github.com/PlayerR9/LyneParser/Parser"
+	prd "github.com/PlayerR9/LyneParser/Production"
+)
+
+const (
+	// ErrorSymbolName is the name of the type of the error tokens; that is,
+	// the text that no lexer rule matches.
+	ErrorSymbolName string = "ERROR"
+)
+
+// Language is a grammar loaded from a grammar file.
+type Language struct {
+	// lexer is the lexer of the grammar.
+	lexer *lx.Lexer[Symbol]
+
+	// parser is the parser of the grammar.
+	parser *ps.Parser[Symbol]
+
+	// terminals are the terminals of the lexer rules, in declaration order.
+	terminals []Symbol
+}
+
+// GetLexer returns the lexer of the language.
+//
+// Returns:
+//   - *lx.Lexer: The lexer. Never nil.
+func (l *Language) GetLexer() *lx.Lexer[Symbol] {
+	return l.lexer
+}
+
+// GetParser returns the parser of the language.
+//
+// Returns:
+//   - *ps.Parser: The parser. Never nil.
+func (l *Language) GetParser() *ps.Parser[Symbol] {
+	return l.parser
+}
+
+// GetTerminals returns the terminals of the lexer rules.
+//
+// Returns:
+//   - []Symbol: The terminals, in declaration order.
+func (l *Language) GetTerminals() []Symbol {
+	return slices.Clone(l.terminals)
+}
+
+// check is a helper function that checks that a grammar file does not
+// define reserved terminals and that its productions only use defined
+// symbols.
+//
+// Parameters:
+//   - f: The grammar file.
+//
+// Returns:
+//   - error: An error of type *ErrInvalidLine if a symbol is reserved or not
+//     defined.
+func check(f *prd.File) error {
+	defined := make(map[string]bool)
+
+	for _, rule := range f.LexerRules {
+		if rule.Name == gr.EOFTokenID || rule.Name == ErrorSymbolName {
+			return NewErrInvalidLine(rule.Line, errors.New(strconv.Quote(rule.Name)+" is reserved"))
+		}
+
+		defined[rule.Name] = true
+	}
+
+	for _, p := range f.Rules {
+		defined[p.Lhs] = true
+	}
+
+	has_eof := false
+
+	for _, p := range f.Rules {
+		for _, s := range p.Rhs {
+			if s == gr.EOFTokenID {
+				has_eof = true
+				continue
+			}
+
+			if defined[s] {
+				continue
+			}
+
+			if is_terminal_name(s) {
+				return NewErrInvalidLine(p.Line, errors.New("no lexer rule for "+s))
+			}
+
+			return NewErrInvalidLine(p.Line, errors.New("no production for "+s))
+		}
+	}
+
+	if !defined[gr.StartSymbolID] || is_terminal_name(gr.StartSymbolID) {
+		return NewErrInvalidLine(0, errors.New("missing a production for "+gr.StartSymbolID))
+	}
+
+	if !has_eof {
+		return NewErrInvalidLine(0, errors.New("no production uses "+gr.EOFTokenID))
+	}
+
+	return nil
+}
+
+// ParseLanguage parses a grammar file. See Production.Parse for the format;
+// in addition:
+//   - The start symbol is "source" and one of its productions must end
+//     with EOF.
+//   - EOF and ERROR cannot be the names of lexer rules.
+//   - The lexer rules are tried in the order of the file.
+//
+// Parameters:
+//   - data: The content of the file.
+//
+// Returns:
+//   - *Language: The language.
+//   - error: An error if the grammar is invalid.
+//
+// Errors:
+//   - *ErrInvalidLine: If a line is invalid or a symbol is not defined.
+//   - any error of the parser while solving the conflicts of the grammar.
+//
+// Behaviors:
+//   - The text that no rule matches, up to the next whitespace, becomes an
+//     error token of type ERROR.
+func ParseLanguage(data []byte) (*Language, error) {
+	f, err := prd.Parse(data)
+	if err != nil {
+		var syntax_err *prd.ErrSyntax
+
+		if errors.As(err, &syntax_err) {
+			return nil, NewErrInvalidLine(syntax_err.Line, syntax_err.Reason)
+		}
+
+		return nil, err
+	}
+
+	err = check(f)
+	if err != nil {
+		return nil, err
+	}
+
+	to_skip := make([]Symbol, 0, len(f.ToSkip))
+
+	for _, name := range f.ToSkip {
+		to_skip = append(to_skip, SymbolOf(name))
+	}
+
+	lg := lx.NewGrammar(to_skip)
+
+	var terminals []Symbol
+
+	for _, rule := range f.LexerRules {
+		lhs := SymbolOf(rule.Name)
+
+		if rule.Quoted {
+			err = lg.AddLiteral(lhs, rule.Value, false)
+		} else {
+			err = lg.AddRule(lhs, rule.Value)
+		}
+
+		if err != nil {
+			return nil, NewErrInvalidLine(rule.Line, err)
+		}
+
+		if !slices.Contains(terminals, lhs) {
+			terminals = append(terminals, lhs)
+		}
+	}
+
+	lexer := lx.NewLexer(lg)
+	lexer.SetRecovery(lx.NewSkipToWhitespaceRecovery(SymbolOf(ErrorSymbolName)))
+
+	pg, err := ps.NewGrammar[Symbol]()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range f.Rules {
+		rhs := make([]Symbol, 0, len(p.Rhs))
+
+		for _, name := range p.Rhs {
+			rhs = append(rhs, SymbolOf(name))
+		}
+
+		err := pg.AddRule(SymbolOf(p.Lhs), rhs)
+		if err != nil {
+			return nil, NewErrInvalidLine(p.Line, err)
+		}
+	}
+
+	parser, err := ps.NewParser(pg)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Language{
+		lexer:     lexer,
+		parser:    parser,
+		terminals: terminals,
+	}
+
+	return l, nil
+}
+
+// LoadLanguage reads a grammar file. See ParseLanguage.
+//
+// Parameters:
+//   - loc: The location of the file.
+//
+// Returns:
+//   - *Language: The language.
+//   - error: An error if the file cannot be read or the grammar is invalid.
+func LoadLanguage(loc string) (*Language, error) {
+	data, err := os.ReadFile(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseLanguage(data)
+}
@@ -0,0 +1,111 @@
+package Playground
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseLanguage(t *testing.T) {
+	const (
+		InputStr string = "%skip WS\nLET = \"let\"\nWORD = [a-z]+\nWS = [ ]+\n\n" +
+			"source -> stmt EOF\nstmt -> LET WORD\n"
+	)
+
+	l, err := ParseLanguage([]byte(InputStr))
+	if err != nil {
+		t.Fatalf("ParseLanguage failed: %s", err.Error())
+	}
+
+	expected := []Symbol{SymbolOf("LET"), SymbolOf("WORD"), SymbolOf("WS")}
+
+	if !slices.Equal(l.GetTerminals(), expected) {
+		t.Errorf("expected the terminals %v, got %v", expected, l.GetTerminals())
+	}
+
+	// The text that no rule matches becomes an error token.
+	stream, err := l.GetLexer().Lex([]byte("let x #")).Consume()
+	if err != nil {
+		t.Fatalf("Lex failed: %s", err.Error())
+	}
+
+	var names []string
+
+	for _, tok := range stream.GetItems() {
+		names = append(names, tok.ID.String())
+	}
+
+	if !slices.Equal(names, []string{"LET", "WORD", ErrorSymbolName}) {
+		t.Errorf("expected [LET WORD ERROR], got %v", names)
+	}
+}
+
+func TestParseLanguageErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+		want  string
+	}{
+		{
+			name:  "reserved EOF",
+			input: "EOF = eof\nsource -> EOF\n",
+			line:  1,
+			want:  `line 1: "EOF" is reserved`,
+		},
+		{
+			name:  "reserved ERROR",
+			input: "A = a\nERROR = [#]+\nsource -> A EOF\n",
+			line:  2,
+			want:  `line 2: "ERROR" is reserved`,
+		},
+		{
+			name:  "undefined terminal",
+			input: "A = a\nsource -> A B EOF\n",
+			line:  2,
+			want:  "line 2: no lexer rule for B",
+		},
+		{
+			name:  "undefined non-terminal",
+			input: "A = a\nsource -> stmt EOF\n",
+			line:  2,
+			want:  "line 2: no production for stmt",
+		},
+		{
+			name:  "missing source",
+			input: "A = a\nstmt -> A EOF\n",
+			line:  0,
+			want:  "grammar: missing a production for source",
+		},
+		{
+			name:  "missing EOF",
+			input: "A = a\nsource -> A\n",
+			line:  0,
+			want:  "grammar: no production uses EOF",
+		},
+		{
+			name:  "syntax error",
+			input: "A = a\nsource -> A EOF\nb ->\n",
+			line:  3,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := ParseLanguage([]byte(test.input))
+
+		var target *ErrInvalidLine
+
+		if !errors.As(err, &target) {
+			t.Errorf("%s: expected *ErrInvalidLine, got %v", test.name, err)
+			continue
+		}
+
+		if target.Line != test.line {
+			t.Errorf("%s: expected line %d, got %d", test.name, test.line, target.Line)
+		}
+
+		if test.want != "" && err.Error() != test.want {
+			t.Errorf("%s: expected %q, got %q", test.name, test.want, err.Error())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package Playground
+
+import (
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Symbol is a symbol of a grammar loaded at run time. Symbols are interned
+// by name, so the same name is always the same symbol, even across reloads
+// of a grammar.
+type Symbol int
+
+var (
+	// symbol_mu protects the symbol table.
+	symbol_mu sync.RWMutex
+
+	// symbol_names are the names of the symbols, by value.
+	symbol_names []string
+
+	// symbol_ids are the symbols, by name.
+	symbol_ids = make(map[string]Symbol)
+)
+
+// SymbolOf returns the symbol with the given name.
+//
+// Parameters:
+//   - name: The name of the symbol.
+//
+// Returns:
+//   - Symbol: The symbol. It is created the first time the name is seen.
+func SymbolOf(name string) Symbol {
+	symbol_mu.RLock()
+	s, ok := symbol_ids[name]
+	symbol_mu.RUnlock()
+
+	if ok {
+		return s
+	}
+
+	symbol_mu.Lock()
+	defer symbol_mu.Unlock()
+
+	s, ok = symbol_ids[name]
+	if ok {
+		return s
+	}
+
+	s = Symbol(len(symbol_names))
+
+	symbol_names = append(symbol_names, name)
+	symbol_ids[name] = s
+
+	return s
+}
+
+// String implements the fmt.Stringer interface.
+func (s Symbol) String() string {
+	symbol_mu.RLock()
+	defer symbol_mu.RUnlock()
+
+	if s < 0 || int(s) >= len(symbol_names) {
+		return "Symbol(?)"
+	}
+
+	return symbol_names[s]
+}
+
+// IsTerminal implements the gr.TokenTyper interface.
+//
+// Behaviors:
+//   - A symbol is a terminal if its name starts with an upper-case letter.
+func (s Symbol) IsTerminal() bool {
+	return is_terminal_name(s.String())
+}
+
+// is_terminal_name is a helper function that checks whether a name is the
+// name of a terminal.
+//
+// Parameters:
+//   - name: The name.
+//
+// Returns:
+//   - bool: True if the name starts with an upper-case letter.
+func is_terminal_name(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
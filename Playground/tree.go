@@ -0,0 +1,211 @@
+package Playground
+
+import (
+	"strconv"
+	"strings"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+// tree_row is a visible node of a tree view.
+type tree_row struct {
+	// node is the node.
+	node *gr.Token[Symbol]
+
+	// depth is the depth of the node. 0 for the root.
+	depth int
+}
+
+// label returns the text of the row.
+//
+// Format:
+//
+//	<indentation>▾ <nonterminal>
+//	<indentation>▸ <nonterminal> (collapsed)
+//	<indentation>  <terminal> "<text>"
+//
+// Parameters:
+//   - collapsed: True if the node is collapsed.
+//
+// Returns:
+//   - string: The text.
+func (r *tree_row) label(collapsed bool) string {
+	var builder strings.Builder
+
+	builder.WriteString(strings.Repeat("  ", r.depth))
+
+	text, ok := r.node.Data.(string)
+	if ok {
+		builder.WriteString("  ")
+		builder.WriteString(r.node.ID.String())
+		builder.WriteRune(' ')
+		builder.WriteString(strconv.Quote(text))
+
+		return builder.String()
+	}
+
+	if collapsed {
+		builder.WriteString("▸ ")
+	} else {
+		builder.WriteString("▾ ")
+	}
+
+	builder.WriteString(r.node.ID.String())
+
+	return builder.String()
+}
+
+// tree_view is a parse tree whose nodes can be collapsed.
+type tree_view struct {
+	// root is the root of the tree. Nil if there is none.
+	root *gr.Token[Symbol]
+
+	// collapsed are the collapsed nodes. The nodes are expanded by default.
+	collapsed map[*gr.Token[Symbol]]bool
+
+	// rows are the visible nodes, in pre-order.
+	rows []*tree_row
+
+	// selected is the index of the selected row.
+	selected int
+}
+
+// new_tree_view creates an empty tree view.
+//
+// Returns:
+//   - *tree_view: The new tree view. Never nil.
+func new_tree_view() *tree_view {
+	tv := &tree_view{
+		collapsed: make(map[*gr.Token[Symbol]]bool),
+	}
+
+	return tv
+}
+
+// add_rows is a helper method that adds the visible rows of a subtree.
+//
+// Parameters:
+//   - node: The root of the subtree.
+//   - depth: The depth of the node.
+//   - seen: The nodes of the tree, which this method fills.
+func (tv *tree_view) add_rows(node *gr.Token[Symbol], depth int, seen map[*gr.Token[Symbol]]bool) {
+	seen[node] = true
+
+	tv.rows = append(tv.rows, &tree_row{
+		node:  node,
+		depth: depth,
+	})
+
+	if tv.collapsed[node] {
+		return
+	}
+
+	children, _ := node.Data.([]*gr.Token[Symbol])
+
+	for _, child := range children {
+		tv.add_rows(child, depth+1, seen)
+	}
+}
+
+// set_root changes the tree of the view.
+//
+// Parameters:
+//   - root: The root of the tree. Nil if there is none.
+//
+// Behaviors:
+//   - The nodes that are still in the tree stay collapsed and the selected
+//     node stays selected, so that the view is stable across edits of a
+//     document.
+func (tv *tree_view) set_root(root *gr.Token[Symbol]) {
+	var selected *gr.Token[Symbol]
+
+	if tv.selected < len(tv.rows) {
+		selected = tv.rows[tv.selected].node
+	}
+
+	tv.root = tv.rebuild(root)
+
+	for i, row := range tv.rows {
+		if row.node == selected {
+			tv.selected = i
+			return
+		}
+	}
+
+	tv.selected = min(tv.selected, max(len(tv.rows)-1, 0))
+}
+
+// rebuild is a helper method that computes the rows of a tree and forgets
+// the collapsed nodes that are not in it anymore.
+//
+// Parameters:
+//   - root: The root of the tree. Nil if there is none.
+//
+// Returns:
+//   - *gr.Token: The root.
+func (tv *tree_view) rebuild(root *gr.Token[Symbol]) *gr.Token[Symbol] {
+	tv.rows = tv.rows[:0]
+
+	if root == nil {
+		return nil
+	}
+
+	seen := make(map[*gr.Token[Symbol]]bool)
+	tv.add_rows(root, 0, seen)
+
+	for node := range tv.collapsed {
+		if !seen[node] {
+			delete(tv.collapsed, node)
+		}
+	}
+
+	return root
+}
+
+// up selects the row above.
+func (tv *tree_view) up() {
+	if tv.selected > 0 {
+		tv.selected--
+	}
+}
+
+// down selects the row below.
+func (tv *tree_view) down() {
+	if tv.selected+1 < len(tv.rows) {
+		tv.selected++
+	}
+}
+
+// set_collapsed collapses or expands the selected node.
+//
+// Parameters:
+//   - collapsed: True to collapse the node, false to expand it.
+func (tv *tree_view) set_collapsed(collapsed bool) {
+	if tv.selected >= len(tv.rows) {
+		return
+	}
+
+	node := tv.rows[tv.selected].node
+
+	if _, ok := node.Data.(string); ok {
+		return
+	}
+
+	if collapsed {
+		tv.collapsed[node] = true
+	} else {
+		delete(tv.collapsed, node)
+	}
+
+	tv.rebuild(tv.root)
+}
+
+// toggle collapses the selected node if it is expanded, and expands it
+// otherwise.
+func (tv *tree_view) toggle() {
+	if tv.selected >= len(tv.rows) {
+		return
+	}
+
+	tv.set_collapsed(!tv.collapsed[tv.rows[tv.selected].node])
+}
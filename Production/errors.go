@@ -0,0 +1,56 @@
+package Production
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ErrSyntax is an error that is returned when a grammar file is not
+// well-formed.
+type ErrSyntax struct {
+	// Line is the number of the line, starting at 1.
+	Line int
+
+	// Reason is the reason why the line is invalid.
+	Reason error
+}
+
+// Error implements the error interface.
+//
+// Message: "line <line>: <reason>"
+func (e *ErrSyntax) Error() string {
+	var builder strings.Builder
+
+	builder.WriteString("line ")
+	builder.WriteString(strconv.Itoa(e.Line))
+	builder.WriteString(": ")
+
+	if e.Reason != nil {
+		builder.WriteString(e.Reason.Error())
+	} else {
+		builder.WriteString("syntax error")
+	}
+
+	return builder.String()
+}
+
+// Unwrap returns the reason why the line is invalid.
+func (e *ErrSyntax) Unwrap() error {
+	return e.Reason
+}
+
+// NewErrSyntax creates a new error of type *ErrSyntax.
+//
+// Parameters:
+//   - line: The number of the line, starting at 1.
+//   - reason: The reason why the line is invalid.
+//
+// Returns:
+//   - *ErrSyntax: The new error. Never nil.
+func NewErrSyntax(line int, reason error) *ErrSyntax {
+	e := &ErrSyntax{
+		Line:   line,
+		Reason: reason,
+	}
+	return e
+}
@@ -0,0 +1,477 @@
+// Code generated by LyneParser/Generator. DO NOT EDIT.
+
+package Production
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+)
+
+// Parser is a direct-coded parser of the grammar:
+//
+//	source -> lines EOF
+//	source -> NEWLINE lines EOF
+//	lines -> line
+//	lines -> line NEWLINE
+//	lines -> line NEWLINE lines
+//	line -> SKIP terminals
+//	line -> TERMINAL VALUE
+//	line -> NONTERMINAL ARROW alternatives
+//	terminals -> TERMINAL
+//	terminals -> terminals TERMINAL
+//	alternatives -> symbols
+//	alternatives -> alternatives PIPE symbols
+//	symbols -> TERMINAL
+//	symbols -> NONTERMINAL
+//	symbols -> symbols TERMINAL
+//	symbols -> symbols NONTERMINAL
+type Parser struct{}
+
+// NewParser creates a new parser.
+//
+// Returns:
+//   - *Parser: The new parser. Never nil.
+func NewParser() *Parser {
+	p := &Parser{}
+	return p
+}
+
+// parser_kind is the kind of an action of Parser.
+type parser_kind int
+
+const (
+	// parser_shift pushes the next token onto the stack.
+	parser_shift parser_kind = iota
+
+	// parser_reduce replaces the top of the stack by the left-hand side of a
+	// rule.
+	parser_reduce
+
+	// parser_accept reduces by a rule and ends the parse.
+	parser_accept
+)
+
+// parser_action is an action of Parser.
+type parser_action struct {
+	// kind is the kind of the action.
+	kind parser_kind
+
+	// rule is the index of the rule to reduce by. Only valid for the
+	// reductions and the acceptances.
+	rule int
+}
+
+// decide returns the actions that match the stack, in the order of the
+// decision table.
+//
+// Parameters:
+//   - stack: The stack. Never empty.
+//
+// Returns:
+//   - []parser_action: The actions. Nil if there are none.
+func (p *Parser) decide(stack []*gr.Token[TokenType]) []parser_action {
+	top := stack[len(stack)-1]
+
+	var actions []parser_action
+
+	switch top.ID {
+	case TkEof:
+		// source -> lines [EOF] (0) (accept{})
+		actions = append(actions, parser_action{kind: parser_accept, rule: 0})
+		// source -> NEWLINE lines [EOF] (1) (accept{})
+		actions = append(actions, parser_action{kind: parser_accept, rule: 1})
+	case TkNewline:
+		// source -> [NEWLINE] lines EOF (2) (shift{})
+		actions = append(actions, parser_action{kind: parser_shift})
+		// lines -> line [NEWLINE] (3) (reduce{NEWLINE line})
+		if len(stack) >= 2 && stack[len(stack)-2].ID == TkLine {
+			actions = append(actions, parser_action{kind: parser_reduce, rule: 3})
+		}
+		// lines -> line [NEWLINE] SKIP (4) (shift{SKIP <- })
+		if top.Lookahead != nil && top.Lookahead.ID == TkSkip {
+			actions = append(actions, parser_action{kind: parser_shift})
+		}
+	case TkSkip:
+		// line -> [SKIP] terminals (5) (shift{})
+		actions = append(actions, parser_action{kind: parser_shift})
+	case TkArrow:
+		// line -> NONTERMINAL [ARROW] alternatives (6) (shift{})
+		actions = append(actions, parser_action{kind: parser_shift})
+	case TkPipe:
+		// alternatives -> alternatives [PIPE] symbols (7) (shift{})
+		actions = append(actions, parser_action{kind: parser_shift})
+	case TkValue:
+		// line -> TERMINAL [VALUE] (8) (reduce{})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 6})
+	case TkTerminal:
+		// line -> [TERMINAL] VALUE (9) (shift{VALUE <- })
+		if top.Lookahead != nil && top.Lookahead.ID == TkValue {
+			actions = append(actions, parser_action{kind: parser_shift})
+		}
+		// terminals -> [TERMINAL] (10) (reduce{TERMINAL})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 8})
+		// terminals -> terminals [TERMINAL] (11) (reduce{})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 9})
+		// symbols -> [TERMINAL] (12) (reduce{TERMINAL})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 12})
+		// symbols -> symbols [TERMINAL] (13) (reduce{})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 14})
+	case TkNonterminal:
+		// line -> [NONTERMINAL] ARROW alternatives (14) (shift{ARROW <- })
+		if top.Lookahead != nil && top.Lookahead.ID == TkArrow {
+			actions = append(actions, parser_action{kind: parser_shift})
+		}
+		// symbols -> [NONTERMINAL] (15) (reduce{})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 13})
+		// symbols -> symbols [NONTERMINAL] (16) (reduce{})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 15})
+	case TkLines:
+		// source -> [lines] EOF (17) (shift{EOF <- lines})
+		if top.Lookahead != nil && top.Lookahead.ID == TkEof {
+			actions = append(actions, parser_action{kind: parser_shift})
+		}
+		// source -> NEWLINE [lines] EOF (18) (shift{EOF <- lines NEWLINE})
+		if top.Lookahead != nil && top.Lookahead.ID == TkEof && len(stack) >= 2 && stack[len(stack)-2].ID == TkNewline {
+			actions = append(actions, parser_action{kind: parser_shift})
+		}
+		// lines -> line NEWLINE [lines] (19) (reduce{})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 4})
+	case TkLine:
+		// lines -> [line] (20) (reduce{})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 2})
+		// lines -> [line] NEWLINE (21) (shift{NEWLINE <- line})
+		if top.Lookahead != nil && top.Lookahead.ID == TkNewline {
+			actions = append(actions, parser_action{kind: parser_shift})
+		}
+		// lines -> [line] NEWLINE lines (22) (shift{NEWLINE <- line})
+		if top.Lookahead != nil && top.Lookahead.ID == TkNewline {
+			actions = append(actions, parser_action{kind: parser_shift})
+		}
+	case TkTerminals:
+		// line -> SKIP [terminals] (23) (reduce{})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 5})
+		// terminals -> [terminals] TERMINAL (24) (shift{TERMINAL <- terminals})
+		if top.Lookahead != nil && top.Lookahead.ID == TkTerminal {
+			actions = append(actions, parser_action{kind: parser_shift})
+		}
+	case TkAlternatives:
+		// line -> NONTERMINAL ARROW [alternatives] (25) (reduce{})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 7})
+		// alternatives -> [alternatives] PIPE symbols (26) (shift{PIPE <- })
+		if top.Lookahead != nil && top.Lookahead.ID == TkPipe {
+			actions = append(actions, parser_action{kind: parser_shift})
+		}
+	case TkSymbols:
+		// alternatives -> [symbols] (27) (reduce{})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 10})
+		// alternatives -> alternatives PIPE [symbols] (28) (reduce{})
+		actions = append(actions, parser_action{kind: parser_reduce, rule: 11})
+		// symbols -> [symbols] TERMINAL (29) (shift{TERMINAL <- symbols})
+		if top.Lookahead != nil && top.Lookahead.ID == TkTerminal {
+			actions = append(actions, parser_action{kind: parser_shift})
+		}
+		// symbols -> [symbols] NONTERMINAL (30) (shift{NONTERMINAL <- })
+		if top.Lookahead != nil && top.Lookahead.ID == TkNonterminal {
+			actions = append(actions, parser_action{kind: parser_shift})
+		}
+	}
+
+	return actions
+}
+
+// reduce replaces the top of the stack by the left-hand side of a rule.
+//
+// Parameters:
+//   - stack: The stack. It may be modified.
+//   - rule: The index of the rule.
+//
+// Returns:
+//   - []*gr.Token: The new stack.
+//   - error: An error if the top of the stack is not the right-hand side of
+//     the rule.
+func (p *Parser) reduce(stack []*gr.Token[TokenType], rule int) ([]*gr.Token[TokenType], error) {
+	var lhs TokenType
+	var n int
+
+	switch rule {
+	case 0:
+		// source -> lines EOF
+		if len(stack) < 2 || stack[len(stack)-2].ID != TkLines || stack[len(stack)-1].ID != TkEof {
+			return nil, errors.New("cannot reduce by source -> lines EOF")
+		}
+
+		lhs, n = TkSource, 2
+	case 1:
+		// source -> NEWLINE lines EOF
+		if len(stack) < 3 || stack[len(stack)-3].ID != TkNewline || stack[len(stack)-2].ID != TkLines || stack[len(stack)-1].ID != TkEof {
+			return nil, errors.New("cannot reduce by source -> NEWLINE lines EOF")
+		}
+
+		lhs, n = TkSource, 3
+	case 2:
+		// lines -> line
+		if len(stack) < 1 || stack[len(stack)-1].ID != TkLine {
+			return nil, errors.New("cannot reduce by lines -> line")
+		}
+
+		lhs, n = TkLines, 1
+	case 3:
+		// lines -> line NEWLINE
+		if len(stack) < 2 || stack[len(stack)-2].ID != TkLine || stack[len(stack)-1].ID != TkNewline {
+			return nil, errors.New("cannot reduce by lines -> line NEWLINE")
+		}
+
+		lhs, n = TkLines, 2
+	case 4:
+		// lines -> line NEWLINE lines
+		if len(stack) < 3 || stack[len(stack)-3].ID != TkLine || stack[len(stack)-2].ID != TkNewline || stack[len(stack)-1].ID != TkLines {
+			return nil, errors.New("cannot reduce by lines -> line NEWLINE lines")
+		}
+
+		lhs, n = TkLines, 3
+	case 5:
+		// line -> SKIP terminals
+		if len(stack) < 2 || stack[len(stack)-2].ID != TkSkip || stack[len(stack)-1].ID != TkTerminals {
+			return nil, errors.New("cannot reduce by line -> SKIP terminals")
+		}
+
+		lhs, n = TkLine, 2
+	case 6:
+		// line -> TERMINAL VALUE
+		if len(stack) < 2 || stack[len(stack)-2].ID != TkTerminal || stack[len(stack)-1].ID != TkValue {
+			return nil, errors.New("cannot reduce by line -> TERMINAL VALUE")
+		}
+
+		lhs, n = TkLine, 2
+	case 7:
+		// line -> NONTERMINAL ARROW alternatives
+		if len(stack) < 3 || stack[len(stack)-3].ID != TkNonterminal || stack[len(stack)-2].ID != TkArrow || stack[len(stack)-1].ID != TkAlternatives {
+			return nil, errors.New("cannot reduce by line -> NONTERMINAL ARROW alternatives")
+		}
+
+		lhs, n = TkLine, 3
+	case 8:
+		// terminals -> TERMINAL
+		if len(stack) < 1 || stack[len(stack)-1].ID != TkTerminal {
+			return nil, errors.New("cannot reduce by terminals -> TERMINAL")
+		}
+
+		lhs, n = TkTerminals, 1
+	case 9:
+		// terminals -> terminals TERMINAL
+		if len(stack) < 2 || stack[len(stack)-2].ID != TkTerminals || stack[len(stack)-1].ID != TkTerminal {
+			return nil, errors.New("cannot reduce by terminals -> terminals TERMINAL")
+		}
+
+		lhs, n = TkTerminals, 2
+	case 10:
+		// alternatives -> symbols
+		if len(stack) < 1 || stack[len(stack)-1].ID != TkSymbols {
+			return nil, errors.New("cannot reduce by alternatives -> symbols")
+		}
+
+		lhs, n = TkAlternatives, 1
+	case 11:
+		// alternatives -> alternatives PIPE symbols
+		if len(stack) < 3 || stack[len(stack)-3].ID != TkAlternatives || stack[len(stack)-2].ID != TkPipe || stack[len(stack)-1].ID != TkSymbols {
+			return nil, errors.New("cannot reduce by alternatives -> alternatives PIPE symbols")
+		}
+
+		lhs, n = TkAlternatives, 3
+	case 12:
+		// symbols -> TERMINAL
+		if len(stack) < 1 || stack[len(stack)-1].ID != TkTerminal {
+			return nil, errors.New("cannot reduce by symbols -> TERMINAL")
+		}
+
+		lhs, n = TkSymbols, 1
+	case 13:
+		// symbols -> NONTERMINAL
+		if len(stack) < 1 || stack[len(stack)-1].ID != TkNonterminal {
+			return nil, errors.New("cannot reduce by symbols -> NONTERMINAL")
+		}
+
+		lhs, n = TkSymbols, 1
+	case 14:
+		// symbols -> symbols TERMINAL
+		if len(stack) < 2 || stack[len(stack)-2].ID != TkSymbols || stack[len(stack)-1].ID != TkTerminal {
+			return nil, errors.New("cannot reduce by symbols -> symbols TERMINAL")
+		}
+
+		lhs, n = TkSymbols, 2
+	case 15:
+		// symbols -> symbols NONTERMINAL
+		if len(stack) < 2 || stack[len(stack)-2].ID != TkSymbols || stack[len(stack)-1].ID != TkNonterminal {
+			return nil, errors.New("cannot reduce by symbols -> symbols NONTERMINAL")
+		}
+
+		lhs, n = TkSymbols, 2
+	default:
+		return nil, fmt.Errorf("unknown rule %d", rule)
+	}
+
+	children := slices.Clone(stack[len(stack)-n:])
+
+	var lookahead *gr.Token[TokenType]
+
+	for i := len(children) - 1; i >= 0 && lookahead == nil; i-- {
+		lookahead = children[i].Lookahead
+	}
+
+	var at int
+
+	if n > 0 {
+		at = children[0].At
+	}
+
+	stack = append(stack[:len(stack)-n], gr.NewToken(lhs, children, at, lookahead))
+
+	return stack, nil
+}
+
+// apply applies an action.
+//
+// Parameters:
+//   - stack: The stack. It may be modified.
+//   - input: The tokens that are not shifted yet.
+//   - action: The action.
+//
+// Returns:
+//   - []*gr.Token: The new stack.
+//   - []*gr.Token: The new input.
+//   - bool: True if the parse is accepted.
+//   - error: An error if the action cannot be applied.
+func (p *Parser) apply(stack, input []*gr.Token[TokenType], action parser_action) ([]*gr.Token[TokenType], []*gr.Token[TokenType], bool, error) {
+	switch action.kind {
+	case parser_shift:
+		if len(input) == 0 {
+			return nil, nil, false, errors.New("reached end of input stream without accepting")
+		}
+
+		return append(stack, input[0]), input[1:], false, nil
+	case parser_reduce:
+		stack, err := p.reduce(stack, action.rule)
+
+		return stack, input, false, err
+	default:
+		stack, err := p.reduce(stack, action.rule)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		if len(stack) != 1 {
+			return nil, nil, false, fmt.Errorf("accepted with %d tokens left on the stack", len(stack)-1)
+		}
+
+		return stack, input, true, nil
+	}
+}
+
+// run runs the parser until it accepts or fails.
+//
+// Parameters:
+//   - stack: The stack. It may be modified.
+//   - input: The tokens that are not shifted yet.
+//
+// Returns:
+//   - *gr.Token: The root of the parse tree.
+//   - error: An error if the parse fails.
+//
+// Behaviors:
+//   - When several decisions match, each distinct action is tried once, in
+//     table order. Several items of the table may lead to the same action;
+//     their branches would be the same, and trying each of them would
+//     multiply the work at every such decision.
+func (p *Parser) run(stack, input []*gr.Token[TokenType]) (*gr.Token[TokenType], error) {
+	for {
+		actions := p.decide(stack)
+
+		if len(actions) == 0 {
+			top := stack[len(stack)-1]
+
+			return nil, fmt.Errorf("unexpected %s at %d", top.ID, top.At)
+		}
+
+		if len(actions) > 1 {
+			var first error
+
+			for i, action := range actions {
+				// Several items may lead to the same action, whose branch
+				// was already tried.
+				if slices.Contains(actions[:i], action) {
+					continue
+				}
+
+				root, err := p.branch(slices.Clone(stack), input, action)
+				if err == nil {
+					return root, nil
+				}
+
+				if first == nil {
+					first = err
+				}
+			}
+
+			return nil, first
+		}
+
+		var done bool
+		var err error
+
+		stack, input, done, err = p.apply(stack, input, actions[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if done {
+			return stack[len(stack)-1], nil
+		}
+	}
+}
+
+// branch applies an action and runs the parser from there.
+//
+// Parameters:
+//   - stack: The stack. It may be modified.
+//   - input: The tokens that are not shifted yet.
+//   - action: The action.
+//
+// Returns:
+//   - *gr.Token: The root of the parse tree.
+//   - error: An error if the parse fails.
+func (p *Parser) branch(stack, input []*gr.Token[TokenType], action parser_action) (*gr.Token[TokenType], error) {
+	stack, input, done, err := p.apply(stack, input, action)
+	if err != nil {
+		return nil, err
+	}
+
+	if done {
+		return stack[len(stack)-1], nil
+	}
+
+	return p.run(stack, input)
+}
+
+// Parse parses a stream of tokens.
+//
+// Parameters:
+//   - tokens: The tokens, each one with the next one as its lookahead.
+//
+// Returns:
+//   - *gr.Token: The root of the parse tree.
+//   - error: An error if the tokens could not be parsed.
+//
+// Behaviors:
+//   - When several decisions match, they are tried in the order of the
+//     decision table and the first accepted parse is returned.
+//   - A parse is only accepted once the whole stack is reduced to its root.
+func (p *Parser) Parse(tokens []*gr.Token[TokenType]) (*gr.Token[TokenType], error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("no tokens to parse")
+	}
+
+	return p.run(tokens[:1:1], tokens[1:])
+}
@@ -0,0 +1,405 @@
+package Production
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+)
+
+var (
+	// lexer is the lexer of the grammar files.
+	lexer *lx.Lexer[TokenType]
+)
+
+func init() {
+	l, err := NewLexer()
+	if err != nil {
+		panic(err)
+	}
+
+	lexer = l
+}
+
+// LexerRule is a lexer rule of a grammar file: "NAME = regex", or
+// `NAME = "literal"` with the literal quoted as in Go.
+type LexerRule struct {
+	// Line is the number of the line of the rule.
+	Line int
+
+	// Name is the left-hand side; that is, the type of the tokens.
+	Name string
+
+	// Value is the regular expression, or the literal if Quoted is true.
+	Value string
+
+	// Quoted is true if the value is a literal.
+	Quoted bool
+}
+
+// Rule is one alternative of a production of a grammar file.
+type Rule struct {
+	// Line is the number of the line of the production.
+	Line int
+
+	// Lhs is the left-hand side.
+	Lhs string
+
+	// Rhs are the symbols of the right-hand side. Never empty.
+	Rhs []string
+}
+
+// File is the content of a grammar file.
+type File struct {
+	// ToSkip are the terminals to skip, in the order of the file.
+	ToSkip []string
+
+	// LexerRules are the lexer rules, in the order of the file.
+	LexerRules []*LexerRule
+
+	// Rules are the alternatives of the productions, in the order of the
+	// file.
+	Rules []*Rule
+}
+
+// line_of is a helper function that returns the line of a position.
+//
+// Parameters:
+//   - data: The content of the file.
+//   - at: The position.
+//
+// Returns:
+//   - int: The number of the line, starting at 1.
+func line_of(data []byte, at int) int {
+	if at > len(data) {
+		at = len(data)
+	}
+
+	return bytes.Count(data[:at], []byte{'\n'}) + 1
+}
+
+// lex is a helper function that splits a grammar file into tokens.
+//
+// Parameters:
+//   - data: The content of the file.
+//
+// Returns:
+//   - []*gr.Token: The tokens that are not skipped, followed by an EOF
+//     token, each one with the next one as its lookahead.
+//   - error: An error of type *ErrSyntax if no lexer rule matches.
+func lex(data []byte) ([]*gr.Token[TokenType], error) {
+	var tokens []*gr.Token[TokenType]
+	var modes *lx.ModeStack
+
+	for at := 0; at < len(data); {
+		step, err := lexer.Step(data, at, modes)
+		if err != nil {
+			r, _ := utf8.DecodeRune(data[at:])
+
+			return nil, NewErrSyntax(line_of(data, at), errors.New("unexpected character "+strconv.QuoteRune(r)))
+		}
+
+		text, _ := step.Token.Data.(string)
+		if text == "" {
+			return nil, NewErrSyntax(line_of(data, at), errors.New(step.Token.ID.String()+" matches the empty string"))
+		}
+
+		if !step.Skipped {
+			tokens = append(tokens, step.Token)
+		}
+
+		at += len(text)
+		modes = step.Modes
+	}
+
+	tokens = append(tokens, gr.NewToken(TkEof, "", len(data), nil))
+
+	for i := 0; i < len(tokens)-1; i++ {
+		tokens[i].SetLookahead(tokens[i+1])
+	}
+
+	return tokens, nil
+}
+
+// locate is a helper function that finds the line that a parse error is
+// about. Since the lines of a grammar file do not depend on each other, it
+// is the first line that cannot be parsed on its own.
+//
+// Parameters:
+//   - data: The content of the file.
+//   - tokens: The tokens of the file, with the EOF token.
+//   - reason: The error of the parser on the whole file.
+//
+// Returns:
+//   - *ErrSyntax: The error. Never nil.
+func locate(data []byte, tokens []*gr.Token[TokenType], reason error) *ErrSyntax {
+	var line []*gr.Token[TokenType]
+
+	for _, tok := range tokens {
+		if tok.ID != TkNewline && tok.ID != TkEof {
+			line = append(line, tok)
+			continue
+		}
+
+		if len(line) == 0 {
+			continue
+		}
+
+		eof := gr.NewToken(TkEof, "", tok.At, nil)
+
+		stream := make([]*gr.Token[TokenType], 0, len(line)+1)
+
+		for _, t := range line {
+			stream = append(stream, gr.NewToken(t.ID, t.Data, t.At, nil))
+		}
+
+		stream = append(stream, eof)
+
+		for i := 0; i < len(stream)-1; i++ {
+			stream[i].SetLookahead(stream[i+1])
+		}
+
+		_, err := NewParser().Parse(stream)
+		if err != nil {
+			return NewErrSyntax(line_of(data, line[0].At), err)
+		}
+
+		line = line[:0]
+	}
+
+	return NewErrSyntax(line_of(data, len(data)), reason)
+}
+
+// children is a helper function that returns the children of a node of the
+// parse tree.
+//
+// Parameters:
+//   - node: The node.
+//
+// Returns:
+//   - []*gr.Token: The children. Nil for a leaf or a nil node.
+func children(node *gr.Token[TokenType]) []*gr.Token[TokenType] {
+	if node == nil {
+		return nil
+	}
+
+	data, _ := node.Data.([]*gr.Token[TokenType])
+	return data
+}
+
+// flatten is a helper function that returns the elements of a list, such
+// as "symbols -> TERMINAL | symbols TERMINAL".
+//
+// Parameters:
+//   - node: The node of the list.
+//
+// Returns:
+//   - []*gr.Token: The children of each level of the list, but the list
+//     itself, in the order of the file.
+func flatten(node *gr.Token[TokenType]) []*gr.Token[TokenType] {
+	data := children(node)
+	if len(data) == 0 {
+		return nil
+	}
+
+	last := len(data) - 1
+
+	switch {
+	case data[0].ID == node.ID:
+		return append(flatten(data[0]), data[1:]...)
+	case data[last].ID == node.ID:
+		return append(slices.Clone(data[:last]), flatten(data[last])...)
+	default:
+		return data
+	}
+}
+
+// text is a helper function that returns the text of a leaf of the parse
+// tree.
+//
+// Parameters:
+//   - leaf: The leaf.
+//
+// Returns:
+//   - string: The text.
+func text(leaf *gr.Token[TokenType]) string {
+	str, _ := leaf.Data.(string)
+	return str
+}
+
+// add_line is a helper method that adds a line of the parse tree to the
+// file.
+//
+// Parameters:
+//   - line: The number of the line.
+//   - node: The node of the line.
+//
+// Returns:
+//   - error: An error if the line is invalid.
+func (f *File) add_line(line int, node *gr.Token[TokenType]) error {
+	data := children(node)
+	if len(data) < 2 {
+		return errors.New("invalid line")
+	}
+
+	switch data[0].ID {
+	case TkSkip:
+		for _, leaf := range flatten(data[1]) {
+			f.ToSkip = append(f.ToSkip, text(leaf))
+		}
+	case TkTerminal:
+		rule := &LexerRule{
+			Line:  line,
+			Name:  text(data[0]),
+			Value: strings.TrimSpace(strings.TrimPrefix(text(data[1]), "=")),
+		}
+
+		if rule.Value == "" {
+			return errors.New("missing the regular expression of " + rule.Name)
+		}
+
+		if strings.HasPrefix(rule.Value, `"`) {
+			literal, err := strconv.Unquote(rule.Value)
+			if err != nil {
+				return errors.New("invalid literal " + rule.Value)
+			}
+
+			if literal == "" {
+				return errors.New("empty literal")
+			}
+
+			rule.Value = literal
+			rule.Quoted = true
+		}
+
+		f.LexerRules = append(f.LexerRules, rule)
+	case TkNonterminal:
+		lhs := text(data[0])
+
+		for _, alt := range flatten(data[len(data)-1]) {
+			if alt.ID == TkPipe {
+				continue
+			}
+
+			var rhs []string
+
+			for _, symbol := range flatten(alt) {
+				rhs = append(rhs, text(symbol))
+			}
+
+			f.Rules = append(f.Rules, &Rule{
+				Line: line,
+				Lhs:  lhs,
+				Rhs:  rhs,
+			})
+		}
+	default:
+		return errors.New("invalid line")
+	}
+
+	return nil
+}
+
+// Parse parses a grammar file with the lexer and the parser generated from
+// meta.grammar.
+//
+// Format:
+//
+//	# A comment.
+//	%skip WS
+//
+//	WORD = [a-z]+
+//	EQ = "="
+//	WS = [ \t\r\n]+
+//
+//	source -> stmts EOF
+//	stmts -> stmt stmts | stmt
+//	stmt -> WORD EQ WORD
+//
+// Every line is either empty, a comment, a list of terminals to skip, a
+// lexer rule or a production:
+//   - The name of a terminal starts with an upper-case letter and the one
+//     of a non-terminal does not.
+//   - The value of a lexer rule is the rest of the line after the '=',
+//     without the spaces around it: a regular expression, or a literal if
+//     it is quoted as in Go.
+//   - The alternatives of a production are separated by '|' and cannot be
+//     empty.
+//   - A comment starts with '#' outside of the value of a lexer rule and
+//     goes up to the end of the line.
+//
+// Parameters:
+//   - data: The content of the file.
+//
+// Returns:
+//   - *File: The content of the file. Never nil if err is nil.
+//   - error: An error of type *ErrSyntax if the file is not well-formed.
+//
+// Behaviors:
+//   - Parse only checks the syntax of the file; whether the symbols are
+//     defined and the regular expressions compile is up to the caller.
+func Parse(data []byte) (*File, error) {
+	tokens, err := lex(data)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{}
+
+	// The grammar does not accept a file without lines; that is, a file with
+	// only empty lines and comments.
+	if len(tokens) == 1 || len(tokens) == 2 && tokens[0].ID == TkNewline {
+		return f, nil
+	}
+
+	root, err := NewParser().Parse(tokens)
+	if err != nil {
+		return nil, locate(data, tokens, err)
+	}
+
+	var lines *gr.Token[TokenType]
+
+	for _, child := range children(root) {
+		if child.ID == TkLines {
+			lines = child
+		}
+	}
+
+	for _, node := range flatten(lines) {
+		if node.ID != TkLine {
+			continue
+		}
+
+		line := line_of(data, node.At)
+
+		err := f.add_line(line, node)
+		if err != nil {
+			return nil, NewErrSyntax(line, err)
+		}
+	}
+
+	return f, nil
+}
+
+// Load reads a grammar file. See Parse.
+//
+// Parameters:
+//   - loc: The location of the file.
+//
+// Returns:
+//   - *File: The content of the file. Never nil if err is nil.
+//   - error: An error if the file cannot be read or is not well-formed.
+func Load(loc string) (*File, error) {
+	data, err := os.ReadFile(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
@@ -0,0 +1,139 @@
+package Production
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const (
+		InputStr string = "# A comment.\n%skip WS\n\nWORD = [a-z]+ # not a comment\n" +
+			"EQ = \"=\"\nWS = [ \\t\\r\\n]+\n\n  # Another one.\nsource -> stmts EOF # a comment\n" +
+			"stmts -> stmt stmts | stmt\nstmt -> WORD EQ WORD\n"
+	)
+
+	f, err := Parse([]byte(InputStr))
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if !slices.Equal(f.ToSkip, []string{"WS"}) {
+		t.Errorf("expected [WS] to skip, got %v", f.ToSkip)
+	}
+
+	expected_rules := []LexerRule{
+		{Line: 4, Name: "WORD", Value: "[a-z]+ # not a comment"},
+		{Line: 5, Name: "EQ", Value: "=", Quoted: true},
+		{Line: 6, Name: "WS", Value: `[ \t\r\n]+`},
+	}
+
+	if len(f.LexerRules) != len(expected_rules) {
+		t.Fatalf("expected %d lexer rules, got %d", len(expected_rules), len(f.LexerRules))
+	}
+
+	for i, rule := range f.LexerRules {
+		if *rule != expected_rules[i] {
+			t.Errorf("lexer rule %d: expected %+v, got %+v", i, expected_rules[i], *rule)
+		}
+	}
+
+	expected := []string{
+		"9: source -> stmts EOF",
+		"10: stmts -> stmt stmts",
+		"10: stmts -> stmt",
+		"11: stmt -> WORD EQ WORD",
+	}
+
+	var got []string
+
+	for _, rule := range f.Rules {
+		got = append(got, strconv.Itoa(rule.Line)+": "+rule.Lhs+" -> "+strings.Join(rule.Rhs, " "))
+	}
+
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		line  int
+	}{
+		{"source -> A EOF\nb ->\n", 2},
+		{"A = a\n\nsource -> A | | EOF\n", 3},
+		{"A = \"\"\n", 1},
+		{"source -> A\nA = a\n  $\n", 3},
+		{"A\n", 1},
+	}
+
+	for _, test := range tests {
+		_, err := Parse([]byte(test.input))
+
+		var target *ErrSyntax
+
+		if !errors.As(err, &target) {
+			t.Errorf("%q: expected *ErrSyntax, got %v", test.input, err)
+			continue
+		}
+
+		if target.Line != test.line {
+			t.Errorf("%q: expected line %d, got %d (%s)", test.input, test.line, target.Line, err.Error())
+		}
+	}
+
+	f, err := Parse([]byte("# Only a comment.\n\n"))
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if len(f.LexerRules) != 0 || len(f.Rules) != 0 {
+		t.Errorf("expected no rule, got %d and %d", len(f.LexerRules), len(f.Rules))
+	}
+}
+
+// TestBootstrap checks that the generated files are up to date with
+// meta.grammar: the symbols are numbered by "lyne generate" as EOF, the
+// lexer rules, then the left-hand sides, in the order of the file.
+func TestBootstrap(t *testing.T) {
+	f, err := Load("meta.grammar")
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	names := []string{"EOF"}
+
+	for _, rule := range f.LexerRules {
+		if !slices.Contains(names, rule.Name) {
+			names = append(names, rule.Name)
+		}
+	}
+
+	// A lexer rule may be repeated: the terminals are the distinct names.
+	terminals := len(names)
+
+	for _, rule := range f.Rules {
+		if !slices.Contains(names, rule.Lhs) {
+			names = append(names, rule.Lhs)
+		}
+	}
+
+	for i, name := range names {
+		tt := TokenType(i)
+
+		if tt.String() != name {
+			t.Fatalf("symbol %d: expected %s, got %s; run go generate", i, name, tt.String())
+		}
+
+		if tt.IsTerminal() != (i < terminals) {
+			t.Errorf("%s: expected IsTerminal to be %t", name, !tt.IsTerminal())
+		}
+	}
+
+	if !slices.Equal(f.ToSkip, []string{TkWs.String(), TkComment.String()}) {
+		t.Errorf("expected to skip WS and COMMENT, got %v", f.ToSkip)
+	}
+}
@@ -0,0 +1,146 @@
+// Code generated by lyne generate. DO NOT EDIT.
+
+package Production
+
+import (
+	lx "github.com/PlayerR9/LyneParser/Lexer"
+)
+
+// TokenType is a symbol of the grammar.
+type TokenType int
+
+const (
+	// TkEof is the symbol "EOF".
+	TkEof TokenType = iota
+
+	// TkNewline is the symbol "NEWLINE".
+	TkNewline
+
+	// TkWs is the symbol "WS".
+	TkWs
+
+	// TkComment is the symbol "COMMENT".
+	TkComment
+
+	// TkSkip is the symbol "SKIP".
+	TkSkip
+
+	// TkArrow is the symbol "ARROW".
+	TkArrow
+
+	// TkPipe is the symbol "PIPE".
+	TkPipe
+
+	// TkValue is the symbol "VALUE".
+	TkValue
+
+	// TkTerminal is the symbol "TERMINAL".
+	TkTerminal
+
+	// TkNonterminal is the symbol "NONTERMINAL".
+	TkNonterminal
+
+	// TkSource is the symbol "source".
+	TkSource
+
+	// TkLines is the symbol "lines".
+	TkLines
+
+	// TkLine is the symbol "line".
+	TkLine
+
+	// TkTerminals is the symbol "terminals".
+	TkTerminals
+
+	// TkAlternatives is the symbol "alternatives".
+	TkAlternatives
+
+	// TkSymbols is the symbol "symbols".
+	TkSymbols
+)
+
+// String implements the fmt.Stringer interface.
+func (t TokenType) String() string {
+	return [...]string{
+		"EOF",
+		"NEWLINE",
+		"WS",
+		"COMMENT",
+		"SKIP",
+		"ARROW",
+		"PIPE",
+		"VALUE",
+		"TERMINAL",
+		"NONTERMINAL",
+		"source",
+		"lines",
+		"line",
+		"terminals",
+		"alternatives",
+		"symbols",
+	}[t]
+}
+
+// IsTerminal implements the gr.TokenTyper interface.
+func (t TokenType) IsTerminal() bool {
+	return t <= TkNonterminal
+}
+
+// NewLexer creates the lexer of the grammar. The rules are in the order of
+// the grammar file.
+//
+// Returns:
+//   - *lx.Lexer: The lexer. Nil if an error occurred.
+//   - error: An error if a regular expression does not compile.
+func NewLexer() (*lx.Lexer[TokenType], error) {
+	grammar := lx.NewGrammar([]TokenType{TkWs, TkComment})
+
+	var err error
+
+	err = grammar.AddRule(TkNewline, `(\n[ \t\r]*(#[^\n]*)?)+`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = grammar.AddRule(TkWs, `[ \t\r]+`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = grammar.AddRule(TkComment, `#[^\n]*`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = grammar.AddLiteral(TkSkip, `%skip`, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = grammar.AddLiteral(TkArrow, `->`, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = grammar.AddLiteral(TkPipe, `|`, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = grammar.AddRule(TkValue, `=[^\n]*`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = grammar.AddRule(TkTerminal, `\p{Lu}[\pL\pN_]*`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = grammar.AddRule(TkNonterminal, `[\p{Ll}_][\pL\pN_]*`)
+	if err != nil {
+		return nil, err
+	}
+
+	return lx.NewLexer(grammar), nil
+}
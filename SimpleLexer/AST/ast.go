@@ -0,0 +1,190 @@
+package AST
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Register is one of the 26 registers of the machine, from $a to $z.
+type Register uint8
+
+// String implements the fmt.Stringer interface.
+//
+// Format: "$<letter>"
+func (r Register) String() string {
+	return "$" + string(rune('a'+r))
+}
+
+// Expr is the value of a statement.
+type Expr interface {
+	// Pos returns the byte offset of the expression in the source.
+	//
+	// Returns:
+	//   - int: The offset.
+	Pos() int
+
+	fmt_expr(builder *strings.Builder)
+}
+
+// Load is a load immediate expression, "(<immediate>)".
+type Load struct {
+	// Text is the immediate as written in the source.
+	Text string
+
+	// At is the offset of the immediate.
+	At int
+}
+
+// Pos implements the Expr interface.
+func (l *Load) Pos() int {
+	return l.At
+}
+
+// Value returns the value of the immediate.
+//
+// Returns:
+//   - uint8: The value.
+//   - error: An error of type *ErrImmediateRange if the immediate does not
+//     fit in a register.
+func (l *Load) Value() (uint8, error) {
+	value, err := strconv.ParseUint(l.Text, 10, 8)
+	if err != nil {
+		return 0, NewErrImmediateRange(l.Text, l.At)
+	}
+
+	return uint8(value), nil
+}
+
+func (l *Load) fmt_expr(builder *strings.Builder) {
+	builder.WriteRune('(')
+	builder.WriteString(l.Text)
+	builder.WriteRune(')')
+}
+
+// Read is the value of a register used as an operand.
+type Read struct {
+	// Reg is the register.
+	Reg Register
+
+	// At is the offset of the register.
+	At int
+}
+
+// Pos implements the Expr interface.
+func (r *Read) Pos() int {
+	return r.At
+}
+
+func (r *Read) fmt_expr(builder *strings.Builder) {
+	builder.WriteString(r.Reg.String())
+}
+
+// Unary is a unary instruction, "<x> <op>". The operators are:
+//   - "++": x + 1.
+//   - "--": x - 1.
+//   - ">>": x shifted right by one bit; that is, x / 2.
+//
+// Like every value of the machine, the result is 8 bits wide and wraps
+// around, so "$a --" with $a = 0 is 255.
+type Unary struct {
+	// Op is the operator: "++", "--" or ">>".
+	Op string
+
+	// X is the operand.
+	X Expr
+
+	// At is the offset of the operator.
+	At int
+}
+
+// Pos implements the Expr interface.
+func (u *Unary) Pos() int {
+	return u.X.Pos()
+}
+
+func (u *Unary) fmt_expr(builder *strings.Builder) {
+	u.X.fmt_expr(builder)
+	builder.WriteRune(' ')
+	builder.WriteString(u.Op)
+}
+
+// Binary is a binary instruction, "<x> <y> <op>". The operators are:
+//   - "+": x + y.
+//   - "-": x - y.
+//   - "|": the bitwise OR of x and y.
+//   - "&": the bitwise AND of x and y.
+//   - "^": the bitwise XOR of x and y.
+//   - "!": the bits of x that are not set in y; that is, x AND NOT y.
+//
+// Like every value of the machine, the result is 8 bits wide and wraps
+// around.
+type Binary struct {
+	// Op is the operator: "+", "-", "|", "&", "^" or "!".
+	Op string
+
+	// X is the left operand.
+	X Expr
+
+	// Y is the right operand.
+	Y Expr
+
+	// At is the offset of the operator.
+	At int
+}
+
+// Pos implements the Expr interface.
+func (b *Binary) Pos() int {
+	return b.X.Pos()
+}
+
+func (b *Binary) fmt_expr(builder *strings.Builder) {
+	b.X.fmt_expr(builder)
+	builder.WriteRune(' ')
+	b.Y.fmt_expr(builder)
+	builder.WriteRune(' ')
+	builder.WriteString(b.Op)
+}
+
+// Statement stores the value of an expression in a register.
+type Statement struct {
+	// Value is the expression.
+	Value Expr
+
+	// Dest is the destination register.
+	Dest Register
+
+	// At is the offset of the destination register.
+	At int
+}
+
+// String implements the fmt.Stringer interface.
+//
+// Format: "<value> > <dest>"
+func (s *Statement) String() string {
+	var builder strings.Builder
+
+	s.Value.fmt_expr(&builder)
+	builder.WriteString(" > ")
+	builder.WriteString(s.Dest.String())
+
+	return builder.String()
+}
+
+// Program is a sequence of statements.
+type Program struct {
+	// Statements are the statements in source order.
+	Statements []*Statement
+}
+
+// String implements the fmt.Stringer interface.
+//
+// Format: one statement per line.
+func (p *Program) String() string {
+	values := make([]string, 0, len(p.Statements))
+
+	for _, stmt := range p.Statements {
+		values = append(values, stmt.String())
+	}
+
+	return strings.Join(values, "\n")
+}
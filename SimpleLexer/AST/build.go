@@ -0,0 +1,272 @@
+package AST
+
+import (
+	"fmt"
+	"strconv"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	com "github.com/PlayerR9/LyneParser/SimpleLexer/Common"
+)
+
+// children returns the children of a non-leaf token.
+//
+// Parameters:
+//   - tok: The token.
+//   - id: The expected ID of the token.
+//
+// Returns:
+//   - []*gr.Token: The children.
+//   - error: An error of type *ErrUnexpectedNode if the token is not a
+//     non-leaf token with the given ID.
+func children(tok *gr.Token[com.TestTkType], id com.TestTkType) ([]*gr.Token[com.TestTkType], error) {
+	if tok == nil {
+		return nil, NewErrUnexpectedNode(id.String(), "nothing")
+	}
+
+	if tok.ID != id {
+		return nil, NewErrUnexpectedNode(id.String(), tok.ID.String())
+	}
+
+	data, ok := tok.Data.([]*gr.Token[com.TestTkType])
+	if !ok || len(data) == 0 {
+		return nil, NewErrUnexpectedNode(id.String(), "a leaf")
+	}
+
+	return data, nil
+}
+
+// text returns the text of a leaf token.
+//
+// Parameters:
+//   - tok: The token.
+//   - id: The expected ID of the token.
+//
+// Returns:
+//   - string: The text.
+//   - error: An error of type *ErrUnexpectedNode if the token is not a leaf
+//     token with the given ID.
+func text(tok *gr.Token[com.TestTkType], id com.TestTkType) (string, error) {
+	if tok == nil {
+		return "", NewErrUnexpectedNode(id.String(), "nothing")
+	}
+
+	if tok.ID != id {
+		return "", NewErrUnexpectedNode(id.String(), tok.ID.String())
+	}
+
+	data, ok := tok.Data.(string)
+	if !ok {
+		return "", NewErrUnexpectedNode(id.String(), "a non-leaf")
+	}
+
+	return data, nil
+}
+
+// Build converts the tree of SimpleLexer/Parser into a program.
+//
+// Parameters:
+//   - root: The source token returned by the parser.
+//
+// Returns:
+//   - *Program: The program.
+//   - error: An error if the tree does not have the shape of the parser.
+//
+// Errors:
+//   - *ErrUnexpectedNode: If a node is not the expected one.
+func Build(root *gr.Token[com.TestTkType]) (*Program, error) {
+	data, err := children(root, com.TkSource)
+	if err != nil {
+		return nil, err
+	}
+
+	prog := &Program{}
+
+	// Source1 is either "Statement" or "Statement Source1", with the
+	// newlines already dropped by the parser.
+	for next := data[0]; next != nil; {
+		data, err := children(next, com.TkSource1)
+		if err != nil {
+			return nil, err
+		}
+
+		stmt, err := build_statement(data[0])
+		if err != nil {
+			return nil, fmt.Errorf("statement %d: %w", len(prog.Statements)+1, err)
+		}
+
+		prog.Statements = append(prog.Statements, stmt)
+
+		if len(data) > 1 {
+			next = data[1]
+		} else {
+			next = nil
+		}
+	}
+
+	return prog, nil
+}
+
+// build_register converts a register token.
+//
+// Parameters:
+//   - tok: The register token.
+//
+// Returns:
+//   - Register: The register.
+//   - error: An error of type *ErrUnexpectedNode if the token is not a
+//     register.
+func build_register(tok *gr.Token[com.TestTkType]) (Register, error) {
+	str, err := text(tok, com.TkRegister)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(str) != 2 || str[0] != '$' || str[1] < 'a' || str[1] > 'z' {
+		return 0, NewErrUnexpectedNode("register", strconv.Quote(str))
+	}
+
+	return Register(str[1] - 'a'), nil
+}
+
+// build_statement converts a statement token; that is, the value followed
+// by the destination register.
+//
+// Parameters:
+//   - tok: The statement token.
+//
+// Returns:
+//   - *Statement: The statement.
+//   - error: An error of type *ErrUnexpectedNode if the tree is invalid.
+func build_statement(tok *gr.Token[com.TestTkType]) (*Statement, error) {
+	data, err := children(tok, com.TkStatement)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) != 2 {
+		return nil, NewErrUnexpectedNode("value and register", strconv.Itoa(len(data))+" nodes")
+	}
+
+	value, err := build_expr(data[0])
+	if err != nil {
+		return nil, err
+	}
+
+	dest, err := build_register(data[1])
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &Statement{
+		Value: value,
+		Dest:  dest,
+		At:    data[1].At,
+	}
+
+	return stmt, nil
+}
+
+// build_expr converts the value of a statement.
+//
+// Parameters:
+//   - tok: The unary instruction, binary instruction or load immediate token.
+//
+// Returns:
+//   - Expr: The expression.
+//   - error: An error of type *ErrUnexpectedNode if the tree is invalid.
+func build_expr(tok *gr.Token[com.TestTkType]) (Expr, error) {
+	if tok == nil {
+		return nil, NewErrUnexpectedNode("instruction", "nothing")
+	}
+
+	switch tok.ID {
+	case com.TkLoadImmediate:
+		data, err := children(tok, com.TkLoadImmediate)
+		if err != nil {
+			return nil, err
+		}
+
+		str, err := text(data[0], com.TkImmediate)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Load{Text: str, At: data[0].At}, nil
+	case com.TkUnaryInstruction:
+		data, err := children(tok, com.TkUnaryInstruction)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) != 2 {
+			return nil, NewErrUnexpectedNode("operand and operator", strconv.Itoa(len(data))+" nodes")
+		}
+
+		x, err := build_operand(data[0])
+		if err != nil {
+			return nil, err
+		}
+
+		op, err := text(data[1], com.TkUnaryOp)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Unary{Op: op, X: x, At: data[1].At}, nil
+	case com.TkBinInstruction:
+		data, err := children(tok, com.TkBinInstruction)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) != 3 {
+			return nil, NewErrUnexpectedNode("two operands and operator", strconv.Itoa(len(data))+" nodes")
+		}
+
+		x, err := build_operand(data[0])
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := build_operand(data[1])
+		if err != nil {
+			return nil, err
+		}
+
+		op, err := text(data[2], com.TkBinOp)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Binary{Op: op, X: x, Y: y, At: data[2].At}, nil
+	default:
+		return nil, NewErrUnexpectedNode("instruction", tok.ID.String())
+	}
+}
+
+// build_operand converts an operand token; that is, a register or a nested
+// binary instruction.
+//
+// Parameters:
+//   - tok: The operand token.
+//
+// Returns:
+//   - Expr: The expression.
+//   - error: An error of type *ErrUnexpectedNode if the tree is invalid.
+func build_operand(tok *gr.Token[com.TestTkType]) (Expr, error) {
+	data, err := children(tok, com.TkOperand)
+	if err != nil {
+		return nil, err
+	}
+
+	if data[0] != nil && data[0].ID == com.TkBinInstruction {
+		return build_expr(data[0])
+	}
+
+	reg, err := build_register(data[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Read{Reg: reg, At: data[0].At}, nil
+}
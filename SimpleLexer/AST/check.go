@@ -0,0 +1,67 @@
+package AST
+
+import "errors"
+
+// Check verifies that every register is written before it is read and that
+// every immediate fits in a register.
+//
+// Parameters:
+//   - prog: The program.
+//
+// Returns:
+//   - error: The errors found, joined, or nil if the program is valid.
+//
+// Errors:
+//   - *ErrUndefinedRegister: For each read of a register that no earlier
+//     statement writes.
+//   - *ErrImmediateRange: For each immediate greater than 255.
+//
+// Behaviors:
+//   - A statement may read its own destination only if an earlier statement
+//     wrote it.
+func Check(prog *Program) error {
+	if prog == nil {
+		return nil
+	}
+
+	var defined [26]bool
+	var errs []error
+
+	for _, stmt := range prog.Statements {
+		errs = check_expr(stmt.Value, &defined, errs)
+
+		defined[stmt.Dest] = true
+	}
+
+	return errors.Join(errs...)
+}
+
+// check_expr checks an expression against the registers defined so far.
+//
+// Parameters:
+//   - expr: The expression.
+//   - defined: The registers defined so far.
+//   - errs: The errors found so far.
+//
+// Returns:
+//   - []error: The errors found so far, with the ones of the expression.
+func check_expr(expr Expr, defined *[26]bool, errs []error) []error {
+	switch expr := expr.(type) {
+	case *Load:
+		_, err := expr.Value()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	case *Read:
+		if !defined[expr.Reg] {
+			errs = append(errs, NewErrUndefinedRegister(expr.Reg, expr.At))
+		}
+	case *Unary:
+		errs = check_expr(expr.X, defined, errs)
+	case *Binary:
+		errs = check_expr(expr.X, defined, errs)
+		errs = check_expr(expr.Y, defined, errs)
+	}
+
+	return errs
+}
@@ -0,0 +1,105 @@
+package AST
+
+import "strconv"
+
+// ErrUnexpectedNode is an error that is returned when the parse tree has a
+// node where the AST does not expect it.
+type ErrUnexpectedNode struct {
+	// Expected is the expected node.
+	Expected string
+
+	// Got is the node found.
+	Got string
+}
+
+// Error implements the error interface.
+//
+// Message: "expected <expected>, got <got> instead"
+func (e *ErrUnexpectedNode) Error() string {
+	return "expected " + e.Expected + ", got " + e.Got + " instead"
+}
+
+// NewErrUnexpectedNode creates a new error of type *ErrUnexpectedNode.
+//
+// Parameters:
+//   - expected: The expected node.
+//   - got: The node found.
+//
+// Returns:
+//   - *ErrUnexpectedNode: The new error. Never nil.
+func NewErrUnexpectedNode(expected, got string) *ErrUnexpectedNode {
+	e := &ErrUnexpectedNode{
+		Expected: expected,
+		Got:      got,
+	}
+
+	return e
+}
+
+// ErrUndefinedRegister is an error that is returned when a register is read
+// before any statement writes it.
+type ErrUndefinedRegister struct {
+	// Reg is the register.
+	Reg Register
+
+	// At is the offset of the read.
+	At int
+}
+
+// Error implements the error interface.
+//
+// Message: "register <reg> is read at <at> before it is written"
+func (e *ErrUndefinedRegister) Error() string {
+	return "register " + e.Reg.String() + " is read at " + strconv.Itoa(e.At) + " before it is written"
+}
+
+// NewErrUndefinedRegister creates a new error of type *ErrUndefinedRegister.
+//
+// Parameters:
+//   - reg: The register.
+//   - at: The offset of the read.
+//
+// Returns:
+//   - *ErrUndefinedRegister: The new error. Never nil.
+func NewErrUndefinedRegister(reg Register, at int) *ErrUndefinedRegister {
+	e := &ErrUndefinedRegister{
+		Reg: reg,
+		At:  at,
+	}
+
+	return e
+}
+
+// ErrImmediateRange is an error that is returned when an immediate does not
+// fit in a register.
+type ErrImmediateRange struct {
+	// Text is the immediate as written in the source.
+	Text string
+
+	// At is the offset of the immediate.
+	At int
+}
+
+// Error implements the error interface.
+//
+// Message: "immediate <text> at <at> is not in [0, 255]"
+func (e *ErrImmediateRange) Error() string {
+	return "immediate " + e.Text + " at " + strconv.Itoa(e.At) + " is not in [0, 255]"
+}
+
+// NewErrImmediateRange creates a new error of type *ErrImmediateRange.
+//
+// Parameters:
+//   - text: The immediate as written in the source.
+//   - at: The offset of the immediate.
+//
+// Returns:
+//   - *ErrImmediateRange: The new error. Never nil.
+func NewErrImmediateRange(text string, at int) *ErrImmediateRange {
+	e := &ErrImmediateRange{
+		Text: text,
+		At:   at,
+	}
+
+	return e
+}
@@ -0,0 +1,92 @@
+package Combinator
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ErrUnexpectedRune is the error returned when a scanner does not accept
+// the next rune of the stream.
+type ErrUnexpectedRune struct {
+	// Expected is the description of what the scanner expected.
+	Expected string
+
+	// Got is the rune that was found. Nil if the stream was exhausted.
+	Got *rune
+
+	// At is the position of the rune, in bytes.
+	At int
+}
+
+// Error implements the error interface.
+//
+// Message: "expected <expected>, got <got> at <at>"
+func (e *ErrUnexpectedRune) Error() string {
+	var builder strings.Builder
+
+	builder.WriteString("expected ")
+	builder.WriteString(e.Expected)
+	builder.WriteString(", got ")
+
+	if e.Got != nil {
+		builder.WriteString(strconv.QuoteRune(*e.Got))
+	} else {
+		builder.WriteString("end of input")
+	}
+
+	builder.WriteString(" at ")
+	builder.WriteString(strconv.Itoa(e.At))
+
+	return builder.String()
+}
+
+// NewErrUnexpectedRune creates a new unexpected rune error.
+//
+// Parameters:
+//   - expected: The description of what the scanner expected.
+//   - got: The rune that was found. Nil if the stream was exhausted.
+//   - at: The position of the rune, in bytes.
+//
+// Returns:
+//   - *ErrUnexpectedRune: The new error.
+func NewErrUnexpectedRune(expected string, got *rune, at int) *ErrUnexpectedRune {
+	e := &ErrUnexpectedRune{
+		Expected: expected,
+		Got:      got,
+		At:       at,
+	}
+	return e
+}
+
+// ErrEmptyToken is the error returned when a rule matches an empty text,
+// which would make the lexer loop forever.
+type ErrEmptyToken struct {
+	// Rule is the description of the rule.
+	Rule string
+
+	// At is the position of the token, in bytes.
+	At int
+}
+
+// Error implements the error interface.
+//
+// Message: "rule <rule> matched an empty token at <at>"
+func (e *ErrEmptyToken) Error() string {
+	return "rule " + e.Rule + " matched an empty token at " + strconv.Itoa(e.At)
+}
+
+// NewErrEmptyToken creates a new empty token error.
+//
+// Parameters:
+//   - rule: The description of the rule.
+//   - at: The position of the token, in bytes.
+//
+// Returns:
+//   - *ErrEmptyToken: The new error.
+func NewErrEmptyToken(rule string, at int) *ErrEmptyToken {
+	e := &ErrEmptyToken{
+		Rule: rule,
+		At:   at,
+	}
+	return e
+}
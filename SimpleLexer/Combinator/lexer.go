@@ -0,0 +1,244 @@
+package Combinator
+
+import (
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	stm "github.com/PlayerR9/LyneParser/SimpleLexer/Stream"
+)
+
+// Rule is a rule of a lexer: the tokens of a type and the scanner of their
+// text.
+type Rule[T gr.TokenTyper] struct {
+	// id is the type of the tokens.
+	id T
+
+	// scanner is the scanner of the text of the tokens.
+	scanner Scanner
+
+	// keywords are the types of the texts that are keywords. Nil if there
+	// are none.
+	keywords map[string]T
+}
+
+// NewRule creates a new rule.
+//
+// Parameters:
+//   - id: The type of the tokens.
+//   - scanner: The scanner of the text of the tokens.
+//
+// Returns:
+//   - *Rule: The new rule.
+func NewRule[T gr.TokenTyper](id T, scanner Scanner) *Rule[T] {
+	r := &Rule[T]{
+		id:      id,
+		scanner: scanner,
+	}
+
+	return r
+}
+
+// SetKeywords sets the keyword table of the rule: a token whose text is in
+// the table gets the type of the table instead of the one of the rule. For
+// instance, a rule of identifiers with the table {"if": TkIf} makes "if" a
+// TkIf token.
+//
+// Parameters:
+//   - keywords: The types of the keywords. It is copied.
+//
+// Returns:
+//   - *Rule: The rule, for chaining.
+func (r *Rule[T]) SetKeywords(keywords map[string]T) *Rule[T] {
+	r.keywords = maps.Clone(keywords)
+
+	return r
+}
+
+// String returns the description of the rule.
+//
+// Format:
+//
+//	<id> = <scanner>
+func (r *Rule[T]) String() string {
+	return r.id.String() + " = " + r.scanner.String()
+}
+
+// Lexer is a lexer made of rules of scanners.
+type Lexer[T gr.TokenTyper] struct {
+	// rules are the rules, by priority.
+	rules []*Rule[T]
+
+	// to_skip are the types of the tokens to skip.
+	to_skip []T
+}
+
+// NewLexer creates a new lexer.
+//
+// Parameters:
+//   - rules: The rules, by priority. Nil rules are ignored.
+//
+// Returns:
+//   - *Lexer: The new lexer.
+func NewLexer[T gr.TokenTyper](rules ...*Rule[T]) *Lexer[T] {
+	l := &Lexer[T]{
+		rules: slices.DeleteFunc(slices.Clone(rules), func(r *Rule[T]) bool {
+			return r == nil
+		}),
+	}
+
+	return l
+}
+
+// SetToSkip sets the types of the tokens that Lex drops.
+//
+// Parameters:
+//   - ids: The types of the tokens to skip.
+func (l *Lexer[T]) SetToSkip(ids ...T) {
+	l.to_skip = slices.Clone(ids)
+}
+
+// Next scans the next token of a stream.
+//
+// Parameters:
+//   - s: The stream.
+//
+// Returns:
+//   - *gr.Token: The token, skipped ones included. Its lookahead is not set.
+//   - error: An error if no token could be scanned.
+//
+// Errors:
+//   - *stm.ErrStreamExhausted: If the stream is exhausted.
+//   - *ErrUnexpectedRune: If no rule starts with the next rune, or the rule
+//     that starts with it does not match.
+//   - *ErrEmptyToken: If the rule that starts with the next rune matches an
+//     empty text.
+//   - any other error of the stream.
+//
+// Behaviors:
+//   - The first rule that starts with the next rune is used. If it fails
+//     without consuming anything, the next one that starts with the rune
+//     is tried.
+func (l *Lexer[T]) Next(s *stm.Stream) (*gr.Token[T], error) {
+	at := s.Pos()
+
+	r, ok, err := peek(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, stm.NewStreamExhausted()
+	}
+
+	var rule *Rule[T]
+	var builder strings.Builder
+
+	for _, candidate := range l.rules {
+		if !candidate.scanner.Starts(r) {
+			continue
+		}
+
+		rule = candidate
+
+		err = rule.scanner.Scan(s, &builder)
+		if err == nil || s.Pos() != at {
+			break
+		}
+	}
+
+	if rule == nil {
+		return nil, NewErrUnexpectedRune("a token", &r, at)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	text := builder.String()
+	if text == "" {
+		return nil, NewErrEmptyToken(rule.String(), at)
+	}
+
+	id := rule.id
+
+	if kw, ok := rule.keywords[text]; ok {
+		id = kw
+	}
+
+	return gr.NewToken(id, text, at, nil), nil
+}
+
+// Lex lexes a whole text.
+//
+// Parameters:
+//   - data: The text.
+//   - eof: The type of the end-of-file token.
+//
+// Returns:
+//   - []*gr.Token: The tokens, without the skipped ones, followed by an
+//     end-of-file token. Each token has the next one as its lookahead.
+//   - error: An error if the text could not be lexed. See Next.
+//
+// Behaviors:
+//   - On error, the tokens lexed so far are returned, without an
+//     end-of-file token.
+func (l *Lexer[T]) Lex(data []byte, eof T) ([]*gr.Token[T], error) {
+	s := stm.NewStream(data)
+
+	return l.lex_stream(&s, eof)
+}
+
+// LexReader is like Lex but reads the text from a reader, one chunk at a
+// time.
+//
+// Parameters:
+//   - r: The reader.
+//   - eof: The type of the end-of-file token.
+//
+// Returns:
+//   - []*gr.Token: The tokens. See Lex.
+//   - error: An error if the text could not be lexed or read. See Next.
+func (l *Lexer[T]) LexReader(r io.Reader, eof T) ([]*gr.Token[T], error) {
+	s := stm.NewReaderStream(r, stm.DefaultChunkSize)
+
+	return l.lex_stream(&s, eof)
+}
+
+// lex_stream is a helper method that lexes a whole stream.
+//
+// Parameters:
+//   - s: The stream.
+//   - eof: The type of the end-of-file token.
+//
+// Returns:
+//   - []*gr.Token: The tokens. See Lex.
+//   - error: An error if the stream could not be lexed. See Next.
+func (l *Lexer[T]) lex_stream(s *stm.Stream, eof T) ([]*gr.Token[T], error) {
+	var tokens []*gr.Token[T]
+
+	for {
+		tok, err := l.Next(s)
+		if stm.IsStreamExhausted(err) {
+			break
+		}
+
+		if err != nil {
+			return tokens, err
+		}
+
+		if !slices.Contains(l.to_skip, tok.ID) {
+			tokens = append(tokens, tok)
+		}
+	}
+
+	tokens = append(tokens, gr.NewToken(eof, "", s.Pos(), nil))
+
+	for i := 0; i < len(tokens)-1; i++ {
+		tokens[i].SetLookahead(tokens[i+1])
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,188 @@
+package Combinator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode"
+)
+
+type CombTokenType int
+
+const (
+	CtEOF CombTokenType = iota
+	CtIdent
+	CtIf
+	CtNumber
+	CtArrow
+	CtMinus
+	CtWs
+)
+
+func (t CombTokenType) IsTerminal() bool {
+	return true
+}
+
+func (t CombTokenType) String() string {
+	return [...]string{
+		"EOF",
+		"IDENT",
+		"IF",
+		"NUMBER",
+		"ARROW",
+		"MINUS",
+		"WS",
+	}[t]
+}
+
+func new_comb_lexer() *Lexer[CombTokenType] {
+	ident_start := Alt(Letter, Char('_'))
+	ident_rest := Many(Alt(Letter, Digit, Char('_')))
+
+	l := NewLexer(
+		NewRule(CtIdent, Seq(ident_start, ident_rest)).SetKeywords(map[string]CombTokenType{
+			"if": CtIf,
+		}),
+		NewRule(CtNumber, Seq(Many1(Digit), Optional(Seq(Char('.'), Many1(Digit))))),
+		NewRule(CtMinus, Seq(Char('-'), Optional(Char('>')))).SetKeywords(map[string]CombTokenType{
+			"->": CtArrow,
+		}),
+		NewRule(CtWs, Many1(Class("whitespace", unicode.IsSpace))),
+	)
+
+	l.SetToSkip(CtWs)
+
+	return l
+}
+
+func TestLexer(t *testing.T) {
+	const input = "if x_1 -> 3.14 - iffy"
+
+	tokens, err := new_comb_lexer().Lex([]byte(input), CtEOF)
+	if err != nil {
+		t.Fatalf("Lex failed: %s", err.Error())
+	}
+
+	want := []struct {
+		id   CombTokenType
+		text string
+		at   int
+	}{
+		{CtIf, "if", 0},
+		{CtIdent, "x_1", 3},
+		{CtArrow, "->", 7},
+		{CtNumber, "3.14", 10},
+		{CtMinus, "-", 15},
+		{CtIdent, "iffy", 17},
+		{CtEOF, "", 21},
+	}
+
+	if len(tokens) != len(want) {
+		t.Fatalf("expected %d tokens, got %d", len(want), len(tokens))
+	}
+
+	for i, tok := range tokens {
+		if tok.ID != want[i].id || tok.Data != want[i].text || tok.At != want[i].at {
+			t.Errorf("token %d: expected %s %q at %d, got %s %q at %d", i, want[i].id, want[i].text, want[i].at, tok.ID, tok.Data, tok.At)
+		}
+
+		if i+1 < len(tokens) && tok.Lookahead != tokens[i+1] {
+			t.Errorf("token %d: wrong lookahead", i)
+		}
+	}
+}
+
+func TestLexerErrors(t *testing.T) {
+	rune_ptr := func(r rune) *rune {
+		return &r
+	}
+
+	tests := []struct {
+		input string
+		at    int
+		got   *rune
+	}{
+		{"x = 1", 2, rune_ptr('=')},
+		{"3.", 2, nil},
+		{"3.x", 2, rune_ptr('x')},
+	}
+
+	for _, test := range tests {
+		_, err := new_comb_lexer().Lex([]byte(test.input), CtEOF)
+
+		var target *ErrUnexpectedRune
+
+		if !errors.As(err, &target) {
+			t.Errorf("%q: expected *ErrUnexpectedRune, got %v", test.input, err)
+			continue
+		}
+
+		if target.At != test.at || (target.Got == nil) != (test.got == nil) || (target.Got != nil && *target.Got != *test.got) {
+			t.Errorf("%q: unexpected error %s", test.input, err.Error())
+		}
+	}
+}
+
+func TestScannerString(t *testing.T) {
+	s := Seq(Char('$'), Optional(Digit), Many(Letter), Many1(OneOf("ab")), Alt(Literal("->"), Range('0', '9')))
+
+	want := `'$' [digit] {letter} one of "ab" {one of "ab"} ("->" | '0'..'9')`
+	if s.String() != want {
+		t.Errorf("expected %s, got %s", want, s.String())
+	}
+}
+
+func TestTry(t *testing.T) {
+	l := NewLexer(
+		NewRule(CtNumber, Seq(Many1(Digit), Optional(Try(Seq(Char('.'), Many1(Digit)))))),
+		NewRule(CtArrow, Literal("->")),
+		NewRule(CtMinus, Char('-')),
+		NewRule(CtIdent, Alt(Try(Literal("..")), Char('.'))),
+	)
+
+	tokens, err := l.Lex([]byte("3.-->3..4"), CtEOF)
+	if err != nil {
+		t.Fatalf("Lex failed: %s", err.Error())
+	}
+
+	var got []string
+
+	for _, tok := range tokens {
+		got = append(got, tok.ID.String()+" "+tok.Data.(string))
+	}
+
+	want := "NUMBER 3,IDENT .,MINUS -,ARROW ->,NUMBER 3,IDENT ..,NUMBER 4,EOF "
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, ","))
+	}
+}
+
+func TestLexReader(t *testing.T) {
+	const input = "if x_1 -> 3.14 - iffy"
+
+	want, err := new_comb_lexer().Lex([]byte(input), CtEOF)
+	if err != nil {
+		t.Fatalf("Lex failed: %s", err.Error())
+	}
+
+	tokens, err := new_comb_lexer().LexReader(iotest.OneByteReader(strings.NewReader(input)), CtEOF)
+	if err != nil {
+		t.Fatalf("LexReader failed: %s", err.Error())
+	}
+
+	if len(tokens) != len(want) {
+		t.Fatalf("expected %d tokens, got %d", len(want), len(tokens))
+	}
+
+	for i, tok := range tokens {
+		if tok.ID != want[i].ID || tok.Data != want[i].Data || tok.At != want[i].At {
+			t.Errorf("token %d: expected %s %q at %d, got %s %q at %d", i, want[i].ID, want[i].Data, want[i].At, tok.ID, tok.Data, tok.At)
+		}
+	}
+
+	_, err = new_comb_lexer().LexReader(iotest.TimeoutReader(strings.NewReader("if x")), CtEOF)
+	if !errors.Is(err, iotest.ErrTimeout) {
+		t.Errorf("expected %v, got %v", iotest.ErrTimeout, err)
+	}
+}
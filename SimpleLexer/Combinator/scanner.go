@@ -0,0 +1,682 @@
+package Combinator
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	stm "github.com/PlayerR9/LyneParser/SimpleLexer/Stream"
+)
+
+// Scanner is a part of the text of a token. The scanners are predictive:
+// they choose what to do from the next rune only and only backtrack when
+// wrapped in Try, so a lexer made of them reads every rune once unless
+// asked otherwise.
+type Scanner interface {
+	// Starts checks whether the scanner consumes the given rune when it is
+	// the next one.
+	//
+	// Parameters:
+	//   - r: The next rune.
+	//
+	// Returns:
+	//   - bool: True if the scanner starts with the rune, false otherwise.
+	Starts(r rune) bool
+
+	// Nullable checks whether the scanner can match an empty text.
+	//
+	// Returns:
+	//   - bool: True if the scanner can match an empty text.
+	Nullable() bool
+
+	// Scan consumes the runes of the stream that the scanner matches.
+	//
+	// Parameters:
+	//   - s: The stream.
+	//   - builder: The builder the matched runes are written to.
+	//
+	// Returns:
+	//   - error: An error if the next runes do not match. The runes read
+	//     before the error are consumed; a scanner that fails without
+	//     consuming anything lets Alt and Repeat try something else.
+	//
+	// Errors:
+	//   - *ErrUnexpectedRune: If a rune does not match.
+	//   - any error of the stream other than its exhaustion.
+	Scan(s *stm.Stream, builder *strings.Builder) error
+
+	// String returns the description of the scanner, used by the errors.
+	String() string
+}
+
+// peek is a helper function that returns the next rune of a stream.
+//
+// Parameters:
+//   - s: The stream.
+//
+// Returns:
+//   - rune: The next rune.
+//   - bool: False if the stream is exhausted.
+//   - error: An error if the stream failed.
+func peek(s *stm.Stream) (rune, bool, error) {
+	r, err := s.Peek()
+	if err == nil {
+		return r, true, nil
+	}
+
+	if stm.IsStreamExhausted(err) {
+		return 0, false, nil
+	}
+
+	return 0, false, err
+}
+
+// accept_if is a helper function that consumes the next rune of a stream if
+// it satisfies a predicate.
+//
+// Parameters:
+//   - s: The stream.
+//   - builder: The builder the rune is written to.
+//   - expected: The description of the expected rune.
+//   - pred: The predicate.
+//
+// Returns:
+//   - error: An error of type *ErrUnexpectedRune if the rune does not
+//     satisfy the predicate or the stream is exhausted.
+func accept_if(s *stm.Stream, builder *strings.Builder, expected string, pred func(rune) bool) error {
+	at := s.Pos()
+
+	r, ok, err := peek(s)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return NewErrUnexpectedRune(expected, nil, at)
+	}
+
+	if !pred(r) {
+		return NewErrUnexpectedRune(expected, &r, at)
+	}
+
+	builder.WriteRune(r)
+	s.Accept()
+
+	return nil
+}
+
+// class_scanner is a scanner of one rune of a class.
+type class_scanner struct {
+	// name is the description of the class.
+	name string
+
+	// pred is the predicate of the runes of the class.
+	pred func(rune) bool
+}
+
+// Starts implements the Scanner interface.
+func (cs *class_scanner) Starts(r rune) bool {
+	return cs.pred(r)
+}
+
+// Nullable implements the Scanner interface.
+func (cs *class_scanner) Nullable() bool {
+	return false
+}
+
+// Scan implements the Scanner interface.
+func (cs *class_scanner) Scan(s *stm.Stream, builder *strings.Builder) error {
+	return accept_if(s, builder, cs.name, cs.pred)
+}
+
+// String implements the Scanner interface.
+func (cs *class_scanner) String() string {
+	return cs.name
+}
+
+// Class creates a scanner of one rune of a class.
+//
+// Parameters:
+//   - name: The description of the class, such as "digit".
+//   - pred: The predicate of the runes of the class.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func Class(name string, pred func(rune) bool) Scanner {
+	cs := &class_scanner{
+		name: name,
+		pred: pred,
+	}
+
+	return cs
+}
+
+// Char creates a scanner of one given rune.
+//
+// Parameters:
+//   - c: The rune.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func Char(c rune) Scanner {
+	return Class(strconv.QuoteRune(c), func(r rune) bool {
+		return r == c
+	})
+}
+
+// Range creates a scanner of one rune of a range.
+//
+// Parameters:
+//   - lo: The first rune of the range.
+//   - hi: The last rune of the range. Included.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func Range(lo, hi rune) Scanner {
+	return Class(strconv.QuoteRune(lo)+".."+strconv.QuoteRune(hi), func(r rune) bool {
+		return lo <= r && r <= hi
+	})
+}
+
+// OneOf creates a scanner of one of the runes of a string.
+//
+// Parameters:
+//   - chars: The runes.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func OneOf(chars string) Scanner {
+	return Class("one of "+strconv.Quote(chars), func(r rune) bool {
+		return strings.ContainsRune(chars, r)
+	})
+}
+
+// NoneOf creates a scanner of one rune that is not in a string.
+//
+// Parameters:
+//   - chars: The runes that are not accepted.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func NoneOf(chars string) Scanner {
+	return Class("none of "+strconv.Quote(chars), func(r rune) bool {
+		return !strings.ContainsRune(chars, r)
+	})
+}
+
+var (
+	// Digit is a scanner of one decimal digit.
+	Digit Scanner
+
+	// Letter is a scanner of one letter.
+	Letter Scanner
+
+	// Space is a scanner of one whitespace character.
+	Space Scanner
+
+	// Any is a scanner of any rune.
+	Any Scanner
+)
+
+func init() {
+	Digit = Class("digit", unicode.IsDigit)
+	Letter = Class("letter", unicode.IsLetter)
+	Space = Class("whitespace", unicode.IsSpace)
+	Any = Class("any character", func(r rune) bool {
+		return true
+	})
+}
+
+// literal_scanner is a scanner of a fixed text.
+type literal_scanner struct {
+	// text is the text.
+	text string
+
+	// runes are the runes of the text.
+	runes []rune
+}
+
+// Starts implements the Scanner interface.
+func (ls *literal_scanner) Starts(r rune) bool {
+	return len(ls.runes) > 0 && ls.runes[0] == r
+}
+
+// Nullable implements the Scanner interface.
+func (ls *literal_scanner) Nullable() bool {
+	return len(ls.runes) == 0
+}
+
+// Scan implements the Scanner interface.
+//
+// Behaviors:
+//   - The whole literal is looked ahead before anything is consumed, so a
+//     text that only starts like the literal fails without consuming it.
+//     The error is at the first rune that differs.
+func (ls *literal_scanner) Scan(s *stm.Stream, builder *strings.Builder) error {
+	ok, err := s.HasPrefix(ls.text)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		for range ls.runes {
+			s.Accept()
+		}
+
+		builder.WriteString(ls.text)
+
+		return nil
+	}
+
+	at := s.Pos()
+
+	for i, c := range ls.runes {
+		r, err := s.PeekAt(i)
+		if stm.IsStreamExhausted(err) {
+			return NewErrUnexpectedRune(ls.String(), nil, at)
+		} else if err != nil {
+			return err
+		}
+
+		if r != c {
+			return NewErrUnexpectedRune(ls.String(), &r, at)
+		}
+
+		at += utf8.RuneLen(c)
+	}
+
+	return nil
+}
+
+// String implements the Scanner interface.
+func (ls *literal_scanner) String() string {
+	return strconv.Quote(ls.text)
+}
+
+// Literal creates a scanner of a fixed text.
+//
+// Parameters:
+//   - text: The text.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func Literal(text string) Scanner {
+	ls := &literal_scanner{
+		text:  text,
+		runes: []rune(text),
+	}
+
+	return ls
+}
+
+// seq_scanner is a scanner of a sequence of scanners.
+type seq_scanner struct {
+	// elems are the scanners, in order.
+	elems []Scanner
+}
+
+// Starts implements the Scanner interface.
+func (ss *seq_scanner) Starts(r rune) bool {
+	for _, elem := range ss.elems {
+		if elem.Starts(r) {
+			return true
+		}
+
+		if !elem.Nullable() {
+			return false
+		}
+	}
+
+	return false
+}
+
+// Nullable implements the Scanner interface.
+func (ss *seq_scanner) Nullable() bool {
+	for _, elem := range ss.elems {
+		if !elem.Nullable() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Scan implements the Scanner interface.
+func (ss *seq_scanner) Scan(s *stm.Stream, builder *strings.Builder) error {
+	for _, elem := range ss.elems {
+		err := elem.Scan(s, builder)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// String implements the Scanner interface.
+func (ss *seq_scanner) String() string {
+	values := make([]string, 0, len(ss.elems))
+
+	for _, elem := range ss.elems {
+		values = append(values, elem.String())
+	}
+
+	return strings.Join(values, " ")
+}
+
+// Seq creates a scanner of a sequence of scanners.
+//
+// Parameters:
+//   - elems: The scanners, in order.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func Seq(elems ...Scanner) Scanner {
+	ss := &seq_scanner{
+		elems: elems,
+	}
+
+	return ss
+}
+
+// alt_scanner is a scanner of one of several scanners.
+type alt_scanner struct {
+	// alts are the alternatives, by priority.
+	alts []Scanner
+}
+
+// Starts implements the Scanner interface.
+func (as *alt_scanner) Starts(r rune) bool {
+	for _, alt := range as.alts {
+		if alt.Starts(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Nullable implements the Scanner interface.
+func (as *alt_scanner) Nullable() bool {
+	for _, alt := range as.alts {
+		if alt.Nullable() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Scan implements the Scanner interface.
+//
+// Behaviors:
+//   - The first alternative that starts with the next rune is used. If it
+//     fails without consuming anything, the next one that starts with the
+//     rune is tried. If there is none, the first nullable alternative
+//     matches an empty text.
+func (as *alt_scanner) Scan(s *stm.Stream, builder *strings.Builder) error {
+	at := s.Pos()
+
+	r, ok, err := peek(s)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		var last error
+
+		for _, alt := range as.alts {
+			if !alt.Starts(r) {
+				continue
+			}
+
+			last = alt.Scan(s, builder)
+			if last == nil || s.Pos() != at {
+				return last
+			}
+		}
+
+		if last != nil {
+			return last
+		}
+	}
+
+	for _, alt := range as.alts {
+		if alt.Nullable() {
+			return alt.Scan(s, builder)
+		}
+	}
+
+	if !ok {
+		return NewErrUnexpectedRune(as.String(), nil, at)
+	}
+
+	return NewErrUnexpectedRune(as.String(), &r, at)
+}
+
+// String implements the Scanner interface.
+func (as *alt_scanner) String() string {
+	values := make([]string, 0, len(as.alts))
+
+	for _, alt := range as.alts {
+		values = append(values, alt.String())
+	}
+
+	return "(" + strings.Join(values, " | ") + ")"
+}
+
+// Alt creates a scanner of one of several scanners.
+//
+// Parameters:
+//   - alts: The alternatives, by priority.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func Alt(alts ...Scanner) Scanner {
+	as := &alt_scanner{
+		alts: alts,
+	}
+
+	return as
+}
+
+// repeat_scanner is a scanner of a repetition of a scanner.
+type repeat_scanner struct {
+	// elem is the repeated scanner.
+	elem Scanner
+
+	// min is the minimum number of repetitions.
+	min int
+
+	// max is the maximum number of repetitions. -1 if there is none.
+	max int
+}
+
+// Starts implements the Scanner interface.
+func (rs *repeat_scanner) Starts(r rune) bool {
+	return rs.max != 0 && rs.elem.Starts(r)
+}
+
+// Nullable implements the Scanner interface.
+func (rs *repeat_scanner) Nullable() bool {
+	return rs.min == 0 || rs.elem.Nullable()
+}
+
+// Scan implements the Scanner interface.
+//
+// Behaviors:
+//   - Past the minimum, the repetition goes on while the next rune starts
+//     the repeated scanner. It stops if a repetition matches an empty text
+//     or fails without consuming anything.
+func (rs *repeat_scanner) Scan(s *stm.Stream, builder *strings.Builder) error {
+	for count := 0; rs.max < 0 || count < rs.max; count++ {
+		if count >= rs.min {
+			r, ok, err := peek(s)
+			if err != nil {
+				return err
+			}
+
+			if !ok || !rs.elem.Starts(r) {
+				return nil
+			}
+		}
+
+		at := s.Pos()
+
+		err := rs.elem.Scan(s, builder)
+		if err != nil && (s.Pos() != at || count < rs.min) {
+			return err
+		}
+
+		if s.Pos() == at && count >= rs.min {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// String implements the Scanner interface.
+//
+// Format:
+//
+//	[x] | {x} | x {x} | x{min,max}
+func (rs *repeat_scanner) String() string {
+	elem := rs.elem.String()
+
+	switch {
+	case rs.min == 0 && rs.max == 1:
+		return "[" + elem + "]"
+	case rs.min == 0 && rs.max < 0:
+		return "{" + elem + "}"
+	case rs.min == 1 && rs.max < 0:
+		return elem + " {" + elem + "}"
+	case rs.max < 0:
+		return elem + "{" + strconv.Itoa(rs.min) + ",}"
+	default:
+		return elem + "{" + strconv.Itoa(rs.min) + "," + strconv.Itoa(rs.max) + "}"
+	}
+}
+
+// Repeat creates a scanner of a repetition of a scanner.
+//
+// Parameters:
+//   - elem: The repeated scanner.
+//   - min: The minimum number of repetitions. Negative values are 0.
+//   - max: The maximum number of repetitions. Negative if there is none.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func Repeat(elem Scanner, min, max int) Scanner {
+	rs := &repeat_scanner{
+		elem: elem,
+		min:  min,
+		max:  max,
+	}
+
+	if rs.min < 0 {
+		rs.min = 0
+	}
+
+	if rs.max < 0 {
+		rs.max = -1
+	}
+
+	return rs
+}
+
+// Optional creates a scanner that matches a scanner or an empty text.
+//
+// Parameters:
+//   - elem: The scanner.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func Optional(elem Scanner) Scanner {
+	return Repeat(elem, 0, 1)
+}
+
+// Many creates a scanner of zero or more repetitions of a scanner.
+//
+// Parameters:
+//   - elem: The repeated scanner.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func Many(elem Scanner) Scanner {
+	return Repeat(elem, 0, -1)
+}
+
+// Many1 creates a scanner of one or more repetitions of a scanner.
+//
+// Parameters:
+//   - elem: The repeated scanner.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func Many1(elem Scanner) Scanner {
+	return Repeat(elem, 1, -1)
+}
+
+// try_scanner is a scanner that backtracks when another scanner fails.
+type try_scanner struct {
+	// elem is the scanner.
+	elem Scanner
+}
+
+// Starts implements the Scanner interface.
+func (ts *try_scanner) Starts(r rune) bool {
+	return ts.elem.Starts(r)
+}
+
+// Nullable implements the Scanner interface.
+func (ts *try_scanner) Nullable() bool {
+	return ts.elem.Nullable()
+}
+
+// Scan implements the Scanner interface.
+//
+// Behaviors:
+//   - On failure, the stream is moved back to where the scanner started,
+//     so that Alt and Repeat can try something else.
+func (ts *try_scanner) Scan(s *stm.Stream, builder *strings.Builder) error {
+	var sub strings.Builder
+
+	s.Mark()
+
+	err := ts.elem.Scan(s, &sub)
+	if err != nil {
+		_ = s.Reset()
+
+		return err
+	}
+
+	_ = s.Release()
+
+	builder.WriteString(sub.String())
+
+	return nil
+}
+
+// String implements the Scanner interface.
+func (ts *try_scanner) String() string {
+	return ts.elem.String()
+}
+
+// Try creates a scanner that backtracks when a scanner fails. For
+// instance, Seq(Many1(Digit), Optional(Try(Seq(Char('.'), Many1(Digit)))))
+// scans "3." as "3" and leaves the dot to the next token.
+//
+// Parameters:
+//   - elem: The scanner.
+//
+// Returns:
+//   - Scanner: The new scanner.
+func Try(elem Scanner) Scanner {
+	ts := &try_scanner{
+		elem: elem,
+	}
+
+	return ts
+}
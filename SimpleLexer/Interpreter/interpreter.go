@@ -0,0 +1,72 @@
+package Interpreter
+
+import (
+	"fmt"
+
+	ast "github.com/PlayerR9/LyneParser/SimpleLexer/AST"
+	lx "github.com/PlayerR9/LyneParser/SimpleLexer/Lexer"
+	ps "github.com/PlayerR9/LyneParser/SimpleLexer/Parser"
+)
+
+// Compile lexes, parses and checks a program.
+//
+// Parameters:
+//   - source: The source of the program.
+//
+// Returns:
+//   - *ast.Program: The program.
+//   - error: An error if the program is invalid.
+//
+// Errors:
+//   - error: If the source cannot be lexed or parsed.
+//   - *ast.ErrUnexpectedNode: If the tree of the parser cannot be converted.
+//   - *ast.ErrUndefinedRegister, *ast.ErrImmediateRange: The errors of
+//     ast.Check, joined.
+func Compile(source []byte) (*ast.Program, error) {
+	tokens, err := lx.Lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := ps.NewParser().Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	prog, err := ast.Build(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parse tree: %w", err)
+	}
+
+	err = ast.Check(prog)
+	if err != nil {
+		return nil, err
+	}
+
+	return prog, nil
+}
+
+// Run compiles a program and executes it on a new machine.
+//
+// Parameters:
+//   - source: The source of the program.
+//
+// Returns:
+//   - *Machine: The machine after the execution. Nil if the program is
+//     invalid.
+//   - error: An error if the program is invalid. See Compile.
+func Run(source []byte) (*Machine, error) {
+	prog, err := Compile(source)
+	if err != nil {
+		return nil, err
+	}
+
+	m := NewMachine()
+
+	err = m.Execute(prog)
+	if err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,75 @@
+package Interpreter
+
+import (
+	"errors"
+	"testing"
+
+	ast "github.com/PlayerR9/LyneParser/SimpleLexer/AST"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		source string
+		state  string
+	}{
+		{"(5) > $a", "$a=5"},
+		{"(5) > $a\n$a $a + > $b\n$b -- > $c", "$a=5 $b=10 $c=9"},
+		{"(200) > $a\n(100) > $b\n$a $b + > $c\n$b $a - > $d", "$a=200 $b=100 $c=44 $d=156"},
+		{"(12) > $a\n(10) > $b\n$a $b ! > $c\n$a $b ^ > $d\n$a $b | > $e\n$a $b & > $f\n$a >> > $g", "$a=12 $b=10 $c=4 $d=6 $e=14 $f=8 $g=6"},
+		{"(9) > $a\n(2) > $b\n(3) > $c\n$a $b $c + - > $d", "$a=9 $b=2 $c=3 $d=4"},
+		{"(0) > $z\n$z -- > $z\n$z ++ > $y", "$y=0 $z=255"},
+	}
+
+	for _, test := range tests {
+		m, err := Run([]byte(test.source))
+		if err != nil {
+			t.Errorf("%q: expected no error, got %s", test.source, err.Error())
+			continue
+		}
+
+		if m.String() != test.state {
+			t.Errorf("%q: expected %q, got %q", test.source, test.state, m.String())
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	var undefined *ast.ErrUndefinedRegister
+
+	_, err := Run([]byte("(1) > $a\n$a $b + > $c"))
+	if !errors.As(err, &undefined) {
+		t.Errorf("expected *ast.ErrUndefinedRegister, got %v", err)
+	} else if undefined.Reg.String() != "$b" {
+		t.Errorf("expected register $b, got %s", undefined.Reg.String())
+	}
+
+	_, err = Run([]byte("$a ++ > $a"))
+	if !errors.As(err, &undefined) {
+		t.Errorf("expected *ast.ErrUndefinedRegister, got %v", err)
+	}
+
+	var out_of_range *ast.ErrImmediateRange
+
+	_, err = Run([]byte("(256) > $a"))
+	if !errors.As(err, &out_of_range) {
+		t.Errorf("expected *ast.ErrImmediateRange, got %v", err)
+	}
+
+	_, err = Run([]byte("$a > $b"))
+	if err == nil {
+		t.Errorf("expected a parse error, got nil")
+	}
+}
+
+func TestCompile(t *testing.T) {
+	const source string = "(9) > $a\n$a $a $a + - > $b\n$b ++ > $c"
+
+	prog, err := Compile([]byte(source))
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if prog.String() != source {
+		t.Errorf("expected %q, got %q", source, prog.String())
+	}
+}
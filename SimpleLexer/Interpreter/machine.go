@@ -0,0 +1,177 @@
+package Interpreter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ast "github.com/PlayerR9/LyneParser/SimpleLexer/AST"
+)
+
+// Machine is the register machine that executes the programs. Registers are
+// 8 bits wide and the arithmetic wraps around.
+type Machine struct {
+	// regs are the values of the registers.
+	regs [26]uint8
+
+	// defined tells whether a register was written.
+	defined [26]bool
+}
+
+// NewMachine creates a machine with no register written.
+//
+// Returns:
+//   - *Machine: The new machine. Never nil.
+func NewMachine() *Machine {
+	m := &Machine{}
+	return m
+}
+
+// Get returns the value of a register.
+//
+// Parameters:
+//   - reg: The register.
+//
+// Returns:
+//   - uint8: The value.
+//   - bool: False if the register was never written.
+func (m *Machine) Get(reg ast.Register) (uint8, bool) {
+	if int(reg) >= len(m.regs) {
+		return 0, false
+	}
+
+	return m.regs[reg], m.defined[reg]
+}
+
+// Registers returns the written registers.
+//
+// Returns:
+//   - map[ast.Register]uint8: The value of each written register. Never nil.
+func (m *Machine) Registers() map[ast.Register]uint8 {
+	regs := make(map[ast.Register]uint8)
+
+	for i, ok := range m.defined {
+		if ok {
+			regs[ast.Register(i)] = m.regs[i]
+		}
+	}
+
+	return regs
+}
+
+// String implements the fmt.Stringer interface.
+//
+// Format: "$a=<value> $b=<value> ..." for the written registers only,
+// in alphabetical order.
+func (m *Machine) String() string {
+	var values []string
+
+	for i, ok := range m.defined {
+		if ok {
+			values = append(values, ast.Register(i).String()+"="+strconv.Itoa(int(m.regs[i])))
+		}
+	}
+
+	return strings.Join(values, " ")
+}
+
+// Execute runs the statements of a program in order.
+//
+// Parameters:
+//   - prog: The program.
+//
+// Returns:
+//   - error: An error if a statement cannot be executed.
+//
+// Errors:
+//   - *ast.ErrUndefinedRegister: If a register is read before it is written.
+//   - *ast.ErrImmediateRange: If an immediate does not fit in a register.
+//   - error: If an operator is unknown.
+//
+// Behaviors:
+//   - Statements executed before the error keep their effect.
+func (m *Machine) Execute(prog *ast.Program) error {
+	if prog == nil {
+		return nil
+	}
+
+	for i, stmt := range prog.Statements {
+		value, err := m.eval(stmt.Value)
+		if err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+
+		m.regs[stmt.Dest] = value
+		m.defined[stmt.Dest] = true
+	}
+
+	return nil
+}
+
+// eval evaluates an expression. The meaning of each operator is documented
+// on ast.Unary and ast.Binary.
+//
+// Parameters:
+//   - expr: The expression.
+//
+// Returns:
+//   - uint8: The value.
+//   - error: An error if the expression cannot be evaluated.
+func (m *Machine) eval(expr ast.Expr) (uint8, error) {
+	switch expr := expr.(type) {
+	case *ast.Load:
+		return expr.Value()
+	case *ast.Read:
+		value, ok := m.Get(expr.Reg)
+		if !ok {
+			return 0, ast.NewErrUndefinedRegister(expr.Reg, expr.At)
+		}
+
+		return value, nil
+	case *ast.Unary:
+		x, err := m.eval(expr.X)
+		if err != nil {
+			return 0, err
+		}
+
+		switch expr.Op {
+		case "++":
+			return x + 1, nil
+		case "--":
+			return x - 1, nil
+		case ">>":
+			return x >> 1, nil
+		default:
+			return 0, fmt.Errorf("unknown unary operator %q", expr.Op)
+		}
+	case *ast.Binary:
+		x, err := m.eval(expr.X)
+		if err != nil {
+			return 0, err
+		}
+
+		y, err := m.eval(expr.Y)
+		if err != nil {
+			return 0, err
+		}
+
+		switch expr.Op {
+		case "+":
+			return x + y, nil
+		case "-":
+			return x - y, nil
+		case "|":
+			return x | y, nil
+		case "&":
+			return x & y, nil
+		case "^":
+			return x ^ y, nil
+		case "!":
+			return x &^ y, nil
+		default:
+			return 0, fmt.Errorf("unknown binary operator %q", expr.Op)
+		}
+	default:
+		return 0, fmt.Errorf("unknown expression %T", expr)
+	}
+}
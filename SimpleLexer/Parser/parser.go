@@ -34,6 +34,14 @@ func (p *Parser) getDecision(top *gr.Token[com.TestTkType]) (Actioner, error) {
 		com.TkOpParen:
 		act = NewShiftAct()
 	case com.TkSource1:
+		below, ok := p.below()
+		if ok && below.GetID() == com.TkNewline {
+			// [Source1] newline Statement -> Source1 : Reduce
+			act = NewReduceAct(false)
+
+			return act, nil
+		}
+
 		la := top.GetLookahead()
 
 		if la == nil {
@@ -98,6 +106,22 @@ func (p *Parser) getDecision(top *gr.Token[com.TestTkType]) (Actioner, error) {
 	return act, nil
 }
 
+// below returns the token under the top of the stack.
+//
+// Returns:
+//   - *gr.Token: The token.
+//   - bool: False if the stack has less than two tokens.
+func (p *Parser) below() (*gr.Token[com.TestTkType], bool) {
+	top, ok := p.stack.Pop()
+	if !ok {
+		return nil, false
+	}
+
+	defer p.stack.Push(top)
+
+	return p.stack.Peek()
+}
+
 func (p *Parser) shift() {
 	uc.Assert(len(p.input) > 0, "input is empty")
 
@@ -127,7 +151,7 @@ func (p *Parser) reduce() error {
 			return fmt.Errorf("expected Source1, got %s instead", id2)
 		}
 
-		la := top2.GetLookahead()
+		la := top1.GetLookahead()
 
 		tok := gr.NewToken(com.TkSource, []*gr.Token[com.TestTkType]{top2, top1}, 0, la)
 
@@ -156,7 +180,7 @@ func (p *Parser) reduce() error {
 			return fmt.Errorf("expected Statement, got %s instead", id3)
 		}
 
-		la := top3.GetLookahead()
+		la := top1.GetLookahead()
 
 		tok := gr.NewToken(com.TkSource1, []*gr.Token[com.TestTkType]{top3, top1}, 0, la)
 
@@ -207,7 +231,7 @@ func (p *Parser) reduce() error {
 		case com.TkUnaryInstruction:
 			// [register] right_arrow UnaryInstruction -> Statement : Reduce
 
-			la := top3.GetLookahead()
+			la := top1.GetLookahead()
 
 			tok := gr.NewToken(com.TkStatement, []*gr.Token[com.TestTkType]{top3, top1}, 0, la)
 
@@ -215,7 +239,7 @@ func (p *Parser) reduce() error {
 		case com.TkBinInstruction:
 			// [register] right_arrow BinaryInstruction -> Statement : Reduce
 
-			la := top3.GetLookahead()
+			la := top1.GetLookahead()
 
 			tok := gr.NewToken(com.TkStatement, []*gr.Token[com.TestTkType]{top3, top1}, 0, la)
 
@@ -223,7 +247,7 @@ func (p *Parser) reduce() error {
 		case com.TkLoadImmediate:
 			// [register] right_arrow LoadImmediate -> Statement : Reduce
 
-			la := top3.GetLookahead()
+			la := top1.GetLookahead()
 
 			tok := gr.NewToken(com.TkStatement, []*gr.Token[com.TestTkType]{top3, top1}, 0, la)
 
@@ -239,7 +263,7 @@ func (p *Parser) reduce() error {
 		tok := gr.NewToken(com.TkOperand, []*gr.Token[com.TestTkType]{top1}, 0, la)
 
 		p.stack.Push(tok)
-	case com.TkUnaryInstruction:
+	case com.TkUnaryOp:
 		// [unary_operator] Operand -> UnaryInstruction : Reduce
 
 		top2, ok := p.stack.Pop()
@@ -253,7 +277,7 @@ func (p *Parser) reduce() error {
 			return fmt.Errorf("expected Operand, got %s instead", id2)
 		}
 
-		la := top2.GetLookahead()
+		la := top1.GetLookahead()
 
 		tok := gr.NewToken(com.TkUnaryInstruction, []*gr.Token[com.TestTkType]{top2, top1}, 0, la)
 
@@ -283,7 +307,7 @@ func (p *Parser) reduce() error {
 			return fmt.Errorf("expected Operand, got %s instead", id3)
 		}
 
-		la := top3.GetLookahead()
+		la := top1.GetLookahead()
 
 		tok := gr.NewToken(com.TkBinInstruction, []*gr.Token[com.TestTkType]{top3, top2, top1}, 0, la)
 
@@ -313,7 +337,7 @@ func (p *Parser) reduce() error {
 			return fmt.Errorf("expected op_paren, got %s instead", id3)
 		}
 
-		la := top3.GetLookahead()
+		la := top1.GetLookahead()
 
 		tok := gr.NewToken(com.TkLoadImmediate, []*gr.Token[com.TestTkType]{top2}, 0, la)
 
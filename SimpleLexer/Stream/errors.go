@@ -39,3 +39,23 @@ func IsStreamExhausted(err error) bool {
 	ok := errors.As(err, &streamExhaustedErr)
 	return ok
 }
+
+// ErrNoMark is the error returned when a stream is reset or released
+// without a checkpoint.
+type ErrNoMark struct{}
+
+// Error implements the error interface.
+//
+// Message: "stream has no checkpoint"
+func (e *ErrNoMark) Error() string {
+	return "stream has no checkpoint"
+}
+
+// NewErrNoMark creates a new no mark error.
+//
+// Returns:
+//   - *ErrNoMark: The no mark error.
+func NewErrNoMark() *ErrNoMark {
+	e := &ErrNoMark{}
+	return e
+}
@@ -1,34 +1,71 @@
 package Stream
 
 import (
+	"bytes"
+	"io"
+	"strconv"
 	"unicode/utf8"
 )
 
-// lookahead is a lookahead rune.
-type lookahead struct {
-	// char is the rune.
-	char rune
+const (
+	// DefaultChunkSize is the number of bytes a stream reads at once from
+	// its reader when no chunk size is given.
+	DefaultChunkSize int = 4096
 
-	// size is the size of the rune.
-	size int
+	// max_empty_reads is the number of consecutive reads without data
+	// after which a reader is considered broken.
+	max_empty_reads int = 100
+)
+
+// Position is a position in a stream.
+type Position struct {
+	// Offset is the position in bytes, starting at 0.
+	Offset int
+
+	// Line is the line, starting at 1.
+	Line int
+
+	// Column is the column in runes, starting at 1.
+	Column int
+}
+
+// String implements the fmt.Stringer interface.
+//
+// Format:
+//
+//	<line>:<column>
+func (p Position) String() string {
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
 }
 
-// Stream is a stream of runes.
+// Stream is a cursor over a stream of runes. The stream is either in
+// memory or read from an io.Reader in chunks; it can look any number of
+// runes ahead and be rewound to nested checkpoints.
 type Stream struct {
-	// data is the data of the stream.
-	data []byte
+	// reader is the reader of the data. Nil if the data is in memory.
+	reader io.Reader
+
+	// chunk is the number of bytes read at once from the reader.
+	chunk int
+
+	// buf are the bytes read and not released yet.
+	buf []byte
+
+	// base is the offset of the first byte of buf.
+	base int
 
 	// pos is the current position of the stream.
-	pos int
+	pos Position
 
-	// lastPos is the last position of the stream.
-	lastPos int
+	// marks are the checkpoints, from the oldest to the newest.
+	marks []Position
 
-	// lookahead is the next rune.
-	lookahead *lookahead
+	// err is the error that ended the reader. io.EOF if there is no more
+	// data.
+	err error
 }
 
-// NewStream creates a new stream.
+// NewStream creates a new stream over some data in memory.
 //
 // Parameters:
 //   - data: The data of the stream.
@@ -37,39 +74,225 @@ type Stream struct {
 //   - Stream: The stream.
 func NewStream(data []byte) Stream {
 	s := Stream{
-		data:      data,
-		pos:       0,
-		lastPos:   0,
-		lookahead: nil,
+		buf: data,
+		pos: Position{Line: 1, Column: 1},
+		err: io.EOF,
 	}
 	return s
 }
 
+// NewReaderStream creates a new stream that reads its data from a reader,
+// one chunk at a time.
+//
+// Parameters:
+//   - r: The reader.
+//   - chunk: The number of bytes read at once. DefaultChunkSize if it is
+//     not positive.
+//
+// Returns:
+//   - Stream: The stream.
+//
+// Behaviors:
+//   - Only the bytes from the oldest checkpoint, or from the current
+//     position if there is none, are kept in memory.
+func NewReaderStream(r io.Reader, chunk int) Stream {
+	if chunk <= 0 {
+		chunk = DefaultChunkSize
+	}
+
+	s := Stream{
+		reader: r,
+		chunk:  chunk,
+		pos:    Position{Line: 1, Column: 1},
+	}
+
+	if r == nil {
+		s.err = io.EOF
+	}
+
+	return s
+}
+
+// fill is a helper method that reads the next chunk of the reader.
+//
+// Returns:
+//   - error: The error of the reader if no byte could be read. io.EOF if
+//     there is no more data.
+func (s *Stream) fill() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	keep := s.pos.Offset
+	if len(s.marks) > 0 {
+		keep = s.marks[0].Offset
+	}
+
+	if drop := keep - s.base; drop > 0 {
+		n := copy(s.buf, s.buf[drop:])
+		s.buf = s.buf[:n]
+		s.base = keep
+	}
+
+	if cap(s.buf)-len(s.buf) < s.chunk {
+		buf := make([]byte, len(s.buf), 2*cap(s.buf)+s.chunk)
+		copy(buf, s.buf)
+		s.buf = buf
+	}
+
+	for range max_empty_reads {
+		n, err := s.reader.Read(s.buf[len(s.buf) : len(s.buf)+s.chunk])
+		s.buf = s.buf[:len(s.buf)+n]
+
+		if err != nil {
+			s.err = err
+		}
+
+		if n > 0 {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	s.err = io.ErrNoProgress
+
+	return s.err
+}
+
+// ensure is a helper method that reads data until some bytes are buffered
+// from an offset, or the reader has no more data.
+//
+// Parameters:
+//   - offset: The offset. Never before the first byte kept in memory.
+//   - n: The number of bytes.
+//
+// Returns:
+//   - []byte: The buffered bytes from the offset. Fewer than n only at the
+//     end of the data.
+//   - error: The error of the reader, other than io.EOF, if fewer than n
+//     bytes are buffered.
+func (s *Stream) ensure(offset, n int) ([]byte, error) {
+	for s.base+len(s.buf) < offset+n {
+		err := s.fill()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return s.buf[offset-s.base:], err
+		}
+	}
+
+	return s.buf[offset-s.base:], nil
+}
+
+// decode_at is a helper method that decodes the rune at an offset.
+//
+// Parameters:
+//   - offset: The offset.
+//
+// Returns:
+//   - rune: The rune.
+//   - int: The size of the rune, in bytes.
+//   - error: An error if there is no rune at the offset.
+//
+// Errors:
+//   - *ErrStreamExhausted: If the offset is at the end of the data.
+//   - any error of the reader.
+func (s *Stream) decode_at(offset int) (rune, int, error) {
+	data, err := s.ensure(offset, utf8.UTFMax)
+	if len(data) == 0 {
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return 0, 0, NewStreamExhausted()
+	}
+
+	r, size := utf8.DecodeRune(data)
+
+	return r, size, nil
+}
+
 // Peek peeks the next rune without consuming it.
 //
 // Returns:
 //   - rune: The next rune.
 //   - error: The error if any.
+//
+// Errors:
+//   - *ErrStreamExhausted: If there is no next rune.
+//   - any error of the reader.
 func (s *Stream) Peek() (rune, error) {
-	if s.lookahead != nil {
-		return s.lookahead.char, nil
-	}
+	r, _, err := s.decode_at(s.pos.Offset)
+	return r, err
+}
 
-	if len(s.data) == 0 {
-		return 0, NewStreamExhausted()
+// PeekAt peeks a rune ahead of the current position without consuming
+// anything.
+//
+// Parameters:
+//   - n: The number of runes to look past. 0 is the next rune. Negative
+//     values are 0.
+//
+// Returns:
+//   - rune: The rune.
+//   - error: The error if any.
+//
+// Errors:
+//   - *ErrStreamExhausted: If the stream ends before the rune.
+//   - any error of the reader.
+func (s *Stream) PeekAt(n int) (rune, error) {
+	offset := s.pos.Offset
+
+	for ; n > 0; n-- {
+		_, size, err := s.decode_at(offset)
+		if err != nil {
+			return 0, err
+		}
+
+		offset += size
 	}
 
-	r, size := utf8.DecodeRune(s.data)
-	s.data = s.data[size:]
+	r, _, err := s.decode_at(offset)
+	return r, err
+}
 
-	la := &lookahead{
-		char: r,
-		size: size,
+// HasPrefix checks whether the next bytes of the stream are a given text,
+// without consuming them.
+//
+// Parameters:
+//   - prefix: The text.
+//
+// Returns:
+//   - bool: True if the stream continues with the text, false otherwise.
+//   - error: An error of the reader, if any.
+func (s *Stream) HasPrefix(prefix string) (bool, error) {
+	data, err := s.ensure(s.pos.Offset, len(prefix))
+	if err != nil {
+		return false, err
 	}
 
-	s.lookahead = la
+	return bytes.HasPrefix(data, []byte(prefix)), nil
+}
 
-	return r, nil
+// advance is a helper method that moves the position past a rune.
+//
+// Parameters:
+//   - r: The rune.
+//   - size: The size of the rune, in bytes.
+func (s *Stream) advance(r rune, size int) {
+	s.pos.Offset += size
+
+	if r == '\n' {
+		s.pos.Line++
+		s.pos.Column = 1
+	} else {
+		s.pos.Column++
+	}
 }
 
 // Next returns the next rune and consumes it.
@@ -77,23 +300,17 @@ func (s *Stream) Peek() (rune, error) {
 // Returns:
 //   - rune: The next rune.
 //   - error: The error if any.
+//
+// Errors:
+//   - *ErrStreamExhausted: If there is no next rune.
+//   - any error of the reader.
 func (s *Stream) Next() (rune, error) {
-	if s.lookahead != nil {
-		r := s.lookahead.char
-		s.lastPos = s.pos
-
-		s.lookahead = nil
-
-		return r, nil
-	}
-
-	if len(s.data) == 0 {
-		return 0, NewStreamExhausted()
+	r, size, err := s.decode_at(s.pos.Offset)
+	if err != nil {
+		return 0, err
 	}
 
-	r, size := utf8.DecodeRune(s.data)
-	s.data = s.data[size:]
-	s.pos += size
+	s.advance(r, size)
 
 	return r, nil
 }
@@ -101,20 +318,73 @@ func (s *Stream) Next() (rune, error) {
 // Pos returns the current position of the stream.
 //
 // Returns:
-//   - int: The current position.
+//   - int: The current position, in bytes.
 func (s *Stream) Pos() int {
-	return s.lastPos
+	return s.pos.Offset
 }
 
-// Accept accepts the lookahead rune.
+// Position returns the current position of the stream, with its line and
+// column.
 //
-// Does nothing if there is no lookahead rune.
+// Returns:
+//   - Position: The current position.
+func (s *Stream) Position() Position {
+	return s.pos
+}
+
+// Accept consumes the next rune, usually the one returned by Peek.
+//
+// Does nothing if there is no next rune.
 func (s *Stream) Accept() {
-	if s.lookahead == nil {
-		return
+	_, _ = s.Next()
+}
+
+// Mark saves the current position as a checkpoint. The checkpoints are
+// nested: Reset and Release act on the newest one.
+func (s *Stream) Mark() {
+	s.marks = append(s.marks, s.pos)
+}
+
+// Reset moves the stream back to the newest checkpoint and removes it.
+//
+// Returns:
+//   - error: An error of type *ErrNoMark if there is no checkpoint.
+func (s *Stream) Reset() error {
+	if len(s.marks) == 0 {
+		return NewErrNoMark()
+	}
+
+	s.pos = s.marks[len(s.marks)-1]
+	s.marks = s.marks[:len(s.marks)-1]
+
+	return nil
+}
+
+// Release removes the newest checkpoint without moving the stream.
+//
+// Returns:
+//   - error: An error of type *ErrNoMark if there is no checkpoint.
+func (s *Stream) Release() error {
+	if len(s.marks) == 0 {
+		return NewErrNoMark()
+	}
+
+	s.marks = s.marks[:len(s.marks)-1]
+
+	return nil
+}
+
+// Since returns the text consumed since the newest checkpoint.
+//
+// Returns:
+//   - string: The text.
+//   - error: An error of type *ErrNoMark if there is no checkpoint.
+func (s *Stream) Since() (string, error) {
+	if len(s.marks) == 0 {
+		return "", NewErrNoMark()
 	}
 
-	s.lastPos += s.lookahead.size
+	from := s.marks[len(s.marks)-1].Offset
 
-	s.lookahead = nil
+	return string(s.buf[from-s.base : s.pos.Offset-s.base]), nil
 }
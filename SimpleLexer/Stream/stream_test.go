@@ -0,0 +1,124 @@
+package Stream
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStream(t *testing.T) {
+	s := NewStream([]byte("aé\nb"))
+
+	r, err := s.PeekAt(2)
+	if err != nil || r != '\n' {
+		t.Fatalf("expected '\\n', got %q (%v)", r, err)
+	}
+
+	_, err = s.PeekAt(4)
+	if !IsStreamExhausted(err) {
+		t.Fatalf("expected the stream to be exhausted, got %v", err)
+	}
+
+	var runes []rune
+
+	for {
+		r, err := s.Next()
+		if IsStreamExhausted(err) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("Next failed: %s", err.Error())
+		}
+
+		runes = append(runes, r)
+	}
+
+	if string(runes) != "aé\nb" {
+		t.Errorf("expected %q, got %q", "aé\nb", string(runes))
+	}
+
+	want := Position{Offset: 5, Line: 2, Column: 2}
+	if s.Position() != want {
+		t.Errorf("expected %v, got %v", want, s.Position())
+	}
+}
+
+func TestPeekAccept(t *testing.T) {
+	s := NewStream([]byte("ab"))
+
+	r, _ := s.Peek()
+	s.Accept()
+
+	if r != 'a' || s.Pos() != 1 {
+		t.Fatalf("expected 'a' then 1, got %q then %d", r, s.Pos())
+	}
+
+	r, _ = s.Next()
+	if r != 'b' || s.Pos() != 2 {
+		t.Fatalf("expected 'b' then 2, got %q then %d", r, s.Pos())
+	}
+}
+
+func TestMarkReset(t *testing.T) {
+	s := NewReaderStream(iotest.OneByteReader(strings.NewReader("ab\ncd")), 1)
+
+	s.Mark()
+	_, _ = s.Next()
+
+	s.Mark()
+	_, _ = s.Next()
+	_, _ = s.Next()
+	_, _ = s.Next()
+
+	text, err := s.Since()
+	if err != nil || text != "b\nc" {
+		t.Fatalf("expected %q, got %q (%v)", "b\nc", text, err)
+	}
+
+	if s.Position().String() != "2:2" {
+		t.Errorf("expected 2:2, got %s", s.Position())
+	}
+
+	err = s.Reset()
+	if err != nil || s.Pos() != 1 {
+		t.Fatalf("expected 1, got %d (%v)", s.Pos(), err)
+	}
+
+	err = s.Reset()
+	if err != nil || s.Position() != (Position{Offset: 0, Line: 1, Column: 1}) {
+		t.Fatalf("expected the start, got %v (%v)", s.Position(), err)
+	}
+
+	var target *ErrNoMark
+
+	err = s.Release()
+	if !errors.As(err, &target) {
+		t.Errorf("expected *ErrNoMark, got %v", err)
+	}
+
+	ok, err := s.HasPrefix("ab\ncd")
+	if err != nil || !ok {
+		t.Errorf("expected the whole text, got %t (%v)", ok, err)
+	}
+
+	ok, _ = s.HasPrefix("ab\ncde")
+	if ok {
+		t.Errorf("expected a too long prefix not to match")
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	s := NewReaderStream(iotest.TimeoutReader(strings.NewReader("a")), 0)
+
+	r, err := s.Next()
+	if err != nil || r != 'a' {
+		t.Fatalf("expected 'a', got %q (%v)", r, err)
+	}
+
+	_, err = s.Peek()
+	if !errors.Is(err, iotest.ErrTimeout) {
+		t.Errorf("expected %v, got %v", iotest.ErrTimeout, err)
+	}
+}
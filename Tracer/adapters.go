@@ -0,0 +1,215 @@
+package Tracer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SlogHook is a hook that logs every event with a slog.Logger.
+type SlogHook struct {
+	// logger is the logger to use.
+	logger *slog.Logger
+
+	// level is the level of the records.
+	level slog.Level
+}
+
+// Fire implements the Hooker interface.
+//
+// The message of the record is the kind of the event and its attributes are
+// the fields of the event.
+func (sh *SlogHook) Fire(event Eventer) {
+	sh.logger.LogAttrs(context.Background(), sh.level, event.Kind().String(), event.Attrs()...)
+}
+
+// NewSlogHook creates a hook that logs every event with the given logger.
+//
+// Parameters:
+//   - logger: The logger to use. If nil, slog.Default() is used.
+//   - level: The level of the records.
+//
+// Returns:
+//   - *SlogHook: The new hook. Never nil.
+func NewSlogHook(logger *slog.Logger, level slog.Level) *SlogHook {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	sh := &SlogHook{
+		logger: logger,
+		level:  level,
+	}
+
+	return sh
+}
+
+// Recorder is a hook that keeps every event in memory. It is intended to be
+// used in tests.
+type Recorder struct {
+	// events are the recorded events.
+	events []Eventer
+
+	// mu protects events.
+	mu sync.Mutex
+}
+
+// Fire implements the Hooker interface.
+func (r *Recorder) Fire(event Eventer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+// NewRecorder creates a new, empty recorder.
+//
+// Returns:
+//   - *Recorder: The new recorder. Never nil.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Events returns a copy of the recorded events, in the order they were fired.
+//
+// Returns:
+//   - []Eventer: The recorded events.
+func (r *Recorder) Events() []Eventer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]Eventer, len(r.events))
+	copy(events, r.events)
+
+	return events
+}
+
+// EventsOf returns the recorded events of the given kind, in the order they
+// were fired.
+//
+// Parameters:
+//   - kind: The kind of the events.
+//
+// Returns:
+//   - []Eventer: The recorded events of the given kind.
+func (r *Recorder) EventsOf(kind EventKind) []Eventer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []Eventer
+
+	for _, event := range r.events {
+		if event.Kind() == kind {
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+// Count returns the number of recorded events of the given kind.
+//
+// Parameters:
+//   - kind: The kind of the events.
+//
+// Returns:
+//   - int: The number of recorded events of the given kind.
+func (r *Recorder) Count(kind EventKind) int {
+	return len(r.EventsOf(kind))
+}
+
+// Reset removes all the recorded events.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = nil
+}
+
+// WriterHook is a hook that writes one line of text per event to an
+// io.Writer.
+type WriterHook struct {
+	// w is the writer to write to.
+	w io.Writer
+
+	// mu serializes the writes.
+	mu sync.Mutex
+}
+
+// Fire implements the Hooker interface.
+//
+// Each event is written as its kind followed by its fields in the key=value
+// form. For example:
+//
+//	shift: type=word at=6
+func (wh *WriterHook) Fire(event Eventer) {
+	line := FormatEvent(event)
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	fmt.Fprintln(wh.w, line)
+}
+
+// NewWriterHook creates a hook that writes the events to the given writer.
+//
+// Parameters:
+//   - w: The writer to write to. If nil, the events are discarded.
+//
+// Returns:
+//   - *WriterHook: The new hook. Never nil.
+func NewWriterHook(w io.Writer) *WriterHook {
+	if w == nil {
+		w = io.Discard
+	}
+
+	wh := &WriterHook{
+		w: w,
+	}
+
+	return wh
+}
+
+// FormatEvent returns the one-line text representation of an event, as
+// written by WriterHook.
+//
+// Parameters:
+//   - event: The event to format.
+//
+// Returns:
+//   - string: The text representation of the event.
+func FormatEvent(event Eventer) string {
+	var builder strings.Builder
+
+	builder.WriteString(event.Kind().String())
+	builder.WriteRune(':')
+
+	for _, attr := range event.Attrs() {
+		builder.WriteRune(' ')
+		builder.WriteString(attr.Key)
+		builder.WriteRune('=')
+		builder.WriteString(quote_if_needed(attr.Value.String()))
+	}
+
+	return builder.String()
+}
+
+// quote_if_needed is a helper function that quotes a value if it is empty or
+// contains spaces, quotes or equal signs.
+//
+// Parameters:
+//   - value: The value to quote.
+//
+// Returns:
+//   - string: The value, quoted if needed.
+func quote_if_needed(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
@@ -0,0 +1,246 @@
+package Tracer
+
+import (
+	"log/slog"
+)
+
+// EventKind is the kind of an event.
+type EventKind int
+
+const (
+	// KindTokenMatched is the kind of the event fired when the lexer matches
+	// a token.
+	KindTokenMatched EventKind = iota
+
+	// KindBranchForked is the kind of the event fired when the lexer or the
+	// parser has more than one way to continue.
+	KindBranchForked
+
+	// KindBranchKilled is the kind of the event fired when a branch of the
+	// lexer or of the parser cannot continue.
+	KindBranchKilled
+
+	// KindShift is the kind of the event fired when the parser shifts a token.
+	KindShift
+
+	// KindReduce is the kind of the event fired when the parser reduces a rule.
+	KindReduce
+
+	// KindAccept is the kind of the event fired when the parser accepts the
+	// input.
+	KindAccept
+
+	// KindConflictResolved is the kind of the event fired when the conflict
+	// solver resolves a conflict between rules.
+	KindConflictResolved
+)
+
+// String implements the fmt.Stringer interface.
+func (k EventKind) String() string {
+	return [...]string{
+		"token matched",
+		"branch forked",
+		"branch killed",
+		"shift",
+		"reduce",
+		"accept",
+		"conflict resolved",
+	}[k]
+}
+
+// Eventer is the interface that all events implement.
+type Eventer interface {
+	// Kind returns the kind of the event.
+	//
+	// Returns:
+	//   - EventKind: The kind of the event.
+	Kind() EventKind
+
+	// Attrs returns the fields of the event as key-value pairs.
+	//
+	// Returns:
+	//   - []slog.Attr: The fields of the event. The order is always the same.
+	Attrs() []slog.Attr
+}
+
+// Origin is the component that fired an event.
+type Origin string
+
+const (
+	// FromLexer means that the event was fired by the lexer.
+	FromLexer Origin = "lexer"
+
+	// FromParser means that the event was fired by the parser.
+	FromParser Origin = "parser"
+)
+
+// TokenMatchedEvent is fired when the lexer matches a token.
+type TokenMatchedEvent struct {
+	// Type is the type of the token.
+	Type string
+
+	// Data is the matched text.
+	Data string
+
+	// At is the position of the token in the source.
+	At int
+}
+
+// Kind implements the Eventer interface.
+func (e *TokenMatchedEvent) Kind() EventKind {
+	return KindTokenMatched
+}
+
+// Attrs implements the Eventer interface.
+func (e *TokenMatchedEvent) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("type", e.Type),
+		slog.String("data", e.Data),
+		slog.Int("at", e.At),
+	}
+}
+
+// BranchForkedEvent is fired when the lexer or the parser has more than one
+// way to continue.
+type BranchForkedEvent struct {
+	// Origin is the component that forked.
+	Origin Origin
+
+	// At is the position in the input where the fork happened.
+	At int
+
+	// Count is the number of branches that were created.
+	Count int
+}
+
+// Kind implements the Eventer interface.
+func (e *BranchForkedEvent) Kind() EventKind {
+	return KindBranchForked
+}
+
+// Attrs implements the Eventer interface.
+func (e *BranchForkedEvent) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("origin", string(e.Origin)),
+		slog.Int("at", e.At),
+		slog.Int("count", e.Count),
+	}
+}
+
+// BranchKilledEvent is fired when a branch of the lexer or of the parser
+// cannot continue.
+type BranchKilledEvent struct {
+	// Origin is the component that killed the branch.
+	Origin Origin
+
+	// At is the position in the input where the branch stopped.
+	At int
+
+	// Reason is the reason the branch was killed.
+	Reason error
+}
+
+// Kind implements the Eventer interface.
+func (e *BranchKilledEvent) Kind() EventKind {
+	return KindBranchKilled
+}
+
+// Attrs implements the Eventer interface.
+func (e *BranchKilledEvent) Attrs() []slog.Attr {
+	var reason string
+
+	if e.Reason != nil {
+		reason = e.Reason.Error()
+	}
+
+	return []slog.Attr{
+		slog.String("origin", string(e.Origin)),
+		slog.Int("at", e.At),
+		slog.String("reason", reason),
+	}
+}
+
+// ShiftEvent is fired when the parser shifts a token.
+type ShiftEvent struct {
+	// Type is the type of the shifted token.
+	Type string
+
+	// At is the position of the shifted token.
+	At int
+}
+
+// Kind implements the Eventer interface.
+func (e *ShiftEvent) Kind() EventKind {
+	return KindShift
+}
+
+// Attrs implements the Eventer interface.
+func (e *ShiftEvent) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("type", e.Type),
+		slog.Int("at", e.At),
+	}
+}
+
+// ReduceEvent is fired when the parser reduces a rule.
+type ReduceEvent struct {
+	// Rule is the rule that was reduced.
+	Rule string
+
+	// At is the position of the first token of the reduced rule.
+	At int
+}
+
+// Kind implements the Eventer interface.
+func (e *ReduceEvent) Kind() EventKind {
+	return KindReduce
+}
+
+// Attrs implements the Eventer interface.
+func (e *ReduceEvent) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("rule", e.Rule),
+		slog.Int("at", e.At),
+	}
+}
+
+// AcceptEvent is fired when the parser accepts the input.
+type AcceptEvent struct {
+	// Rule is the rule that was reduced last.
+	Rule string
+}
+
+// Kind implements the Eventer interface.
+func (e *AcceptEvent) Kind() EventKind {
+	return KindAccept
+}
+
+// Attrs implements the Eventer interface.
+func (e *AcceptEvent) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("rule", e.Rule),
+	}
+}
+
+// ConflictResolvedEvent is fired when the conflict solver resolves a conflict
+// between rules.
+type ConflictResolvedEvent struct {
+	// Rules are the rules that were in conflict.
+	Rules []string
+
+	// Resolution describes how the conflict was resolved.
+	Resolution string
+}
+
+// Kind implements the Eventer interface.
+func (e *ConflictResolvedEvent) Kind() EventKind {
+	return KindConflictResolved
+}
+
+// Attrs implements the Eventer interface.
+func (e *ConflictResolvedEvent) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.Any("rules", e.Rules),
+		slog.String("resolution", e.Resolution),
+	}
+}
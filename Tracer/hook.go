@@ -0,0 +1,123 @@
+package Tracer
+
+import (
+	"slices"
+)
+
+// Hooker is the interface that receives the events fired by the lexer, the
+// parser and the conflict solver.
+//
+// Fire may be called concurrently; implementations must be safe for
+// concurrent use.
+type Hooker interface {
+	// Fire is called for every event.
+	//
+	// Parameters:
+	//   - event: The event. Never nil.
+	Fire(event Eventer)
+}
+
+// HookFunc is a function that implements the Hooker interface.
+type HookFunc func(event Eventer)
+
+// Fire implements the Hooker interface.
+func (f HookFunc) Fire(event Eventer) {
+	f(event)
+}
+
+// Fire is a convenience function that fires an event on a hook.
+//
+// Parameters:
+//   - hook: The hook to fire the event on.
+//   - event: The event to fire.
+//
+// Behaviors:
+//   - If the hook or the event is nil, nothing happens.
+func Fire(hook Hooker, event Eventer) {
+	if hook == nil || event == nil {
+		return
+	}
+
+	hook.Fire(event)
+}
+
+// multi_hook is a hook that forwards the events to several hooks.
+type multi_hook struct {
+	// hooks are the hooks to forward the events to.
+	hooks []Hooker
+}
+
+// Fire implements the Hooker interface.
+func (mh *multi_hook) Fire(event Eventer) {
+	for _, hook := range mh.hooks {
+		hook.Fire(event)
+	}
+}
+
+// Multi creates a hook that forwards every event to all the given hooks, in
+// order.
+//
+// Parameters:
+//   - hooks: The hooks to forward the events to. Nil hooks are ignored.
+//
+// Returns:
+//   - Hooker: The new hook. Nil if no non-nil hooks were given.
+func Multi(hooks ...Hooker) Hooker {
+	var valid []Hooker
+
+	for _, hook := range hooks {
+		if hook != nil {
+			valid = append(valid, hook)
+		}
+	}
+
+	switch len(valid) {
+	case 0:
+		return nil
+	case 1:
+		return valid[0]
+	}
+
+	mh := &multi_hook{
+		hooks: valid,
+	}
+
+	return mh
+}
+
+// filter_hook is a hook that only forwards some kinds of events.
+type filter_hook struct {
+	// hook is the hook to forward the events to.
+	hook Hooker
+
+	// kinds are the kinds of events to forward.
+	kinds []EventKind
+}
+
+// Fire implements the Hooker interface.
+func (fh *filter_hook) Fire(event Eventer) {
+	if slices.Contains(fh.kinds, event.Kind()) {
+		fh.hook.Fire(event)
+	}
+}
+
+// Filter creates a hook that only forwards the events of the given kinds.
+//
+// Parameters:
+//   - hook: The hook to forward the events to.
+//   - kinds: The kinds of events to forward.
+//
+// Returns:
+//   - Hooker: The new hook. Nil if the hook is nil.
+func Filter(hook Hooker, kinds ...EventKind) Hooker {
+	if hook == nil {
+		return nil
+	}
+
+	fh := &filter_hook{
+		hook:  hook,
+		kinds: kinds,
+	}
+
+	return fh
+}
@@ -0,0 +1,66 @@
+package Tracer
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWriterHook(t *testing.T) {
+	const (
+		Expected string = "shift: type=word at=6\n" +
+			"branch killed: origin=parser at=8 reason=\"no accept\"\n"
+	)
+
+	var buff bytes.Buffer
+
+	hook := NewWriterHook(&buff)
+
+	Fire(hook, &ShiftEvent{Type: "word", At: 6})
+	Fire(hook, &BranchKilledEvent{Origin: FromParser, At: 8, Reason: errors.New("no accept")})
+
+	if buff.String() != Expected {
+		t.Errorf("WriterHook =\n%s, want\n%s", buff.String(), Expected)
+	}
+}
+
+func TestFilterMulti(t *testing.T) {
+	all := NewRecorder()
+	shifts := NewRecorder()
+
+	hook := Multi(all, nil, Filter(shifts, KindShift))
+
+	Fire(hook, &ShiftEvent{Type: "word", At: 0})
+	Fire(hook, &ReduceEvent{Rule: "Source -> word", At: 0})
+	Fire(hook, &AcceptEvent{Rule: "Source -> word"})
+
+	if len(all.Events()) != 3 {
+		t.Errorf("len(all.Events()) = %d, want %d", len(all.Events()), 3)
+	}
+
+	if shifts.Count(KindShift) != 1 || len(shifts.Events()) != 1 {
+		t.Errorf("shifts.Events() = %v, want only the shift", shifts.Events())
+	}
+
+	all.Reset()
+
+	if len(all.Events()) != 0 {
+		t.Errorf("len(all.Events()) = %d, want %d", len(all.Events()), 0)
+	}
+}
+
+func TestSlogHook(t *testing.T) {
+	var buff bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buff, nil))
+
+	Fire(NewSlogHook(logger, slog.LevelInfo), &TokenMatchedEvent{Type: "word", Data: "Hello", At: 0})
+
+	str := buff.String()
+
+	if !strings.Contains(str, `msg="token matched" type=word data=Hello at=0`) {
+		t.Errorf("SlogHook = %s", str)
+	}
+}
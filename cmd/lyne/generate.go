@@ -0,0 +1,366 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	gen "github.com/PlayerR9/LyneParser/Generator"
+	gr "github.com/PlayerR9/LyneParser/Grammar"
+	ps "github.com/PlayerR9/LyneParser/Parser"
+	prd "github.com/PlayerR9/LyneParser/Production"
+)
+
+// symbol is a symbol of the grammar being generated. The terminals come
+// first, starting with EOF, in the order of the lexer rules; then the
+// non-terminals in the order of their first production.
+type symbol int
+
+var (
+	// symbol_names are the names of the symbols, by value.
+	symbol_names []string
+
+	// terminal_count is the number of terminals, EOF included.
+	terminal_count int
+)
+
+// String implements the fmt.Stringer interface.
+func (s symbol) String() string {
+	return symbol_names[s]
+}
+
+// IsTerminal implements the gr.TokenTyper interface.
+func (s symbol) IsTerminal() bool {
+	return int(s) < terminal_count
+}
+
+// ident is a helper function that returns the Go identifier of a symbol:
+// "Tk" followed by its name in camel case, such as TkOpParen for OP_PAREN.
+//
+// Parameters:
+//   - name: The name of the symbol.
+//
+// Returns:
+//   - string: The identifier.
+func ident(name string) string {
+	var builder strings.Builder
+
+	builder.WriteString("Tk")
+
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+
+		first, size := utf8.DecodeRuneInString(part)
+		rest := part[size:]
+
+		if strings.ToUpper(part) == part {
+			rest = strings.ToLower(rest)
+		}
+
+		builder.WriteRune(unicode.ToUpper(first))
+		builder.WriteString(rest)
+	}
+
+	return builder.String()
+}
+
+// is_terminal_name is a helper function that checks whether a name is the
+// name of a terminal.
+//
+// Parameters:
+//   - name: The name.
+//
+// Returns:
+//   - bool: True if the name starts with an upper-case letter.
+func is_terminal_name(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+// number_symbols is a helper function that fills the symbol table with the
+// symbols of a grammar file and checks that they are all defined.
+//
+// Parameters:
+//   - f: The grammar file.
+//
+// Returns:
+//   - map[string]symbol: The symbols, by name.
+//   - error: An error if a symbol is reserved, not defined or has the same
+//     identifier as another one.
+func number_symbols(f *prd.File) (map[string]symbol, error) {
+	ids := map[string]symbol{gr.EOFTokenID: 0}
+	symbol_names = []string{gr.EOFTokenID}
+
+	add := func(name string) {
+		_, ok := ids[name]
+		if !ok {
+			ids[name] = symbol(len(symbol_names))
+			symbol_names = append(symbol_names, name)
+		}
+	}
+
+	for _, rule := range f.LexerRules {
+		if rule.Name == gr.EOFTokenID {
+			return nil, prd.NewErrSyntax(rule.Line, errors.New(strconv.Quote(gr.EOFTokenID)+" is reserved"))
+		}
+
+		add(rule.Name)
+	}
+
+	terminal_count = len(symbol_names)
+
+	for _, rule := range f.Rules {
+		add(rule.Lhs)
+	}
+
+	for _, name := range f.ToSkip {
+		s, ok := ids[name]
+		if !ok || !s.IsTerminal() {
+			return nil, fmt.Errorf("no lexer rule for %s to skip", name)
+		}
+	}
+
+	for _, rule := range f.Rules {
+		for _, name := range rule.Rhs {
+			_, ok := ids[name]
+			if ok {
+				continue
+			}
+
+			if is_terminal_name(name) {
+				return nil, prd.NewErrSyntax(rule.Line, errors.New("no lexer rule for "+name))
+			}
+
+			return nil, prd.NewErrSyntax(rule.Line, errors.New("no production for "+name))
+		}
+	}
+
+	s, ok := ids[gr.StartSymbolID]
+	if !ok || s.IsTerminal() {
+		return nil, errors.New("missing a production for " + gr.StartSymbolID)
+	}
+
+	seen := make(map[string]string, len(symbol_names))
+
+	for _, name := range symbol_names {
+		id := ident(name)
+
+		other, ok := seen[id]
+		if ok {
+			return nil, fmt.Errorf("%s and %s are both %s", other, name, id)
+		}
+
+		seen[id] = name
+	}
+
+	return ids, nil
+}
+
+// go_string is a helper function that quotes a string as a Go literal,
+// preferring a raw string.
+//
+// Parameters:
+//   - str: The string.
+//
+// Returns:
+//   - string: The literal.
+func go_string(str string) string {
+	if strconv.CanBackquote(str) {
+		return "`" + str + "`"
+	}
+
+	return strconv.Quote(str)
+}
+
+// tokens_source is a helper function that writes the source of the type of
+// the symbols and of the lexer of a grammar file.
+//
+// Parameters:
+//   - f: The grammar file.
+//   - pkg: The name of the package.
+//   - ids: The symbols, by name.
+//
+// Returns:
+//   - []byte: The formatted source.
+//   - error: An error if the source cannot be formatted.
+func tokens_source(f *prd.File, pkg string, ids map[string]symbol) ([]byte, error) {
+	var builder strings.Builder
+
+	builder.WriteString("// Code generated by lyne generate. DO NOT EDIT.\n\n")
+	builder.WriteString("package " + pkg + "\n\n")
+	builder.WriteString("import (\n\tlx \"github.com/PlayerR9/LyneParser/Lexer\"\n)\n\n")
+
+	builder.WriteString("// TokenType is a symbol of the grammar.\ntype TokenType int\n\nconst (\n")
+
+	for i, name := range symbol_names {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+
+		builder.WriteString("\t// " + ident(name) + " is the symbol " + strconv.Quote(name) + ".\n")
+		builder.WriteString("\t" + ident(name))
+
+		if i == 0 {
+			builder.WriteString(" TokenType = iota")
+		}
+
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString(")\n\n// String implements the fmt.Stringer interface.\nfunc (t TokenType) String() string {\n\treturn [...]string{\n")
+
+	for _, name := range symbol_names {
+		builder.WriteString("\t\t" + strconv.Quote(name) + ",\n")
+	}
+
+	builder.WriteString("\t}[t]\n}\n\n")
+	builder.WriteString("// IsTerminal implements the gr.TokenTyper interface.\nfunc (t TokenType) IsTerminal() bool {\n")
+	builder.WriteString("\treturn t <= " + ident(symbol_names[terminal_count-1]) + "\n}\n\n")
+
+	builder.WriteString(`// NewLexer creates the lexer of the grammar. The rules are in the order of
+// the grammar file.
+//
+// Returns:
+//   - *lx.Lexer: The lexer. Nil if an error occurred.
+//   - error: An error if a regular expression does not compile.
+func NewLexer() (*lx.Lexer[TokenType], error) {
+`)
+
+	skipped := make([]string, 0, len(f.ToSkip))
+
+	for _, name := range f.ToSkip {
+		skipped = append(skipped, ident(name))
+	}
+
+	builder.WriteString("\tgrammar := lx.NewGrammar([]TokenType{" + strings.Join(skipped, ", ") + "})\n\n")
+
+	if len(f.LexerRules) > 0 {
+		builder.WriteString("\tvar err error\n\n")
+	}
+
+	for _, rule := range f.LexerRules {
+		id := ident(symbol_names[ids[rule.Name]])
+
+		if rule.Quoted {
+			builder.WriteString("\terr = grammar.AddLiteral(" + id + ", " + go_string(rule.Value) + ", false)\n")
+		} else {
+			builder.WriteString("\terr = grammar.AddRule(" + id + ", " + go_string(rule.Value) + ")\n")
+		}
+
+		builder.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n\n")
+	}
+
+	builder.WriteString("\treturn lx.NewLexer(grammar), nil\n}\n")
+
+	return format.Source([]byte(builder.String()))
+}
+
+// sources is a helper function that generates the files of a grammar file.
+//
+// Parameters:
+//   - f: The grammar file.
+//   - pkg: The name of the package.
+//
+// Returns:
+//   - []byte: The source of tokens.go.
+//   - []byte: The source of parser.go.
+//   - error: An error if the grammar file is invalid or a source cannot be
+//     generated.
+func sources(f *prd.File, pkg string) ([]byte, []byte, error) {
+	ids, err := number_symbols(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens_src, err := tokens_source(f, pkg, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	grammar, err := ps.NewGrammar[symbol]()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, rule := range f.Rules {
+		rhs := make([]symbol, 0, len(rule.Rhs))
+
+		for _, name := range rule.Rhs {
+			rhs = append(rhs, ids[name])
+		}
+
+		err := grammar.AddRule(ids[rule.Lhs], rhs)
+		if err != nil {
+			return nil, nil, prd.NewErrSyntax(rule.Line, err)
+		}
+	}
+
+	parser_src, err := gen.Generate(grammar, nil, gen.Config[symbol]{
+		Package:   pkg,
+		TokenType: "TokenType",
+		SymbolName: func(s symbol) string {
+			return ident(s.String())
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens_src, parser_src, nil
+}
+
+// generate runs the generate command.
+//
+// Parameters:
+//   - args: The arguments after the name of the command.
+//
+// Returns:
+//   - error: An error if the grammar file is invalid or the files cannot be
+//     written.
+func generate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+
+	out := fs.String("o", ".", "the directory of the generated files")
+	pkg := fs.String("package", "", "the package of the generated files (default: the name of the directory)")
+
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected one grammar file, got %d", fs.NArg())
+	}
+
+	if *pkg == "" {
+		dir, err := filepath.Abs(*out)
+		if err != nil {
+			return err
+		}
+
+		*pkg = filepath.Base(dir)
+	}
+
+	f, err := prd.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	tokens_src, parser_src, err := sources(f, *pkg)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(*out, "tokens.go"), tokens_src, 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(*out, "parser.go"), parser_src, 0o644)
+}
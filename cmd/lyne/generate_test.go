@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	prd "github.com/PlayerR9/LyneParser/Production"
+)
+
+func TestGenerateProduction(t *testing.T) {
+	dir := filepath.Join("..", "..", "Production")
+
+	f, err := prd.Load(filepath.Join(dir, "meta.grammar"))
+	if err != nil {
+		t.Fatalf("Load failed: %s", err.Error())
+	}
+
+	tokens_src, parser_src, err := sources(f, "Production")
+	if err != nil {
+		t.Fatalf("sources failed: %s", err.Error())
+	}
+
+	// The checked-in files are those that go generate writes.
+	files := []struct {
+		name string
+		src  []byte
+	}{
+		{"tokens.go", tokens_src},
+		{"parser.go", parser_src},
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, file.name))
+		if err != nil {
+			t.Fatalf("ReadFile failed: %s", err.Error())
+		}
+
+		if !bytes.Equal(data, file.src) {
+			t.Errorf("Production/%s does not match meta.grammar; run go generate", file.name)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Command lyne is the command-line tool of LyneParser.
+//
+// Usage:
+//
+//	lyne play [-theme theme.json] [grammar file]
+//	lyne generate [-o dir] [-package name] grammar file
+//
+// The play command opens a terminal playground to develop a grammar: the
+// grammar file is loaded in one pane, the input is typed in another, and the
+// last one shows the tokens, the highlighted input and the parse tree as the
+// input changes. See Playground.ParseLanguage for the format of the grammar
+// files and Highlighter.ParseTheme for the one of the themes.
+//
+// The generate command writes the lexer and the parser of a grammar file as
+// Go code, so that they need neither the grammar file nor the conflict
+// solver at run time: tokens.go declares the TokenType of the symbols and
+// NewLexer, and parser.go the parser of Generator.Generate. The loader of
+// the grammar files in the Production package is generated this way from
+// its own grammar.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	hl "github.com/PlayerR9/LyneParser/Highlighter"
+	pg "github.com/PlayerR9/LyneParser/Playground"
+)
+
+// usage is the help of the command.
+const usage string = `usage: lyne <command> [arguments]
+
+commands:
+  play [-theme theme.json] [grammar file]
+        open the grammar playground
+  generate [-o dir] [-package name] grammar file
+        write the lexer and the parser of a grammar as Go code
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "play":
+		err := play(os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "lyne play:", err)
+			os.Exit(1)
+		}
+	case "generate":
+		err := generate(os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "lyne generate:", err)
+			os.Exit(1)
+		}
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "lyne: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+// play runs the play command.
+//
+// Parameters:
+//   - args: The arguments after the name of the command.
+//
+// Returns:
+//   - error: An error if the theme cannot be loaded or the terminal cannot
+//     be set up.
+func play(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+
+	theme_loc := fs.String("theme", "", "the JSON theme of the highlighted input")
+
+	fs.Parse(args)
+
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected at most one grammar file, got %d", fs.NArg())
+	}
+
+	var theme *hl.Theme
+
+	if *theme_loc != "" {
+		var err error
+
+		theme, err = hl.LoadTheme(*theme_loc)
+		if err != nil {
+			return err
+		}
+	}
+
+	app := pg.NewApp(fs.Arg(0), theme)
+
+	return app.Run()
+}
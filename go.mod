@@ -7,7 +7,7 @@ require (
 	github.com/PlayerR9/tree v0.1.7
 )
 
-require github.com/PlayerR9/stack v0.1.2 // indirect
+require github.com/PlayerR9/stack v0.1.2
 
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect